@@ -0,0 +1,145 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/piusalfred/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+// keyService is a registry.Service that only authenticates api keys, the
+// other methods are not used by the middlewares under test
+type keyService struct {
+	registry.Service
+	keys map[string]registry.APIKey
+}
+
+func (s keyService) AuthAPIKey(_ context.Context, plain string) (registry.APIKey, error) {
+	key, ok := s.keys[plain]
+	if !ok {
+		return registry.APIKey{}, registry.ErrInvalidAPIKey
+	}
+
+	return key, nil
+}
+
+func TestAuthenticate(t *testing.T) {
+	svc := keyService{keys: map[string]registry.APIKey{
+		"admin.secret":  {ID: "admin", Role: int(registry.Admin)},
+		"reader.secret": {ID: "reader", Role: int(registry.RegionUser), Region: "AA001"},
+	}}
+
+	var got registry.APIKey
+	var found bool
+	h := authenticate(svc)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, found = registry.APIKeyFromContext(r.Context())
+	}))
+
+	cases := []struct {
+		desc   string
+		method string
+		auth   string
+		code   int
+		key    string
+	}{
+		{desc: "no key", method: http.MethodGet, code: http.StatusOK},
+		{desc: "valid key", method: http.MethodPost, auth: "Bearer admin.secret", code: http.StatusOK, key: "admin"},
+		{desc: "unknown key", method: http.MethodGet, auth: "Bearer admin.guess", code: http.StatusUnauthorized},
+		{desc: "read-only key reading", method: http.MethodGet, auth: "Bearer reader.secret", code: http.StatusOK, key: "reader"},
+		{desc: "read-only key writing", method: http.MethodDelete, auth: "Bearer reader.secret", code: http.StatusForbidden},
+		{desc: "not a bearer token", method: http.MethodGet, auth: "Basic admin.secret", code: http.StatusOK},
+	}
+
+	for _, tc := range cases {
+		got, found = registry.APIKey{}, false
+
+		r := httptest.NewRequest(tc.method, "/nodes", nil)
+		if tc.auth != "" {
+			r.Header.Set("Authorization", tc.auth)
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		assert.Equal(t, tc.code, w.Code, tc.desc)
+		assert.Equal(t, tc.key != "", found, tc.desc)
+		assert.Equal(t, tc.key, got.ID, tc.desc)
+	}
+}
+
+func TestRequireAPIKey(t *testing.T) {
+	h := RequireAPIKey(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	cases := []struct {
+		desc   string
+		method string
+		path   string
+		auth   string
+		code   int
+	}{
+		{desc: "key", method: http.MethodGet, path: "/nodes", auth: "Bearer admin.secret", code: http.StatusOK},
+		{desc: "no key", method: http.MethodGet, path: "/nodes", code: http.StatusUnauthorized},
+		{desc: "provisioning", method: http.MethodPost, path: "/provision", code: http.StatusOK},
+		{desc: "node authentication", method: http.MethodPost, path: "/nodes/authenticate", code: http.StatusOK},
+		{desc: "crl", method: http.MethodGet, path: "/certs/crl", code: http.StatusOK},
+		{desc: "docs", method: http.MethodGet, path: "/docs", code: http.StatusOK},
+		{desc: "writing a public path", method: http.MethodDelete, path: "/certs/crl", code: http.StatusUnauthorized},
+	}
+
+	for _, tc := range cases {
+		r := httptest.NewRequest(tc.method, tc.path, nil)
+		if tc.auth != "" {
+			r.Header.Set("Authorization", tc.auth)
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		assert.Equal(t, tc.code, w.Code, tc.desc)
+	}
+}
+
+func TestOpenAccess(t *testing.T) {
+	var (
+		exist bool
+		calls int
+	)
+	h := OpenAccess(func(context.Context) (bool, error) {
+		calls++
+		return exist, nil
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	serve := func(auth string) int {
+		r := httptest.NewRequest(http.MethodGet, "/nodes", nil)
+		if auth != "" {
+			r.Header.Set("Authorization", auth)
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve(""), "request without a key before any key exists")
+	assert.Equal(t, http.StatusOK, serve("Bearer admin.secret"))
+	assert.Equal(t, 1, calls, "keys looked up for a request with a key")
+
+	//the first key is created at runtime
+	exist = true
+	assert.Equal(t, http.StatusUnauthorized, serve(""), "request without a key once a key exists")
+	assert.Equal(t, http.StatusOK, serve("Bearer admin.secret"))
+
+	//revoking every key does not open the registry again
+	exist = false
+	assert.Equal(t, http.StatusUnauthorized, serve(""))
+	assert.Equal(t, 2, calls, "keys looked up once they exist")
+
+	r := httptest.NewRequest(http.MethodPost, "/provision", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code, "provisioning without a key")
+}
@@ -2,12 +2,15 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	endpoint "github.com/go-kit/kit/endpoint"
 	kithttp "github.com/go-kit/kit/transport/http"
 	registry "github.com/piusalfred/registry"
 	http1 "net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // Endpoints collects all of the endpoints that compose a profile service. It's
@@ -27,6 +30,66 @@ type Endpoints struct {
 	UpdateNodeEndpoint  endpoint.Endpoint
 	AddRegionEndpoint   endpoint.Endpoint
 	ListRegionsEndpoint endpoint.Endpoint
+
+	SuggestRegionEndpoint   endpoint.Endpoint
+	MoveRegionEndpoint      endpoint.Endpoint
+	RegionSubtreeEndpoint   endpoint.Endpoint
+	RegionAncestorsEndpoint endpoint.Endpoint
+
+	AddNodeTypeEndpoint    endpoint.Endpoint
+	GetNodeTypeEndpoint    endpoint.Endpoint
+	ListNodeTypesEndpoint  endpoint.Endpoint
+	DeleteNodeTypeEndpoint endpoint.Endpoint
+	UpdateNodeTypeEndpoint endpoint.Endpoint
+
+	CreateClaimCodeEndpoint  endpoint.Endpoint
+	ListClaimCodesEndpoint   endpoint.Endpoint
+	ProvisionNodeEndpoint    endpoint.Endpoint
+	AuthenticateNodeEndpoint endpoint.Endpoint
+	ListClaimsEndpoint       endpoint.Endpoint
+
+	IssueCertificateEndpoint  endpoint.Endpoint
+	RevokeCertificateEndpoint endpoint.Endpoint
+	ListCertificatesEndpoint  endpoint.Endpoint
+
+	RevocationListEndpoint endpoint.Endpoint
+
+	CreateAPIKeyEndpoint endpoint.Endpoint
+	ListAPIKeysEndpoint  endpoint.Endpoint
+	RevokeAPIKeyEndpoint endpoint.Endpoint
+	AuthAPIKeyEndpoint   endpoint.Endpoint
+
+	AddOrganizationEndpoint    endpoint.Endpoint
+	GetOrganizationEndpoint    endpoint.Endpoint
+	ListOrganizationsEndpoint  endpoint.Endpoint
+	DeleteOrganizationEndpoint endpoint.Endpoint
+
+	AddFirmwareEndpoint     endpoint.Endpoint
+	GetFirmwareEndpoint     endpoint.Endpoint
+	ListFirmwareEndpoint    endpoint.Endpoint
+	DeleteFirmwareEndpoint  endpoint.Endpoint
+	CreateCampaignEndpoint  endpoint.Endpoint
+	GetCampaignEndpoint     endpoint.Endpoint
+	ListCampaignsEndpoint   endpoint.Endpoint
+	AdvanceCampaignEndpoint endpoint.Endpoint
+	ListRolloutsEndpoint    endpoint.Endpoint
+	ReportRolloutEndpoint   endpoint.Endpoint
+
+	AddSchemaEndpoint       endpoint.Endpoint
+	GetSchemaEndpoint       endpoint.Endpoint
+	ListSchemasEndpoint     endpoint.Endpoint
+	NodeSchemaEndpoint      endpoint.Endpoint
+	ValidatePayloadEndpoint endpoint.Endpoint
+
+	SetQuotaEndpoint   endpoint.Endpoint
+	ListQuotasEndpoint endpoint.Endpoint
+	UsageEndpoint      endpoint.Endpoint
+
+	InventoryEndpoint     endpoint.Endpoint
+	GrowthEndpoint        endpoint.Endpoint
+	OrphanedNodesEndpoint endpoint.Endpoint
+
+	WatchEndpoint endpoint.Endpoint
 }
 
 // NewServerEndpoints returns a Endpoints struct that wraps the provided service, and wires in all of the
@@ -46,6 +109,65 @@ func MakeServerEndpoints(s registry.Service) Endpoints {
 		ListUserEndpoint:    MakeListUserEndpoint(s),
 		UpdateNodeEndpoint:  MakeUpdateNodeEndpoint(s),
 		UpdateUserEndpoint:  MakeUpdateUserEndpoint(s),
+
+		SuggestRegionEndpoint:   MakeSuggestRegionEndpoint(s),
+		MoveRegionEndpoint:      MakeMoveRegionEndpoint(s),
+		RegionSubtreeEndpoint:   MakeRegionSubtreeEndpoint(s),
+		RegionAncestorsEndpoint: MakeRegionAncestorsEndpoint(s),
+
+		AddNodeTypeEndpoint:    MakeAddNodeTypeEndpoint(s),
+		GetNodeTypeEndpoint:    MakeGetNodeTypeEndpoint(s),
+		ListNodeTypesEndpoint:  MakeListNodeTypesEndpoint(s),
+		DeleteNodeTypeEndpoint: MakeDeleteNodeTypeEndpoint(s),
+		UpdateNodeTypeEndpoint: MakeUpdateNodeTypeEndpoint(s),
+
+		CreateClaimCodeEndpoint:  MakeCreateClaimCodeEndpoint(s),
+		ListClaimCodesEndpoint:   MakeListClaimCodesEndpoint(s),
+		ProvisionNodeEndpoint:    MakeProvisionNodeEndpoint(s),
+		AuthenticateNodeEndpoint: MakeAuthenticateNodeEndpoint(s),
+		ListClaimsEndpoint:       MakeListClaimsEndpoint(s),
+
+		IssueCertificateEndpoint:  MakeIssueCertificateEndpoint(s),
+		RevokeCertificateEndpoint: MakeRevokeCertificateEndpoint(s),
+		ListCertificatesEndpoint:  MakeListCertificatesEndpoint(s),
+
+		RevocationListEndpoint: MakeRevocationListEndpoint(s),
+
+		CreateAPIKeyEndpoint: MakeCreateAPIKeyEndpoint(s),
+		ListAPIKeysEndpoint:  MakeListAPIKeysEndpoint(s),
+		RevokeAPIKeyEndpoint: MakeRevokeAPIKeyEndpoint(s),
+		AuthAPIKeyEndpoint:   MakeAuthAPIKeyEndpoint(s),
+
+		AddOrganizationEndpoint:    MakeAddOrganizationEndpoint(s),
+		GetOrganizationEndpoint:    MakeGetOrganizationEndpoint(s),
+		ListOrganizationsEndpoint:  MakeListOrganizationsEndpoint(s),
+		DeleteOrganizationEndpoint: MakeDeleteOrganizationEndpoint(s),
+
+		AddFirmwareEndpoint:     MakeAddFirmwareEndpoint(s),
+		GetFirmwareEndpoint:     MakeGetFirmwareEndpoint(s),
+		ListFirmwareEndpoint:    MakeListFirmwareEndpoint(s),
+		DeleteFirmwareEndpoint:  MakeDeleteFirmwareEndpoint(s),
+		CreateCampaignEndpoint:  MakeCreateCampaignEndpoint(s),
+		GetCampaignEndpoint:     MakeGetCampaignEndpoint(s),
+		ListCampaignsEndpoint:   MakeListCampaignsEndpoint(s),
+		AdvanceCampaignEndpoint: MakeAdvanceCampaignEndpoint(s),
+		ListRolloutsEndpoint:    MakeListRolloutsEndpoint(s),
+		ReportRolloutEndpoint:   MakeReportRolloutEndpoint(s),
+
+		AddSchemaEndpoint:       MakeAddSchemaEndpoint(s),
+		GetSchemaEndpoint:       MakeGetSchemaEndpoint(s),
+		ListSchemasEndpoint:     MakeListSchemasEndpoint(s),
+		NodeSchemaEndpoint:      MakeNodeSchemaEndpoint(s),
+		ValidatePayloadEndpoint: MakeValidatePayloadEndpoint(s),
+
+		SetQuotaEndpoint:      MakeSetQuotaEndpoint(s),
+		ListQuotasEndpoint:    MakeListQuotasEndpoint(s),
+		UsageEndpoint:         MakeUsageEndpoint(s),
+		InventoryEndpoint:     MakeInventoryEndpoint(s),
+		GrowthEndpoint:        MakeGrowthEndpoint(s),
+		OrphanedNodesEndpoint: MakeOrphanedNodesEndpoint(s),
+
+		WatchEndpoint: MakeWatchEndpoint(s),
 	}
 
 }
@@ -53,7 +175,7 @@ func MakeServerEndpoints(s registry.Service) Endpoints {
 // MakeClientEndpoints returns an Endpoints struct where each endpoint invokes
 // the corresponding method on the remote instance, via a transport/http.Client.
 // Useful in a registry client.
-func MakeClientEndpoints(instance string) (Endpoints, error) {
+func MakeClientEndpoints(instance string, options ...kithttp.ClientOption) (Endpoints, error) {
 	if !strings.HasPrefix(instance, "http") {
 		instance = "http://" + instance
 	}
@@ -63,8 +185,6 @@ func MakeClientEndpoints(instance string) (Endpoints, error) {
 	}
 	tgt.Path = ""
 
-	options := []kithttp.ClientOption{}
-
 	var authUserEndpoint endpoint.Endpoint
 	{
 		authUserEndpoint = kithttp.NewClient(
@@ -92,6 +212,7 @@ func MakeClientEndpoints(instance string) (Endpoints, error) {
 			tgt,
 			encodeAddUserRequest,
 			decodeAddUserResponse,
+			options...,
 		).Endpoint()
 	}
 
@@ -102,6 +223,7 @@ func MakeClientEndpoints(instance string) (Endpoints, error) {
 			tgt,
 			encodeListUserRequest,
 			decodeListUserResponse,
+			options...,
 		).Endpoint()
 	}
 
@@ -112,6 +234,7 @@ func MakeClientEndpoints(instance string) (Endpoints, error) {
 			tgt,
 			encodeDeleteUserRequest,
 			decodeDeleteUserResponse,
+			options...,
 		).Endpoint()
 	}
 
@@ -122,6 +245,7 @@ func MakeClientEndpoints(instance string) (Endpoints, error) {
 			tgt,
 			encodeUpdateUserRequest,
 			decodeUpdateUserResponse,
+			options...,
 		).Endpoint()
 	}
 
@@ -132,6 +256,7 @@ func MakeClientEndpoints(instance string) (Endpoints, error) {
 			tgt,
 			encodeAddRegionRequest,
 			decodeAddRegionResponse,
+			options...,
 		).Endpoint()
 	}
 
@@ -142,6 +267,51 @@ func MakeClientEndpoints(instance string) (Endpoints, error) {
 			tgt,
 			encodeListRegionsRequest,
 			decodeListRegionsResponse,
+			options...,
+		).Endpoint()
+	}
+
+	var suggestRegionEndpoint endpoint.Endpoint
+	{
+		suggestRegionEndpoint = kithttp.NewClient(
+			http1.MethodGet,
+			tgt,
+			encodeSuggestRegionRequest,
+			decodeSuggestRegionResponse,
+			options...,
+		).Endpoint()
+	}
+
+	var moveRegionEndpoint endpoint.Endpoint
+	{
+		moveRegionEndpoint = kithttp.NewClient(
+			http1.MethodPut,
+			tgt,
+			encodeMoveRegionRequest,
+			decodeMoveRegionResponse,
+			options...,
+		).Endpoint()
+	}
+
+	var regionSubtreeEndpoint endpoint.Endpoint
+	{
+		regionSubtreeEndpoint = kithttp.NewClient(
+			http1.MethodGet,
+			tgt,
+			encodeRegionSubtreeRequest,
+			decodeRegionSubtreeResponse,
+			options...,
+		).Endpoint()
+	}
+
+	var regionAncestorsEndpoint endpoint.Endpoint
+	{
+		regionAncestorsEndpoint = kithttp.NewClient(
+			http1.MethodGet,
+			tgt,
+			encodeRegionAncestorsRequest,
+			decodeRegionAncestorsResponse,
+			options...,
 		).Endpoint()
 	}
 
@@ -162,6 +332,7 @@ func MakeClientEndpoints(instance string) (Endpoints, error) {
 			tgt,
 			encodeAddNodeRequest,
 			decodeAddNodeResponse,
+			options...,
 		).Endpoint()
 	}
 
@@ -172,6 +343,7 @@ func MakeClientEndpoints(instance string) (Endpoints, error) {
 			tgt,
 			encodeListNodeRequest,
 			decodeListNodesResponse,
+			options...,
 		).Endpoint()
 	}
 
@@ -182,6 +354,7 @@ func MakeClientEndpoints(instance string) (Endpoints, error) {
 			tgt,
 			encodeDeleteNodeRequest,
 			decodeDeleteNodeResponse,
+			options...,
 		).Endpoint()
 	}
 
@@ -192,453 +365,2439 @@ func MakeClientEndpoints(instance string) (Endpoints, error) {
 			tgt,
 			encodeUpdateNodeRequest,
 			decodeUpdateNodeResponse,
+			options...,
 		).Endpoint()
 	}
 
-	// Note that the request encoders need to modify the request URL, changing
-	// the path. That's fine: we simply need to provide specific encoders for
-	// each endpoint.
-
-	return Endpoints{
-		AuthUserEndpoint:    authUserEndpoint,
-		GetUserEndpoint:     getUserEndpoint,
-		AddUserEndpoint:     addUserEndpoint,
-		ListUserEndpoint:    listUserEndpoint,
-		DeleteUserEndpoint:  deleteUserEndpoint,
-		UpdateUserEndpoint:  updateUserEndpoint,
-		AddNodeEndpoint:     addNodeEndpoint,
-		GetNodeEndpoint:     getNodeEndpoint,
-		ListNodesEndpoint:   listNodeEndpoint,
-		DeleteNodeEndpoint:  deleteNodeEndpoint,
-		UpdateNodeEndpoint:  updateNodeEndpoint,
-		AddRegionEndpoint:   addRegionEndpoint,
-		ListRegionsEndpoint: listRegionsEndpoint,
-	}, nil
+	var addNodeTypeEndpoint endpoint.Endpoint
+	{
+		addNodeTypeEndpoint = kithttp.NewClient(
+			http1.MethodPost,
+			tgt,
+			encodeAddNodeTypeRequest,
+			decodeAddNodeTypeResponse,
+			options...,
+		).Endpoint()
+	}
 
-}
+	var getNodeTypeEndpoint endpoint.Endpoint
+	{
+		getNodeTypeEndpoint = kithttp.NewClient(
+			http1.MethodGet,
+			tgt,
+			encodeGetNodeTypeRequest,
+			decodeGetNodeTypeResponse,
+			options...,
+		).Endpoint()
+	}
 
-func encodeUpdateNodeRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	var listNodeTypesEndpoint endpoint.Endpoint
+	{
+		listNodeTypesEndpoint = kithttp.NewClient(
+			http1.MethodGet,
+			tgt,
+			encodeListNodeTypesRequest,
+			decodeListNodeTypesResponse,
+			options...,
+		).Endpoint()
+	}
 
-	r := request.(UpdateNodeRequest)
-	nodeID := url.QueryEscape(r.Id)
-	req.URL.Path = "/users/" + nodeID
-	return encodeRequest(ctx, req, request)
-}
+	var deleteNodeTypeEndpoint endpoint.Endpoint
+	{
+		deleteNodeTypeEndpoint = kithttp.NewClient(
+			http1.MethodDelete,
+			tgt,
+			encodeDeleteNodeTypeRequest,
+			decodeDeleteNodeTypeResponse,
+			options...,
+		).Endpoint()
+	}
 
-func encodeDeleteNodeRequest(ctx context.Context, req *http1.Request, request interface{}) error {
-	r := request.(DeleteNodeRequest)
-	nodeID := url.QueryEscape(r.Id)
-	req.URL.Path = "/users/" + nodeID
-	return encodeRequest(ctx, req, request)
-}
+	var updateNodeTypeEndpoint endpoint.Endpoint
+	{
+		updateNodeTypeEndpoint = kithttp.NewClient(
+			http1.MethodPatch,
+			tgt,
+			encodeUpdateNodeTypeRequest,
+			decodeUpdateNodeTypeResponse,
+			options...,
+		).Endpoint()
+	}
 
-func encodeListNodeRequest(ctx context.Context, req *http1.Request, request interface{}) error {
-	req.URL.Path = "/nodes"
-	return encodeRequest(ctx, req, request)
-}
+	var createClaimCodeEndpoint endpoint.Endpoint
+	{
+		createClaimCodeEndpoint = kithttp.NewClient(
+			http1.MethodPost,
+			tgt,
+			encodeCreateClaimCodeRequest,
+			decodeCreateClaimCodeResponse,
+			options...,
+		).Endpoint()
+	}
 
-func encodeAddNodeRequest(ctx context.Context, req *http1.Request, request interface{}) error {
-	req.URL.Path = "/nodes"
-	return encodeRequest(ctx, req, request)
-}
+	var listClaimCodesEndpoint endpoint.Endpoint
+	{
+		listClaimCodesEndpoint = kithttp.NewClient(
+			http1.MethodGet,
+			tgt,
+			encodeListClaimCodesRequest,
+			decodeListClaimCodesResponse,
+			options...,
+		).Endpoint()
+	}
 
-func encodeGetNodeRequest(ctx context.Context, req *http1.Request, request interface{}) error {
-	r := request.(GetNodeRequest)
-	nodeID := url.QueryEscape(r.Id)
-	req.URL.Path = "/nodes/" + nodeID
-	return encodeRequest(ctx, req, request)
-}
+	var provisionNodeEndpoint endpoint.Endpoint
+	{
+		provisionNodeEndpoint = kithttp.NewClient(
+			http1.MethodPost,
+			tgt,
+			encodeProvisionNodeRequest,
+			decodeProvisionNodeResponse,
+			options...,
+		).Endpoint()
+	}
 
-func encodeListRegionsRequest(ctx context.Context, req *http1.Request, request interface{}) error {
-	req.URL.Path = "/regions"
-	return encodeRequest(ctx, req, request)
-}
+	var authenticateNodeEndpoint endpoint.Endpoint
+	{
+		authenticateNodeEndpoint = kithttp.NewClient(
+			http1.MethodPost,
+			tgt,
+			encodeAuthenticateNodeRequest,
+			decodeAuthenticateNodeResponse,
+			options...,
+		).Endpoint()
+	}
 
-func encodeAddRegionRequest(ctx context.Context, req *http1.Request, request interface{}) error {
-	req.URL.Path = "/regions"
-	return encodeRequest(ctx, req, request)
-}
+	var listClaimsEndpoint endpoint.Endpoint
+	{
+		listClaimsEndpoint = kithttp.NewClient(
+			http1.MethodGet,
+			tgt,
+			encodeListClaimsRequest,
+			decodeListClaimsResponse,
+			options...,
+		).Endpoint()
+	}
 
-func encodeUpdateUserRequest(ctx context.Context, req *http1.Request, request interface{}) error {
-	// r.Methods("PATCH").Path("/users/{id}")
-	r := request.(UpdateUserRequest)
-	userID := url.QueryEscape(r.Id)
-	req.URL.Path = "/users/" + userID
-	return encodeRequest(ctx, req, request)
-}
+	var issueCertificateEndpoint endpoint.Endpoint
+	{
+		issueCertificateEndpoint = kithttp.NewClient(
+			http1.MethodPost,
+			tgt,
+			encodeIssueCertificateRequest,
+			decodeIssueCertificateResponse,
+			options...,
+		).Endpoint()
+	}
 
-func encodeDeleteUserRequest(ctx context.Context, req *http1.Request, request interface{}) error {
-	// r.Methods("DELETE").Path("/users/{id}")
-	r := request.(DeleteUserRequest)
-	userID := url.QueryEscape(r.Id)
-	req.URL.Path = "/users/" + userID
-	return encodeRequest(ctx, req, request)
-}
+	var revokeCertificateEndpoint endpoint.Endpoint
+	{
+		revokeCertificateEndpoint = kithttp.NewClient(
+			http1.MethodPost,
+			tgt,
+			encodeRevokeCertificateRequest,
+			decodeRevokeCertificateResponse,
+			options...,
+		).Endpoint()
+	}
 
-func encodeListUserRequest(ctx context.Context, req *http1.Request, request interface{}) error {
-	req.URL.Path = "/users"
-	return encodeRequest(ctx, req, request)
-}
+	var listCertificatesEndpoint endpoint.Endpoint
+	{
+		listCertificatesEndpoint = kithttp.NewClient(
+			http1.MethodGet,
+			tgt,
+			encodeListCertificatesRequest,
+			decodeListCertificatesResponse,
+			options...,
+		).Endpoint()
+	}
 
-func encodeAddUserRequest(ctx context.Context, req *http1.Request, request interface{}) error {
-	// r.Methods("POST").Path("/users")
-	req.URL.Path = "/users"
-	return encodeRequest(ctx, req, request)
-}
+	var revocationListEndpoint endpoint.Endpoint
+	{
+		revocationListEndpoint = kithttp.NewClient(
+			http1.MethodGet,
+			tgt,
+			encodeRevocationListRequest,
+			decodeRevocationListResponse,
+			options...,
+		).Endpoint()
+	}
 
-func encodeAuthUserRequest(ctx context.Context, req *http1.Request, request interface{}) error {
-	// r.Methods("GET").Path("/users/{id}")
-	r := request.(AuthUserRequest)
-	userID := url.QueryEscape(r.Id)
-	req.URL.Path = "/auth/" + userID
-	return encodeRequest(ctx, req, request)
-}
+	var createAPIKeyEndpoint endpoint.Endpoint
+	{
+		createAPIKeyEndpoint = kithttp.NewClient(
+			http1.MethodPost,
+			tgt,
+			encodeCreateAPIKeyRequest,
+			decodeCreateAPIKeyResponse,
+			options...,
+		).Endpoint()
+	}
 
-func encodeGetUserRequest(ctx context.Context, req *http1.Request, request interface{}) error {
-	// r.Methods("GET").Path("/users/{id}")
-	r := request.(GetUserRequest)
-	userID := url.QueryEscape(r.Id)
-	req.URL.Path = "/users/" + userID
-	return encodeRequest(ctx, req, request)
-}
+	var listAPIKeysEndpoint endpoint.Endpoint
+	{
+		listAPIKeysEndpoint = kithttp.NewClient(
+			http1.MethodGet,
+			tgt,
+			encodeListAPIKeysRequest,
+			decodeListAPIKeysResponse,
+			options...,
+		).Endpoint()
+	}
 
-// MakeGetUserEndpoint returns an endpoint that invokes GetUser on the service.
-func MakeAuthUserEndpoint(s registry.Service) endpoint.Endpoint {
-	return func(ctx context.Context, request interface{}) (interface{}, error) {
-		req := request.(AuthUserRequest)
-		e1 := s.AuthUser(ctx, req.Id, req.Password)
+	var revokeAPIKeyEndpoint endpoint.Endpoint
+	{
+		revokeAPIKeyEndpoint = kithttp.NewClient(
+			http1.MethodDelete,
+			tgt,
+			encodeRevokeAPIKeyRequest,
+			decodeRevokeAPIKeyResponse,
+			options...,
+		).Endpoint()
+	}
 
-		return AuthUserResponse{Err: e1}, nil
+	var authAPIKeyEndpoint endpoint.Endpoint
+	{
+		authAPIKeyEndpoint = kithttp.NewClient(
+			http1.MethodGet,
+			tgt,
+			encodeAuthAPIKeyRequest,
+			decodeAuthAPIKeyResponse,
+			options...,
+		).Endpoint()
+	}
 
+	var addOrganizationEndpoint endpoint.Endpoint
+	{
+		addOrganizationEndpoint = kithttp.NewClient(
+			http1.MethodPost,
+			tgt,
+			encodeAddOrganizationRequest,
+			decodeAddOrganizationResponse,
+			options...,
+		).Endpoint()
 	}
-}
 
-// MakeGetUserEndpoint returns an endpoint that invokes GetUser on the service.
-func MakeGetUserEndpoint(s registry.Service) endpoint.Endpoint {
-	return func(ctx context.Context, request interface{}) (interface{}, error) {
-		req := request.(GetUserRequest)
-		r0, e1 := s.GetUser(ctx, req.Id)
-		return GetUserResponse{
-			User: r0,
-			Err:  e1,
-		}, nil
+	var getOrganizationEndpoint endpoint.Endpoint
+	{
+		getOrganizationEndpoint = kithttp.NewClient(
+			http1.MethodGet,
+			tgt,
+			encodeGetOrganizationRequest,
+			decodeGetOrganizationResponse,
+			options...,
+		).Endpoint()
 	}
-}
 
-// MakeAddUserEndpoint returns an endpoint that invokes AddUser on the service.
-func MakeAddUserEndpoint(s registry.Service) endpoint.Endpoint {
-	return func(ctx context.Context, request interface{}) (interface{}, error) {
-		req := request.(AddUserRequest)
-		e0 := s.AddUser(ctx, req.User)
-		return AddUserResponse{Err: e0}, nil
+	var listOrganizationsEndpoint endpoint.Endpoint
+	{
+		listOrganizationsEndpoint = kithttp.NewClient(
+			http1.MethodGet,
+			tgt,
+			encodeListOrganizationsRequest,
+			decodeListOrganizationsResponse,
+			options...,
+		).Endpoint()
 	}
-}
 
-// MakeListUserEndpoint returns an endpoint that invokes ListUser on the service.
-func MakeListUserEndpoint(s registry.Service) endpoint.Endpoint {
-	return func(ctx context.Context, request interface{}) (interface{}, error) {
-		r0, e1 := s.ListUser(ctx)
-		return ListUserResponse{
-			Err:   e1,
-			Users: r0,
-		}, nil
+	var deleteOrganizationEndpoint endpoint.Endpoint
+	{
+		deleteOrganizationEndpoint = kithttp.NewClient(
+			http1.MethodDelete,
+			tgt,
+			encodeDeleteOrganizationRequest,
+			decodeDeleteOrganizationResponse,
+			options...,
+		).Endpoint()
 	}
-}
 
-// MakeDeleteUserEndpoint returns an endpoint that invokes DeleteUser on the service.
+	var addFirmwareEndpoint endpoint.Endpoint
+	{
+		addFirmwareEndpoint = kithttp.NewClient(
+			http1.MethodPost,
+			tgt,
+			encodeAddFirmwareRequest,
+			decodeAddFirmwareResponse,
+			options...,
+		).Endpoint()
+	}
+
+	var getFirmwareEndpoint endpoint.Endpoint
+	{
+		getFirmwareEndpoint = kithttp.NewClient(
+			http1.MethodGet,
+			tgt,
+			encodeGetFirmwareRequest,
+			decodeGetFirmwareResponse,
+			options...,
+		).Endpoint()
+	}
+
+	var listFirmwareEndpoint endpoint.Endpoint
+	{
+		listFirmwareEndpoint = kithttp.NewClient(
+			http1.MethodGet,
+			tgt,
+			encodeListFirmwareRequest,
+			decodeListFirmwareResponse,
+			options...,
+		).Endpoint()
+	}
+
+	var deleteFirmwareEndpoint endpoint.Endpoint
+	{
+		deleteFirmwareEndpoint = kithttp.NewClient(
+			http1.MethodDelete,
+			tgt,
+			encodeDeleteFirmwareRequest,
+			decodeDeleteFirmwareResponse,
+			options...,
+		).Endpoint()
+	}
+
+	var createCampaignEndpoint endpoint.Endpoint
+	{
+		createCampaignEndpoint = kithttp.NewClient(
+			http1.MethodPost,
+			tgt,
+			encodeCreateCampaignRequest,
+			decodeCreateCampaignResponse,
+			options...,
+		).Endpoint()
+	}
+
+	var getCampaignEndpoint endpoint.Endpoint
+	{
+		getCampaignEndpoint = kithttp.NewClient(
+			http1.MethodGet,
+			tgt,
+			encodeGetCampaignRequest,
+			decodeGetCampaignResponse,
+			options...,
+		).Endpoint()
+	}
+
+	var listCampaignsEndpoint endpoint.Endpoint
+	{
+		listCampaignsEndpoint = kithttp.NewClient(
+			http1.MethodGet,
+			tgt,
+			encodeListCampaignsRequest,
+			decodeListCampaignsResponse,
+			options...,
+		).Endpoint()
+	}
+
+	var advanceCampaignEndpoint endpoint.Endpoint
+	{
+		advanceCampaignEndpoint = kithttp.NewClient(
+			http1.MethodPut,
+			tgt,
+			encodeAdvanceCampaignRequest,
+			decodeAdvanceCampaignResponse,
+			options...,
+		).Endpoint()
+	}
+
+	var listRolloutsEndpoint endpoint.Endpoint
+	{
+		listRolloutsEndpoint = kithttp.NewClient(
+			http1.MethodGet,
+			tgt,
+			encodeListRolloutsRequest,
+			decodeListRolloutsResponse,
+			options...,
+		).Endpoint()
+	}
+
+	var reportRolloutEndpoint endpoint.Endpoint
+	{
+		reportRolloutEndpoint = kithttp.NewClient(
+			http1.MethodPut,
+			tgt,
+			encodeReportRolloutRequest,
+			decodeReportRolloutResponse,
+			options...,
+		).Endpoint()
+	}
+
+	var addSchemaEndpoint endpoint.Endpoint
+	{
+		addSchemaEndpoint = kithttp.NewClient(
+			http1.MethodPost,
+			tgt,
+			encodeAddSchemaRequest,
+			decodeAddSchemaResponse,
+			options...,
+		).Endpoint()
+	}
+
+	var getSchemaEndpoint endpoint.Endpoint
+	{
+		getSchemaEndpoint = kithttp.NewClient(
+			http1.MethodGet,
+			tgt,
+			encodeGetSchemaRequest,
+			decodeGetSchemaResponse,
+			options...,
+		).Endpoint()
+	}
+
+	var listSchemasEndpoint endpoint.Endpoint
+	{
+		listSchemasEndpoint = kithttp.NewClient(
+			http1.MethodGet,
+			tgt,
+			encodeListSchemasRequest,
+			decodeListSchemasResponse,
+			options...,
+		).Endpoint()
+	}
+
+	var nodeSchemaEndpoint endpoint.Endpoint
+	{
+		nodeSchemaEndpoint = kithttp.NewClient(
+			http1.MethodGet,
+			tgt,
+			encodeNodeSchemaRequest,
+			decodeNodeSchemaResponse,
+			options...,
+		).Endpoint()
+	}
+
+	var validatePayloadEndpoint endpoint.Endpoint
+	{
+		validatePayloadEndpoint = kithttp.NewClient(
+			http1.MethodPost,
+			tgt,
+			encodeValidatePayloadRequest,
+			decodeValidatePayloadResponse,
+			options...,
+		).Endpoint()
+	}
+
+	var setQuotaEndpoint endpoint.Endpoint
+	{
+		setQuotaEndpoint = kithttp.NewClient(
+			http1.MethodPut,
+			tgt,
+			encodeSetQuotaRequest,
+			decodeSetQuotaResponse,
+			options...,
+		).Endpoint()
+	}
+
+	var listQuotasEndpoint endpoint.Endpoint
+	{
+		listQuotasEndpoint = kithttp.NewClient(
+			http1.MethodGet,
+			tgt,
+			encodeListQuotasRequest,
+			decodeListQuotasResponse,
+			options...,
+		).Endpoint()
+	}
+
+	var usageEndpoint endpoint.Endpoint
+	{
+		usageEndpoint = kithttp.NewClient(
+			http1.MethodGet,
+			tgt,
+			encodeUsageRequest,
+			decodeUsageResponse,
+			options...,
+		).Endpoint()
+	}
+
+	var inventoryEndpoint endpoint.Endpoint
+	{
+		inventoryEndpoint = kithttp.NewClient(
+			http1.MethodGet,
+			tgt,
+			encodeInventoryRequest,
+			decodeInventoryResponse,
+			options...,
+		).Endpoint()
+	}
+
+	var growthEndpoint endpoint.Endpoint
+	{
+		growthEndpoint = kithttp.NewClient(
+			http1.MethodGet,
+			tgt,
+			encodeGrowthRequest,
+			decodeGrowthResponse,
+			options...,
+		).Endpoint()
+	}
+
+	var orphanedNodesEndpoint endpoint.Endpoint
+	{
+		orphanedNodesEndpoint = kithttp.NewClient(
+			http1.MethodGet,
+			tgt,
+			encodeOrphanedNodesRequest,
+			decodeOrphanedNodesResponse,
+			options...,
+		).Endpoint()
+	}
+
+	//the events are read from the response body after the endpoint returns
+	var watchEndpoint endpoint.Endpoint
+	{
+		watchEndpoint = kithttp.NewClient(
+			http1.MethodGet,
+			tgt,
+			encodeWatchRequest,
+			decodeWatchResponse,
+			append(options, kithttp.BufferedStream(true))...,
+		).Endpoint()
+	}
+
+	// Note that the request encoders need to modify the request URL, changing
+	// the path. That's fine: we simply need to provide specific encoders for
+	// each endpoint.
+
+	return Endpoints{
+		AuthUserEndpoint:    authUserEndpoint,
+		GetUserEndpoint:     getUserEndpoint,
+		AddUserEndpoint:     addUserEndpoint,
+		ListUserEndpoint:    listUserEndpoint,
+		DeleteUserEndpoint:  deleteUserEndpoint,
+		UpdateUserEndpoint:  updateUserEndpoint,
+		AddNodeEndpoint:     addNodeEndpoint,
+		GetNodeEndpoint:     getNodeEndpoint,
+		ListNodesEndpoint:   listNodeEndpoint,
+		DeleteNodeEndpoint:  deleteNodeEndpoint,
+		UpdateNodeEndpoint:  updateNodeEndpoint,
+		AddRegionEndpoint:   addRegionEndpoint,
+		ListRegionsEndpoint: listRegionsEndpoint,
+
+		SuggestRegionEndpoint:   suggestRegionEndpoint,
+		MoveRegionEndpoint:      moveRegionEndpoint,
+		RegionSubtreeEndpoint:   regionSubtreeEndpoint,
+		RegionAncestorsEndpoint: regionAncestorsEndpoint,
+
+		AddNodeTypeEndpoint:    addNodeTypeEndpoint,
+		GetNodeTypeEndpoint:    getNodeTypeEndpoint,
+		ListNodeTypesEndpoint:  listNodeTypesEndpoint,
+		DeleteNodeTypeEndpoint: deleteNodeTypeEndpoint,
+		UpdateNodeTypeEndpoint: updateNodeTypeEndpoint,
+
+		CreateClaimCodeEndpoint:  createClaimCodeEndpoint,
+		ListClaimCodesEndpoint:   listClaimCodesEndpoint,
+		ProvisionNodeEndpoint:    provisionNodeEndpoint,
+		AuthenticateNodeEndpoint: authenticateNodeEndpoint,
+		ListClaimsEndpoint:       listClaimsEndpoint,
+
+		IssueCertificateEndpoint:  issueCertificateEndpoint,
+		RevokeCertificateEndpoint: revokeCertificateEndpoint,
+		ListCertificatesEndpoint:  listCertificatesEndpoint,
+
+		RevocationListEndpoint: revocationListEndpoint,
+
+		CreateAPIKeyEndpoint: createAPIKeyEndpoint,
+		ListAPIKeysEndpoint:  listAPIKeysEndpoint,
+		RevokeAPIKeyEndpoint: revokeAPIKeyEndpoint,
+		AuthAPIKeyEndpoint:   authAPIKeyEndpoint,
+
+		AddOrganizationEndpoint:    addOrganizationEndpoint,
+		GetOrganizationEndpoint:    getOrganizationEndpoint,
+		ListOrganizationsEndpoint:  listOrganizationsEndpoint,
+		DeleteOrganizationEndpoint: deleteOrganizationEndpoint,
+
+		AddFirmwareEndpoint:     addFirmwareEndpoint,
+		GetFirmwareEndpoint:     getFirmwareEndpoint,
+		ListFirmwareEndpoint:    listFirmwareEndpoint,
+		DeleteFirmwareEndpoint:  deleteFirmwareEndpoint,
+		CreateCampaignEndpoint:  createCampaignEndpoint,
+		GetCampaignEndpoint:     getCampaignEndpoint,
+		ListCampaignsEndpoint:   listCampaignsEndpoint,
+		AdvanceCampaignEndpoint: advanceCampaignEndpoint,
+		ListRolloutsEndpoint:    listRolloutsEndpoint,
+		ReportRolloutEndpoint:   reportRolloutEndpoint,
+
+		AddSchemaEndpoint:       addSchemaEndpoint,
+		GetSchemaEndpoint:       getSchemaEndpoint,
+		ListSchemasEndpoint:     listSchemasEndpoint,
+		NodeSchemaEndpoint:      nodeSchemaEndpoint,
+		ValidatePayloadEndpoint: validatePayloadEndpoint,
+
+		SetQuotaEndpoint:      setQuotaEndpoint,
+		ListQuotasEndpoint:    listQuotasEndpoint,
+		UsageEndpoint:         usageEndpoint,
+		InventoryEndpoint:     inventoryEndpoint,
+		GrowthEndpoint:        growthEndpoint,
+		OrphanedNodesEndpoint: orphanedNodesEndpoint,
+
+		WatchEndpoint: watchEndpoint,
+	}, nil
+
+}
+
+func encodeAddNodeTypeRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	req.URL.Path = "/types"
+	return encodeRequest(ctx, req, request)
+}
+
+func encodeGetNodeTypeRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	r := request.(GetNodeTypeRequest)
+	req.URL.Path = "/types/" + strconv.Itoa(r.Id)
+	return encodeRequest(ctx, req, request)
+}
+
+func encodeListNodeTypesRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	req.URL.Path = "/types"
+	return encodeRequest(ctx, req, request)
+}
+
+func encodeDeleteNodeTypeRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	r := request.(DeleteNodeTypeRequest)
+	req.URL.Path = "/types/" + strconv.Itoa(r.Id)
+	return encodeRequest(ctx, req, request)
+}
+
+func encodeUpdateNodeTypeRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	r := request.(UpdateNodeTypeRequest)
+	req.URL.Path = "/types/" + strconv.Itoa(r.Id)
+	return encodeRequest(ctx, req, request)
+}
+
+func encodeUpdateNodeRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+
+	r := request.(UpdateNodeRequest)
+	nodeID := url.QueryEscape(r.Id)
+	req.URL.Path = "/users/" + nodeID
+	return encodeRequest(ctx, req, request)
+}
+
+func encodeDeleteNodeRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	r := request.(DeleteNodeRequest)
+	nodeID := url.QueryEscape(r.Id)
+	req.URL.Path = "/users/" + nodeID
+	return encodeRequest(ctx, req, request)
+}
+
+func encodeListNodeRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	r := request.(ListNodesRequest)
+	req.URL.Path = "/nodes"
+	req.URL.RawQuery = regionQuery(r.Region, r.Subregions)
+	return nil
+}
+
+func encodeAddNodeRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	req.URL.Path = "/nodes"
+	return encodeRequest(ctx, req, request)
+}
+
+func encodeGetNodeRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	r := request.(GetNodeRequest)
+	nodeID := url.QueryEscape(r.Id)
+	req.URL.Path = "/nodes/" + nodeID
+	return encodeRequest(ctx, req, request)
+}
+
+func encodeListRegionsRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	req.URL.Path = "/regions"
+	return encodeRequest(ctx, req, request)
+}
+
+func encodeAddRegionRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	req.URL.Path = "/regions"
+	return encodeRequest(ctx, req, request)
+}
+
+func encodeSuggestRegionRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	r := request.(SuggestRegionRequest)
+	req.URL.Path = "/regions/suggest"
+	req.URL.RawQuery = url.Values{
+		"latitude":  {strconv.FormatFloat(r.Latitude, 'f', -1, 64)},
+		"longitude": {strconv.FormatFloat(r.Longitude, 'f', -1, 64)},
+	}.Encode()
+	return nil
+}
+
+func encodeMoveRegionRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	r := request.(MoveRegionRequest)
+	req.URL.Path = "/regions/" + url.PathEscape(r.Id) + "/parent"
+	return encodeRequest(ctx, req, request)
+}
+
+func encodeRegionSubtreeRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	r := request.(RegionSubtreeRequest)
+	req.URL.Path = "/regions/" + url.PathEscape(r.Id) + "/subtree"
+	return nil
+}
+
+func encodeRegionAncestorsRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	r := request.(RegionAncestorsRequest)
+	req.URL.Path = "/regions/" + url.PathEscape(r.Id) + "/ancestors"
+	return nil
+}
+
+func encodeUpdateUserRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	// r.Methods("PATCH").Path("/users/{id}")
+	r := request.(UpdateUserRequest)
+	userID := url.QueryEscape(r.Id)
+	req.URL.Path = "/users/" + userID
+	return encodeRequest(ctx, req, request)
+}
+
+func encodeDeleteUserRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	// r.Methods("DELETE").Path("/users/{id}")
+	r := request.(DeleteUserRequest)
+	userID := url.QueryEscape(r.Id)
+	req.URL.Path = "/users/" + userID
+	return encodeRequest(ctx, req, request)
+}
+
+func encodeListUserRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	r := request.(ListUserRequest)
+	req.URL.Path = "/users"
+	req.URL.RawQuery = regionQuery(r.Region, r.Subregions)
+	return nil
+}
+
+// regionQuery encodes the region filter of the list requests
+func regionQuery(region string, subregions bool) string {
+	q := url.Values{}
+	if region != "" {
+		q.Set("region", region)
+	}
+	if subregions {
+		q.Set("subregions", "true")
+	}
+	return q.Encode()
+}
+
+// scopePath is the path of the quota resource of a region or organization
+func scopePath(scope registry.QuotaScope, id, resource string) string {
+	if scope == registry.QuotaOrg {
+		return "/orgs/" + url.PathEscape(id) + "/" + resource
+	}
+	return "/regions/" + url.PathEscape(id) + "/" + resource
+}
+
+func encodeAddUserRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	// r.Methods("POST").Path("/users")
+	req.URL.Path = "/users"
+	return encodeRequest(ctx, req, request)
+}
+
+func encodeAuthUserRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	// r.Methods("GET").Path("/users/{id}")
+	r := request.(AuthUserRequest)
+	userID := url.QueryEscape(r.Id)
+	req.URL.Path = "/auth/" + userID
+	return encodeRequest(ctx, req, request)
+}
+
+func encodeGetUserRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	// r.Methods("GET").Path("/users/{id}")
+	r := request.(GetUserRequest)
+	userID := url.QueryEscape(r.Id)
+	req.URL.Path = "/users/" + userID
+	return encodeRequest(ctx, req, request)
+}
+
+// MakeGetUserEndpoint returns an endpoint that invokes GetUser on the service.
+func MakeAuthUserEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(AuthUserRequest)
+		e1 := s.AuthUser(ctx, req.Id, req.Password)
+
+		return AuthUserResponse{Err: e1}, nil
+
+	}
+}
+
+// MakeGetUserEndpoint returns an endpoint that invokes GetUser on the service.
+func MakeGetUserEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(GetUserRequest)
+		r0, e1 := s.GetUser(ctx, req.Id)
+		return GetUserResponse{
+			User: r0,
+			Err:  e1,
+		}, nil
+	}
+}
+
+// MakeAddUserEndpoint returns an endpoint that invokes AddUser on the service.
+func MakeAddUserEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(AddUserRequest)
+		e0 := s.AddUser(ctx, req.User)
+		return AddUserResponse{Err: e0}, nil
+	}
+}
+
+// MakeListUserEndpoint returns an endpoint that invokes ListUser on the service.
+func MakeListUserEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ListUserRequest)
+		r0, e1 := s.ListUser(ctx, registry.RegionFilter{Region: req.Region, Subregions: req.Subregions})
+		return ListUserResponse{
+			Err:   e1,
+			Users: r0,
+		}, nil
+	}
+}
+
+// MakeDeleteUserEndpoint returns an endpoint that invokes DeleteUser on the service.
 func MakeDeleteUserEndpoint(s registry.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
-		req := request.(DeleteUserRequest)
-		e0 := s.DeleteUser(ctx, req.Id)
-		return DeleteUserResponse{Err: e0}, nil
+		req := request.(DeleteUserRequest)
+		e0 := s.DeleteUser(ctx, req.Id)
+		return DeleteUserResponse{Err: e0}, nil
+	}
+}
+
+// MakeUpdateUserEndpoint returns an endpoint that invokes UpdateUser on the service.
+func MakeUpdateUserEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(UpdateUserRequest)
+		r0, e1 := s.UpdateUser(ctx, req.Id, req.User)
+		return UpdateUserResponse{
+			Err:  e1,
+			User: r0,
+		}, nil
+	}
+}
+
+// AddNodeRequest collects the request parameters for the AddNode method.
+type AddNodeRequest struct {
+	Node registry.Node `json:"node"`
+}
+
+// AddNodeResponse collects the response parameters for the AddNode method.
+type AddNodeResponse struct {
+	Err error `json:"e0"`
+}
+
+// MakeAddNodeEndpoint returns an endpoint that invokes AddNode on the service.
+func MakeAddNodeEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(AddNodeRequest)
+		e0 := s.AddNode(ctx, req.Node)
+		return AddNodeResponse{Err: e0}, nil
+	}
+}
+
+// MakeGetNodeEndpoint returns an endpoint that invokes GetNode on the service.
+func MakeGetNodeEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(GetNodeRequest)
+		r0, e1 := s.GetNode(ctx, req.Id)
+		return GetNodeResponse{
+			Err:  e1,
+			Node: r0,
+		}, nil
+	}
+}
+
+// Failed implements Failer.
+func (r GetNodeResponse) Failed() error {
+	return r.Err
+}
+
+// ListNodesRequest collects the request parameters for the ListNodes method.
+type ListNodesRequest struct {
+	Region     string `json:"region"`
+	Subregions bool   `json:"subregions"`
+}
+
+// ListNodesResponse collects the response parameters for the ListNodes method.
+type ListNodesResponse struct {
+	Nodes []registry.Node `json:"nodes"`
+	Err   error           `json:"err"`
+}
+
+// MakeListNodesEndpoint returns an endpoint that invokes ListNodes on the service.
+func MakeListNodesEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ListNodesRequest)
+		r0, e1 := s.ListNodes(ctx, registry.RegionFilter{Region: req.Region, Subregions: req.Subregions})
+		return ListNodesResponse{
+			Err:   e1,
+			Nodes: r0,
+		}, nil
+	}
+}
+
+// Failed implements Failer.
+func (r ListNodesResponse) Failed() error {
+	return r.Err
+}
+
+// DeleteNodeRequest collects the request parameters for the DeleteNode method.
+type DeleteNodeRequest struct {
+	Id string `json:"id"`
+}
+
+// DeleteNodeResponse collects the response parameters for the DeleteNode method.
+type DeleteNodeResponse struct {
+	Err error `json:"e0"`
+}
+
+// MakeDeleteNodeEndpoint returns an endpoint that invokes DeleteNode on the service.
+func MakeDeleteNodeEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(DeleteNodeRequest)
+		e0 := s.DeleteNode(ctx, req.Id)
+		return DeleteNodeResponse{Err: e0}, nil
+	}
+}
+
+// Failed implements Failer.
+func (r DeleteNodeResponse) Failed() error {
+	return r.Err
+}
+
+// UpdateNodeRequest collects the request parameters for the UpdateNode method.
+type UpdateNodeRequest struct {
+	Id   string        `json:"id"`
+	User registry.Node `json:"user"`
+}
+
+// UpdateNodeResponse collects the response parameters for the UpdateNode method.
+type UpdateNodeResponse struct {
+	Node registry.Node `json:"r0"`
+	Err  error         `json:"e1"`
+}
+
+// MakeUpdateNodeEndpoint returns an endpoint that invokes UpdateNode on the service.
+func MakeUpdateNodeEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(UpdateNodeRequest)
+		r0, e1 := s.UpdateNode(ctx, req.Id, req.User)
+		return UpdateNodeResponse{
+			Err:  e1,
+			Node: r0,
+		}, nil
+	}
+}
+
+// Failed implements Failer.
+func (r UpdateNodeResponse) Failed() error {
+	return r.Err
+}
+
+// MakeAddRegionEndpoint returns an endpoint that invokes AddRegion on the service.
+func MakeAddRegionEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(AddRegionRequest)
+		e0 := s.AddRegion(ctx, req.Region)
+		return AddRegionResponse{Err: e0}, nil
+	}
+}
+
+// MakeSuggestRegionEndpoint returns an endpoint that invokes SuggestRegion on the service.
+func MakeSuggestRegionEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(SuggestRegionRequest)
+		r0, e1 := s.SuggestRegion(ctx, req.Latitude, req.Longitude)
+		return SuggestRegionResponse{
+			Regions: r0,
+			Err:     e1,
+		}, nil
+	}
+}
+
+// MakeMoveRegionEndpoint returns an endpoint that invokes MoveRegion on the service.
+func MakeMoveRegionEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(MoveRegionRequest)
+		r0, e1 := s.MoveRegion(ctx, req.Id, req.Parent)
+		return MoveRegionResponse{
+			Err:    e1,
+			Region: r0,
+		}, nil
+	}
+}
+
+// MakeRegionSubtreeEndpoint returns an endpoint that invokes RegionSubtree on the service.
+func MakeRegionSubtreeEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(RegionSubtreeRequest)
+		r0, e1 := s.RegionSubtree(ctx, req.Id)
+		return RegionSubtreeResponse{
+			Err:     e1,
+			Regions: r0,
+		}, nil
+	}
+}
+
+// MakeRegionAncestorsEndpoint returns an endpoint that invokes RegionAncestors on the service.
+func MakeRegionAncestorsEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(RegionAncestorsRequest)
+		r0, e1 := s.RegionAncestors(ctx, req.Id)
+		return RegionAncestorsResponse{
+			Err:     e1,
+			Regions: r0,
+		}, nil
+	}
+}
+
+// MakeListRegionsEndpoint returns an endpoint that invokes ListRegions on the service.
+func MakeListRegionsEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		r0, e1 := s.ListRegions(ctx)
+		return ListRegionsResponse{
+			Err:     e1,
+			Regions: r0,
+		}, nil
+	}
+}
+
+// MakeAddNodeTypeEndpoint returns an endpoint that invokes AddNodeType on the service.
+func MakeAddNodeTypeEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(AddNodeTypeRequest)
+		e0 := s.AddNodeType(ctx, req.NodeType)
+		return AddNodeTypeResponse{Err: e0}, nil
+	}
+}
+
+// MakeGetNodeTypeEndpoint returns an endpoint that invokes GetNodeType on the service.
+func MakeGetNodeTypeEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(GetNodeTypeRequest)
+		r0, e1 := s.GetNodeType(ctx, req.Id)
+		return GetNodeTypeResponse{
+			NodeType: r0,
+			Err:      e1,
+		}, nil
+	}
+}
+
+// MakeListNodeTypesEndpoint returns an endpoint that invokes ListNodeTypes on the service.
+func MakeListNodeTypesEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		r0, e1 := s.ListNodeTypes(ctx)
+		return ListNodeTypesResponse{
+			NodeTypes: r0,
+			Err:       e1,
+		}, nil
+	}
+}
+
+// MakeDeleteNodeTypeEndpoint returns an endpoint that invokes DeleteNodeType on the service.
+func MakeDeleteNodeTypeEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(DeleteNodeTypeRequest)
+		e0 := s.DeleteNodeType(ctx, req.Id)
+		return DeleteNodeTypeResponse{Err: e0}, nil
+	}
+}
+
+// MakeUpdateNodeTypeEndpoint returns an endpoint that invokes UpdateNodeType on the service.
+func MakeUpdateNodeTypeEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(UpdateNodeTypeRequest)
+		r0, e1 := s.UpdateNodeType(ctx, req.Id, req.NodeType)
+		return UpdateNodeTypeResponse{
+			NodeType: r0,
+			Err:      e1,
+		}, nil
+	}
+}
+
+func (e Endpoints) AuthUser(ctx context.Context, id, password string) (err error) {
+	request := AuthUserRequest{
+		Id:       id,
+		Password: password,
+	}
+	response, err := e.AuthUserEndpoint(ctx, request)
+	if err != nil {
+		return
+	}
+
+	err = response.(AuthUserResponse).Err
+	return
+}
+
+// GetUser implements Service. Primarily useful in a client.
+func (e Endpoints) GetUser(ctx context.Context, id string) (user registry.User, err error) {
+	request := GetUserRequest{Id: id}
+	response, err := e.GetUserEndpoint(ctx, request)
+	if err != nil {
+		return
+	}
+
+	user = response.(GetUserResponse).User
+	return
+}
+
+// AddUser implements Service. Primarily useful in a client.
+func (e Endpoints) AddUser(ctx context.Context, user registry.User) (e0 error) {
+	request := AddUserRequest{User: user}
+	response, err := e.AddUserEndpoint(ctx, request)
+	if err != nil {
+		return err
+	}
+	return response.(AddUserResponse).Err
+}
+
+// ListUser implements Service. Primarily useful in a client.
+func (e Endpoints) ListUser(ctx context.Context, filter registry.RegionFilter) (users []registry.User, err error) {
+	request := ListUserRequest{Region: filter.Region, Subregions: filter.Subregions}
+	response, err := e.ListUserEndpoint(ctx, request)
+	if err != nil {
+		return
+	}
+	users = response.(ListUserResponse).Users
+	return
+}
+
+// DeleteUser implements Service. Primarily useful in a client.
+func (e Endpoints) DeleteUser(ctx context.Context, id string) (e0 error) {
+	request := DeleteUserRequest{Id: id}
+	response, err := e.DeleteUserEndpoint(ctx, request)
+	if err != nil {
+		return err
+	}
+	return response.(DeleteUserResponse).Err
+}
+
+// UpdateUser implements Service. Primarily useful in a client.
+func (e Endpoints) UpdateUser(ctx context.Context, id string, user registry.User) (r0 registry.User, e1 error) {
+	request := UpdateUserRequest{
+		Id:   id,
+		User: user,
+	}
+	response, err := e.UpdateUserEndpoint(ctx, request)
+	if err != nil {
+		return r0, err
+	}
+	return response.(UpdateUserResponse).User, response.(UpdateUserResponse).Err
+}
+
+// AddNode implements Service. Primarily useful in a client.
+func (e Endpoints) AddNode(ctx context.Context, node registry.Node) (e0 error) {
+	request := AddNodeRequest{Node: node}
+	response, err := e.AddNodeEndpoint(ctx, request)
+	if err != nil {
+		return err
+	}
+	return response.(AddNodeResponse).Err
+}
+
+// GetNode implements Service. Primarily useful in a client.
+func (e Endpoints) GetNode(ctx context.Context, id string) (r0 registry.Node, e1 error) {
+	request := GetNodeRequest{Id: id}
+	response, err := e.GetNodeEndpoint(ctx, request)
+	if err != nil {
+		return r0, err
+	}
+	return response.(GetNodeResponse).Node, response.(GetNodeResponse).Err
+}
+
+// ListNodes implements Service. Primarily useful in a client.
+func (e Endpoints) ListNodes(ctx context.Context, filter registry.RegionFilter) (r0 []registry.Node, e1 error) {
+	request := ListNodesRequest{Region: filter.Region, Subregions: filter.Subregions}
+	response, err := e.ListNodesEndpoint(ctx, request)
+	if err != nil {
+		return r0, err
+	}
+	return response.(ListNodesResponse).Nodes, response.(ListNodesResponse).Err
+}
+
+// DeleteNode implements Service. Primarily useful in a client.
+func (e Endpoints) DeleteNode(ctx context.Context, id string) (e0 error) {
+	request := DeleteNodeRequest{Id: id}
+	response, err := e.DeleteNodeEndpoint(ctx, request)
+	if err != nil {
+		return err
+	}
+	return response.(DeleteNodeResponse).Err
+}
+
+// UpdateNode implements Service. Primarily useful in a client.
+func (e Endpoints) UpdateNode(ctx context.Context, id string, user registry.Node) (r0 registry.Node, e1 error) {
+	request := UpdateNodeRequest{
+		Id:   id,
+		User: user,
+	}
+	response, err := e.UpdateNodeEndpoint(ctx, request)
+	if err != nil {
+		return r0, err
+	}
+	return response.(UpdateNodeResponse).Node, response.(UpdateNodeResponse).Err
+}
+
+// AddRegion implements Service. Primarily useful in a client.
+func (e Endpoints) AddRegion(ctx context.Context, region registry.Region) (e0 error) {
+	request := AddRegionRequest{Region: region}
+	response, err := e.AddRegionEndpoint(ctx, request)
+	if err != nil {
+		return err
+	}
+	return response.(AddRegionResponse).Err
+}
+
+// SuggestRegion implements Service. Primarily useful in a client.
+func (e Endpoints) SuggestRegion(ctx context.Context, lat, long float64) (r0 []registry.Region, e1 error) {
+	request := SuggestRegionRequest{Latitude: lat, Longitude: long}
+	response, err := e.SuggestRegionEndpoint(ctx, request)
+	if err != nil {
+		return r0, err
+	}
+	return response.(SuggestRegionResponse).Regions, response.(SuggestRegionResponse).Err
+}
+
+// MoveRegion implements Service. Primarily useful in a client.
+func (e Endpoints) MoveRegion(ctx context.Context, id, parent string) (r0 registry.Region, e1 error) {
+	request := MoveRegionRequest{Id: id, Parent: parent}
+	response, err := e.MoveRegionEndpoint(ctx, request)
+	if err != nil {
+		return r0, err
+	}
+	return response.(MoveRegionResponse).Region, response.(MoveRegionResponse).Err
+}
+
+// RegionSubtree implements Service. Primarily useful in a client.
+func (e Endpoints) RegionSubtree(ctx context.Context, id string) (r0 []registry.Region, e1 error) {
+	request := RegionSubtreeRequest{Id: id}
+	response, err := e.RegionSubtreeEndpoint(ctx, request)
+	if err != nil {
+		return r0, err
+	}
+	return response.(RegionSubtreeResponse).Regions, response.(RegionSubtreeResponse).Err
+}
+
+// RegionAncestors implements Service. Primarily useful in a client.
+func (e Endpoints) RegionAncestors(ctx context.Context, id string) (r0 []registry.Region, e1 error) {
+	request := RegionAncestorsRequest{Id: id}
+	response, err := e.RegionAncestorsEndpoint(ctx, request)
+	if err != nil {
+		return r0, err
+	}
+	return response.(RegionAncestorsResponse).Regions, response.(RegionAncestorsResponse).Err
+}
+
+// ListRegions implements Service. Primarily useful in a client.
+func (e Endpoints) ListRegions(ctx context.Context) (r0 []registry.Region, e1 error) {
+	request := ListRegionsRequest{}
+	response, err := e.ListRegionsEndpoint(ctx, request)
+	if err != nil {
+		return r0, err
+	}
+	return response.(ListRegionsResponse).Regions, response.(ListRegionsResponse).Err
+}
+
+// AddNodeType implements Service. Primarily useful in a client.
+func (e Endpoints) AddNodeType(ctx context.Context, nodeType registry.NodeType) (e0 error) {
+	request := AddNodeTypeRequest{NodeType: nodeType}
+	response, err := e.AddNodeTypeEndpoint(ctx, request)
+	if err != nil {
+		return err
+	}
+	return response.(AddNodeTypeResponse).Err
+}
+
+// GetNodeType implements Service. Primarily useful in a client.
+func (e Endpoints) GetNodeType(ctx context.Context, id int) (r0 registry.NodeType, e1 error) {
+	request := GetNodeTypeRequest{Id: id}
+	response, err := e.GetNodeTypeEndpoint(ctx, request)
+	if err != nil {
+		return r0, err
+	}
+	return response.(GetNodeTypeResponse).NodeType, response.(GetNodeTypeResponse).Err
+}
+
+// ListNodeTypes implements Service. Primarily useful in a client.
+func (e Endpoints) ListNodeTypes(ctx context.Context) (r0 []registry.NodeType, e1 error) {
+	request := ListNodeTypesRequest{}
+	response, err := e.ListNodeTypesEndpoint(ctx, request)
+	if err != nil {
+		return r0, err
+	}
+	return response.(ListNodeTypesResponse).NodeTypes, response.(ListNodeTypesResponse).Err
+}
+
+// DeleteNodeType implements Service. Primarily useful in a client.
+func (e Endpoints) DeleteNodeType(ctx context.Context, id int) (e0 error) {
+	request := DeleteNodeTypeRequest{Id: id}
+	response, err := e.DeleteNodeTypeEndpoint(ctx, request)
+	if err != nil {
+		return err
+	}
+	return response.(DeleteNodeTypeResponse).Err
+}
+
+// UpdateNodeType implements Service. Primarily useful in a client.
+func (e Endpoints) UpdateNodeType(ctx context.Context, id int, nodeType registry.NodeType) (r0 registry.NodeType, e1 error) {
+	request := UpdateNodeTypeRequest{
+		Id:       id,
+		NodeType: nodeType,
+	}
+	response, err := e.UpdateNodeTypeEndpoint(ctx, request)
+	if err != nil {
+		return r0, err
+	}
+	return response.(UpdateNodeTypeResponse).NodeType, response.(UpdateNodeTypeResponse).Err
+}
+
+func encodeCreateClaimCodeRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	req.URL.Path = "/claims/codes"
+	return encodeRequest(ctx, req, request)
+}
+
+func encodeListClaimCodesRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	req.URL.Path = "/claims/codes"
+	return encodeRequest(ctx, req, request)
+}
+
+func encodeProvisionNodeRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	req.URL.Path = "/provision"
+	return encodeRequest(ctx, req, request)
+}
+
+func encodeAuthenticateNodeRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	req.URL.Path = "/nodes/authenticate"
+	return encodeRequest(ctx, req, request)
+}
+
+func encodeListClaimsRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	req.URL.Path = "/claims"
+	return encodeRequest(ctx, req, request)
+}
+
+// parseTTL reads durations like "24h" or "30m", an empty ttl lets the
+// service use its default.
+func parseTTL(ttl string) (time.Duration, error) {
+	if ttl == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(ttl)
+}
+
+// MakeCreateClaimCodeEndpoint returns an endpoint that invokes CreateClaimCode on the service.
+func MakeCreateClaimCodeEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(CreateClaimCodeRequest)
+		ttl, err := parseTTL(req.TTL)
+		if err != nil {
+			return CreateClaimCodeResponse{Err: err}, nil
+		}
+		r0, e1 := s.CreateClaimCode(ctx, req.Region, req.Type, ttl)
+		return CreateClaimCodeResponse{
+			Code: r0,
+			Err:  e1,
+		}, nil
+	}
+}
+
+// MakeListClaimCodesEndpoint returns an endpoint that invokes ListClaimCodes on the service.
+func MakeListClaimCodesEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		r0, e1 := s.ListClaimCodes(ctx)
+		return ListClaimCodesResponse{
+			Codes: r0,
+			Err:   e1,
+		}, nil
+	}
+}
+
+// MakeProvisionNodeEndpoint returns an endpoint that invokes ProvisionNode on the service.
+func MakeProvisionNodeEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ProvisionNodeRequest)
+		r0, e1 := s.ProvisionNode(ctx, req.Code, req.Node)
+		return ProvisionNodeResponse{
+			Credentials: r0,
+			Err:         e1,
+		}, nil
+	}
+}
+
+// MakeAuthenticateNodeEndpoint returns an endpoint that invokes AuthenticateNode on the service.
+func MakeAuthenticateNodeEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(AuthenticateNodeRequest)
+		r0, e1 := s.AuthenticateNode(ctx, req.NodeID, req.Key)
+		return AuthenticateNodeResponse{
+			Node: r0,
+			Err:  e1,
+		}, nil
+	}
+}
+
+// MakeListClaimsEndpoint returns an endpoint that invokes ListClaims on the service.
+func MakeListClaimsEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		r0, e1 := s.ListClaims(ctx)
+		return ListClaimsResponse{
+			Claims: r0,
+			Err:    e1,
+		}, nil
+	}
+}
+
+// CreateClaimCode implements Service. Primarily useful in a client.
+func (e Endpoints) CreateClaimCode(ctx context.Context, region string, typ int, ttl time.Duration) (r0 registry.ClaimCode, e1 error) {
+	request := CreateClaimCodeRequest{
+		Region: region,
+		Type:   typ,
+		TTL:    ttl.String(),
+	}
+	response, err := e.CreateClaimCodeEndpoint(ctx, request)
+	if err != nil {
+		return r0, err
+	}
+	return response.(CreateClaimCodeResponse).Code, response.(CreateClaimCodeResponse).Err
+}
+
+// ListClaimCodes implements Service. Primarily useful in a client.
+func (e Endpoints) ListClaimCodes(ctx context.Context) (r0 []registry.ClaimCode, e1 error) {
+	request := ListClaimCodesRequest{}
+	response, err := e.ListClaimCodesEndpoint(ctx, request)
+	if err != nil {
+		return r0, err
+	}
+	return response.(ListClaimCodesResponse).Codes, response.(ListClaimCodesResponse).Err
+}
+
+// ProvisionNode implements Service. Primarily useful in a client.
+func (e Endpoints) ProvisionNode(ctx context.Context, code string, node registry.Node) (r0 registry.Credentials, e1 error) {
+	request := ProvisionNodeRequest{
+		Code: code,
+		Node: node,
+	}
+	response, err := e.ProvisionNodeEndpoint(ctx, request)
+	if err != nil {
+		return r0, err
+	}
+	return response.(ProvisionNodeResponse).Credentials, response.(ProvisionNodeResponse).Err
+}
+
+// AuthenticateNode implements Service. Primarily useful in a client.
+func (e Endpoints) AuthenticateNode(ctx context.Context, node string, key string) (r0 registry.Node, e1 error) {
+	request := AuthenticateNodeRequest{
+		NodeID: node,
+		Key:    key,
+	}
+	response, err := e.AuthenticateNodeEndpoint(ctx, request)
+	if err != nil {
+		return r0, err
+	}
+	return response.(AuthenticateNodeResponse).Node, response.(AuthenticateNodeResponse).Err
+}
+
+// ListClaims implements Service. Primarily useful in a client.
+func (e Endpoints) ListClaims(ctx context.Context) (r0 []registry.Claim, e1 error) {
+	request := ListClaimsRequest{}
+	response, err := e.ListClaimsEndpoint(ctx, request)
+	if err != nil {
+		return r0, err
+	}
+	return response.(ListClaimsResponse).Claims, response.(ListClaimsResponse).Err
+}
+
+func encodeIssueCertificateRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	req.URL.Path = "/certs"
+	return encodeRequest(ctx, req, request)
+}
+
+func encodeRevokeCertificateRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	r := request.(RevokeCertificateRequest)
+	req.URL.Path = "/certs/" + r.Serial + "/revoke"
+	return encodeRequest(ctx, req, request)
+}
+
+func encodeListCertificatesRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	req.URL.Path = "/certs"
+	return encodeRequest(ctx, req, request)
+}
+
+// MakeIssueCertificateEndpoint returns an endpoint that invokes IssueCertificate on the service.
+func MakeIssueCertificateEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(IssueCertificateRequest)
+		ttl, err := parseTTL(req.TTL)
+		if err != nil {
+			return IssueCertificateResponse{Err: err}, nil
+		}
+		r0, e1 := s.IssueCertificate(ctx, req.Node, req.CSR, ttl)
+		return IssueCertificateResponse{
+			Certificate: r0,
+			Err:         e1,
+		}, nil
+	}
+}
+
+// MakeRevokeCertificateEndpoint returns an endpoint that invokes RevokeCertificate on the service.
+func MakeRevokeCertificateEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(RevokeCertificateRequest)
+		e0 := s.RevokeCertificate(ctx, req.Serial)
+		return RevokeCertificateResponse{Err: e0}, nil
+	}
+}
+
+// MakeListCertificatesEndpoint returns an endpoint that invokes ListCertificates on the service.
+func MakeListCertificatesEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		r0, e1 := s.ListCertificates(ctx)
+		return ListCertificatesResponse{
+			Certificates: r0,
+			Err:          e1,
+		}, nil
+	}
+}
+
+// IssueCertificate implements Service. Primarily useful in a client.
+func (e Endpoints) IssueCertificate(ctx context.Context, node string, csr string, ttl time.Duration) (r0 registry.Certificate, e1 error) {
+	request := IssueCertificateRequest{
+		Node: node,
+		CSR:  csr,
+		TTL:  ttl.String(),
+	}
+	response, err := e.IssueCertificateEndpoint(ctx, request)
+	if err != nil {
+		return r0, err
+	}
+	return response.(IssueCertificateResponse).Certificate, response.(IssueCertificateResponse).Err
+}
+
+// RevokeCertificate implements Service. Primarily useful in a client.
+func (e Endpoints) RevokeCertificate(ctx context.Context, serial string) (e0 error) {
+	request := RevokeCertificateRequest{Serial: serial}
+	response, err := e.RevokeCertificateEndpoint(ctx, request)
+	if err != nil {
+		return err
+	}
+	return response.(RevokeCertificateResponse).Err
+}
+
+// ListCertificates implements Service. Primarily useful in a client.
+func (e Endpoints) ListCertificates(ctx context.Context) (r0 []registry.Certificate, e1 error) {
+	request := ListCertificatesRequest{}
+	response, err := e.ListCertificatesEndpoint(ctx, request)
+	if err != nil {
+		return r0, err
+	}
+	return response.(ListCertificatesResponse).Certificates, response.(ListCertificatesResponse).Err
+}
+
+func encodeRevocationListRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	req.URL.Path = "/certs/crl"
+	return encodeRequest(ctx, req, request)
+}
+
+// MakeRevocationListEndpoint returns an endpoint that invokes RevocationList on the service.
+func MakeRevocationListEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		r0, e1 := s.RevocationList(ctx)
+		return RevocationListResponse{
+			CRL: r0,
+			Err: e1,
+		}, nil
+	}
+}
+
+// RevocationList implements Service. Primarily useful in a client.
+func (e Endpoints) RevocationList(ctx context.Context) (r0 []byte, e1 error) {
+	request := RevocationListRequest{}
+	response, err := e.RevocationListEndpoint(ctx, request)
+	if err != nil {
+		return r0, err
+	}
+	return response.(RevocationListResponse).CRL, response.(RevocationListResponse).Err
+}
+
+func encodeCreateAPIKeyRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	req.URL.Path = "/apikeys"
+	return encodeRequest(ctx, req, request)
+}
+
+func encodeListAPIKeysRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	r := request.(ListAPIKeysRequest)
+	req.URL.Path = "/apikeys"
+	if r.Owner != "" {
+		req.URL.RawQuery = url.Values{"owner": {r.Owner}}.Encode()
+	}
+	return nil
+}
+
+func encodeRevokeAPIKeyRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	r := request.(RevokeAPIKeyRequest)
+	req.URL.Path = "/apikeys/" + r.Id
+	return encodeRequest(ctx, req, request)
+}
+
+func encodeAuthAPIKeyRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	r := request.(AuthAPIKeyRequest)
+	req.URL.Path = "/auth"
+	req.Header.Set("Authorization", "Bearer "+r.Key)
+	return nil
+}
+
+// MakeCreateAPIKeyEndpoint returns an endpoint that invokes CreateAPIKey on the service.
+func MakeCreateAPIKeyEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(CreateAPIKeyRequest)
+		ttl, err := parseTTL(req.TTL)
+		if err != nil {
+			return CreateAPIKeyResponse{Err: err}, nil
+		}
+		r0, e1 := s.CreateAPIKey(ctx, req.Owner, req.Name, req.Role, req.Region, ttl)
+		return CreateAPIKeyResponse{
+			Key: r0,
+			Err: e1,
+		}, nil
+	}
+}
+
+// MakeListAPIKeysEndpoint returns an endpoint that invokes ListAPIKeys on the service.
+func MakeListAPIKeysEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ListAPIKeysRequest)
+		r0, e1 := s.ListAPIKeys(ctx, req.Owner)
+		return ListAPIKeysResponse{
+			Keys: r0,
+			Err:  e1,
+		}, nil
+	}
+}
+
+// MakeRevokeAPIKeyEndpoint returns an endpoint that invokes RevokeAPIKey on the service.
+func MakeRevokeAPIKeyEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(RevokeAPIKeyRequest)
+		e0 := s.RevokeAPIKey(ctx, req.Id)
+		return RevokeAPIKeyResponse{Err: e0}, nil
+	}
+}
+
+// MakeAuthAPIKeyEndpoint returns an endpoint that invokes AuthAPIKey on the service.
+func MakeAuthAPIKeyEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(AuthAPIKeyRequest)
+		r0, e1 := s.AuthAPIKey(ctx, req.Key)
+		return AuthAPIKeyResponse{
+			Key: r0,
+			Err: e1,
+		}, nil
+	}
+}
+
+// CreateAPIKey implements Service. Primarily useful in a client.
+func (e Endpoints) CreateAPIKey(ctx context.Context, owner string, name string, role int, region string, ttl time.Duration) (r0 registry.APIKey, e1 error) {
+	request := CreateAPIKeyRequest{
+		Owner:  owner,
+		Name:   name,
+		Role:   role,
+		Region: region,
+		TTL:    ttl.String(),
+	}
+	response, err := e.CreateAPIKeyEndpoint(ctx, request)
+	if err != nil {
+		return r0, err
+	}
+	return response.(CreateAPIKeyResponse).Key, response.(CreateAPIKeyResponse).Err
+}
+
+// ListAPIKeys implements Service. Primarily useful in a client.
+func (e Endpoints) ListAPIKeys(ctx context.Context, owner string) (r0 []registry.APIKey, e1 error) {
+	request := ListAPIKeysRequest{Owner: owner}
+	response, err := e.ListAPIKeysEndpoint(ctx, request)
+	if err != nil {
+		return r0, err
+	}
+	return response.(ListAPIKeysResponse).Keys, response.(ListAPIKeysResponse).Err
+}
+
+// RevokeAPIKey implements Service. Primarily useful in a client.
+func (e Endpoints) RevokeAPIKey(ctx context.Context, id string) (e0 error) {
+	request := RevokeAPIKeyRequest{Id: id}
+	response, err := e.RevokeAPIKeyEndpoint(ctx, request)
+	if err != nil {
+		return err
+	}
+	return response.(RevokeAPIKeyResponse).Err
+}
+
+// AuthAPIKey implements Service. Primarily useful in a client.
+func (e Endpoints) AuthAPIKey(ctx context.Context, key string) (r0 registry.APIKey, e1 error) {
+	request := AuthAPIKeyRequest{Key: key}
+	response, err := e.AuthAPIKeyEndpoint(ctx, request)
+	if err != nil {
+		return r0, err
+	}
+	return response.(AuthAPIKeyResponse).Key, response.(AuthAPIKeyResponse).Err
+}
+
+func encodeAddOrganizationRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	req.URL.Path = "/orgs"
+	return encodeRequest(ctx, req, request)
+}
+
+func encodeGetOrganizationRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	r := request.(GetOrganizationRequest)
+	req.URL.Path = "/orgs/" + r.Id
+	return encodeRequest(ctx, req, request)
+}
+
+func encodeListOrganizationsRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	req.URL.Path = "/orgs"
+	return encodeRequest(ctx, req, request)
+}
+
+func encodeDeleteOrganizationRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	r := request.(DeleteOrganizationRequest)
+	req.URL.Path = "/orgs/" + r.Id
+	return encodeRequest(ctx, req, request)
+}
+
+// MakeAddOrganizationEndpoint returns an endpoint that invokes AddOrganization on the service.
+func MakeAddOrganizationEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(AddOrganizationRequest)
+		r0, e1 := s.AddOrganization(ctx, req.Org)
+		return AddOrganizationResponse{
+			Org: r0,
+			Err: e1,
+		}, nil
+	}
+}
+
+// MakeGetOrganizationEndpoint returns an endpoint that invokes GetOrganization on the service.
+func MakeGetOrganizationEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(GetOrganizationRequest)
+		r0, e1 := s.GetOrganization(ctx, req.Id)
+		return GetOrganizationResponse{
+			Org: r0,
+			Err: e1,
+		}, nil
+	}
+}
+
+// MakeListOrganizationsEndpoint returns an endpoint that invokes ListOrganizations on the service.
+func MakeListOrganizationsEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		r0, e1 := s.ListOrganizations(ctx)
+		return ListOrganizationsResponse{
+			Orgs: r0,
+			Err:  e1,
+		}, nil
+	}
+}
+
+// MakeDeleteOrganizationEndpoint returns an endpoint that invokes DeleteOrganization on the service.
+func MakeDeleteOrganizationEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(DeleteOrganizationRequest)
+		e0 := s.DeleteOrganization(ctx, req.Id)
+		return DeleteOrganizationResponse{Err: e0}, nil
+	}
+}
+
+// AddOrganization implements Service. Primarily useful in a client.
+func (e Endpoints) AddOrganization(ctx context.Context, org registry.Organization) (r0 registry.Organization, e1 error) {
+	request := AddOrganizationRequest{Org: org}
+	response, err := e.AddOrganizationEndpoint(ctx, request)
+	if err != nil {
+		return r0, err
+	}
+	return response.(AddOrganizationResponse).Org, response.(AddOrganizationResponse).Err
+}
+
+// GetOrganization implements Service. Primarily useful in a client.
+func (e Endpoints) GetOrganization(ctx context.Context, id string) (r0 registry.Organization, e1 error) {
+	request := GetOrganizationRequest{Id: id}
+	response, err := e.GetOrganizationEndpoint(ctx, request)
+	if err != nil {
+		return r0, err
+	}
+	return response.(GetOrganizationResponse).Org, response.(GetOrganizationResponse).Err
+}
+
+// ListOrganizations implements Service. Primarily useful in a client.
+func (e Endpoints) ListOrganizations(ctx context.Context) (r0 []registry.Organization, e1 error) {
+	request := ListOrganizationsRequest{}
+	response, err := e.ListOrganizationsEndpoint(ctx, request)
+	if err != nil {
+		return r0, err
+	}
+	return response.(ListOrganizationsResponse).Orgs, response.(ListOrganizationsResponse).Err
+}
+
+// DeleteOrganization implements Service. Primarily useful in a client.
+func (e Endpoints) DeleteOrganization(ctx context.Context, id string) (e0 error) {
+	request := DeleteOrganizationRequest{Id: id}
+	response, err := e.DeleteOrganizationEndpoint(ctx, request)
+	if err != nil {
+		return err
+	}
+	return response.(DeleteOrganizationResponse).Err
+}
+
+func encodeAddFirmwareRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	req.URL.Path = "/firmware"
+	return encodeRequest(ctx, req, request)
+}
+
+func encodeGetFirmwareRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	r := request.(GetFirmwareRequest)
+	req.URL.Path = "/firmware/" + url.PathEscape(r.Id)
+	return nil
+}
+
+func encodeListFirmwareRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	req.URL.Path = "/firmware"
+	return nil
+}
+
+func encodeDeleteFirmwareRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	r := request.(DeleteFirmwareRequest)
+	req.URL.Path = "/firmware/" + url.PathEscape(r.Id)
+	return encodeRequest(ctx, req, request)
+}
+
+func encodeCreateCampaignRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	req.URL.Path = "/campaigns"
+	return encodeRequest(ctx, req, request)
+}
+
+func encodeGetCampaignRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	r := request.(GetCampaignRequest)
+	req.URL.Path = "/campaigns/" + url.PathEscape(r.Id)
+	return nil
+}
+
+func encodeListCampaignsRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	req.URL.Path = "/campaigns"
+	return nil
+}
+
+func encodeAdvanceCampaignRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	r := request.(AdvanceCampaignRequest)
+	req.URL.Path = "/campaigns/" + url.PathEscape(r.Id) + "/rollout"
+	return encodeRequest(ctx, req, request)
+}
+
+func encodeListRolloutsRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	r := request.(ListRolloutsRequest)
+	req.URL.Path = "/campaigns/" + url.PathEscape(r.Id) + "/nodes"
+	return nil
+}
+
+func encodeReportRolloutRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	r := request.(ReportRolloutRequest)
+	req.URL.Path = "/campaigns/" + url.PathEscape(r.Id) + "/nodes/" + url.PathEscape(r.Node)
+	return encodeRequest(ctx, req, request)
+}
+
+// MakeAddFirmwareEndpoint returns an endpoint that invokes AddFirmware on the service.
+func MakeAddFirmwareEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(AddFirmwareRequest)
+		r0, e1 := s.AddFirmware(ctx, req.Firmware)
+		return AddFirmwareResponse{
+			Firmware: r0,
+			Err:      e1,
+		}, nil
+	}
+}
+
+// MakeGetFirmwareEndpoint returns an endpoint that invokes GetFirmware on the service.
+func MakeGetFirmwareEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(GetFirmwareRequest)
+		r0, e1 := s.GetFirmware(ctx, req.Id)
+		return GetFirmwareResponse{
+			Firmware: r0,
+			Err:      e1,
+		}, nil
+	}
+}
+
+// MakeListFirmwareEndpoint returns an endpoint that invokes ListFirmware on the service.
+func MakeListFirmwareEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		r0, e1 := s.ListFirmware(ctx)
+		return ListFirmwareResponse{
+			Firmware: r0,
+			Err:      e1,
+		}, nil
+	}
+}
+
+// MakeDeleteFirmwareEndpoint returns an endpoint that invokes DeleteFirmware on the service.
+func MakeDeleteFirmwareEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(DeleteFirmwareRequest)
+		e0 := s.DeleteFirmware(ctx, req.Id)
+		return DeleteFirmwareResponse{Err: e0}, nil
+	}
+}
+
+// MakeCreateCampaignEndpoint returns an endpoint that invokes CreateCampaign on the service.
+func MakeCreateCampaignEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(CreateCampaignRequest)
+		r0, e1 := s.CreateCampaign(ctx, req.Campaign)
+		return CreateCampaignResponse{
+			Campaign: r0,
+			Err:      e1,
+		}, nil
+	}
+}
+
+// MakeGetCampaignEndpoint returns an endpoint that invokes GetCampaign on the service.
+func MakeGetCampaignEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(GetCampaignRequest)
+		r0, e1 := s.GetCampaign(ctx, req.Id)
+		return GetCampaignResponse{
+			Campaign: r0,
+			Err:      e1,
+		}, nil
+	}
+}
+
+// MakeListCampaignsEndpoint returns an endpoint that invokes ListCampaigns on the service.
+func MakeListCampaignsEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		r0, e1 := s.ListCampaigns(ctx)
+		return ListCampaignsResponse{
+			Campaigns: r0,
+			Err:       e1,
+		}, nil
+	}
+}
+
+// MakeAdvanceCampaignEndpoint returns an endpoint that invokes AdvanceCampaign on the service.
+func MakeAdvanceCampaignEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(AdvanceCampaignRequest)
+		r0, e1 := s.AdvanceCampaign(ctx, req.Id, req.Rollout)
+		return AdvanceCampaignResponse{
+			Campaign: r0,
+			Err:      e1,
+		}, nil
+	}
+}
+
+// MakeListRolloutsEndpoint returns an endpoint that invokes ListRollouts on the service.
+func MakeListRolloutsEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ListRolloutsRequest)
+		r0, e1 := s.ListRollouts(ctx, req.Id)
+		return ListRolloutsResponse{
+			Rollouts: r0,
+			Err:      e1,
+		}, nil
+	}
+}
+
+// MakeReportRolloutEndpoint returns an endpoint that invokes ReportRollout on the service.
+func MakeReportRolloutEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ReportRolloutRequest)
+		r0, e1 := s.ReportRollout(ctx, req.Id, req.Node, req.Status, req.Err)
+		return ReportRolloutResponse{
+			Rollout: r0,
+			Err:     e1,
+		}, nil
+	}
+}
+
+// AddFirmware implements Service. Primarily useful in a client.
+func (e Endpoints) AddFirmware(ctx context.Context, fw registry.Firmware) (r0 registry.Firmware, e1 error) {
+	request := AddFirmwareRequest{Firmware: fw}
+	response, err := e.AddFirmwareEndpoint(ctx, request)
+	if err != nil {
+		return r0, err
+	}
+	return response.(AddFirmwareResponse).Firmware, response.(AddFirmwareResponse).Err
+}
+
+// GetFirmware implements Service. Primarily useful in a client.
+func (e Endpoints) GetFirmware(ctx context.Context, id string) (r0 registry.Firmware, e1 error) {
+	request := GetFirmwareRequest{Id: id}
+	response, err := e.GetFirmwareEndpoint(ctx, request)
+	if err != nil {
+		return r0, err
+	}
+	return response.(GetFirmwareResponse).Firmware, response.(GetFirmwareResponse).Err
+}
+
+// ListFirmware implements Service. Primarily useful in a client.
+func (e Endpoints) ListFirmware(ctx context.Context) (r0 []registry.Firmware, e1 error) {
+	request := ListFirmwareRequest{}
+	response, err := e.ListFirmwareEndpoint(ctx, request)
+	if err != nil {
+		return r0, err
+	}
+	return response.(ListFirmwareResponse).Firmware, response.(ListFirmwareResponse).Err
+}
+
+// DeleteFirmware implements Service. Primarily useful in a client.
+func (e Endpoints) DeleteFirmware(ctx context.Context, id string) (e0 error) {
+	request := DeleteFirmwareRequest{Id: id}
+	response, err := e.DeleteFirmwareEndpoint(ctx, request)
+	if err != nil {
+		return err
+	}
+	return response.(DeleteFirmwareResponse).Err
+}
+
+// CreateCampaign implements Service. Primarily useful in a client.
+func (e Endpoints) CreateCampaign(ctx context.Context, campaign registry.Campaign) (r0 registry.Campaign, e1 error) {
+	request := CreateCampaignRequest{Campaign: campaign}
+	response, err := e.CreateCampaignEndpoint(ctx, request)
+	if err != nil {
+		return r0, err
 	}
+	return response.(CreateCampaignResponse).Campaign, response.(CreateCampaignResponse).Err
 }
 
-// MakeUpdateUserEndpoint returns an endpoint that invokes UpdateUser on the service.
-func MakeUpdateUserEndpoint(s registry.Service) endpoint.Endpoint {
-	return func(ctx context.Context, request interface{}) (interface{}, error) {
-		req := request.(UpdateUserRequest)
-		r0, e1 := s.UpdateUser(ctx, req.Id, req.User)
-		return UpdateUserResponse{
-			Err:  e1,
-			User: r0,
-		}, nil
+// GetCampaign implements Service. Primarily useful in a client.
+func (e Endpoints) GetCampaign(ctx context.Context, id string) (r0 registry.Campaign, e1 error) {
+	request := GetCampaignRequest{Id: id}
+	response, err := e.GetCampaignEndpoint(ctx, request)
+	if err != nil {
+		return r0, err
 	}
+	return response.(GetCampaignResponse).Campaign, response.(GetCampaignResponse).Err
 }
 
-// AddNodeRequest collects the request parameters for the AddNode method.
-type AddNodeRequest struct {
-	Node registry.Node `json:"node"`
+// ListCampaigns implements Service. Primarily useful in a client.
+func (e Endpoints) ListCampaigns(ctx context.Context) (r0 []registry.Campaign, e1 error) {
+	request := ListCampaignsRequest{}
+	response, err := e.ListCampaignsEndpoint(ctx, request)
+	if err != nil {
+		return r0, err
+	}
+	return response.(ListCampaignsResponse).Campaigns, response.(ListCampaignsResponse).Err
 }
 
-// AddNodeResponse collects the response parameters for the AddNode method.
-type AddNodeResponse struct {
-	Err error `json:"e0"`
+// AdvanceCampaign implements Service. Primarily useful in a client.
+func (e Endpoints) AdvanceCampaign(ctx context.Context, id string, percent int) (r0 registry.Campaign, e1 error) {
+	request := AdvanceCampaignRequest{Id: id, Rollout: percent}
+	response, err := e.AdvanceCampaignEndpoint(ctx, request)
+	if err != nil {
+		return r0, err
+	}
+	return response.(AdvanceCampaignResponse).Campaign, response.(AdvanceCampaignResponse).Err
 }
 
-// MakeAddNodeEndpoint returns an endpoint that invokes AddNode on the service.
-func MakeAddNodeEndpoint(s registry.Service) endpoint.Endpoint {
-	return func(ctx context.Context, request interface{}) (interface{}, error) {
-		req := request.(AddNodeRequest)
-		e0 := s.AddNode(ctx, req.Node)
-		return AddNodeResponse{Err: e0}, nil
+// ListRollouts implements Service. Primarily useful in a client.
+func (e Endpoints) ListRollouts(ctx context.Context, campaign string) (r0 []registry.Rollout, e1 error) {
+	request := ListRolloutsRequest{Id: campaign}
+	response, err := e.ListRolloutsEndpoint(ctx, request)
+	if err != nil {
+		return r0, err
 	}
+	return response.(ListRolloutsResponse).Rollouts, response.(ListRolloutsResponse).Err
 }
 
-// MakeGetNodeEndpoint returns an endpoint that invokes GetNode on the service.
-func MakeGetNodeEndpoint(s registry.Service) endpoint.Endpoint {
-	return func(ctx context.Context, request interface{}) (interface{}, error) {
-		req := request.(GetNodeRequest)
-		r0, e1 := s.GetNode(ctx, req.Id)
-		return GetNodeResponse{
-			Err:  e1,
-			Node: r0,
-		}, nil
+// ReportRollout implements Service. Primarily useful in a client.
+func (e Endpoints) ReportRollout(ctx context.Context, campaign, node string, status registry.RolloutStatus, msg string) (r0 registry.Rollout, e1 error) {
+	request := ReportRolloutRequest{Id: campaign, Node: node, Status: status, Err: msg}
+	response, err := e.ReportRolloutEndpoint(ctx, request)
+	if err != nil {
+		return r0, err
 	}
+	return response.(ReportRolloutResponse).Rollout, response.(ReportRolloutResponse).Err
 }
 
-// Failed implements Failer.
-func (r GetNodeResponse) Failed() error {
-	return r.Err
+func encodeAddSchemaRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	req.URL.Path = "/schemas"
+	return encodeRequest(ctx, req, request)
 }
 
-// ListNodesRequest collects the request parameters for the ListNodes method.
-type ListNodesRequest struct{}
+func encodeGetSchemaRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	r := request.(GetSchemaRequest)
+	req.URL.Path = "/schemas/" + url.PathEscape(r.Id)
+	return nil
+}
 
-// ListNodesResponse collects the response parameters for the ListNodes method.
-type ListNodesResponse struct {
-	Nodes []registry.Node `json:"nodes"`
-	Err   error           `json:"err"`
+func encodeListSchemasRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	r := request.(ListSchemasRequest)
+	req.URL.Path = "/schemas"
+	q := url.Values{}
+	if r.Type != 0 {
+		q.Set("type", strconv.Itoa(r.Type))
+	}
+	if r.Node != "" {
+		q.Set("node", r.Node)
+	}
+	if r.Kind != "" {
+		q.Set("kind", string(r.Kind))
+	}
+	req.URL.RawQuery = q.Encode()
+	return nil
 }
 
-// MakeListNodesEndpoint returns an endpoint that invokes ListNodes on the service.
-func MakeListNodesEndpoint(s registry.Service) endpoint.Endpoint {
+func encodeNodeSchemaRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	r := request.(NodeSchemaRequest)
+	req.URL.Path = "/nodes/" + url.PathEscape(r.Id) + "/schema"
+	q := url.Values{}
+	if r.Kind != "" {
+		q.Set("kind", string(r.Kind))
+	}
+	if r.Version != 0 {
+		q.Set("version", strconv.Itoa(r.Version))
+	}
+	req.URL.RawQuery = q.Encode()
+	return nil
+}
+
+func encodeValidatePayloadRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	r := request.(ValidatePayloadRequest)
+	req.URL.Path = "/nodes/" + url.PathEscape(r.Id) + "/schema/validate"
+	return encodeRequest(ctx, req, request)
+}
+
+// MakeAddSchemaEndpoint returns an endpoint that invokes AddSchema on the service.
+func MakeAddSchemaEndpoint(s registry.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
-		r0, e1 := s.ListNodes(ctx)
-		return ListNodesResponse{
-			Err:   e1,
-			Nodes: r0,
+		req := request.(AddSchemaRequest)
+		r0, e1 := s.AddSchema(ctx, req.Schema)
+		return AddSchemaResponse{
+			Schema: r0,
+			Err:    e1,
 		}, nil
 	}
 }
 
-// Failed implements Failer.
-func (r ListNodesResponse) Failed() error {
-	return r.Err
+// MakeGetSchemaEndpoint returns an endpoint that invokes GetSchema on the service.
+func MakeGetSchemaEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(GetSchemaRequest)
+		r0, e1 := s.GetSchema(ctx, req.Id)
+		return GetSchemaResponse{
+			Schema: r0,
+			Err:    e1,
+		}, nil
+	}
 }
 
-// DeleteNodeRequest collects the request parameters for the DeleteNode method.
-type DeleteNodeRequest struct {
-	Id string `json:"id"`
+// MakeListSchemasEndpoint returns an endpoint that invokes ListSchemas on the service.
+func MakeListSchemasEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ListSchemasRequest)
+		r0, e1 := s.ListSchemas(ctx, registry.SchemaFilter{Type: req.Type, Node: req.Node, Kind: req.Kind})
+		return ListSchemasResponse{
+			Schemas: r0,
+			Err:     e1,
+		}, nil
+	}
 }
 
-// DeleteNodeResponse collects the response parameters for the DeleteNode method.
-type DeleteNodeResponse struct {
-	Err error `json:"e0"`
+// MakeNodeSchemaEndpoint returns an endpoint that invokes NodeSchema on the service.
+func MakeNodeSchemaEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(NodeSchemaRequest)
+		r0, e1 := s.NodeSchema(ctx, req.Id, req.Kind, req.Version)
+		return NodeSchemaResponse{
+			Schema: r0,
+			Err:    e1,
+		}, nil
+	}
 }
 
-// MakeDeleteNodeEndpoint returns an endpoint that invokes DeleteNode on the service.
-func MakeDeleteNodeEndpoint(s registry.Service) endpoint.Endpoint {
+// MakeValidatePayloadEndpoint returns an endpoint that invokes ValidatePayload on the service.
+func MakeValidatePayloadEndpoint(s registry.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
-		req := request.(DeleteNodeRequest)
-		e0 := s.DeleteNode(ctx, req.Id)
-		return DeleteNodeResponse{Err: e0}, nil
+		req := request.(ValidatePayloadRequest)
+		r0, e1 := s.ValidatePayload(ctx, req.Id, req.Kind, req.Version, req.Payload)
+		return ValidatePayloadResponse{
+			Validation: r0,
+			Err:        e1,
+		}, nil
 	}
 }
 
-// Failed implements Failer.
-func (r DeleteNodeResponse) Failed() error {
-	return r.Err
+// AddSchema implements Service. Primarily useful in a client.
+func (e Endpoints) AddSchema(ctx context.Context, schema registry.Schema) (r0 registry.Schema, e1 error) {
+	request := AddSchemaRequest{Schema: schema}
+	response, err := e.AddSchemaEndpoint(ctx, request)
+	if err != nil {
+		return r0, err
+	}
+	return response.(AddSchemaResponse).Schema, response.(AddSchemaResponse).Err
 }
 
-// UpdateNodeRequest collects the request parameters for the UpdateNode method.
-type UpdateNodeRequest struct {
-	Id   string        `json:"id"`
-	User registry.Node `json:"user"`
+// GetSchema implements Service. Primarily useful in a client.
+func (e Endpoints) GetSchema(ctx context.Context, id string) (r0 registry.Schema, e1 error) {
+	request := GetSchemaRequest{Id: id}
+	response, err := e.GetSchemaEndpoint(ctx, request)
+	if err != nil {
+		return r0, err
+	}
+	return response.(GetSchemaResponse).Schema, response.(GetSchemaResponse).Err
 }
 
-// UpdateNodeResponse collects the response parameters for the UpdateNode method.
-type UpdateNodeResponse struct {
-	Node registry.Node `json:"r0"`
-	Err  error         `json:"e1"`
+// ListSchemas implements Service. Primarily useful in a client.
+func (e Endpoints) ListSchemas(ctx context.Context, filter registry.SchemaFilter) (r0 []registry.Schema, e1 error) {
+	request := ListSchemasRequest{Type: filter.Type, Node: filter.Node, Kind: filter.Kind}
+	response, err := e.ListSchemasEndpoint(ctx, request)
+	if err != nil {
+		return r0, err
+	}
+	return response.(ListSchemasResponse).Schemas, response.(ListSchemasResponse).Err
 }
 
-// MakeUpdateNodeEndpoint returns an endpoint that invokes UpdateNode on the service.
-func MakeUpdateNodeEndpoint(s registry.Service) endpoint.Endpoint {
-	return func(ctx context.Context, request interface{}) (interface{}, error) {
-		req := request.(UpdateNodeRequest)
-		r0, e1 := s.UpdateNode(ctx, req.Id, req.User)
-		return UpdateNodeResponse{
-			Err:  e1,
-			Node: r0,
-		}, nil
+// NodeSchema implements Service. Primarily useful in a client.
+func (e Endpoints) NodeSchema(ctx context.Context, node string, kind registry.SchemaKind, version int) (r0 registry.Schema, e1 error) {
+	request := NodeSchemaRequest{Id: node, Kind: kind, Version: version}
+	response, err := e.NodeSchemaEndpoint(ctx, request)
+	if err != nil {
+		return r0, err
 	}
+	return response.(NodeSchemaResponse).Schema, response.(NodeSchemaResponse).Err
 }
 
-// Failed implements Failer.
-func (r UpdateNodeResponse) Failed() error {
-	return r.Err
+// ValidatePayload implements Service. Primarily useful in a client.
+func (e Endpoints) ValidatePayload(ctx context.Context, node string, kind registry.SchemaKind, version int, payload json.RawMessage) (r0 registry.Validation, e1 error) {
+	request := ValidatePayloadRequest{Id: node, Kind: kind, Version: version, Payload: payload}
+	response, err := e.ValidatePayloadEndpoint(ctx, request)
+	if err != nil {
+		return r0, err
+	}
+	return response.(ValidatePayloadResponse).Validation, response.(ValidatePayloadResponse).Err
 }
 
-// MakeAddRegionEndpoint returns an endpoint that invokes AddRegion on the service.
-func MakeAddRegionEndpoint(s registry.Service) endpoint.Endpoint {
+func encodeSetQuotaRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	r := request.(SetQuotaRequest)
+	req.URL.Path = scopePath(r.Quota.Scope, r.Quota.ID, "quota")
+	return encodeRequest(ctx, req, request)
+}
+
+func encodeListQuotasRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	req.URL.Path = "/quotas"
+	return nil
+}
+
+func encodeUsageRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	r := request.(UsageRequest)
+	req.URL.Path = scopePath(r.Scope, r.Id, "usage")
+	return nil
+}
+
+// MakeSetQuotaEndpoint returns an endpoint that invokes SetQuota on the service.
+func MakeSetQuotaEndpoint(s registry.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
-		req := request.(AddRegionRequest)
-		e0 := s.AddRegion(ctx, req.Region)
-		return AddRegionResponse{Err: e0}, nil
+		req := request.(SetQuotaRequest)
+		r0, e1 := s.SetQuota(ctx, req.Quota)
+		return SetQuotaResponse{
+			Quota: r0,
+			Err:   e1,
+		}, nil
 	}
 }
 
-// MakeListRegionsEndpoint returns an endpoint that invokes ListRegions on the service.
-func MakeListRegionsEndpoint(s registry.Service) endpoint.Endpoint {
+// MakeListQuotasEndpoint returns an endpoint that invokes ListQuotas on the service.
+func MakeListQuotasEndpoint(s registry.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
-		r0, e1 := s.ListRegions(ctx)
-		return ListRegionsResponse{
-			Err:     e1,
-			Regions: r0,
+		r0, e1 := s.ListQuotas(ctx)
+		return ListQuotasResponse{
+			Quotas: r0,
+			Err:    e1,
 		}, nil
 	}
 }
 
-func (e Endpoints) AuthUser(ctx context.Context, id, password string) (err error) {
-	request := AuthUserRequest{
-		Id:       id,
-		Password: password,
+// MakeUsageEndpoint returns an endpoint that invokes Usage on the service.
+func MakeUsageEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(UsageRequest)
+		r0, e1 := s.Usage(ctx, req.Scope, req.Id)
+		return UsageResponse{
+			Usage: r0,
+			Err:   e1,
+		}, nil
 	}
-	response, err := e.AuthUserEndpoint(ctx, request)
+}
+
+// SetQuota implements Service. Primarily useful in a client.
+func (e Endpoints) SetQuota(ctx context.Context, quota registry.Quota) (r0 registry.Quota, e1 error) {
+	request := SetQuotaRequest{Quota: quota}
+	response, err := e.SetQuotaEndpoint(ctx, request)
 	if err != nil {
-		return
+		return r0, err
 	}
-
-	err = response.(AuthUserResponse).Err
-	return
+	return response.(SetQuotaResponse).Quota, response.(SetQuotaResponse).Err
 }
 
-// GetUser implements Service. Primarily useful in a client.
-func (e Endpoints) GetUser(ctx context.Context, id string) (user registry.User, err error) {
-	request := GetUserRequest{Id: id}
-	response, err := e.GetUserEndpoint(ctx, request)
+// ListQuotas implements Service. Primarily useful in a client.
+func (e Endpoints) ListQuotas(ctx context.Context) (r0 []registry.Quota, e1 error) {
+	request := ListQuotasRequest{}
+	response, err := e.ListQuotasEndpoint(ctx, request)
 	if err != nil {
-		return
+		return r0, err
 	}
-
-	user = response.(GetUserResponse).User
-	return
+	return response.(ListQuotasResponse).Quotas, response.(ListQuotasResponse).Err
 }
 
-// AddUser implements Service. Primarily useful in a client.
-func (e Endpoints) AddUser(ctx context.Context, user registry.User) (e0 error) {
-	request := AddUserRequest{User: user}
-	response, err := e.AddUserEndpoint(ctx, request)
+// Usage implements Service. Primarily useful in a client.
+func (e Endpoints) Usage(ctx context.Context, scope registry.QuotaScope, id string) (r0 registry.Usage, e1 error) {
+	request := UsageRequest{Scope: scope, Id: id}
+	response, err := e.UsageEndpoint(ctx, request)
 	if err != nil {
-		return
+		return r0, err
 	}
-	return response.(AddUserResponse).Err
+	return response.(UsageResponse).Usage, response.(UsageResponse).Err
 }
 
-// ListUser implements Service. Primarily useful in a client.
-func (e Endpoints) ListUser(ctx context.Context) (users []registry.User, err error) {
-	request := ListUserRequest{}
-	response, err := e.ListUserEndpoint(ctx, request)
-	if err != nil {
-		return
+func encodeInventoryRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	r := request.(InventoryRequest)
+	req.URL.Path = "/reports/inventory"
+	q := url.Values{}
+	for _, d := range r.By {
+		q.Add("by", string(d))
 	}
-	users = response.(ListUserResponse).Users
-	return
+	req.URL.RawQuery = regionQuery(r.Region, r.Subregions)
+	if len(q) > 0 {
+		req.URL.RawQuery += "&" + q.Encode()
+	}
+	return nil
 }
 
-// DeleteUser implements Service. Primarily useful in a client.
-func (e Endpoints) DeleteUser(ctx context.Context, id string) (e0 error) {
-	request := DeleteUserRequest{Id: id}
-	response, err := e.DeleteUserEndpoint(ctx, request)
-	if err != nil {
-		return
+func encodeGrowthRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	r := request.(GrowthRequest)
+	req.URL.Path = "/reports/growth"
+	q, _ := url.ParseQuery(regionQuery(r.Region, r.Subregions))
+	if r.Period != "" {
+		q.Set("period", string(r.Period))
 	}
-	return response.(DeleteUserResponse).Err
+	if !r.Since.IsZero() {
+		q.Set("since", r.Since.Format(time.RFC3339))
+	}
+	req.URL.RawQuery = q.Encode()
+	return nil
 }
 
-// UpdateUser implements Service. Primarily useful in a client.
-func (e Endpoints) UpdateUser(ctx context.Context, id string, user registry.User) (r0 registry.User, e1 error) {
-	request := UpdateUserRequest{
-		Id:   id,
-		User: user,
+func encodeOrphanedNodesRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	r := request.(OrphanedNodesRequest)
+	req.URL.Path = "/reports/orphans"
+	req.URL.RawQuery = regionQuery(r.Region, r.Subregions)
+	return nil
+}
+
+// MakeInventoryEndpoint returns an endpoint that invokes Inventory on the service.
+func MakeInventoryEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(InventoryRequest)
+		r0, e1 := s.Inventory(ctx, registry.RegionFilter{Region: req.Region, Subregions: req.Subregions}, req.By)
+		return InventoryResponse{
+			Inventory: r0,
+			Err:       e1,
+		}, nil
 	}
-	response, err := e.UpdateUserEndpoint(ctx, request)
-	if err != nil {
-		return
+}
+
+// MakeGrowthEndpoint returns an endpoint that invokes Growth on the service.
+func MakeGrowthEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(GrowthRequest)
+		r0, e1 := s.Growth(ctx, registry.RegionFilter{Region: req.Region, Subregions: req.Subregions}, req.Period, req.Since)
+		return GrowthResponse{
+			Growth: r0,
+			Err:    e1,
+		}, nil
 	}
-	return response.(UpdateUserResponse).User, response.(UpdateUserResponse).Err
 }
 
-// AddNode implements Service. Primarily useful in a client.
-func (e Endpoints) AddNode(ctx context.Context, node registry.Node) (e0 error) {
-	request := AddNodeRequest{Node: node}
-	response, err := e.AddNodeEndpoint(ctx, request)
-	if err != nil {
-		return
+// MakeOrphanedNodesEndpoint returns an endpoint that invokes OrphanedNodes on the service.
+func MakeOrphanedNodesEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(OrphanedNodesRequest)
+		r0, e1 := s.OrphanedNodes(ctx, registry.RegionFilter{Region: req.Region, Subregions: req.Subregions})
+		return OrphanedNodesResponse{
+			Nodes: r0,
+			Err:   e1,
+		}, nil
 	}
-	return response.(AddNodeResponse).Err
 }
 
-// GetNode implements Service. Primarily useful in a client.
-func (e Endpoints) GetNode(ctx context.Context, id string) (r0 registry.Node, e1 error) {
-	request := GetNodeRequest{Id: id}
-	response, err := e.GetNodeEndpoint(ctx, request)
+// Inventory implements Service. Primarily useful in a client.
+func (e Endpoints) Inventory(ctx context.Context, filter registry.RegionFilter, by []registry.Dimension) (r0 []registry.InventoryCount, e1 error) {
+	request := InventoryRequest{Region: filter.Region, Subregions: filter.Subregions, By: by}
+	response, err := e.InventoryEndpoint(ctx, request)
 	if err != nil {
-		return
+		return r0, err
 	}
-	return response.(GetNodeResponse).Node, response.(GetNodeResponse).Err
+	return response.(InventoryResponse).Inventory, response.(InventoryResponse).Err
 }
 
-// ListNodes implements Service. Primarily useful in a client.
-func (e Endpoints) ListNodes(ctx context.Context) (r0 []registry.Node, e1 error) {
-	request := ListNodesRequest{}
-	response, err := e.ListNodesEndpoint(ctx, request)
+// Growth implements Service. Primarily useful in a client.
+func (e Endpoints) Growth(ctx context.Context, filter registry.RegionFilter, period registry.GrowthPeriod, since time.Time) (r0 []registry.Growth, e1 error) {
+	request := GrowthRequest{Region: filter.Region, Subregions: filter.Subregions, Period: period, Since: since}
+	response, err := e.GrowthEndpoint(ctx, request)
 	if err != nil {
-		return
+		return r0, err
 	}
-	return response.(ListNodesResponse).Nodes, response.(ListNodesResponse).Err
+	return response.(GrowthResponse).Growth, response.(GrowthResponse).Err
 }
 
-// DeleteNode implements Service. Primarily useful in a client.
-func (e Endpoints) DeleteNode(ctx context.Context, id string) (e0 error) {
-	request := DeleteNodeRequest{Id: id}
-	response, err := e.DeleteNodeEndpoint(ctx, request)
+// OrphanedNodes implements Service. Primarily useful in a client.
+func (e Endpoints) OrphanedNodes(ctx context.Context, filter registry.RegionFilter) (r0 []registry.Node, e1 error) {
+	request := OrphanedNodesRequest{Region: filter.Region, Subregions: filter.Subregions}
+	response, err := e.OrphanedNodesEndpoint(ctx, request)
 	if err != nil {
-		return
+		return r0, err
 	}
-	return response.(DeleteNodeResponse).Err
+	return response.(OrphanedNodesResponse).Nodes, response.(OrphanedNodesResponse).Err
 }
 
-// UpdateNode implements Service. Primarily useful in a client.
-func (e Endpoints) UpdateNode(ctx context.Context, id string, user registry.Node) (r0 registry.Node, e1 error) {
-	request := UpdateNodeRequest{
-		Id:   id,
-		User: user,
+func encodeWatchRequest(ctx context.Context, req *http1.Request, request interface{}) error {
+	r := request.(WatchRequest)
+	q := url.Values{}
+	for _, entity := range r.Entities {
+		q.Add("entity", entity)
 	}
-	response, err := e.UpdateNodeEndpoint(ctx, request)
-	if err != nil {
-		return
+	for _, region := range r.Regions {
+		q.Add("region", region)
 	}
-	return response.(UpdateNodeResponse).Node, response.(UpdateNodeResponse).Err
+	for _, name := range r.Names {
+		q.Add("name", name)
+	}
+	if r.Cursor > 0 {
+		q.Set("cursor", strconv.FormatInt(int64(r.Cursor), 10))
+	}
+
+	req.URL.Path = "/events/watch"
+	req.URL.RawQuery = q.Encode()
+	req.Header.Set("Accept", eventStreamType)
+	return nil
 }
 
-// AddRegion implements Service. Primarily useful in a client.
-func (e Endpoints) AddRegion(ctx context.Context, region registry.Region) (e0 error) {
-	request := AddRegionRequest{Region: region}
-	response, err := e.AddRegionEndpoint(ctx, request)
-	if err != nil {
-		return
+// MakeWatchEndpoint returns an endpoint that invokes Watch on the service.
+func MakeWatchEndpoint(s registry.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(WatchRequest)
+		filter := registry.EventFilter{
+			Entities: req.Entities,
+			Regions:  req.Regions,
+			Names:    req.Names,
+		}
+		r0, e1 := s.Watch(ctx, filter, req.Cursor)
+		return WatchResponse{
+			Events: r0,
+			Err:    e1,
+		}, nil
 	}
-	return response.(AddRegionResponse).Err
 }
 
-// ListRegions implements Service. Primarily useful in a client.
-func (e Endpoints) ListRegions(ctx context.Context) (r0 []registry.Region, e1 error) {
-	request := ListRegionsRequest{}
-	response, err := e.ListRegionsEndpoint(ctx, request)
+// Watch implements Service. Primarily useful in a client.
+func (e Endpoints) Watch(ctx context.Context, filter registry.EventFilter, cursor time.Duration) (r0 <-chan registry.Event, e1 error) {
+	request := WatchRequest{
+		Entities: filter.Entities,
+		Regions:  filter.Regions,
+		Names:    filter.Names,
+		Cursor:   cursor,
+	}
+	response, err := e.WatchEndpoint(ctx, request)
 	if err != nil {
-		return
+		return r0, err
 	}
-	return response.(ListRegionsResponse).Regions, response.(ListRegionsResponse).Err
+	return response.(WatchResponse).Events, response.(WatchResponse).Err
 }
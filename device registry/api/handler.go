@@ -5,28 +5,55 @@ import (
 	"encoding/json"
 	"errors"
 	"github.com/go-kit/kit/log"
-	"github.com/go-kit/kit/transport"
 	kithttp "github.com/go-kit/kit/transport/http"
 	"github.com/gorilla/mux"
 	"github.com/piusalfred/registry"
+	"github.com/piusalfred/registry/feed"
+	"github.com/piusalfred/registry/logger"
+	errors2 "github.com/piusalfred/registry/pkg/errors"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 )
 
 var (
 	// ErrBadRouting is returned when an expected path variable is missing.
 	// It always indicates programmer error.
 	ErrBadRouting = errors.New("inconsistent mapping between route and handler (programmer error)")
+
+	// ErrMissingAPIKey is returned by RequireAPIKey for requests without a key
+	ErrMissingAPIKey = errors.New("api key required")
+
+	// ErrInvalidSubregions is returned for a subregions query parameter that
+	// is not a boolean
+	ErrInvalidSubregions = errors.New("subregions must be true or false")
+
+	// ErrInvalidNumber is returned for a numeric query parameter, like the
+	// node type or the version of a schema, that is not an integer
+	ErrInvalidNumber = errors.New("query parameter must be a positive integer")
+
+	// ErrInvalidSince is returned when the start of a report is neither a
+	// date nor an RFC3339 timestamp
+	ErrInvalidSince = errors.New("since must be a date like 2006-01-02 or an RFC3339 timestamp")
 )
 
-func MakeHTTPHandler(service registry.Service, logger log.Logger) http.Handler {
+// MakeHTTPHandler routes the requests to the endpoints of service, the
+// middlewares run after the api key of the request is authenticated.
+func MakeHTTPHandler(service registry.Service, logger log.Logger, middlewares ...mux.MiddlewareFunc) http.Handler {
 	r := mux.NewRouter()
 	e := MakeServerEndpoints(service)
 
 	options := []kithttp.ServerOption{
-		kithttp.ServerErrorHandler(transport.NewLogErrorHandler(logger)),
+		kithttp.ServerErrorHandler(logErrorHandler{logger}),
 		kithttp.ServerErrorEncoder(ErrorEncoder),
 	}
 
+	r.Use(authenticate(service))
+	r.Use(middlewares...)
+
 	//GET /users/{id}
 	//POST /users
 	//GET /users
@@ -90,6 +117,34 @@ func MakeHTTPHandler(service registry.Service, logger log.Logger) http.Handler {
 		options...,
 	))
 
+	r.Methods(http.MethodGet).Path("/regions/suggest").Handler(kithttp.NewServer(
+		e.SuggestRegionEndpoint,
+		decodeSuggestRegionRequest,
+		encodeSuggestRegionResponse,
+		options...,
+	))
+
+	r.Methods(http.MethodGet).Path("/regions/{id}/subtree").Handler(kithttp.NewServer(
+		e.RegionSubtreeEndpoint,
+		decodeRegionSubtreeRequest,
+		encodeRegionSubtreeResponse,
+		options...,
+	))
+
+	r.Methods(http.MethodGet).Path("/regions/{id}/ancestors").Handler(kithttp.NewServer(
+		e.RegionAncestorsEndpoint,
+		decodeRegionAncestorsRequest,
+		encodeRegionAncestorsResponse,
+		options...,
+	))
+
+	r.Methods(http.MethodPut).Path("/regions/{id}/parent").Handler(kithttp.NewServer(
+		e.MoveRegionEndpoint,
+		decodeMoveRegionRequest,
+		encodeMoveRegionResponse,
+		options...,
+	))
+
 	//nodes
 	r.Methods(http.MethodGet).Path("/nodes/{id}").Handler(kithttp.NewServer(
 		e.GetNodeEndpoint,
@@ -126,6 +181,343 @@ func MakeHTTPHandler(service registry.Service, logger log.Logger) http.Handler {
 		options...,
 	))
 
+	//node types
+	r.Methods(http.MethodPost).Path("/types").Handler(kithttp.NewServer(
+		e.AddNodeTypeEndpoint,
+		decodeAddNodeTypeRequest,
+		encodeAddNodeTypeResponse,
+		options...,
+	))
+
+	r.Methods(http.MethodGet).Path("/types").Handler(kithttp.NewServer(
+		e.ListNodeTypesEndpoint,
+		decodeListNodeTypesRequest,
+		encodeListNodeTypesResponse,
+		options...,
+	))
+
+	r.Methods(http.MethodGet).Path("/types/{id}").Handler(kithttp.NewServer(
+		e.GetNodeTypeEndpoint,
+		decodeGetNodeTypeRequest,
+		encodeGetNodeTypeResponse,
+		options...,
+	))
+
+	r.Methods(http.MethodDelete).Path("/types/{id}").Handler(kithttp.NewServer(
+		e.DeleteNodeTypeEndpoint,
+		decodeDeleteNodeTypeRequest,
+		encodeDeleteNodeTypeResponse,
+		options...,
+	))
+
+	r.Methods(http.MethodPatch).Path("/types/{id}").Handler(kithttp.NewServer(
+		e.UpdateNodeTypeEndpoint,
+		decodeUpdateNodeTypeRequest,
+		encodeUpdateNodeTypeResponse,
+		options...,
+	))
+
+	//claims
+	r.Methods(http.MethodPost).Path("/claims/codes").Handler(kithttp.NewServer(
+		e.CreateClaimCodeEndpoint,
+		decodeCreateClaimCodeRequest,
+		encodeCreateClaimCodeResponse,
+		options...,
+	))
+
+	r.Methods(http.MethodGet).Path("/claims/codes").Handler(kithttp.NewServer(
+		e.ListClaimCodesEndpoint,
+		decodeListClaimCodesRequest,
+		encodeListClaimCodesResponse,
+		options...,
+	))
+
+	r.Methods(http.MethodPost).Path("/provision").Handler(kithttp.NewServer(
+		e.ProvisionNodeEndpoint,
+		decodeProvisionNodeRequest,
+		encodeProvisionNodeResponse,
+		options...,
+	))
+
+	r.Methods(http.MethodPost).Path("/nodes/authenticate").Handler(kithttp.NewServer(
+		e.AuthenticateNodeEndpoint,
+		decodeAuthenticateNodeRequest,
+		encodeAuthenticateNodeResponse,
+		options...,
+	))
+
+	r.Methods(http.MethodGet).Path("/claims").Handler(kithttp.NewServer(
+		e.ListClaimsEndpoint,
+		decodeListClaimsRequest,
+		encodeListClaimsResponse,
+		options...,
+	))
+
+	//certificates
+	r.Methods(http.MethodPost).Path("/certs").Handler(kithttp.NewServer(
+		e.IssueCertificateEndpoint,
+		decodeIssueCertificateRequest,
+		encodeIssueCertificateResponse,
+		options...,
+	))
+
+	r.Methods(http.MethodPost).Path("/certs/{serial}/revoke").Handler(kithttp.NewServer(
+		e.RevokeCertificateEndpoint,
+		decodeRevokeCertificateRequest,
+		encodeRevokeCertificateResponse,
+		options...,
+	))
+
+	r.Methods(http.MethodGet).Path("/certs").Handler(kithttp.NewServer(
+		e.ListCertificatesEndpoint,
+		decodeListCertificatesRequest,
+		encodeListCertificatesResponse,
+		options...,
+	))
+
+	r.Methods(http.MethodGet).Path("/certs/crl").Handler(kithttp.NewServer(
+		e.RevocationListEndpoint,
+		decodeRevocationListRequest,
+		encodeRevocationListResponse,
+		options...,
+	))
+
+	//api keys
+	r.Methods(http.MethodPost).Path("/apikeys").Handler(kithttp.NewServer(
+		e.CreateAPIKeyEndpoint,
+		decodeCreateAPIKeyRequest,
+		encodeCreateAPIKeyResponse,
+		options...,
+	))
+
+	r.Methods(http.MethodGet).Path("/apikeys").Handler(kithttp.NewServer(
+		e.ListAPIKeysEndpoint,
+		decodeListAPIKeysRequest,
+		encodeListAPIKeysResponse,
+		options...,
+	))
+
+	r.Methods(http.MethodDelete).Path("/apikeys/{id}").Handler(kithttp.NewServer(
+		e.RevokeAPIKeyEndpoint,
+		decodeRevokeAPIKeyRequest,
+		encodeRevokeAPIKeyResponse,
+		options...,
+	))
+
+	r.Methods(http.MethodGet).Path("/auth").Handler(kithttp.NewServer(
+		e.AuthAPIKeyEndpoint,
+		decodeAuthAPIKeyRequest,
+		encodeAuthAPIKeyResponse,
+		options...,
+	))
+
+	//organizations
+	r.Methods(http.MethodPost).Path("/orgs").Handler(kithttp.NewServer(
+		e.AddOrganizationEndpoint,
+		decodeAddOrganizationRequest,
+		encodeAddOrganizationResponse,
+		options...,
+	))
+
+	r.Methods(http.MethodGet).Path("/orgs/{id}").Handler(kithttp.NewServer(
+		e.GetOrganizationEndpoint,
+		decodeGetOrganizationRequest,
+		encodeGetOrganizationResponse,
+		options...,
+	))
+
+	r.Methods(http.MethodGet).Path("/orgs").Handler(kithttp.NewServer(
+		e.ListOrganizationsEndpoint,
+		decodeListOrganizationsRequest,
+		encodeListOrganizationsResponse,
+		options...,
+	))
+
+	r.Methods(http.MethodDelete).Path("/orgs/{id}").Handler(kithttp.NewServer(
+		e.DeleteOrganizationEndpoint,
+		decodeDeleteOrganizationRequest,
+		encodeDeleteOrganizationResponse,
+		options...,
+	))
+
+	//firmware and ota campaigns
+	r.Methods(http.MethodPost).Path("/firmware").Handler(kithttp.NewServer(
+		e.AddFirmwareEndpoint,
+		decodeAddFirmwareRequest,
+		encodeAddFirmwareResponse,
+		options...,
+	))
+
+	r.Methods(http.MethodGet).Path("/firmware/{id}").Handler(kithttp.NewServer(
+		e.GetFirmwareEndpoint,
+		decodeGetFirmwareRequest,
+		encodeGetFirmwareResponse,
+		options...,
+	))
+
+	r.Methods(http.MethodGet).Path("/firmware").Handler(kithttp.NewServer(
+		e.ListFirmwareEndpoint,
+		decodeListFirmwareRequest,
+		encodeListFirmwareResponse,
+		options...,
+	))
+
+	r.Methods(http.MethodDelete).Path("/firmware/{id}").Handler(kithttp.NewServer(
+		e.DeleteFirmwareEndpoint,
+		decodeDeleteFirmwareRequest,
+		encodeDeleteFirmwareResponse,
+		options...,
+	))
+
+	r.Methods(http.MethodPost).Path("/campaigns").Handler(kithttp.NewServer(
+		e.CreateCampaignEndpoint,
+		decodeCreateCampaignRequest,
+		encodeCreateCampaignResponse,
+		options...,
+	))
+
+	r.Methods(http.MethodGet).Path("/campaigns/{id}").Handler(kithttp.NewServer(
+		e.GetCampaignEndpoint,
+		decodeGetCampaignRequest,
+		encodeGetCampaignResponse,
+		options...,
+	))
+
+	r.Methods(http.MethodGet).Path("/campaigns").Handler(kithttp.NewServer(
+		e.ListCampaignsEndpoint,
+		decodeListCampaignsRequest,
+		encodeListCampaignsResponse,
+		options...,
+	))
+
+	r.Methods(http.MethodPut).Path("/campaigns/{id}/rollout").Handler(kithttp.NewServer(
+		e.AdvanceCampaignEndpoint,
+		decodeAdvanceCampaignRequest,
+		encodeAdvanceCampaignResponse,
+		options...,
+	))
+
+	r.Methods(http.MethodGet).Path("/campaigns/{id}/nodes").Handler(kithttp.NewServer(
+		e.ListRolloutsEndpoint,
+		decodeListRolloutsRequest,
+		encodeListRolloutsResponse,
+		options...,
+	))
+
+	r.Methods(http.MethodPut).Path("/campaigns/{id}/nodes/{node}").Handler(kithttp.NewServer(
+		e.ReportRolloutEndpoint,
+		decodeReportRolloutRequest,
+		encodeReportRolloutResponse,
+		options...,
+	))
+
+	//node schemas
+	r.Methods(http.MethodPost).Path("/schemas").Handler(kithttp.NewServer(
+		e.AddSchemaEndpoint,
+		decodeAddSchemaRequest,
+		encodeAddSchemaResponse,
+		options...,
+	))
+
+	r.Methods(http.MethodGet).Path("/schemas/{id}").Handler(kithttp.NewServer(
+		e.GetSchemaEndpoint,
+		decodeGetSchemaRequest,
+		encodeGetSchemaResponse,
+		options...,
+	))
+
+	r.Methods(http.MethodGet).Path("/schemas").Handler(kithttp.NewServer(
+		e.ListSchemasEndpoint,
+		decodeListSchemasRequest,
+		encodeListSchemasResponse,
+		options...,
+	))
+
+	r.Methods(http.MethodGet).Path("/nodes/{id}/schema").Handler(kithttp.NewServer(
+		e.NodeSchemaEndpoint,
+		decodeNodeSchemaRequest,
+		encodeNodeSchemaResponse,
+		options...,
+	))
+
+	r.Methods(http.MethodPost).Path("/nodes/{id}/schema/validate").Handler(kithttp.NewServer(
+		e.ValidatePayloadEndpoint,
+		decodeValidatePayloadRequest,
+		encodeValidatePayloadResponse,
+		options...,
+	))
+
+	//quotas
+	r.Methods(http.MethodPut).Path("/regions/{id}/quota").Handler(kithttp.NewServer(
+		e.SetQuotaEndpoint,
+		decodeSetRegionQuotaRequest,
+		encodeSetQuotaResponse,
+		options...,
+	))
+
+	r.Methods(http.MethodPut).Path("/orgs/{id}/quota").Handler(kithttp.NewServer(
+		e.SetQuotaEndpoint,
+		decodeSetOrgQuotaRequest,
+		encodeSetQuotaResponse,
+		options...,
+	))
+
+	r.Methods(http.MethodGet).Path("/quotas").Handler(kithttp.NewServer(
+		e.ListQuotasEndpoint,
+		decodeListQuotasRequest,
+		encodeListQuotasResponse,
+		options...,
+	))
+
+	r.Methods(http.MethodGet).Path("/regions/{id}/usage").Handler(kithttp.NewServer(
+		e.UsageEndpoint,
+		decodeRegionUsageRequest,
+		encodeUsageResponse,
+		options...,
+	))
+
+	r.Methods(http.MethodGet).Path("/orgs/{id}/usage").Handler(kithttp.NewServer(
+		e.UsageEndpoint,
+		decodeOrgUsageRequest,
+		encodeUsageResponse,
+		options...,
+	))
+
+	//reports
+	r.Methods(http.MethodGet).Path("/reports/inventory").Handler(kithttp.NewServer(
+		e.InventoryEndpoint,
+		decodeInventoryRequest,
+		encodeInventoryResponse,
+		options...,
+	))
+
+	r.Methods(http.MethodGet).Path("/reports/growth").Handler(kithttp.NewServer(
+		e.GrowthEndpoint,
+		decodeGrowthRequest,
+		encodeGrowthResponse,
+		options...,
+	))
+
+	r.Methods(http.MethodGet).Path("/reports/orphans").Handler(kithttp.NewServer(
+		e.OrphanedNodesEndpoint,
+		decodeOrphanedNodesRequest,
+		encodeOrphanedNodesResponse,
+		options...,
+	))
+
+	//GET /events/watch
+	r.Methods(http.MethodGet).Path("/events/watch").Handler(kithttp.NewServer(
+		e.WatchEndpoint,
+		decodeWatchRequest,
+		encodeWatchResponse,
+		append(options, kithttp.ServerBefore(withHTTPRequest))...,
+	))
+
+	//GET /openapi.json
+	//GET /docs
+	r.Methods(http.MethodGet).Path("/openapi.json").HandlerFunc(serveOpenAPI)
+	r.Methods(http.MethodGet).Path("/docs").HandlerFunc(serveDocs)
+
 	return r
 }
 
@@ -197,12 +589,59 @@ func encodeAddUserResponse(ctx context.Context, w http.ResponseWriter, response
 	return
 }
 
-// decodeListUserRequest is a transport/http.DecodeRequestFunc that decodes a
-// JSON-encoded request from the HTTP request body.
+// decodeListUserRequest is a transport/http.DecodeRequestFunc that decodes
+// the region filter from the request query.
 func decodeListUserRequest(_ context.Context, r *http.Request) (interface{}, error) {
-	req := ListUserRequest{}
-	err := json.NewDecoder(r.Body).Decode(&req)
-	return req, err
+	region, subregions, err := regionFilter(r)
+	if err != nil {
+		return nil, err
+	}
+	return ListUserRequest{Region: region, Subregions: subregions}, nil
+}
+
+// regionFilter reads the region and subregions query parameters of the
+// list requests
+func regionFilter(r *http.Request) (string, bool, error) {
+	q := r.URL.Query()
+	if q.Get("subregions") == "" {
+		return q.Get("region"), false, nil
+	}
+
+	subregions, err := strconv.ParseBool(q.Get("subregions"))
+	if err != nil {
+		return "", false, ErrInvalidSubregions
+	}
+	return q.Get("region"), subregions, nil
+}
+
+// intQuery reads the numeric query parameter key, 0 when it is not set
+func intQuery(q url.Values, key string) (int, error) {
+	if q.Get(key) == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.Atoi(q.Get(key))
+	if err != nil || n < 0 {
+		return 0, ErrInvalidNumber
+	}
+	return n, nil
+}
+
+// timeQuery reads the date or timestamp query parameter key, zero when it is
+// not set
+func timeQuery(q url.Values, key string) (time.Time, error) {
+	v := q.Get(key)
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, ErrInvalidSince
+	}
+	return t, nil
 }
 
 // encodeListUserResponse is a transport/http.EncodeResponseFunc that encodes
@@ -311,12 +750,14 @@ func encodeGetNodeResponse(ctx context.Context, w http.ResponseWriter, response
 	return
 }
 
-// decodeListNodesRequest is a transport/http.DecodeRequestFunc that decodes a
-// JSON-encoded request from the HTTP request body.
+// decodeListNodesRequest is a transport/http.DecodeRequestFunc that decodes
+// the region filter from the request query.
 func decodeListNodesRequest(_ context.Context, r *http.Request) (interface{}, error) {
-	req := ListNodesRequest{}
-	err := json.NewDecoder(r.Body).Decode(&req)
-	return req, err
+	region, subregions, err := regionFilter(r)
+	if err != nil {
+		return nil, err
+	}
+	return ListNodesRequest{Region: region, Subregions: subregions}, nil
 }
 
 // encodeListNodesResponse is a transport/http.EncodeResponseFunc that encodes
@@ -421,21 +862,1236 @@ func encodeListRegionsResponse(ctx context.Context, w http.ResponseWriter, respo
 	err = json.NewEncoder(w).Encode(response)
 	return
 }
-func ErrorEncoder(_ context.Context, err error, w http.ResponseWriter) {
-	w.WriteHeader(err2code(err))
-	json.NewEncoder(w).Encode(errorWrapper{Error: err.Error()})
+
+// decodeSuggestRegionRequest is a transport/http.DecodeRequestFunc that
+// decodes the location from the request query.
+func decodeSuggestRegionRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	q := r.URL.Query()
+	lat, long, err := registry.ParseLocation(q.Get("latitude"), q.Get("longitude"))
+	if err != nil {
+		return nil, err
+	}
+
+	return SuggestRegionRequest{Latitude: lat, Longitude: long}, nil
 }
-func ErrorDecoder(r *http.Response) error {
-	var w errorWrapper
-	if err := json.NewDecoder(r.Body).Decode(&w); err != nil {
-		return err
+
+// encodeSuggestRegionResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeSuggestRegionResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
 	}
-	return errors.New(w.Error)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
 }
 
-// This is used to set the http status, see an example here :
-// https://github.com/go-kit/kit/blob/master/examples/addsvc/pkg/addtransport/http.go#L133
-func err2code(err error) int {
+// decodeMoveRegionRequest is a transport/http.DecodeRequestFunc that decodes
+// the region id from the request path and the parent from the request body.
+func decodeMoveRegionRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	vars := mux.Vars(r)
+	id, ok := vars["id"]
+	if !ok {
+		return nil, ErrBadRouting
+	}
+	req := MoveRegionRequest{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	req.Id = id
+	return req, err
+}
+
+// encodeMoveRegionResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeMoveRegionResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeRegionSubtreeRequest is a transport/http.DecodeRequestFunc that
+// decodes the region id from the request path.
+func decodeRegionSubtreeRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	vars := mux.Vars(r)
+	id, ok := vars["id"]
+	if !ok {
+		return nil, ErrBadRouting
+	}
+	return RegionSubtreeRequest{Id: id}, nil
+}
+
+// encodeRegionSubtreeResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeRegionSubtreeResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeRegionAncestorsRequest is a transport/http.DecodeRequestFunc that
+// decodes the region id from the request path.
+func decodeRegionAncestorsRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	vars := mux.Vars(r)
+	id, ok := vars["id"]
+	if !ok {
+		return nil, ErrBadRouting
+	}
+	return RegionAncestorsRequest{Id: id}, nil
+}
+
+// encodeRegionAncestorsResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeRegionAncestorsResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeAddNodeTypeRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded request from the HTTP request body.
+func decodeAddNodeTypeRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	req := AddNodeTypeRequest{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	return req, err
+}
+
+// encodeAddNodeTypeResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeAddNodeTypeResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeListNodeTypesRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded request from the HTTP request body.
+func decodeListNodeTypesRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return ListNodeTypesRequest{}, nil
+}
+
+// encodeListNodeTypesResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeListNodeTypesResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeGetNodeTypeRequest is a transport/http.DecodeRequestFunc that decodes
+// the node type id from the request path.
+func decodeGetNodeTypeRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	id, err := nodeTypeID(r)
+	if err != nil {
+		return nil, err
+	}
+	return GetNodeTypeRequest{Id: id}, nil
+}
+
+// encodeGetNodeTypeResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeGetNodeTypeResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeDeleteNodeTypeRequest is a transport/http.DecodeRequestFunc that decodes
+// the node type id from the request path.
+func decodeDeleteNodeTypeRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	id, err := nodeTypeID(r)
+	if err != nil {
+		return nil, err
+	}
+	return DeleteNodeTypeRequest{Id: id}, nil
+}
+
+// encodeDeleteNodeTypeResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeDeleteNodeTypeResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeUpdateNodeTypeRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded request from the HTTP request body.
+func decodeUpdateNodeTypeRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	id, err := nodeTypeID(r)
+	if err != nil {
+		return nil, err
+	}
+	req := UpdateNodeTypeRequest{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	req.Id = id
+	return req, err
+}
+
+// encodeUpdateNodeTypeResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeUpdateNodeTypeResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// nodeTypeID reads the numeric node type id from the request path.
+func nodeTypeID(r *http.Request) (int, error) {
+	vars := mux.Vars(r)
+	id, ok := vars["id"]
+	if !ok {
+		return 0, ErrBadRouting
+	}
+	return strconv.Atoi(id)
+}
+
+// decodeCreateClaimCodeRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded request from the HTTP request body.
+func decodeCreateClaimCodeRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	req := CreateClaimCodeRequest{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	return req, err
+}
+
+// encodeCreateClaimCodeResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeCreateClaimCodeResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeListClaimCodesRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded request from the HTTP request body.
+func decodeListClaimCodesRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return ListClaimCodesRequest{}, nil
+}
+
+// encodeListClaimCodesResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeListClaimCodesResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeProvisionNodeRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded request from the HTTP request body.
+func decodeProvisionNodeRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	req := ProvisionNodeRequest{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	return req, err
+}
+
+// encodeProvisionNodeResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeProvisionNodeResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	//the response holds a secret that is only returned once
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeAuthenticateNodeRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded request from the HTTP request body.
+func decodeAuthenticateNodeRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	req := AuthenticateNodeRequest{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	return req, err
+}
+
+// encodeAuthenticateNodeResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeAuthenticateNodeResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeListClaimsRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded request from the HTTP request body.
+func decodeListClaimsRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return ListClaimsRequest{}, nil
+}
+
+// encodeListClaimsResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeListClaimsResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeIssueCertificateRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded request from the HTTP request body.
+func decodeIssueCertificateRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	req := IssueCertificateRequest{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	return req, err
+}
+
+// encodeIssueCertificateResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeIssueCertificateResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeRevokeCertificateRequest is a transport/http.DecodeRequestFunc that decodes
+// the request parameters from the request path.
+func decodeRevokeCertificateRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	vars := mux.Vars(r)
+	serial, ok := vars["serial"]
+	if !ok {
+		return nil, ErrBadRouting
+	}
+	return RevokeCertificateRequest{Serial: serial}, nil
+}
+
+// encodeRevokeCertificateResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeRevokeCertificateResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeListCertificatesRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded request from the HTTP request body.
+func decodeListCertificatesRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return ListCertificatesRequest{}, nil
+}
+
+// encodeListCertificatesResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeListCertificatesResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeRevocationListRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded request from the HTTP request body.
+func decodeRevocationListRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return RevocationListRequest{}, nil
+}
+
+// encodeRevocationListResponse is a transport/http.EncodeResponseFunc that
+// writes the PEM encoded CRL to the response writer
+func encodeRevocationListResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/x-pem-file")
+	_, err = w.Write(response.(RevocationListResponse).CRL)
+	return
+}
+
+// decodeCreateAPIKeyRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded request from the HTTP request body.
+func decodeCreateAPIKeyRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	req := CreateAPIKeyRequest{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	return req, err
+}
+
+// encodeCreateAPIKeyResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeCreateAPIKeyResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	//the response holds a secret that is only returned once
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeListAPIKeysRequest is a transport/http.DecodeRequestFunc that decodes
+// the owner from the request query.
+func decodeListAPIKeysRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return ListAPIKeysRequest{Owner: r.URL.Query().Get("owner")}, nil
+}
+
+// encodeListAPIKeysResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeListAPIKeysResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeRevokeAPIKeyRequest is a transport/http.DecodeRequestFunc that decodes
+// the request parameters from the request path.
+func decodeRevokeAPIKeyRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	vars := mux.Vars(r)
+	id, ok := vars["id"]
+	if !ok {
+		return nil, ErrBadRouting
+	}
+	return RevokeAPIKeyRequest{Id: id}, nil
+}
+
+// encodeRevokeAPIKeyResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeRevokeAPIKeyResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeAuthAPIKeyRequest is a transport/http.DecodeRequestFunc that decodes
+// the api key from the Authorization header.
+func decodeAuthAPIKeyRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return AuthAPIKeyRequest{Key: apiKey(r)}, nil
+}
+
+// encodeAuthAPIKeyResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeAuthAPIKeyResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// authenticate checks the api key sent in the Authorization header and adds
+// it to the request context so the service can enforce its region scope.
+// Read-only keys are rejected for anything other than GET requests.
+func authenticate(service registry.Service) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			plain := apiKey(r)
+			if plain == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key, err := service.AuthAPIKey(r.Context(), plain)
+			if err != nil {
+				ErrorEncoder(r.Context(), err, w)
+				return
+			}
+
+			if key.ReadOnly() && r.Method != http.MethodGet {
+				ErrorEncoder(r.Context(), registry.ErrPermissionDenied, w)
+				return
+			}
+
+			ctx := registry.WithAPIKey(r.Context(), key)
+			ctx = logger.ContextWith(ctx, "key_id", key.ID, "org", key.Org)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequireAPIKey rejects the requests that are not authenticated with an api
+// key, so that every request is scoped to the organization of its key.
+// Devices provisioning themselves, authenticating with their node key and
+// fetching the CRL have no api key, neither do readers of the API
+// documentation.
+func RequireAPIKey(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !public(r) && apiKey(r) == "" {
+			ErrorEncoder(r.Context(), ErrMissingAPIKey, w)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// OpenAccess serves the requests without an api key until keysExist
+// reports that a usable key exists, so that the first admin key can be
+// created, and rejects them like RequireAPIKey from then on. keysExist is
+// not called anymore once it did, revoking the keys does not open the
+// registry again.
+func OpenAccess(keysExist func(ctx context.Context) (bool, error)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		var closed int32
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if public(r) || apiKey(r) != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if atomic.LoadInt32(&closed) == 0 {
+				exist, err := keysExist(r.Context())
+				if err != nil {
+					ErrorEncoder(r.Context(), err, w)
+					return
+				}
+
+				if exist {
+					atomic.StoreInt32(&closed, 1)
+				}
+			}
+
+			if atomic.LoadInt32(&closed) == 1 {
+				ErrorEncoder(r.Context(), ErrMissingAPIKey, w)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// public reports whether r is served without an api key even when keys are
+// required
+func public(r *http.Request) bool {
+	return (r.Method == http.MethodPost && (r.URL.Path == "/provision" || r.URL.Path == "/nodes/authenticate")) ||
+		(r.Method == http.MethodGet && r.URL.Path == "/certs/crl") ||
+		(r.Method == http.MethodGet && (r.URL.Path == "/openapi.json" || r.URL.Path == "/docs"))
+}
+
+// apiKey returns the key from an "Authorization: Bearer <key>" header
+func apiKey(r *http.Request) string {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return ""
+	}
+
+	return strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+}
+
+// decodeAddOrganizationRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded request from the HTTP request body.
+func decodeAddOrganizationRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	req := AddOrganizationRequest{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	return req, err
+}
+
+// encodeAddOrganizationResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeAddOrganizationResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeGetOrganizationRequest is a transport/http.DecodeRequestFunc that decodes
+// the request parameters from the request path.
+func decodeGetOrganizationRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	vars := mux.Vars(r)
+	id, ok := vars["id"]
+	if !ok {
+		return nil, ErrBadRouting
+	}
+	return GetOrganizationRequest{Id: id}, nil
+}
+
+// encodeGetOrganizationResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeGetOrganizationResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeListOrganizationsRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded request from the HTTP request body.
+func decodeListOrganizationsRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return ListOrganizationsRequest{}, nil
+}
+
+// encodeListOrganizationsResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeListOrganizationsResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeDeleteOrganizationRequest is a transport/http.DecodeRequestFunc that decodes
+// the request parameters from the request path.
+func decodeDeleteOrganizationRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	vars := mux.Vars(r)
+	id, ok := vars["id"]
+	if !ok {
+		return nil, ErrBadRouting
+	}
+	return DeleteOrganizationRequest{Id: id}, nil
+}
+
+// encodeDeleteOrganizationResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeDeleteOrganizationResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeAddFirmwareRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded request from the HTTP request body.
+func decodeAddFirmwareRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	req := AddFirmwareRequest{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	return req, err
+}
+
+// encodeAddFirmwareResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeAddFirmwareResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeGetFirmwareRequest is a transport/http.DecodeRequestFunc that decodes
+// the request parameters from the request path.
+func decodeGetFirmwareRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	vars := mux.Vars(r)
+	id, ok := vars["id"]
+	if !ok {
+		return nil, ErrBadRouting
+	}
+	return GetFirmwareRequest{Id: id}, nil
+}
+
+// encodeGetFirmwareResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeGetFirmwareResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeListFirmwareRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded request from the HTTP request body.
+func decodeListFirmwareRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return ListFirmwareRequest{}, nil
+}
+
+// encodeListFirmwareResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeListFirmwareResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeDeleteFirmwareRequest is a transport/http.DecodeRequestFunc that decodes
+// the request parameters from the request path.
+func decodeDeleteFirmwareRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	vars := mux.Vars(r)
+	id, ok := vars["id"]
+	if !ok {
+		return nil, ErrBadRouting
+	}
+	return DeleteFirmwareRequest{Id: id}, nil
+}
+
+// encodeDeleteFirmwareResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeDeleteFirmwareResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeCreateCampaignRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded request from the HTTP request body.
+func decodeCreateCampaignRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	req := CreateCampaignRequest{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	return req, err
+}
+
+// encodeCreateCampaignResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeCreateCampaignResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeGetCampaignRequest is a transport/http.DecodeRequestFunc that decodes
+// the request parameters from the request path.
+func decodeGetCampaignRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	vars := mux.Vars(r)
+	id, ok := vars["id"]
+	if !ok {
+		return nil, ErrBadRouting
+	}
+	return GetCampaignRequest{Id: id}, nil
+}
+
+// encodeGetCampaignResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeGetCampaignResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeListCampaignsRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded request from the HTTP request body.
+func decodeListCampaignsRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return ListCampaignsRequest{}, nil
+}
+
+// encodeListCampaignsResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeListCampaignsResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeAdvanceCampaignRequest is a transport/http.DecodeRequestFunc that decodes
+// the campaign id from the request path and the rollout from the request body.
+func decodeAdvanceCampaignRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	vars := mux.Vars(r)
+	id, ok := vars["id"]
+	if !ok {
+		return nil, ErrBadRouting
+	}
+	req := AdvanceCampaignRequest{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	req.Id = id
+	return req, err
+}
+
+// encodeAdvanceCampaignResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeAdvanceCampaignResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeListRolloutsRequest is a transport/http.DecodeRequestFunc that decodes
+// the request parameters from the request path.
+func decodeListRolloutsRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	vars := mux.Vars(r)
+	id, ok := vars["id"]
+	if !ok {
+		return nil, ErrBadRouting
+	}
+	return ListRolloutsRequest{Id: id}, nil
+}
+
+// encodeListRolloutsResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeListRolloutsResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeReportRolloutRequest is a transport/http.DecodeRequestFunc that decodes
+// the campaign and node ids from the request path and the status from the
+// request body.
+func decodeReportRolloutRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	vars := mux.Vars(r)
+	id, ok := vars["id"]
+	if !ok {
+		return nil, ErrBadRouting
+	}
+	node, ok := vars["node"]
+	if !ok {
+		return nil, ErrBadRouting
+	}
+	req := ReportRolloutRequest{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	req.Id = id
+	req.Node = node
+	return req, err
+}
+
+// encodeReportRolloutResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeReportRolloutResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeAddSchemaRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded request from the HTTP request body.
+func decodeAddSchemaRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	req := AddSchemaRequest{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	return req, err
+}
+
+// encodeAddSchemaResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeAddSchemaResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeGetSchemaRequest is a transport/http.DecodeRequestFunc that decodes
+// the request parameters from the request path.
+func decodeGetSchemaRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	vars := mux.Vars(r)
+	id, ok := vars["id"]
+	if !ok {
+		return nil, ErrBadRouting
+	}
+	return GetSchemaRequest{Id: id}, nil
+}
+
+// encodeGetSchemaResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeGetSchemaResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeListSchemasRequest is a transport/http.DecodeRequestFunc that decodes
+// the schema filter from the request query.
+func decodeListSchemasRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	q := r.URL.Query()
+	typ, err := intQuery(q, "type")
+	if err != nil {
+		return nil, err
+	}
+	return ListSchemasRequest{Type: typ, Node: q.Get("node"), Kind: registry.SchemaKind(q.Get("kind"))}, nil
+}
+
+// encodeListSchemasResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeListSchemasResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeNodeSchemaRequest is a transport/http.DecodeRequestFunc that decodes
+// the node id from the request path and the kind and version of the schema
+// from the request query.
+func decodeNodeSchemaRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	vars := mux.Vars(r)
+	id, ok := vars["id"]
+	if !ok {
+		return nil, ErrBadRouting
+	}
+	q := r.URL.Query()
+	version, err := intQuery(q, "version")
+	if err != nil {
+		return nil, err
+	}
+	return NodeSchemaRequest{Id: id, Kind: registry.SchemaKind(q.Get("kind")), Version: version}, nil
+}
+
+// encodeNodeSchemaResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeNodeSchemaResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeValidatePayloadRequest is a transport/http.DecodeRequestFunc that decodes
+// the node id from the request path and the payload from the request body.
+func decodeValidatePayloadRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	vars := mux.Vars(r)
+	id, ok := vars["id"]
+	if !ok {
+		return nil, ErrBadRouting
+	}
+	req := ValidatePayloadRequest{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	req.Id = id
+	return req, err
+}
+
+// encodeValidatePayloadResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeValidatePayloadResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeSetRegionQuotaRequest is a transport/http.DecodeRequestFunc that decodes
+// the region id from the request path and its quota from the request body.
+func decodeSetRegionQuotaRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	vars := mux.Vars(r)
+	id, ok := vars["id"]
+	if !ok {
+		return nil, ErrBadRouting
+	}
+	req := SetQuotaRequest{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	req.Quota.Scope = registry.QuotaRegion
+	req.Quota.ID = id
+	return req, err
+}
+
+// decodeSetOrgQuotaRequest is a transport/http.DecodeRequestFunc that decodes
+// the organization id from the request path and its quota from the request body.
+func decodeSetOrgQuotaRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	vars := mux.Vars(r)
+	id, ok := vars["id"]
+	if !ok {
+		return nil, ErrBadRouting
+	}
+	req := SetQuotaRequest{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	req.Quota.Scope = registry.QuotaOrg
+	req.Quota.ID = id
+	return req, err
+}
+
+// encodeSetQuotaResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeSetQuotaResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeListQuotasRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded request from the HTTP request body.
+func decodeListQuotasRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return ListQuotasRequest{}, nil
+}
+
+// encodeListQuotasResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeListQuotasResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeRegionUsageRequest is a transport/http.DecodeRequestFunc that decodes
+// the region id from the request path.
+func decodeRegionUsageRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	vars := mux.Vars(r)
+	id, ok := vars["id"]
+	if !ok {
+		return nil, ErrBadRouting
+	}
+	return UsageRequest{Scope: registry.QuotaRegion, Id: id}, nil
+}
+
+// decodeOrgUsageRequest is a transport/http.DecodeRequestFunc that decodes
+// the organization id from the request path.
+func decodeOrgUsageRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	vars := mux.Vars(r)
+	id, ok := vars["id"]
+	if !ok {
+		return nil, ErrBadRouting
+	}
+	return UsageRequest{Scope: registry.QuotaOrg, Id: id}, nil
+}
+
+// encodeUsageResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeUsageResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeInventoryRequest is a transport/http.DecodeRequestFunc that decodes
+// the region filter and the dimensions from the request query.
+func decodeInventoryRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	region, subregions, err := regionFilter(r)
+	if err != nil {
+		return nil, err
+	}
+	by, err := registry.ParseDimensions(values(r.URL.Query()["by"]))
+	if err != nil {
+		return nil, err
+	}
+	return InventoryRequest{Region: region, Subregions: subregions, By: by}, nil
+}
+
+// encodeInventoryResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeInventoryResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeGrowthRequest is a transport/http.DecodeRequestFunc that decodes
+// the region filter, the period and the start of the report from the
+// request query.
+func decodeGrowthRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	region, subregions, err := regionFilter(r)
+	if err != nil {
+		return nil, err
+	}
+	since, err := timeQuery(r.URL.Query(), "since")
+	if err != nil {
+		return nil, err
+	}
+	period := registry.GrowthPeriod(r.URL.Query().Get("period"))
+	return GrowthRequest{Region: region, Subregions: subregions, Period: period, Since: since}, nil
+}
+
+// encodeGrowthResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeGrowthResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+// decodeOrphanedNodesRequest is a transport/http.DecodeRequestFunc that decodes
+// the region filter from the request query.
+func decodeOrphanedNodesRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	region, subregions, err := regionFilter(r)
+	if err != nil {
+		return nil, err
+	}
+	return OrphanedNodesRequest{Region: region, Subregions: subregions}, nil
+}
+
+// encodeOrphanedNodesResponse is a transport/http.EncodeResponseFunc that encodes
+// the response as JSON to the response writer
+func encodeOrphanedNodesResponse(ctx context.Context, w http.ResponseWriter, response interface{}) (err error) {
+	if f, ok := response.(Failure); ok && f.Failed() != nil {
+		ErrorEncoder(ctx, f.Failed(), w)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	err = json.NewEncoder(w).Encode(response)
+	return
+}
+
+func ErrorEncoder(_ context.Context, err error, w http.ResponseWriter) {
+	w.WriteHeader(err2code(err))
+	json.NewEncoder(w).Encode(errorWrapper{Error: err.Error()})
+}
+func ErrorDecoder(r *http.Response) error {
+	var w errorWrapper
+	if err := json.NewDecoder(r.Body).Decode(&w); err != nil {
+		return err
+	}
+	return errors.New(w.Error)
+}
+
+// This is used to set the http status, see an example here :
+// https://github.com/go-kit/kit/blob/master/examples/addsvc/pkg/addtransport/http.go#L133
+func err2code(err error) int {
+	switch {
+	case err == ErrMissingAPIKey,
+		errors2.Contains(err, registry.ErrInvalidAPIKey),
+		errors2.Contains(err, registry.ErrAPIKeyExpired),
+		errors2.Contains(err, registry.ErrAPIKeyRevoked):
+		return http.StatusUnauthorized
+
+	case errors2.Contains(err, registry.ErrPermissionDenied),
+		errors2.Contains(err, registry.ErrInvalidKeyScope):
+		return http.StatusForbidden
+
+	case err == ErrInvalidCursor, err == ErrInvalidSubregions, err == ErrInvalidNumber, err == ErrInvalidSince,
+		errors2.Contains(err, registry.ErrInvalidIdempotencyKey):
+		return http.StatusBadRequest
+
+	case errors2.Contains(err, registry.ErrIdempotencyKeyInProgress),
+		errors2.Contains(err, registry.ErrIdempotentResponseNotStored):
+		return http.StatusConflict
+
+	case errors2.Contains(err, feed.ErrClosed):
+		return http.StatusServiceUnavailable
+
+	case errors2.Contains(err, registry.ErrIdempotencyKeyReused):
+		return http.StatusUnprocessableEntity
+	}
+
+	switch errors2.KindOf(err) {
+	case errors2.Invalid:
+		return http.StatusBadRequest
+	case errors2.Unauthenticated:
+		return http.StatusUnauthorized
+	case errors2.PermissionDenied:
+		return http.StatusForbidden
+	case errors2.NotFound:
+		return http.StatusNotFound
+	case errors2.Conflict:
+		return http.StatusConflict
+	case errors2.Unavailable:
+		return http.StatusServiceUnavailable
+	}
+
 	return http.StatusInternalServerError
 }
 
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	statusOK          = "ok"
+	statusUnavailable = "unavailable"
+)
+
+// Check reports whether a dependency of regsvc can be used
+type Check func(ctx context.Context) error
+
+// HealthResponse is the body of the health and readiness probes
+type HealthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// Health serves the liveness and readiness probes of regsvc
+type Health struct {
+	checks   map[string]Check
+	timeout  time.Duration
+	draining int32
+}
+
+// NewHealth returns the probes of a server depending on checks, each check
+// has timeout to complete.
+func NewHealth(timeout time.Duration, checks map[string]Check) *Health {
+	return &Health{
+		checks:  checks,
+		timeout: timeout,
+	}
+}
+
+// Drain makes the readiness probe fail so that no new requests are routed
+// to the server while it shuts down.
+func (h *Health) Drain() {
+	atomic.StoreInt32(&h.draining, 1)
+}
+
+// Healthz reports that the process is up, it does not check dependencies
+func (h *Health) Healthz(w http.ResponseWriter, _ *http.Request) {
+	writeHealth(w, http.StatusOK, HealthResponse{Status: statusOK})
+}
+
+// Readyz reports whether the server can serve requests, it fails while the
+// server drains or when any check fails.
+func (h *Health) Readyz(w http.ResponseWriter, r *http.Request) {
+	if atomic.LoadInt32(&h.draining) == 1 {
+		writeHealth(w, http.StatusServiceUnavailable, HealthResponse{Status: "draining"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+
+	resp := HealthResponse{Status: statusOK, Checks: make(map[string]string)}
+	code := http.StatusOK
+
+	for name, check := range h.checks {
+		if err := check(ctx); err != nil {
+			resp.Checks[name] = err.Error()
+			resp.Status = statusUnavailable
+			code = http.StatusServiceUnavailable
+			continue
+		}
+
+		resp.Checks[name] = statusOK
+	}
+
+	writeHealth(w, code, resp)
+}
+
+func writeHealth(w http.ResponseWriter, code int, resp HealthResponse) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(resp)
+}
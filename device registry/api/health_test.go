@@ -0,0 +1,57 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadyz(t *testing.T) {
+	ok := func(context.Context) error { return nil }
+	fail := func(context.Context) error { return errors.New("database schema is not migrated") }
+
+	draining := NewHealth(time.Second, map[string]Check{"database": ok})
+	draining.Drain()
+
+	cases := []struct {
+		desc   string
+		health *Health
+		code   int
+		status string
+	}{
+		{
+			desc:   "all checks pass",
+			health: NewHealth(time.Second, map[string]Check{"database": ok, "schema": ok}),
+			code:   http.StatusOK,
+			status: statusOK,
+		},
+		{
+			desc:   "a check fails",
+			health: NewHealth(time.Second, map[string]Check{"database": ok, "schema": fail}),
+			code:   http.StatusServiceUnavailable,
+			status: statusUnavailable,
+		},
+		{
+			desc:   "server is draining",
+			health: draining,
+			code:   http.StatusServiceUnavailable,
+			status: "draining",
+		},
+	}
+
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		tc.health.Readyz(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		var resp HealthResponse
+		assert.Nil(t, json.NewDecoder(w.Body).Decode(&resp), tc.desc)
+		assert.Equal(t, tc.code, w.Code, tc.desc)
+		assert.Equal(t, tc.status, resp.Status, tc.desc)
+	}
+}
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/piusalfred/registry"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// IdempotencyKeyHeader is the header clients send to make a POST request safe
+// to retry, the response of the first request is replayed to the retries.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// idempotentReplayedHeader marks the responses that are replayed
+const idempotentReplayedHeader = "Idempotent-Replayed"
+
+// Idempotent records the responses of the POST requests sent with an
+// Idempotency-Key header for window. A request sent again with the same key
+// and body gets the recorded response, one with the same key and another body
+// is rejected. Keys are scoped to the api key the request is authenticated
+// with, so Idempotent runs after authentication: MakeHTTPHandler takes it as
+// a middleware. Responses with a 5xx status are not recorded so that the
+// request can be retried. Responses sent with Cache-Control: no-store hold a
+// secret, ErrIdempotentResponseNotStored is recorded in their place. Expired keys are taken over by the next request
+// sent with them, the repository is purged of the others by the caller.
+func Idempotent(repo registry.IdempotencyRepository, window time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if r.Method != http.MethodPost || key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if len(key) > 255 {
+				ErrorEncoder(r.Context(), registry.ErrInvalidIdempotencyKey, w)
+				return
+			}
+
+			body, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				ErrorEncoder(r.Context(), err, w)
+				return
+			}
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+			//requests without an api key share the same scope
+			client, _ := registry.APIKeyFromContext(r.Context())
+
+			now := time.Now()
+			req := registry.IdempotentRequest{
+				Key:     digest(client.ID, key),
+				Hash:    digest(r.Method, r.URL.Path, string(body)),
+				Created: now,
+			}
+
+			recorded, reserved, err := repo.Reserve(r.Context(), req, now.Add(-window))
+			if err != nil {
+				ErrorEncoder(r.Context(), err, w)
+				return
+			}
+
+			if !reserved {
+				replay(w, r, req, recorded)
+				return
+			}
+
+			rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+
+			//the client may have gone away, the response is recorded anyway
+			ctx := context.Background()
+
+			if rec.status >= http.StatusInternalServerError {
+				repo.Release(ctx, req.Key)
+				return
+			}
+
+			req.Status = rec.status
+			req.ContentType = rec.Header().Get("Content-Type")
+			req.Body = rec.body.Bytes()
+
+			if strings.Contains(rec.Header().Get("Cache-Control"), "no-store") {
+				err := registry.ErrIdempotentResponseNotStored
+				req.Status = err2code(err)
+				req.ContentType = ""
+				req.Body, _ = json.Marshal(errorWrapper{Error: err.Error()})
+			}
+
+			if err := repo.Complete(ctx, req); err != nil {
+				repo.Release(ctx, req.Key)
+			}
+		})
+	}
+}
+
+// replay writes the recorded response of the request with the key of req
+func replay(w http.ResponseWriter, r *http.Request, req, recorded registry.IdempotentRequest) {
+	switch {
+	case recorded.Hash != req.Hash:
+		ErrorEncoder(r.Context(), registry.ErrIdempotencyKeyReused, w)
+
+	case !recorded.Completed():
+		ErrorEncoder(r.Context(), registry.ErrIdempotencyKeyInProgress, w)
+
+	default:
+		if recorded.ContentType != "" {
+			w.Header().Set("Content-Type", recorded.ContentType)
+		}
+		w.Header().Set(idempotentReplayedHeader, "true")
+		w.WriteHeader(recorded.Status)
+		w.Write(recorded.Body)
+	}
+}
+
+// digest is the hex encoded sha256 of parts
+func digest(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recorder copies the response written to the client
+type recorder struct {
+	http.ResponseWriter
+	status      int
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func (r *recorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
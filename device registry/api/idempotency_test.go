@@ -0,0 +1,251 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/piusalfred/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+// memIdempotency is an in memory registry.IdempotencyRepository
+type memIdempotency struct {
+	mu   sync.Mutex
+	keys map[string]registry.IdempotentRequest
+}
+
+func (m *memIdempotency) Reserve(_ context.Context, req registry.IdempotentRequest, expired time.Time) (registry.IdempotentRequest, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if recorded, ok := m.keys[req.Key]; ok && !recorded.Created.Before(expired) {
+		return recorded, false, nil
+	}
+	m.keys[req.Key] = req
+
+	return req, true, nil
+}
+
+func (m *memIdempotency) Complete(_ context.Context, req registry.IdempotentRequest) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.keys[req.Key] = req
+
+	return nil
+}
+
+func (m *memIdempotency) Release(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.keys, key)
+
+	return nil
+}
+
+func (m *memIdempotency) Purge(_ context.Context, before time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key, req := range m.keys {
+		if req.Created.Before(before) {
+			delete(m.keys, key)
+		}
+	}
+
+	return nil
+}
+
+func TestIdempotent(t *testing.T) {
+	calls := 0
+	status := http.StatusCreated
+	h := Idempotent(&memIdempotency{keys: map[string]registry.IdempotentRequest{}}, time.Hour)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(status)
+			w.Write([]byte(`{"id":"1"}`))
+		}))
+
+	cases := []struct {
+		desc     string
+		key      string
+		client   string
+		body     string
+		status   int
+		calls    int
+		replayed bool
+	}{
+		{
+			desc:   "first request",
+			key:    "k1",
+			body:   `{"name":"node"}`,
+			status: http.StatusCreated,
+			calls:  1,
+		},
+		{
+			desc:     "retry replays the response",
+			key:      "k1",
+			body:     `{"name":"node"}`,
+			status:   http.StatusCreated,
+			calls:    1,
+			replayed: true,
+		},
+		{
+			desc:   "key reused with another body",
+			key:    "k1",
+			body:   `{"name":"other"}`,
+			status: http.StatusUnprocessableEntity,
+			calls:  1,
+		},
+		{
+			desc:   "request without a key",
+			body:   `{"name":"node"}`,
+			status: http.StatusCreated,
+			calls:  2,
+		},
+		{
+			desc:   "key used by another client",
+			key:    "k1",
+			client: "admin",
+			body:   `{"name":"node"}`,
+			status: http.StatusCreated,
+			calls:  3,
+		},
+		{
+			desc:   "key too long",
+			key:    strings.Repeat("k", 256),
+			body:   `{"name":"node"}`,
+			status: http.StatusBadRequest,
+			calls:  3,
+		},
+	}
+
+	for _, tc := range cases {
+		r := httptest.NewRequest(http.MethodPost, "/nodes", strings.NewReader(tc.body))
+		if tc.key != "" {
+			r.Header.Set(IdempotencyKeyHeader, tc.key)
+		}
+		if tc.client != "" {
+			r = r.WithContext(registry.WithAPIKey(r.Context(), registry.APIKey{ID: tc.client}))
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		assert.Equal(t, tc.status, w.Code, tc.desc)
+		assert.Equal(t, tc.calls, calls, tc.desc)
+		assert.Equal(t, tc.replayed, w.Header().Get(idempotentReplayedHeader) == "true", tc.desc)
+	}
+
+	//failed requests are not recorded so the retry runs again
+	status = http.StatusInternalServerError
+	for i := 0; i < 2; i++ {
+		r := httptest.NewRequest(http.MethodPost, "/nodes", strings.NewReader(`{}`))
+		r.Header.Set(IdempotencyKeyHeader, "k2")
+		h.ServeHTTP(httptest.NewRecorder(), r)
+	}
+	assert.Equal(t, 5, calls, "failed request replayed")
+}
+
+func TestIdempotentExpired(t *testing.T) {
+	repo := &memIdempotency{keys: map[string]registry.IdempotentRequest{}}
+	calls := 0
+	h := Idempotent(repo, time.Hour)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+
+	for i := 0; i < 2; i++ {
+		r := httptest.NewRequest(http.MethodPost, "/nodes", strings.NewReader(`{}`))
+		r.Header.Set(IdempotencyKeyHeader, "k1")
+		h.ServeHTTP(httptest.NewRecorder(), r)
+
+		//the key was used longer than the window ago
+		for key, req := range repo.keys {
+			req.Created = req.Created.Add(-2 * time.Hour)
+			repo.keys[key] = req
+		}
+	}
+
+	assert.Equal(t, 2, calls, "response of an expired key replayed")
+}
+
+// TestIdempotentAuthenticated checks that requests rejected by the
+// authentication of MakeHTTPHandler do not reserve their idempotency key
+func TestIdempotentAuthenticated(t *testing.T) {
+	repo := &memIdempotency{keys: map[string]registry.IdempotentRequest{}}
+	svc := keyService{keys: map[string]registry.APIKey{
+		"reader.secret": {ID: "reader", Role: int(registry.RegionUser), Region: "AA001"},
+	}}
+	h := MakeHTTPHandler(svc, log.NewNopLogger(), Idempotent(repo, time.Hour))
+
+	cases := []struct {
+		desc   string
+		auth   string
+		status int
+	}{
+		{desc: "unknown key", auth: "Bearer admin.guess", status: http.StatusUnauthorized},
+		{desc: "read-only key", auth: "Bearer reader.secret", status: http.StatusForbidden},
+	}
+
+	for _, tc := range cases {
+		r := httptest.NewRequest(http.MethodPost, "/nodes", strings.NewReader(`{}`))
+		r.Header.Set("Authorization", tc.auth)
+		r.Header.Set(IdempotencyKeyHeader, "k1")
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		assert.Equal(t, tc.status, w.Code, tc.desc)
+		assert.Empty(t, repo.keys, "%s: key reserved", tc.desc)
+	}
+}
+
+// secretService creates api keys for the admin key of keyService
+type secretService struct {
+	keyService
+}
+
+func (s secretService) CreateAPIKey(_ context.Context, owner, name string, role int, region string, _ time.Duration) (registry.APIKey, error) {
+	return registry.APIKey{ID: "new", Name: name, Owner: owner, Role: role, Region: region, Key: "new.plaintext"}, nil
+}
+
+// TestIdempotentSecret checks that the plain key returned on creation is
+// not recorded and that retries are told the key was created
+func TestIdempotentSecret(t *testing.T) {
+	repo := &memIdempotency{keys: map[string]registry.IdempotentRequest{}}
+	svc := secretService{keyService{keys: map[string]registry.APIKey{
+		"admin.secret": {ID: "admin", Role: int(registry.Admin)},
+	}}}
+	h := MakeHTTPHandler(svc, log.NewNopLogger(), Idempotent(repo, time.Hour))
+
+	send := func() *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/apikeys", strings.NewReader(`{"owner":"admin","name":"ci","role":1}`))
+		r.Header.Set("Authorization", "Bearer admin.secret")
+		r.Header.Set(IdempotencyKeyHeader, "k1")
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	w := send()
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "new.plaintext")
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+
+	assert.Len(t, repo.keys, 1)
+	for _, req := range repo.keys {
+		assert.NotContains(t, string(req.Body), "new.plaintext", "secret recorded")
+	}
+
+	w = send()
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "true", w.Header().Get(idempotentReplayedHeader))
+	assert.NotContains(t, w.Body.String(), "new.plaintext", "secret replayed")
+	assert.Contains(t, w.Body.String(), registry.ErrIdempotentResponseNotStored.Error())
+}
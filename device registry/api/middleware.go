@@ -2,7 +2,7 @@ package api
 
 import (
 	"context"
-	"fmt"
+	"encoding/json"
 	"github.com/piusalfred/registry"
 	"github.com/piusalfred/registry/logger"
 	"time"
@@ -25,11 +25,23 @@ type loggingMiddleware struct {
 	logger logger.Logger
 }
 
+// log logs a call to method along with the fields of ctx, calls that failed
+// are logged as warnings. Only identifiers are logged, never whole entities.
+func (l loggingMiddleware) log(ctx context.Context, method string, begin time.Time, err error, keyvals ...interface{}) {
+	kvs := append([]interface{}{"method", method, "took", time.Since(begin).String()}, keyvals...)
+	lg := l.logger.WithContext(ctx)
+
+	if err != nil {
+		lg.Warn("method failed", append(kvs, "err", err.Error())...)
+		return
+	}
+
+	lg.Info("method completed", kvs...)
+}
+
 func (l loggingMiddleware) AuthUser(ctx context.Context, id, password string) (err error) {
 	defer func(begin time.Time) {
-		l.logger.Info(fmt.Sprintf(
-			"method: auth took %v to autheticate user with id %s and returned err %v",
-			time.Since(begin), id, err))
+		l.log(ctx, "AuthUser", begin, err, "user_id", id)
 	}(time.Now())
 
 	err = l.next.AuthUser(ctx, id, password)
@@ -38,9 +50,7 @@ func (l loggingMiddleware) AuthUser(ctx context.Context, id, password string) (e
 
 func (l loggingMiddleware) GetUser(ctx context.Context, id string) (user registry.User, err error) {
 	defer func(begin time.Time) {
-		l.logger.Info(fmt.Sprintf(
-			"method: GetUser with an input %s took %v to return %v with an err %v",
-			id, time.Since(begin), user, err))
+		l.log(ctx, "GetUser", begin, err, "user_id", id)
 	}(time.Now())
 
 	user, err = l.next.GetUser(ctx, id)
@@ -49,31 +59,25 @@ func (l loggingMiddleware) GetUser(ctx context.Context, id string) (user registr
 
 func (l loggingMiddleware) AddUser(ctx context.Context, user registry.User) (err error) {
 	defer func(begin time.Time) {
-		l.logger.Info(fmt.Sprintf(
-			"method: AddUser with an input %v took %v to add user with an err %v",
-			user, time.Since(begin), err))
+		l.log(ctx, "AddUser", begin, err, "user_id", user.ID, "region", user.Region)
 	}(time.Now())
 
 	err = l.next.AddUser(ctx, user)
 	return
 }
 
-func (l loggingMiddleware) ListUser(ctx context.Context) (users []registry.User, err error) {
+func (l loggingMiddleware) ListUser(ctx context.Context, filter registry.RegionFilter) (users []registry.User, err error) {
 	defer func(begin time.Time) {
-		l.logger.Info(fmt.Sprintf(
-			"method: ListUser with took %v to list all users %v with an err %v",
-			time.Since(begin), users, err))
+		l.log(ctx, "ListUser", begin, err, "region", filter.Region, "count", len(users))
 	}(time.Now())
 
-	users, err = l.next.ListUser(ctx)
+	users, err = l.next.ListUser(ctx, filter)
 	return
 }
 
 func (l loggingMiddleware) DeleteUser(ctx context.Context, id string) (err error) {
 	defer func(begin time.Time) {
-		l.logger.Info(fmt.Sprintf(
-			"method: DeleteUser with took %v to delete user with id %s with an err %v",
-			time.Since(begin), id, err))
+		l.log(ctx, "DeleteUser", begin, err, "user_id", id)
 	}(time.Now())
 
 	err = l.next.DeleteUser(ctx, id)
@@ -82,9 +86,7 @@ func (l loggingMiddleware) DeleteUser(ctx context.Context, id string) (err error
 
 func (l loggingMiddleware) UpdateUser(ctx context.Context, id string, user registry.User) (u registry.User, err error) {
 	defer func(begin time.Time) {
-		l.logger.Info(fmt.Sprintf(
-			"method: UpdateUser with took %v to update user with id %s from %v to %v with an err %v",
-			time.Since(begin), id, user, u, err))
+		l.log(ctx, "UpdateUser", begin, err, "user_id", id)
 	}(time.Now())
 
 	u, err = l.next.UpdateUser(ctx, id, user)
@@ -93,9 +95,7 @@ func (l loggingMiddleware) UpdateUser(ctx context.Context, id string, user regis
 
 func (l loggingMiddleware) AddNode(ctx context.Context, node registry.Node) (err error) {
 	defer func(begin time.Time) {
-		l.logger.Info(fmt.Sprintf(
-			"method: AddNode with an input %v took %v to add user with an err %v",
-			node, time.Since(begin), err))
+		l.log(ctx, "AddNode", begin, err, "node_id", node.UUID, "region", node.Region)
 	}(time.Now())
 
 	err = l.next.AddNode(ctx, node)
@@ -104,31 +104,25 @@ func (l loggingMiddleware) AddNode(ctx context.Context, node registry.Node) (err
 
 func (l loggingMiddleware) GetNode(ctx context.Context, id string) (node registry.Node, err error) {
 	defer func(begin time.Time) {
-		l.logger.Info(fmt.Sprintf(
-			"method: GetNode with an input %s took %v to return a user with an err %v",
-			id, time.Since(begin), err))
+		l.log(ctx, "GetNode", begin, err, "node_id", id)
 	}(time.Now())
 
 	node, err = l.next.GetNode(ctx, id)
 	return
 }
 
-func (l loggingMiddleware) ListNodes(ctx context.Context) (nodes []registry.Node, err error) {
+func (l loggingMiddleware) ListNodes(ctx context.Context, filter registry.RegionFilter) (nodes []registry.Node, err error) {
 	defer func(begin time.Time) {
-		l.logger.Info(fmt.Sprintf(
-			"method: ListNodes with took %v to list all users returned with an err %v",
-			time.Since(begin), err))
+		l.log(ctx, "ListNodes", begin, err, "region", filter.Region, "count", len(nodes))
 	}(time.Now())
 
-	nodes, err = l.next.ListNodes(ctx)
+	nodes, err = l.next.ListNodes(ctx, filter)
 	return
 }
 
 func (l loggingMiddleware) DeleteNode(ctx context.Context, id string) (err error) {
 	defer func(begin time.Time) {
-		l.logger.Info(fmt.Sprintf(
-			"method: DeleteNode with took %v to delete user with id %s with an err %v",
-			time.Since(begin), id, err))
+		l.log(ctx, "DeleteNode", begin, err, "node_id", id)
 	}(time.Now())
 
 	err = l.next.DeleteNode(ctx, id)
@@ -137,9 +131,7 @@ func (l loggingMiddleware) DeleteNode(ctx context.Context, id string) (err error
 
 func (l loggingMiddleware) UpdateNode(ctx context.Context, id string, node registry.Node) (n registry.Node, err error) {
 	defer func(begin time.Time) {
-		l.logger.Info(fmt.Sprintf(
-			"method: UpdateNode with took %v to update user with id %s with an err %v",
-			time.Since(begin), id, err))
+		l.log(ctx, "UpdateNode", begin, err, "node_id", id)
 	}(time.Now())
 
 	n, err = l.next.UpdateNode(ctx, id, node)
@@ -148,9 +140,7 @@ func (l loggingMiddleware) UpdateNode(ctx context.Context, id string, node regis
 
 func (l loggingMiddleware) AddRegion(ctx context.Context, region registry.Region) (err error) {
 	defer func(begin time.Time) {
-		l.logger.Info(fmt.Sprintf(
-			"method: AddRegion with an input %v took %v to add region with an err %v",
-			region, time.Since(begin), err))
+		l.log(ctx, "AddRegion", begin, err, "region", region.ID)
 	}(time.Now())
 
 	err = l.next.AddRegion(ctx, region)
@@ -159,11 +149,442 @@ func (l loggingMiddleware) AddRegion(ctx context.Context, region registry.Region
 
 func (l loggingMiddleware) ListRegions(ctx context.Context) (regions []registry.Region, err error) {
 	defer func(begin time.Time) {
-		l.logger.Info(fmt.Sprintf(
-			"method: ListRegions took %v to list regions %v with an err %v",
-			time.Since(begin), regions, err))
+		l.log(ctx, "ListRegions", begin, err, "count", len(regions))
 	}(time.Now())
 
 	regions, err = l.next.ListRegions(ctx)
 	return
 }
+
+func (l loggingMiddleware) SuggestRegion(ctx context.Context, lat, long float64) (regions []registry.Region, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "SuggestRegion", begin, err, "count", len(regions))
+	}(time.Now())
+
+	regions, err = l.next.SuggestRegion(ctx, lat, long)
+	return
+}
+
+func (l loggingMiddleware) MoveRegion(ctx context.Context, id, parent string) (region registry.Region, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "MoveRegion", begin, err, "region_id", id, "parent", parent)
+	}(time.Now())
+
+	region, err = l.next.MoveRegion(ctx, id, parent)
+	return
+}
+
+func (l loggingMiddleware) RegionSubtree(ctx context.Context, id string) (regions []registry.Region, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "RegionSubtree", begin, err, "region_id", id, "count", len(regions))
+	}(time.Now())
+
+	regions, err = l.next.RegionSubtree(ctx, id)
+	return
+}
+
+func (l loggingMiddleware) RegionAncestors(ctx context.Context, id string) (regions []registry.Region, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "RegionAncestors", begin, err, "region_id", id, "count", len(regions))
+	}(time.Now())
+
+	regions, err = l.next.RegionAncestors(ctx, id)
+	return
+}
+
+func (l loggingMiddleware) AddNodeType(ctx context.Context, nodeType registry.NodeType) (err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "AddNodeType", begin, err, "type_id", nodeType.ID)
+	}(time.Now())
+
+	err = l.next.AddNodeType(ctx, nodeType)
+	return
+}
+
+func (l loggingMiddleware) GetNodeType(ctx context.Context, id int) (nodeType registry.NodeType, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "GetNodeType", begin, err, "type_id", id)
+	}(time.Now())
+
+	nodeType, err = l.next.GetNodeType(ctx, id)
+	return
+}
+
+func (l loggingMiddleware) ListNodeTypes(ctx context.Context) (types []registry.NodeType, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "ListNodeTypes", begin, err, "count", len(types))
+	}(time.Now())
+
+	types, err = l.next.ListNodeTypes(ctx)
+	return
+}
+
+func (l loggingMiddleware) DeleteNodeType(ctx context.Context, id int) (err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "DeleteNodeType", begin, err, "type_id", id)
+	}(time.Now())
+
+	err = l.next.DeleteNodeType(ctx, id)
+	return
+}
+
+func (l loggingMiddleware) UpdateNodeType(ctx context.Context, id int, nodeType registry.NodeType) (nt registry.NodeType, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "UpdateNodeType", begin, err, "type_id", id)
+	}(time.Now())
+
+	nt, err = l.next.UpdateNodeType(ctx, id, nodeType)
+	return
+}
+
+func (l loggingMiddleware) CreateClaimCode(ctx context.Context, region string, typ int, ttl time.Duration) (code registry.ClaimCode, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "CreateClaimCode", begin, err, "region", region, "type_id", typ, "ttl", ttl.String())
+	}(time.Now())
+
+	code, err = l.next.CreateClaimCode(ctx, region, typ, ttl)
+	return
+}
+
+func (l loggingMiddleware) ListClaimCodes(ctx context.Context) (codes []registry.ClaimCode, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "ListClaimCodes", begin, err, "count", len(codes))
+	}(time.Now())
+
+	codes, err = l.next.ListClaimCodes(ctx)
+	return
+}
+
+func (l loggingMiddleware) ProvisionNode(ctx context.Context, code string, node registry.Node) (creds registry.Credentials, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "ProvisionNode", begin, err, "addr", node.Addr, "node_id", creds.NodeID)
+	}(time.Now())
+
+	creds, err = l.next.ProvisionNode(ctx, code, node)
+	return
+}
+
+func (l loggingMiddleware) AuthenticateNode(ctx context.Context, node string, key string) (n registry.Node, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "AuthenticateNode", begin, err, "node_id", node)
+	}(time.Now())
+
+	n, err = l.next.AuthenticateNode(ctx, node, key)
+	return
+}
+
+func (l loggingMiddleware) ListClaims(ctx context.Context) (claims []registry.Claim, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "ListClaims", begin, err, "count", len(claims))
+	}(time.Now())
+
+	claims, err = l.next.ListClaims(ctx)
+	return
+}
+
+func (l loggingMiddleware) IssueCertificate(ctx context.Context, node string, csr string, ttl time.Duration) (cert registry.Certificate, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "IssueCertificate", begin, err, "node_id", node, "serial", cert.Serial)
+	}(time.Now())
+
+	cert, err = l.next.IssueCertificate(ctx, node, csr, ttl)
+	return
+}
+
+func (l loggingMiddleware) RevokeCertificate(ctx context.Context, serial string) (err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "RevokeCertificate", begin, err, "serial", serial)
+	}(time.Now())
+
+	err = l.next.RevokeCertificate(ctx, serial)
+	return
+}
+
+func (l loggingMiddleware) ListCertificates(ctx context.Context) (certs []registry.Certificate, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "ListCertificates", begin, err, "count", len(certs))
+	}(time.Now())
+
+	certs, err = l.next.ListCertificates(ctx)
+	return
+}
+
+func (l loggingMiddleware) RevocationList(ctx context.Context) (crl []byte, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "RevocationList", begin, err, "size", len(crl))
+	}(time.Now())
+
+	crl, err = l.next.RevocationList(ctx)
+	return
+}
+
+func (l loggingMiddleware) CreateAPIKey(ctx context.Context, owner string, name string, role int, region string, ttl time.Duration) (key registry.APIKey, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "CreateAPIKey", begin, err, "owner", owner, "name", name, "role", role, "key_id", key.ID)
+	}(time.Now())
+
+	key, err = l.next.CreateAPIKey(ctx, owner, name, role, region, ttl)
+	return
+}
+
+func (l loggingMiddleware) ListAPIKeys(ctx context.Context, owner string) (keys []registry.APIKey, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "ListAPIKeys", begin, err, "owner", owner, "count", len(keys))
+	}(time.Now())
+
+	keys, err = l.next.ListAPIKeys(ctx, owner)
+	return
+}
+
+func (l loggingMiddleware) RevokeAPIKey(ctx context.Context, id string) (err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "RevokeAPIKey", begin, err, "key_id", id)
+	}(time.Now())
+
+	err = l.next.RevokeAPIKey(ctx, id)
+	return
+}
+
+func (l loggingMiddleware) AuthAPIKey(ctx context.Context, key string) (k registry.APIKey, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "AuthAPIKey", begin, err, "key_id", k.ID)
+	}(time.Now())
+
+	k, err = l.next.AuthAPIKey(ctx, key)
+	return
+}
+
+func (l loggingMiddleware) AddOrganization(ctx context.Context, org registry.Organization) (o registry.Organization, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "AddOrganization", begin, err, "name", org.Name, "org_id", o.ID)
+	}(time.Now())
+
+	o, err = l.next.AddOrganization(ctx, org)
+	return
+}
+
+func (l loggingMiddleware) GetOrganization(ctx context.Context, id string) (org registry.Organization, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "GetOrganization", begin, err, "org_id", id)
+	}(time.Now())
+
+	org, err = l.next.GetOrganization(ctx, id)
+	return
+}
+
+func (l loggingMiddleware) ListOrganizations(ctx context.Context) (orgs []registry.Organization, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "ListOrganizations", begin, err, "count", len(orgs))
+	}(time.Now())
+
+	orgs, err = l.next.ListOrganizations(ctx)
+	return
+}
+
+func (l loggingMiddleware) DeleteOrganization(ctx context.Context, id string) (err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "DeleteOrganization", begin, err, "org_id", id)
+	}(time.Now())
+
+	err = l.next.DeleteOrganization(ctx, id)
+	return
+}
+
+func (l loggingMiddleware) AddFirmware(ctx context.Context, fw registry.Firmware) (f registry.Firmware, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "AddFirmware", begin, err, "version", fw.Version, "type", fw.Type, "firmware_id", f.ID)
+	}(time.Now())
+
+	f, err = l.next.AddFirmware(ctx, fw)
+	return
+}
+
+func (l loggingMiddleware) GetFirmware(ctx context.Context, id string) (fw registry.Firmware, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "GetFirmware", begin, err, "firmware_id", id)
+	}(time.Now())
+
+	fw, err = l.next.GetFirmware(ctx, id)
+	return
+}
+
+func (l loggingMiddleware) ListFirmware(ctx context.Context) (releases []registry.Firmware, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "ListFirmware", begin, err, "count", len(releases))
+	}(time.Now())
+
+	releases, err = l.next.ListFirmware(ctx)
+	return
+}
+
+func (l loggingMiddleware) DeleteFirmware(ctx context.Context, id string) (err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "DeleteFirmware", begin, err, "firmware_id", id)
+	}(time.Now())
+
+	err = l.next.DeleteFirmware(ctx, id)
+	return
+}
+
+func (l loggingMiddleware) CreateCampaign(ctx context.Context, campaign registry.Campaign) (c registry.Campaign, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "CreateCampaign", begin, err, "name", campaign.Name, "firmware_id", campaign.Firmware,
+			"region", campaign.Target.Region, "campaign_id", c.ID)
+	}(time.Now())
+
+	c, err = l.next.CreateCampaign(ctx, campaign)
+	return
+}
+
+func (l loggingMiddleware) GetCampaign(ctx context.Context, id string) (c registry.Campaign, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "GetCampaign", begin, err, "campaign_id", id)
+	}(time.Now())
+
+	c, err = l.next.GetCampaign(ctx, id)
+	return
+}
+
+func (l loggingMiddleware) ListCampaigns(ctx context.Context) (campaigns []registry.Campaign, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "ListCampaigns", begin, err, "count", len(campaigns))
+	}(time.Now())
+
+	campaigns, err = l.next.ListCampaigns(ctx)
+	return
+}
+
+func (l loggingMiddleware) AdvanceCampaign(ctx context.Context, id string, percent int) (c registry.Campaign, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "AdvanceCampaign", begin, err, "campaign_id", id, "rollout", percent)
+	}(time.Now())
+
+	c, err = l.next.AdvanceCampaign(ctx, id, percent)
+	return
+}
+
+func (l loggingMiddleware) ListRollouts(ctx context.Context, campaign string) (rollouts []registry.Rollout, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "ListRollouts", begin, err, "campaign_id", campaign, "count", len(rollouts))
+	}(time.Now())
+
+	rollouts, err = l.next.ListRollouts(ctx, campaign)
+	return
+}
+
+func (l loggingMiddleware) ReportRollout(ctx context.Context, campaign, node string, status registry.RolloutStatus, msg string) (r registry.Rollout, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "ReportRollout", begin, err, "campaign_id", campaign, "node_id", node, "status", string(status))
+	}(time.Now())
+
+	r, err = l.next.ReportRollout(ctx, campaign, node, status, msg)
+	return
+}
+
+func (l loggingMiddleware) AddSchema(ctx context.Context, schema registry.Schema) (s registry.Schema, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "AddSchema", begin, err, "type", schema.Type, "node_id", schema.Node, "kind", string(schema.Kind), "schema_id", s.ID, "version", s.Version)
+	}(time.Now())
+
+	s, err = l.next.AddSchema(ctx, schema)
+	return
+}
+
+func (l loggingMiddleware) GetSchema(ctx context.Context, id string) (s registry.Schema, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "GetSchema", begin, err, "schema_id", id)
+	}(time.Now())
+
+	s, err = l.next.GetSchema(ctx, id)
+	return
+}
+
+func (l loggingMiddleware) ListSchemas(ctx context.Context, filter registry.SchemaFilter) (schemas []registry.Schema, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "ListSchemas", begin, err, "type", filter.Type, "node_id", filter.Node, "kind", string(filter.Kind), "count", len(schemas))
+	}(time.Now())
+
+	schemas, err = l.next.ListSchemas(ctx, filter)
+	return
+}
+
+func (l loggingMiddleware) NodeSchema(ctx context.Context, node string, kind registry.SchemaKind, version int) (s registry.Schema, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "NodeSchema", begin, err, "node_id", node, "kind", string(kind), "version", version, "schema_id", s.ID)
+	}(time.Now())
+
+	s, err = l.next.NodeSchema(ctx, node, kind, version)
+	return
+}
+
+func (l loggingMiddleware) ValidatePayload(ctx context.Context, node string, kind registry.SchemaKind, version int, payload json.RawMessage) (v registry.Validation, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "ValidatePayload", begin, err, "node_id", node, "kind", string(kind), "schema_id", v.Schema, "valid", v.Valid)
+	}(time.Now())
+
+	v, err = l.next.ValidatePayload(ctx, node, kind, version, payload)
+	return
+}
+
+func (l loggingMiddleware) SetQuota(ctx context.Context, quota registry.Quota) (q registry.Quota, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "SetQuota", begin, err, "scope", string(quota.Scope), "id", quota.ID, "users", quota.Users)
+	}(time.Now())
+
+	q, err = l.next.SetQuota(ctx, quota)
+	return
+}
+
+func (l loggingMiddleware) ListQuotas(ctx context.Context) (quotas []registry.Quota, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "ListQuotas", begin, err, "count", len(quotas))
+	}(time.Now())
+
+	quotas, err = l.next.ListQuotas(ctx)
+	return
+}
+
+func (l loggingMiddleware) Usage(ctx context.Context, scope registry.QuotaScope, id string) (u registry.Usage, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "Usage", begin, err, "scope", string(scope), "id", id)
+	}(time.Now())
+
+	u, err = l.next.Usage(ctx, scope, id)
+	return
+}
+
+func (l loggingMiddleware) Inventory(ctx context.Context, filter registry.RegionFilter, by []registry.Dimension) (inventory []registry.InventoryCount, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "Inventory", begin, err, "region", filter.Region, "by", by, "count", len(inventory))
+	}(time.Now())
+
+	inventory, err = l.next.Inventory(ctx, filter, by)
+	return
+}
+
+func (l loggingMiddleware) Growth(ctx context.Context, filter registry.RegionFilter, period registry.GrowthPeriod, since time.Time) (growth []registry.Growth, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "Growth", begin, err, "region", filter.Region, "period", string(period), "since", since, "count", len(growth))
+	}(time.Now())
+
+	growth, err = l.next.Growth(ctx, filter, period, since)
+	return
+}
+
+func (l loggingMiddleware) OrphanedNodes(ctx context.Context, filter registry.RegionFilter) (nodes []registry.Node, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "OrphanedNodes", begin, err, "region", filter.Region, "count", len(nodes))
+	}(time.Now())
+
+	nodes, err = l.next.OrphanedNodes(ctx, filter)
+	return
+}
+
+func (l loggingMiddleware) Watch(ctx context.Context, filter registry.EventFilter, cursor time.Duration) (events <-chan registry.Event, err error) {
+	defer func(begin time.Time) {
+		l.log(ctx, "Watch", begin, err, "entities", filter.Entities, "regions", filter.Regions, "names", filter.Names, "cursor", int64(cursor))
+	}(time.Now())
+
+	events, err = l.next.Watch(ctx, filter, cursor)
+	return
+}
@@ -0,0 +1,613 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/piusalfred/registry"
+	"html/template"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// operation documents a route of MakeHTTPHandler. The request and response
+// are the values the route decodes and encodes, path and query parameters
+// are documented with the type of the request field of the same json name.
+type operation struct {
+	method   string
+	path     string
+	tag      string
+	summary  string
+	request  interface{}
+	response interface{}
+
+	// body is set when the request is decoded from the JSON body
+	body bool
+
+	// query are the names of the query parameters
+	query []string
+
+	// produces is the content type of the response, JSON if empty
+	produces string
+
+	// public routes are served without an api key
+	public bool
+}
+
+// operations are the documented routes, every route of MakeHTTPHandler
+// must be listed here.
+var operations = []operation{
+	{method: http.MethodGet, path: "/auth/{id}", tag: "users", summary: "authenticate a user with a password",
+		request: AuthUserRequest{}, response: AuthUserResponse{}},
+	{method: http.MethodGet, path: "/users/{id}", tag: "users", summary: "get a user",
+		request: GetUserRequest{}, response: GetUserResponse{}},
+	{method: http.MethodPost, path: "/users", tag: "users", summary: "add a user",
+		request: AddUserRequest{}, response: AddUserResponse{}, body: true},
+	{method: http.MethodGet, path: "/users", tag: "users", summary: "list users, of a region and its sub-regions when subregions is true",
+		request: ListUserRequest{}, response: ListUserResponse{}, query: []string{"region", "subregions"}},
+	{method: http.MethodDelete, path: "/users/{id}", tag: "users", summary: "delete a user",
+		request: DeleteUserRequest{}, response: DeleteUserResponse{}},
+	{method: http.MethodPatch, path: "/users/{id}", tag: "users", summary: "update the group and region of a user",
+		request: UpdateUserRequest{}, response: UpdateUserResponse{}, body: true},
+
+	{method: http.MethodPost, path: "/regions", tag: "regions", summary: "add a region",
+		request: AddRegionRequest{}, response: AddRegionResponse{}, body: true},
+	{method: http.MethodGet, path: "/regions", tag: "regions", summary: "list regions, the body is an empty JSON object",
+		request: ListRegionsRequest{}, response: ListRegionsResponse{}, body: true},
+	{method: http.MethodGet, path: "/regions/suggest", tag: "regions", summary: "regions whose boundary contains a location, the smallest first",
+		request: SuggestRegionRequest{}, response: SuggestRegionResponse{}, query: []string{"latitude", "longitude"}},
+	{method: http.MethodGet, path: "/regions/{id}/subtree", tag: "regions", summary: "a region followed by its descendants, parents before their children",
+		request: RegionSubtreeRequest{}, response: RegionSubtreeResponse{}},
+	{method: http.MethodGet, path: "/regions/{id}/ancestors", tag: "regions", summary: "the parent of a region up to its root region",
+		request: RegionAncestorsRequest{}, response: RegionAncestorsResponse{}},
+	{method: http.MethodPut, path: "/regions/{id}/parent", tag: "regions", summary: "move a region under another one, an empty parent makes it a root",
+		request: MoveRegionRequest{}, response: MoveRegionResponse{}, body: true},
+
+	{method: http.MethodGet, path: "/nodes/{id}", tag: "nodes", summary: "get a node by its uuid or address",
+		request: GetNodeRequest{}, response: GetNodeResponse{}},
+	{method: http.MethodPost, path: "/nodes", tag: "nodes", summary: "add a node",
+		request: AddNodeRequest{}, response: AddNodeResponse{}, body: true},
+	{method: http.MethodGet, path: "/nodes", tag: "nodes", summary: "list nodes, of a region and its sub-regions when subregions is true",
+		request: ListNodesRequest{}, response: ListNodesResponse{}, query: []string{"region", "subregions"}},
+	{method: http.MethodDelete, path: "/nodes/{id}", tag: "nodes", summary: "delete a node and revoke its certificates",
+		request: DeleteNodeRequest{}, response: DeleteNodeResponse{}},
+	{method: http.MethodPatch, path: "/nodes/{id}", tag: "nodes", summary: "update a node",
+		request: UpdateNodeRequest{}, response: UpdateNodeResponse{}, body: true},
+
+	{method: http.MethodPost, path: "/types", tag: "types", summary: "add a node type",
+		request: AddNodeTypeRequest{}, response: AddNodeTypeResponse{}, body: true},
+	{method: http.MethodGet, path: "/types", tag: "types", summary: "list node types",
+		request: ListNodeTypesRequest{}, response: ListNodeTypesResponse{}},
+	{method: http.MethodGet, path: "/types/{id}", tag: "types", summary: "get a node type",
+		request: GetNodeTypeRequest{}, response: GetNodeTypeResponse{}},
+	{method: http.MethodDelete, path: "/types/{id}", tag: "types", summary: "delete a node type",
+		request: DeleteNodeTypeRequest{}, response: DeleteNodeTypeResponse{}},
+	{method: http.MethodPatch, path: "/types/{id}", tag: "types", summary: "update a node type",
+		request: UpdateNodeTypeRequest{}, response: UpdateNodeTypeResponse{}, body: true},
+
+	{method: http.MethodPost, path: "/claims/codes", tag: "provisioning", summary: "create a claim code",
+		request: CreateClaimCodeRequest{}, response: CreateClaimCodeResponse{}, body: true},
+	{method: http.MethodGet, path: "/claims/codes", tag: "provisioning", summary: "list claim codes",
+		request: ListClaimCodesRequest{}, response: ListClaimCodesResponse{}},
+	{method: http.MethodPost, path: "/provision", tag: "provisioning", summary: "provision a node with a claim code",
+		request: ProvisionNodeRequest{}, response: ProvisionNodeResponse{}, body: true, public: true},
+	{method: http.MethodPost, path: "/nodes/authenticate", tag: "provisioning", summary: "check the key of a provisioned node",
+		request: AuthenticateNodeRequest{}, response: AuthenticateNodeResponse{}, body: true, public: true},
+	{method: http.MethodGet, path: "/claims", tag: "provisioning", summary: "list provisioning attempts",
+		request: ListClaimsRequest{}, response: ListClaimsResponse{}},
+
+	{method: http.MethodPost, path: "/certs", tag: "certificates", summary: "issue a node certificate",
+		request: IssueCertificateRequest{}, response: IssueCertificateResponse{}, body: true},
+	{method: http.MethodPost, path: "/certs/{serial}/revoke", tag: "certificates", summary: "revoke a certificate",
+		request: RevokeCertificateRequest{}, response: RevokeCertificateResponse{}},
+	{method: http.MethodGet, path: "/certs", tag: "certificates", summary: "list issued certificates",
+		request: ListCertificatesRequest{}, response: ListCertificatesResponse{}},
+	{method: http.MethodGet, path: "/certs/crl", tag: "certificates", summary: "PEM encoded certificate revocation list",
+		request: RevocationListRequest{}, response: RevocationListResponse{}, produces: "application/x-pem-file", public: true},
+
+	{method: http.MethodPost, path: "/apikeys", tag: "api keys", summary: "create an api key, the key is only returned once",
+		request: CreateAPIKeyRequest{}, response: CreateAPIKeyResponse{}, body: true},
+	{method: http.MethodGet, path: "/apikeys", tag: "api keys", summary: "list api keys",
+		request: ListAPIKeysRequest{}, response: ListAPIKeysResponse{}, query: []string{"owner"}},
+	{method: http.MethodDelete, path: "/apikeys/{id}", tag: "api keys", summary: "revoke an api key",
+		request: RevokeAPIKeyRequest{}, response: RevokeAPIKeyResponse{}},
+	{method: http.MethodGet, path: "/auth", tag: "api keys", summary: "details of the api key of the request",
+		request: AuthAPIKeyRequest{}, response: AuthAPIKeyResponse{}},
+
+	{method: http.MethodPost, path: "/orgs", tag: "organizations", summary: "add an organization",
+		request: AddOrganizationRequest{}, response: AddOrganizationResponse{}, body: true},
+	{method: http.MethodGet, path: "/orgs/{id}", tag: "organizations", summary: "get an organization",
+		request: GetOrganizationRequest{}, response: GetOrganizationResponse{}},
+	{method: http.MethodGet, path: "/orgs", tag: "organizations", summary: "list organizations",
+		request: ListOrganizationsRequest{}, response: ListOrganizationsResponse{}},
+	{method: http.MethodDelete, path: "/orgs/{id}", tag: "organizations", summary: "delete an organization",
+		request: DeleteOrganizationRequest{}, response: DeleteOrganizationResponse{}},
+
+	{method: http.MethodPost, path: "/firmware", tag: "firmware", summary: "add a firmware release, the blob is base64 encoded and its checksum computed when left empty",
+		request: AddFirmwareRequest{}, response: AddFirmwareResponse{}, body: true},
+	{method: http.MethodGet, path: "/firmware", tag: "firmware", summary: "list firmware releases without their blobs",
+		request: ListFirmwareRequest{}, response: ListFirmwareResponse{}},
+	{method: http.MethodGet, path: "/firmware/{id}", tag: "firmware", summary: "get a firmware release with its blob",
+		request: GetFirmwareRequest{}, response: GetFirmwareResponse{}},
+	{method: http.MethodDelete, path: "/firmware/{id}", tag: "firmware", summary: "delete a firmware release no campaign rolls out",
+		request: DeleteFirmwareRequest{}, response: DeleteFirmwareResponse{}},
+	{method: http.MethodPost, path: "/campaigns", tag: "firmware", summary: "start rolling a firmware out to the nodes of a region, type and labels",
+		request: CreateCampaignRequest{}, response: CreateCampaignResponse{}, body: true},
+	{method: http.MethodGet, path: "/campaigns", tag: "firmware", summary: "list campaigns with the count of their nodes by rollout status",
+		request: ListCampaignsRequest{}, response: ListCampaignsResponse{}},
+	{method: http.MethodGet, path: "/campaigns/{id}", tag: "firmware", summary: "get a campaign with the count of its nodes by rollout status",
+		request: GetCampaignRequest{}, response: GetCampaignResponse{}},
+	{method: http.MethodPut, path: "/campaigns/{id}/rollout", tag: "firmware", summary: "enroll nodes until the rollout percentage of the targeted nodes are",
+		request: AdvanceCampaignRequest{}, response: AdvanceCampaignResponse{}, body: true},
+	{method: http.MethodGet, path: "/campaigns/{id}/nodes", tag: "firmware", summary: "the rollout status of the nodes enrolled in a campaign",
+		request: ListRolloutsRequest{}, response: ListRolloutsResponse{}},
+	{method: http.MethodPut, path: "/campaigns/{id}/nodes/{node}", tag: "firmware", summary: "report the rollout status of a node: pending, downloading, applied or failed",
+		request: ReportRolloutRequest{}, response: ReportRolloutResponse{}, body: true},
+
+	{method: http.MethodPost, path: "/schemas", tag: "schemas", summary: "add the next version of the telemetry or commands JSON Schema of a node type or a node",
+		request: AddSchemaRequest{}, response: AddSchemaResponse{}, body: true},
+	{method: http.MethodGet, path: "/schemas", tag: "schemas", summary: "list schemas",
+		request: ListSchemasRequest{}, response: ListSchemasResponse{}, query: []string{"type", "node", "kind"}},
+	{method: http.MethodGet, path: "/schemas/{id}", tag: "schemas", summary: "get a schema",
+		request: GetSchemaRequest{}, response: GetSchemaResponse{}},
+	{method: http.MethodGet, path: "/nodes/{id}/schema", tag: "schemas", summary: "the schema of a node, its own or the one of its type, latest version unless version is set",
+		request: NodeSchemaRequest{}, response: NodeSchemaResponse{}, query: []string{"kind", "version"}},
+	{method: http.MethodPost, path: "/nodes/{id}/schema/validate", tag: "schemas", summary: "validate a sample payload against the schema of a node",
+		request: ValidatePayloadRequest{}, response: ValidatePayloadResponse{}, body: true},
+
+	{method: http.MethodPut, path: "/regions/{id}/quota", tag: "quotas", summary: "set the maximum users and nodes by node type of a region, 0 is unlimited",
+		request: SetQuotaRequest{}, response: SetQuotaResponse{}, body: true},
+	{method: http.MethodPut, path: "/orgs/{id}/quota", tag: "quotas", summary: "set the maximum users and nodes by node type of an organization, 0 is unlimited",
+		request: SetQuotaRequest{}, response: SetQuotaResponse{}, body: true},
+	{method: http.MethodGet, path: "/quotas", tag: "quotas", summary: "list the quotas of regions and organizations",
+		request: ListQuotasRequest{}, response: ListQuotasResponse{}},
+	{method: http.MethodGet, path: "/regions/{id}/usage", tag: "quotas", summary: "the users and nodes by node type of a region against its quota",
+		request: UsageRequest{}, response: UsageResponse{}},
+	{method: http.MethodGet, path: "/orgs/{id}/usage", tag: "quotas", summary: "the users and nodes by node type of an organization against its quota",
+		request: UsageRequest{}, response: UsageResponse{}},
+
+	{method: http.MethodGet, path: "/reports/inventory", tag: "reports", summary: "count nodes by region, type and status, or by the dimensions in by",
+		request: InventoryRequest{}, response: InventoryResponse{}, query: []string{"region", "subregions", "by"}},
+	{method: http.MethodGet, path: "/reports/growth", tag: "reports", summary: "the nodes added in each day, week or month since a date and the running totals",
+		request: GrowthRequest{}, response: GrowthResponse{}, query: []string{"region", "subregions", "period", "since"}},
+	{method: http.MethodGet, path: "/reports/orphans", tag: "reports", summary: "list nodes whose master node does not exist",
+		request: OrphanedNodesRequest{}, response: OrphanedNodesResponse{}, query: []string{"region", "subregions"}},
+
+	{method: http.MethodGet, path: "/events/watch", tag: "events", summary: "stream the changes made to the registry as server-sent events, or over a websocket",
+		request: WatchRequest{}, response: WatchResponse{}, produces: eventStreamType, query: []string{"entity", "region", "name", "cursor"}},
+
+	{method: http.MethodGet, path: "/openapi.json", tag: "docs", summary: "this document",
+		produces: "application/json", public: true},
+	{method: http.MethodGet, path: "/docs", tag: "docs", summary: "this document rendered as HTML",
+		produces: "text/html", public: true},
+
+	{method: http.MethodGet, path: "/healthz", tag: "health", summary: "liveness of regsvc",
+		response: HealthResponse{}, public: true},
+	{method: http.MethodGet, path: "/readyz", tag: "health", summary: "readiness of regsvc, database connectivity and schema",
+		response: HealthResponse{}, public: true},
+}
+
+var pathParam = regexp.MustCompile(`{([^}]+)}`)
+
+// OpenAPI returns the OpenAPI 3 document of the routes of MakeHTTPHandler and
+// the health probes regsvc serves next to them
+func OpenAPI() map[string]interface{} {
+	schemas := map[string]interface{}{
+		"Error": map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"error": map[string]interface{}{"type": "string"},
+			},
+		},
+	}
+
+	paths := map[string]interface{}{}
+
+	for _, op := range operations {
+		item, ok := paths[op.path].(map[string]interface{})
+		if !ok {
+			item = map[string]interface{}{}
+			paths[op.path] = item
+		}
+
+		var params []interface{}
+		for _, m := range pathParam.FindAllStringSubmatch(op.path, -1) {
+			params = append(params, parameter(op.request, m[1], "path"))
+		}
+		for _, q := range op.query {
+			params = append(params, parameter(op.request, q, "query"))
+		}
+		if op.method == http.MethodPost {
+			params = append(params, map[string]interface{}{
+				"name":        IdempotencyKeyHeader,
+				"in":          "header",
+				"description": "replay the response of an earlier request sent with the same key instead of running it again",
+				"schema":      map[string]interface{}{"type": "string", "maxLength": 255},
+			})
+		}
+
+		o := map[string]interface{}{
+			"tags":        []string{op.tag},
+			"summary":     op.summary,
+			"operationId": operationID(op),
+			"responses": map[string]interface{}{
+				"200": response(op, schemas),
+				"default": map[string]interface{}{
+					"description": "the request failed",
+					"content": map[string]interface{}{
+						"application/json": map[string]interface{}{"schema": ref("Error")},
+					},
+				},
+			},
+		}
+
+		if op.request != nil {
+			//request types are documented even when they are not sent as
+			//a body, they name the parameters of the route
+			schema := schemaOf(reflect.TypeOf(op.request), schemas)
+			if op.body {
+				o["requestBody"] = map[string]interface{}{
+					"required": true,
+					"content": map[string]interface{}{
+						"application/json": map[string]interface{}{"schema": schema},
+					},
+				}
+			}
+		}
+
+		if len(params) > 0 {
+			o["parameters"] = params
+		}
+
+		if op.public {
+			o["security"] = []interface{}{}
+		}
+
+		item[strings.ToLower(op.method)] = o
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "iGrid device registry",
+			"description": "registry of the users, regions and nodes of the iGrid network",
+			"version":     "1.0.0",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas,
+			"securitySchemes": map[string]interface{}{
+				"apiKey": map[string]interface{}{
+					"type":        "http",
+					"scheme":      "bearer",
+					"description": "api key created with POST /apikeys",
+				},
+			},
+		},
+		//the api key is optional until the first one is created
+		"security": []interface{}{
+			map[string]interface{}{"apiKey": []string{}},
+			map[string]interface{}{},
+		},
+	}
+}
+
+// operationID is the name of the request without the Request suffix, or
+// the path of routes without a request
+func operationID(op operation) string {
+	if op.request == nil {
+		return strings.TrimSuffix(strings.Trim(op.path, "/"), ".json")
+	}
+
+	name := strings.TrimSuffix(reflect.TypeOf(op.request).Name(), "Request")
+
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+func response(op operation, schemas map[string]interface{}) map[string]interface{} {
+	schema := map[string]interface{}{"type": "object"}
+	if op.response != nil {
+		schema = schemaOf(reflect.TypeOf(op.response), schemas)
+	}
+
+	//the response of these routes is written as is, not as JSON
+	if op.produces == "application/x-pem-file" || op.produces == "text/html" {
+		schema = map[string]interface{}{"type": "string"}
+	}
+
+	//the data of each server-sent event is an event encoded as JSON
+	if op.produces == eventStreamType {
+		schema = schemaOf(reflect.TypeOf(registry.Event{}), schemas)
+	}
+
+	produces := op.produces
+	if produces == "" {
+		produces = "application/json"
+	}
+
+	return map[string]interface{}{
+		"description": op.summary,
+		"content": map[string]interface{}{
+			produces: map[string]interface{}{"schema": schema},
+		},
+	}
+}
+
+// parameter documents the path or query parameter name of the request
+func parameter(request interface{}, name, in string) map[string]interface{} {
+	schema := map[string]interface{}{"type": "string"}
+
+	if request != nil {
+		t := reflect.TypeOf(request)
+		for i := 0; i < t.NumField(); i++ {
+			if jsonName(t.Field(i)) == name {
+				schema = schemaOf(t.Field(i).Type, nil)
+			}
+		}
+	}
+
+	return map[string]interface{}{
+		"name":     name,
+		"in":       in,
+		"required": in == "path",
+		"schema":   schema,
+	}
+}
+
+var (
+	errorType      = reflect.TypeOf((*error)(nil)).Elem()
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	timeType       = reflect.TypeOf(time.Time{})
+)
+
+// schemaOf returns the schema of t, structs are added to schemas and
+// referenced.
+func schemaOf(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	//raw JSON, like the coordinates of geometries, can be any value
+	if t == rawMessageType {
+		return map[string]interface{}{}
+	}
+	//times are encoded as RFC3339 strings
+	if t == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return schemaOf(t.Elem(), schemas)
+
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": schemaOf(t.Elem(), schemas)}
+
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": schemaOf(t.Elem(), schemas)}
+
+	case reflect.Struct:
+		if schemas == nil {
+			return map[string]interface{}{"type": "object"}
+		}
+
+		if _, ok := schemas[t.Name()]; !ok {
+			//registered before the fields are walked so recursive types end
+			schemas[t.Name()] = nil
+
+			props := map[string]interface{}{}
+			for i := 0; i < t.NumField(); i++ {
+				f := t.Field(i)
+				name := jsonName(f)
+
+				//errors are not encoded in the response body, failed requests
+				//are answered with an Error
+				if name == "" || f.Type == errorType {
+					continue
+				}
+
+				props[name] = schemaOf(f.Type, schemas)
+			}
+
+			schemas[t.Name()] = map[string]interface{}{"type": "object", "properties": props}
+		}
+
+		return ref(t.Name())
+	}
+
+	return map[string]interface{}{}
+}
+
+func jsonName(f reflect.StructField) string {
+	if f.PkgPath != "" {
+		return ""
+	}
+
+	name := strings.Split(f.Tag.Get("json"), ",")[0]
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return f.Name
+	}
+
+	return name
+}
+
+func ref(name string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+}
+
+func serveOpenAPI(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(OpenAPI())
+}
+
+// docsOperation is a route of the docs page
+type docsOperation struct {
+	Method   string
+	Path     string
+	Summary  string
+	Public   bool
+	Params   []docsField
+	Body     *docsField
+	Response docsField
+}
+
+// docsField is a parameter, a body or a property of a schema, Ref is the
+// schema its type refers to
+type docsField struct {
+	Name     string
+	In       string
+	Type     string
+	Ref      string
+	Required bool
+}
+
+type docsSchema struct {
+	Name   string
+	Fields []docsField
+}
+
+// docsPage renders the document as plain HTML, it loads no scripts or
+// styles from anywhere so it is served with a CSP that forbids them
+var docsPage = template.Must(template.New("docs").Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>iGrid device registry API</title>
+  <style>
+    body { font-family: sans-serif; margin: 2em auto; max-width: 60em; }
+    h3 code { margin-right: .5em; }
+    table { border-collapse: collapse; margin-bottom: 1em; }
+    td, th { border: 1px solid #ccc; padding: .2em .6em; text-align: left; }
+    .public { color: #080; }
+  </style>
+</head>
+<body>
+  <h1>iGrid device registry API</h1>
+  <p>The OpenAPI 3 document of this API is served at <a href="/openapi.json">/openapi.json</a>.
+  Requests are authenticated with an api key sent as <code>Authorization: Bearer &lt;key&gt;</code>.</p>
+{{define "type"}}{{if .Ref}}<a href="#{{.Ref}}">{{.Type}}</a>{{else}}{{.Type}}{{end}}{{end}}
+{{range $tag, $ops := .Operations}}
+  <h2 id="{{$tag}}">{{$tag}}</h2>
+{{range $ops}}
+  <h3><code>{{.Method}}</code><code>{{.Path}}</code></h3>
+  <p>{{.Summary}}{{if .Public}} <span class="public">no api key needed</span>{{end}}</p>
+{{if .Params}}
+  <table>
+    <tr><th>parameter</th><th>in</th><th>type</th><th>required</th></tr>
+{{range .Params}}    <tr><td>{{.Name}}</td><td>{{.In}}</td><td>{{template "type" .}}</td><td>{{.Required}}</td></tr>
+{{end}}  </table>
+{{end}}{{with .Body}}  <p>body: {{template "type" .}}</p>
+{{end}}  <p>response: {{template "type" .Response}}</p>
+{{end}}{{end}}
+  <h2>schemas</h2>
+{{range .Schemas}}
+  <h3 id="{{.Name}}">{{.Name}}</h3>
+{{if .Fields}}  <table>
+    <tr><th>property</th><th>type</th></tr>
+{{range .Fields}}    <tr><td>{{.Name}}</td><td>{{template "type" .}}</td></tr>
+{{end}}  </table>
+{{end}}{{end}}
+</body>
+</html>
+`))
+
+// docsType describes schema, the type of a parameter or a property
+func docsType(schema map[string]interface{}) docsField {
+	if r, ok := schema["$ref"].(string); ok {
+		name := strings.TrimPrefix(r, "#/components/schemas/")
+		return docsField{Type: name, Ref: name}
+	}
+
+	switch t, _ := schema["type"].(string); {
+	case t == "array":
+		f := docsType(schema["items"].(map[string]interface{}))
+		f.Type = "array of " + f.Type
+		return f
+
+	case t == "object" && schema["additionalProperties"] != nil:
+		f := docsType(schema["additionalProperties"].(map[string]interface{}))
+		f.Type = "map of " + f.Type
+		return f
+
+	case t == "":
+		return docsField{Type: "any"}
+
+	case schema["format"] != nil:
+		return docsField{Type: fmt.Sprintf("%s (%s)", t, schema["format"])}
+
+	default:
+		return docsField{Type: t}
+	}
+}
+
+func serveDocs(w http.ResponseWriter, _ *http.Request) {
+	doc := OpenAPI()
+	paths := doc["paths"].(map[string]interface{})
+
+	//operations are grouped by tag and keep the order of the operations table
+	ops := map[string][]docsOperation{}
+	for _, op := range operations {
+		o := paths[op.path].(map[string]interface{})[strings.ToLower(op.method)].(map[string]interface{})
+
+		d := docsOperation{Method: op.method, Path: op.path, Summary: op.summary, Public: op.public}
+
+		params, _ := o["parameters"].([]interface{})
+		for _, p := range params {
+			p := p.(map[string]interface{})
+			f := docsType(p["schema"].(map[string]interface{}))
+			f.Name, f.In, f.Required = p["name"].(string), p["in"].(string), p["required"] == true
+			d.Params = append(d.Params, f)
+		}
+
+		if body, ok := o["requestBody"].(map[string]interface{}); ok {
+			f := docsType(body["content"].(map[string]interface{})["application/json"].(map[string]interface{})["schema"].(map[string]interface{}))
+			d.Body = &f
+		}
+
+		for _, c := range o["responses"].(map[string]interface{})["200"].(map[string]interface{})["content"].(map[string]interface{}) {
+			d.Response = docsType(c.(map[string]interface{})["schema"].(map[string]interface{}))
+		}
+
+		ops[op.tag] = append(ops[op.tag], d)
+	}
+
+	components := doc["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+
+	var schemas []docsSchema
+	for name, schema := range components {
+		s := docsSchema{Name: name}
+
+		props, _ := schema.(map[string]interface{})["properties"].(map[string]interface{})
+		for prop, p := range props {
+			f := docsType(p.(map[string]interface{}))
+			f.Name = prop
+			s.Fields = append(s.Fields, f)
+		}
+		sort.Slice(s.Fields, func(i, j int) bool { return s.Fields[i].Name < s.Fields[j].Name })
+
+		schemas = append(schemas, s)
+	}
+	sort.Slice(schemas, func(i, j int) bool { return schemas[i].Name < schemas[j].Name })
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'")
+	docsPage.Execute(w, map[string]interface{}{"Operations": ops, "Schemas": schemas})
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenAPIRoutes(t *testing.T) {
+	router, ok := MakeHTTPHandler(nil, log.NewNopLogger()).(*mux.Router)
+	if !ok {
+		t.Fatal("MakeHTTPHandler does not return a mux.Router")
+	}
+
+	paths := OpenAPI()["paths"].(map[string]interface{})
+
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+
+		methods, err := route.GetMethods()
+		if err != nil {
+			return err
+		}
+
+		for _, method := range methods {
+			item, _ := paths[path].(map[string]interface{})
+			_, documented := item[strings.ToLower(method)]
+			assert.True(t, documented, "%s %s is not documented, add it to operations in openapi.go", method, path)
+		}
+
+		return nil
+	})
+	assert.Nil(t, err)
+}
+
+func TestOpenAPITypes(t *testing.T) {
+	schemas := OpenAPI()["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+
+	fset := token.NewFileSet()
+	for _, file := range []string{"requests.go", "responses.go", "endpoint.go"} {
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+
+			for _, spec := range gen.Specs {
+				name := spec.(*ast.TypeSpec).Name.Name
+				if !strings.HasSuffix(name, "Request") && !strings.HasSuffix(name, "Response") {
+					continue
+				}
+
+				_, documented := schemas[name]
+				assert.True(t, documented, "%s of %s is not documented", name, file)
+			}
+		}
+	}
+}
+
+func TestServeOpenAPI(t *testing.T) {
+	h := MakeHTTPHandler(nil, log.NewNopLogger())
+
+	cases := []struct {
+		desc        string
+		path        string
+		contentType string
+	}{
+		{
+			desc:        "serve the openapi document",
+			path:        "/openapi.json",
+			contentType: "application/json; charset=utf-8",
+		},
+		{
+			desc:        "serve the docs ui",
+			path:        "/docs",
+			contentType: "text/html; charset=utf-8",
+		},
+	}
+
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+		assert.Equal(t, http.StatusOK, w.Code, tc.desc)
+		assert.Equal(t, tc.contentType, w.Header().Get("Content-Type"), tc.desc)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	var doc map[string]interface{}
+	assert.Nil(t, json.NewDecoder(w.Body).Decode(&doc))
+	assert.Equal(t, "3.0.3", doc["openapi"])
+
+	//the docs page is rendered by regsvc, nothing is loaded from elsewhere
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs", nil))
+
+	page := w.Body.String()
+	assert.NotContains(t, page, "<script")
+	assert.NotContains(t, page, "https://")
+	assert.Equal(t, "default-src 'none'; style-src 'unsafe-inline'", w.Header().Get("Content-Security-Policy"))
+	for _, op := range operations {
+		assert.Contains(t, page, "<code>"+op.path+"</code>", "%s %s is not documented", op.method, op.path)
+	}
+	assert.Contains(t, page, `<a href="#Node">Node</a>`)
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/transport"
+	"github.com/piusalfred/registry/logger"
+	"github.com/piusalfred/registry/pkg/errors"
+	"net/http"
+)
+
+// RequestIDHeader is the header carrying the id that correlates the log lines
+// of a request, it is echoed in the response.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLen bounds the ids accepted from clients
+const maxRequestIDLen = 128
+
+// RequestID propagates the id of the requests sent with a valid X-Request-ID
+// header and assigns a new one to the others. The id is set on the response
+// and added to the fields of the request context so that every line logged
+// for the request carries it.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(logger.WithRequestID(r.Context(), id)))
+	})
+}
+
+// validRequestID rejects ids that are too long or could forge log lines
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+
+	return hex.EncodeToString(b)
+}
+
+// logErrorHandler is a transport.ErrorHandler that logs the errors along
+// with the fields of the request context
+type logErrorHandler struct {
+	logger log.Logger
+}
+
+var _ transport.ErrorHandler = (*logErrorHandler)(nil)
+
+func (h logErrorHandler) Handle(ctx context.Context, err error) {
+	l := log.With(h.logger, logger.Redact(logger.Fields(ctx))...)
+	if caller := errors.Caller(err); caller != "" {
+		l = log.With(l, "origin", caller)
+	}
+	l.Log("err", err)
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/piusalfred/registry/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	var got string
+	h := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = logger.RequestID(r.Context())
+	}))
+
+	cases := []struct {
+		desc      string
+		header    string
+		propagate bool
+	}{
+		{desc: "id sent by the client", header: "req-7f3a", propagate: true},
+		{desc: "no id", header: ""},
+		{desc: "id forging log lines", header: "a\nlevel=error"},
+	}
+
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/nodes", nil)
+		req.Header.Set(RequestIDHeader, tc.header)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		id := w.Header().Get(RequestIDHeader)
+		assert.NotEmpty(t, id, tc.desc)
+		assert.Equal(t, id, got, tc.desc)
+		assert.Equal(t, tc.propagate, id == tc.header, tc.desc)
+	}
+}
@@ -1,6 +1,10 @@
 package api
 
-import "github.com/piusalfred/registry"
+import (
+	"encoding/json"
+	"github.com/piusalfred/registry"
+	"time"
+)
 
 /*var (
 	_ ReqValidator = (*GetNodeRequest)(nil)
@@ -32,7 +36,10 @@ type AddUserRequest struct {
 }
 
 // ListUserRequest collects the request parameters for the ListUser method.
-type ListUserRequest struct{}
+type ListUserRequest struct {
+	Region     string `json:"region"`
+	Subregions bool   `json:"subregions"`
+}
 
 // DeleteUserRequest collects the request parameters for the DeleteUser method.
 type DeleteUserRequest struct {
@@ -52,3 +59,260 @@ type AddRegionRequest struct {
 
 // ListRegionsRequest collects the request parameters for the ListRegions method.
 type ListRegionsRequest struct{}
+
+// SuggestRegionRequest collects the request parameters for the SuggestRegion method.
+type SuggestRegionRequest struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// MoveRegionRequest collects the request parameters for the MoveRegion method.
+type MoveRegionRequest struct {
+	Id     string `json:"id"`
+	Parent string `json:"parent"`
+}
+
+// RegionSubtreeRequest collects the request parameters for the RegionSubtree method.
+type RegionSubtreeRequest struct {
+	Id string `json:"id"`
+}
+
+// RegionAncestorsRequest collects the request parameters for the RegionAncestors method.
+type RegionAncestorsRequest struct {
+	Id string `json:"id"`
+}
+
+// AddNodeTypeRequest collects the request parameters for the AddNodeType method.
+type AddNodeTypeRequest struct {
+	NodeType registry.NodeType `json:"node_type"`
+}
+
+// GetNodeTypeRequest collects the request parameters for the GetNodeType method.
+type GetNodeTypeRequest struct {
+	Id int `json:"id"`
+}
+
+// ListNodeTypesRequest collects the request parameters for the ListNodeTypes method.
+type ListNodeTypesRequest struct{}
+
+// DeleteNodeTypeRequest collects the request parameters for the DeleteNodeType method.
+type DeleteNodeTypeRequest struct {
+	Id int `json:"id"`
+}
+
+// UpdateNodeTypeRequest collects the request parameters for the UpdateNodeType method.
+type UpdateNodeTypeRequest struct {
+	Id       int               `json:"id"`
+	NodeType registry.NodeType `json:"node_type"`
+}
+
+// CreateClaimCodeRequest collects the request parameters for the CreateClaimCode method.
+type CreateClaimCodeRequest struct {
+	Region string `json:"region"`
+	Type   int    `json:"type"`
+	TTL    string `json:"ttl"`
+}
+
+// ListClaimCodesRequest collects the request parameters for the ListClaimCodes method.
+type ListClaimCodesRequest struct{}
+
+// ProvisionNodeRequest collects the request parameters for the ProvisionNode method.
+type ProvisionNodeRequest struct {
+	Code string        `json:"code"`
+	Node registry.Node `json:"node"`
+}
+
+// AuthenticateNodeRequest collects the request parameters for the AuthenticateNode method.
+type AuthenticateNodeRequest struct {
+	NodeID string `json:"node_id"`
+	Key    string `json:"key"`
+}
+
+// ListClaimsRequest collects the request parameters for the ListClaims method.
+type ListClaimsRequest struct{}
+
+// IssueCertificateRequest collects the request parameters for the IssueCertificate method.
+type IssueCertificateRequest struct {
+	Node string `json:"node"`
+	CSR  string `json:"csr"`
+	TTL  string `json:"ttl"`
+}
+
+// RevokeCertificateRequest collects the request parameters for the RevokeCertificate method.
+type RevokeCertificateRequest struct {
+	Serial string `json:"serial"`
+}
+
+// ListCertificatesRequest collects the request parameters for the ListCertificates method.
+type ListCertificatesRequest struct{}
+
+// RevocationListRequest collects the request parameters for the RevocationList method.
+type RevocationListRequest struct{}
+
+// CreateAPIKeyRequest collects the request parameters for the CreateAPIKey method.
+type CreateAPIKeyRequest struct {
+	Owner  string `json:"owner"`
+	Name   string `json:"name"`
+	Role   int    `json:"role"`
+	Region string `json:"region"`
+	TTL    string `json:"ttl"`
+}
+
+// ListAPIKeysRequest collects the request parameters for the ListAPIKeys method.
+type ListAPIKeysRequest struct {
+	Owner string `json:"owner"`
+}
+
+// RevokeAPIKeyRequest collects the request parameters for the RevokeAPIKey method.
+type RevokeAPIKeyRequest struct {
+	Id string `json:"id"`
+}
+
+// AuthAPIKeyRequest collects the request parameters for the AuthAPIKey method.
+type AuthAPIKeyRequest struct {
+	Key string `json:"-"`
+}
+
+// AddOrganizationRequest collects the request parameters for the AddOrganization method.
+type AddOrganizationRequest struct {
+	Org registry.Organization `json:"org"`
+}
+
+// GetOrganizationRequest collects the request parameters for the GetOrganization method.
+type GetOrganizationRequest struct {
+	Id string `json:"id"`
+}
+
+// ListOrganizationsRequest collects the request parameters for the ListOrganizations method.
+type ListOrganizationsRequest struct{}
+
+// DeleteOrganizationRequest collects the request parameters for the DeleteOrganization method.
+type DeleteOrganizationRequest struct {
+	Id string `json:"id"`
+}
+
+// AddFirmwareRequest collects the request parameters for the AddFirmware method.
+type AddFirmwareRequest struct {
+	Firmware registry.Firmware `json:"firmware"`
+}
+
+// GetFirmwareRequest collects the request parameters for the GetFirmware method.
+type GetFirmwareRequest struct {
+	Id string `json:"id"`
+}
+
+// ListFirmwareRequest collects the request parameters for the ListFirmware method.
+type ListFirmwareRequest struct{}
+
+// DeleteFirmwareRequest collects the request parameters for the DeleteFirmware method.
+type DeleteFirmwareRequest struct {
+	Id string `json:"id"`
+}
+
+// CreateCampaignRequest collects the request parameters for the CreateCampaign method.
+type CreateCampaignRequest struct {
+	Campaign registry.Campaign `json:"campaign"`
+}
+
+// GetCampaignRequest collects the request parameters for the GetCampaign method.
+type GetCampaignRequest struct {
+	Id string `json:"id"`
+}
+
+// ListCampaignsRequest collects the request parameters for the ListCampaigns method.
+type ListCampaignsRequest struct{}
+
+// AdvanceCampaignRequest collects the request parameters for the AdvanceCampaign method.
+type AdvanceCampaignRequest struct {
+	Id      string `json:"id"`
+	Rollout int    `json:"rollout"`
+}
+
+// ListRolloutsRequest collects the request parameters for the ListRollouts method.
+type ListRolloutsRequest struct {
+	Id string `json:"id"`
+}
+
+// ReportRolloutRequest collects the request parameters for the ReportRollout method.
+type ReportRolloutRequest struct {
+	Id     string                 `json:"id"`
+	Node   string                 `json:"node"`
+	Status registry.RolloutStatus `json:"status"`
+	Err    string                 `json:"err"`
+}
+
+// AddSchemaRequest collects the request parameters for the AddSchema method.
+type AddSchemaRequest struct {
+	Schema registry.Schema `json:"schema"`
+}
+
+// GetSchemaRequest collects the request parameters for the GetSchema method.
+type GetSchemaRequest struct {
+	Id string `json:"id"`
+}
+
+// ListSchemasRequest collects the request parameters for the ListSchemas method.
+type ListSchemasRequest struct {
+	Type int                 `json:"type"`
+	Node string              `json:"node"`
+	Kind registry.SchemaKind `json:"kind"`
+}
+
+// NodeSchemaRequest collects the request parameters for the NodeSchema method.
+type NodeSchemaRequest struct {
+	Id      string              `json:"id"`
+	Kind    registry.SchemaKind `json:"kind"`
+	Version int                 `json:"version"`
+}
+
+// ValidatePayloadRequest collects the request parameters for the ValidatePayload method.
+type ValidatePayloadRequest struct {
+	Id      string              `json:"id"`
+	Kind    registry.SchemaKind `json:"kind"`
+	Version int                 `json:"version"`
+	Payload json.RawMessage     `json:"payload"`
+}
+
+// SetQuotaRequest collects the request parameters for the SetQuota method.
+type SetQuotaRequest struct {
+	Quota registry.Quota `json:"quota"`
+}
+
+// ListQuotasRequest collects the request parameters for the ListQuotas method.
+type ListQuotasRequest struct{}
+
+// UsageRequest collects the request parameters for the Usage method.
+type UsageRequest struct {
+	Scope registry.QuotaScope `json:"scope"`
+	Id    string              `json:"id"`
+}
+
+// InventoryRequest collects the request parameters for the Inventory method.
+type InventoryRequest struct {
+	Region     string               `json:"region"`
+	Subregions bool                 `json:"subregions"`
+	By         []registry.Dimension `json:"by"`
+}
+
+// GrowthRequest collects the request parameters for the Growth method.
+type GrowthRequest struct {
+	Region     string                `json:"region"`
+	Subregions bool                  `json:"subregions"`
+	Period     registry.GrowthPeriod `json:"period"`
+	Since      time.Time             `json:"since"`
+}
+
+// OrphanedNodesRequest collects the request parameters for the OrphanedNodes method.
+type OrphanedNodesRequest struct {
+	Region     string `json:"region"`
+	Subregions bool   `json:"subregions"`
+}
+
+// WatchRequest collects the request parameters for the Watch method, empty
+// filters match every event and a zero cursor only streams new events.
+type WatchRequest struct {
+	Entities []string      `json:"entity"`
+	Regions  []string      `json:"region"`
+	Names    []string      `json:"name"`
+	Cursor   time.Duration `json:"cursor"`
+}
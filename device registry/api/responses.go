@@ -93,6 +93,50 @@ func (r ListRegionsResponse) Failed() error {
 	return r.Err
 }
 
+// SuggestRegionResponse collects the response parameters for the SuggestRegion method.
+type SuggestRegionResponse struct {
+	Regions []registry.Region `json:"regions"`
+	Err     error             `json:"err"`
+}
+
+// Failed implements Failer.
+func (r SuggestRegionResponse) Failed() error {
+	return r.Err
+}
+
+// MoveRegionResponse collects the response parameters for the MoveRegion method.
+type MoveRegionResponse struct {
+	Region registry.Region `json:"region"`
+	Err    error           `json:"err"`
+}
+
+// Failed implements Failer.
+func (r MoveRegionResponse) Failed() error {
+	return r.Err
+}
+
+// RegionSubtreeResponse collects the response parameters for the RegionSubtree method.
+type RegionSubtreeResponse struct {
+	Regions []registry.Region `json:"regions"`
+	Err     error             `json:"err"`
+}
+
+// Failed implements Failer.
+func (r RegionSubtreeResponse) Failed() error {
+	return r.Err
+}
+
+// RegionAncestorsResponse collects the response parameters for the RegionAncestors method.
+type RegionAncestorsResponse struct {
+	Regions []registry.Region `json:"regions"`
+	Err     error             `json:"err"`
+}
+
+// Failed implements Failer.
+func (r RegionAncestorsResponse) Failed() error {
+	return r.Err
+}
+
 // Failed implements Failer.
 func (r AddNodeResponse) Failed() error {
 	return r.Err
@@ -103,3 +147,481 @@ type GetNodeResponse struct {
 	Node registry.Node `json:"node"`
 	Err  error         `json:"err"`
 }
+
+// AddNodeTypeResponse collects the response parameters for the AddNodeType method.
+type AddNodeTypeResponse struct {
+	Err error `json:"err"`
+}
+
+// Failed implements Failer.
+func (r AddNodeTypeResponse) Failed() error {
+	return r.Err
+}
+
+// GetNodeTypeResponse collects the response parameters for the GetNodeType method.
+type GetNodeTypeResponse struct {
+	NodeType registry.NodeType `json:"node_type"`
+	Err      error             `json:"err"`
+}
+
+// Failed implements Failer.
+func (r GetNodeTypeResponse) Failed() error {
+	return r.Err
+}
+
+// ListNodeTypesResponse collects the response parameters for the ListNodeTypes method.
+type ListNodeTypesResponse struct {
+	NodeTypes []registry.NodeType `json:"node_types"`
+	Err       error               `json:"err"`
+}
+
+// Failed implements Failer.
+func (r ListNodeTypesResponse) Failed() error {
+	return r.Err
+}
+
+// DeleteNodeTypeResponse collects the response parameters for the DeleteNodeType method.
+type DeleteNodeTypeResponse struct {
+	Err error `json:"err"`
+}
+
+// Failed implements Failer.
+func (r DeleteNodeTypeResponse) Failed() error {
+	return r.Err
+}
+
+// UpdateNodeTypeResponse collects the response parameters for the UpdateNodeType method.
+type UpdateNodeTypeResponse struct {
+	NodeType registry.NodeType `json:"node_type"`
+	Err      error             `json:"err"`
+}
+
+// Failed implements Failer.
+func (r UpdateNodeTypeResponse) Failed() error {
+	return r.Err
+}
+
+// CreateClaimCodeResponse collects the response parameters for the CreateClaimCode method.
+type CreateClaimCodeResponse struct {
+	Code registry.ClaimCode `json:"code"`
+	Err  error              `json:"err"`
+}
+
+// Failed implements Failer.
+func (r CreateClaimCodeResponse) Failed() error {
+	return r.Err
+}
+
+// ListClaimCodesResponse collects the response parameters for the ListClaimCodes method.
+type ListClaimCodesResponse struct {
+	Codes []registry.ClaimCode `json:"codes"`
+	Err   error                `json:"err"`
+}
+
+// Failed implements Failer.
+func (r ListClaimCodesResponse) Failed() error {
+	return r.Err
+}
+
+// ProvisionNodeResponse collects the response parameters for the ProvisionNode method.
+type ProvisionNodeResponse struct {
+	Credentials registry.Credentials `json:"credentials"`
+	Err         error                `json:"err"`
+}
+
+// Failed implements Failer.
+func (r ProvisionNodeResponse) Failed() error {
+	return r.Err
+}
+
+// AuthenticateNodeResponse collects the response parameters for the AuthenticateNode method.
+type AuthenticateNodeResponse struct {
+	Node registry.Node `json:"node"`
+	Err  error         `json:"err"`
+}
+
+// Failed implements Failer.
+func (r AuthenticateNodeResponse) Failed() error {
+	return r.Err
+}
+
+// ListClaimsResponse collects the response parameters for the ListClaims method.
+type ListClaimsResponse struct {
+	Claims []registry.Claim `json:"claims"`
+	Err    error            `json:"err"`
+}
+
+// Failed implements Failer.
+func (r ListClaimsResponse) Failed() error {
+	return r.Err
+}
+
+// IssueCertificateResponse collects the response parameters for the IssueCertificate method.
+type IssueCertificateResponse struct {
+	Certificate registry.Certificate `json:"certificate"`
+	Err         error                `json:"err"`
+}
+
+// Failed implements Failer.
+func (r IssueCertificateResponse) Failed() error {
+	return r.Err
+}
+
+// RevokeCertificateResponse collects the response parameters for the RevokeCertificate method.
+type RevokeCertificateResponse struct {
+	Err error `json:"err"`
+}
+
+// Failed implements Failer.
+func (r RevokeCertificateResponse) Failed() error {
+	return r.Err
+}
+
+// ListCertificatesResponse collects the response parameters for the ListCertificates method.
+type ListCertificatesResponse struct {
+	Certificates []registry.Certificate `json:"certificates"`
+	Err          error                  `json:"err"`
+}
+
+// Failed implements Failer.
+func (r ListCertificatesResponse) Failed() error {
+	return r.Err
+}
+
+// RevocationListResponse collects the response parameters for the RevocationList method.
+type RevocationListResponse struct {
+	CRL []byte `json:"crl"`
+	Err error  `json:"err"`
+}
+
+// Failed implements Failer.
+func (r RevocationListResponse) Failed() error {
+	return r.Err
+}
+
+// CreateAPIKeyResponse collects the response parameters for the CreateAPIKey method.
+type CreateAPIKeyResponse struct {
+	Key registry.APIKey `json:"key"`
+	Err error           `json:"err"`
+}
+
+// Failed implements Failer.
+func (r CreateAPIKeyResponse) Failed() error {
+	return r.Err
+}
+
+// ListAPIKeysResponse collects the response parameters for the ListAPIKeys method.
+type ListAPIKeysResponse struct {
+	Keys []registry.APIKey `json:"keys"`
+	Err  error             `json:"err"`
+}
+
+// Failed implements Failer.
+func (r ListAPIKeysResponse) Failed() error {
+	return r.Err
+}
+
+// RevokeAPIKeyResponse collects the response parameters for the RevokeAPIKey method.
+type RevokeAPIKeyResponse struct {
+	Err error `json:"err"`
+}
+
+// Failed implements Failer.
+func (r RevokeAPIKeyResponse) Failed() error {
+	return r.Err
+}
+
+// AuthAPIKeyResponse collects the response parameters for the AuthAPIKey method.
+type AuthAPIKeyResponse struct {
+	Key registry.APIKey `json:"key"`
+	Err error           `json:"err"`
+}
+
+// Failed implements Failer.
+func (r AuthAPIKeyResponse) Failed() error {
+	return r.Err
+}
+
+// AddOrganizationResponse collects the response parameters for the AddOrganization method.
+type AddOrganizationResponse struct {
+	Org registry.Organization `json:"org"`
+	Err error                 `json:"err"`
+}
+
+// Failed implements Failer.
+func (r AddOrganizationResponse) Failed() error {
+	return r.Err
+}
+
+// GetOrganizationResponse collects the response parameters for the GetOrganization method.
+type GetOrganizationResponse struct {
+	Org registry.Organization `json:"org"`
+	Err error                 `json:"err"`
+}
+
+// Failed implements Failer.
+func (r GetOrganizationResponse) Failed() error {
+	return r.Err
+}
+
+// ListOrganizationsResponse collects the response parameters for the ListOrganizations method.
+type ListOrganizationsResponse struct {
+	Orgs []registry.Organization `json:"orgs"`
+	Err  error                   `json:"err"`
+}
+
+// Failed implements Failer.
+func (r ListOrganizationsResponse) Failed() error {
+	return r.Err
+}
+
+// DeleteOrganizationResponse collects the response parameters for the DeleteOrganization method.
+type DeleteOrganizationResponse struct {
+	Err error `json:"err"`
+}
+
+// Failed implements Failer.
+func (r DeleteOrganizationResponse) Failed() error {
+	return r.Err
+}
+
+// AddFirmwareResponse collects the response parameters for the AddFirmware method.
+type AddFirmwareResponse struct {
+	Firmware registry.Firmware `json:"firmware"`
+	Err      error             `json:"err"`
+}
+
+// Failed implements Failer.
+func (r AddFirmwareResponse) Failed() error {
+	return r.Err
+}
+
+// GetFirmwareResponse collects the response parameters for the GetFirmware method.
+type GetFirmwareResponse struct {
+	Firmware registry.Firmware `json:"firmware"`
+	Err      error             `json:"err"`
+}
+
+// Failed implements Failer.
+func (r GetFirmwareResponse) Failed() error {
+	return r.Err
+}
+
+// ListFirmwareResponse collects the response parameters for the ListFirmware method.
+type ListFirmwareResponse struct {
+	Firmware []registry.Firmware `json:"firmware"`
+	Err      error               `json:"err"`
+}
+
+// Failed implements Failer.
+func (r ListFirmwareResponse) Failed() error {
+	return r.Err
+}
+
+// DeleteFirmwareResponse collects the response parameters for the DeleteFirmware method.
+type DeleteFirmwareResponse struct {
+	Err error `json:"err"`
+}
+
+// Failed implements Failer.
+func (r DeleteFirmwareResponse) Failed() error {
+	return r.Err
+}
+
+// CreateCampaignResponse collects the response parameters for the CreateCampaign method.
+type CreateCampaignResponse struct {
+	Campaign registry.Campaign `json:"campaign"`
+	Err      error             `json:"err"`
+}
+
+// Failed implements Failer.
+func (r CreateCampaignResponse) Failed() error {
+	return r.Err
+}
+
+// GetCampaignResponse collects the response parameters for the GetCampaign method.
+type GetCampaignResponse struct {
+	Campaign registry.Campaign `json:"campaign"`
+	Err      error             `json:"err"`
+}
+
+// Failed implements Failer.
+func (r GetCampaignResponse) Failed() error {
+	return r.Err
+}
+
+// ListCampaignsResponse collects the response parameters for the ListCampaigns method.
+type ListCampaignsResponse struct {
+	Campaigns []registry.Campaign `json:"campaigns"`
+	Err       error               `json:"err"`
+}
+
+// Failed implements Failer.
+func (r ListCampaignsResponse) Failed() error {
+	return r.Err
+}
+
+// AdvanceCampaignResponse collects the response parameters for the AdvanceCampaign method.
+type AdvanceCampaignResponse struct {
+	Campaign registry.Campaign `json:"campaign"`
+	Err      error             `json:"err"`
+}
+
+// Failed implements Failer.
+func (r AdvanceCampaignResponse) Failed() error {
+	return r.Err
+}
+
+// ListRolloutsResponse collects the response parameters for the ListRollouts method.
+type ListRolloutsResponse struct {
+	Rollouts []registry.Rollout `json:"rollouts"`
+	Err      error              `json:"err"`
+}
+
+// Failed implements Failer.
+func (r ListRolloutsResponse) Failed() error {
+	return r.Err
+}
+
+// ReportRolloutResponse collects the response parameters for the ReportRollout method.
+type ReportRolloutResponse struct {
+	Rollout registry.Rollout `json:"rollout"`
+	Err     error            `json:"err"`
+}
+
+// Failed implements Failer.
+func (r ReportRolloutResponse) Failed() error {
+	return r.Err
+}
+
+// AddSchemaResponse collects the response parameters for the AddSchema method.
+type AddSchemaResponse struct {
+	Schema registry.Schema `json:"schema"`
+	Err    error           `json:"err"`
+}
+
+// Failed implements Failer.
+func (r AddSchemaResponse) Failed() error {
+	return r.Err
+}
+
+// GetSchemaResponse collects the response parameters for the GetSchema method.
+type GetSchemaResponse struct {
+	Schema registry.Schema `json:"schema"`
+	Err    error           `json:"err"`
+}
+
+// Failed implements Failer.
+func (r GetSchemaResponse) Failed() error {
+	return r.Err
+}
+
+// ListSchemasResponse collects the response parameters for the ListSchemas method.
+type ListSchemasResponse struct {
+	Schemas []registry.Schema `json:"schemas"`
+	Err     error             `json:"err"`
+}
+
+// Failed implements Failer.
+func (r ListSchemasResponse) Failed() error {
+	return r.Err
+}
+
+// NodeSchemaResponse collects the response parameters for the NodeSchema method.
+type NodeSchemaResponse struct {
+	Schema registry.Schema `json:"schema"`
+	Err    error           `json:"err"`
+}
+
+// Failed implements Failer.
+func (r NodeSchemaResponse) Failed() error {
+	return r.Err
+}
+
+// ValidatePayloadResponse collects the response parameters for the ValidatePayload method.
+type ValidatePayloadResponse struct {
+	Validation registry.Validation `json:"validation"`
+	Err        error               `json:"err"`
+}
+
+// Failed implements Failer.
+func (r ValidatePayloadResponse) Failed() error {
+	return r.Err
+}
+
+// SetQuotaResponse collects the response parameters for the SetQuota method.
+type SetQuotaResponse struct {
+	Quota registry.Quota `json:"quota"`
+	Err   error          `json:"err"`
+}
+
+// Failed implements Failer.
+func (r SetQuotaResponse) Failed() error {
+	return r.Err
+}
+
+// ListQuotasResponse collects the response parameters for the ListQuotas method.
+type ListQuotasResponse struct {
+	Quotas []registry.Quota `json:"quotas"`
+	Err    error            `json:"err"`
+}
+
+// Failed implements Failer.
+func (r ListQuotasResponse) Failed() error {
+	return r.Err
+}
+
+// UsageResponse collects the response parameters for the Usage method.
+type UsageResponse struct {
+	Usage registry.Usage `json:"usage"`
+	Err   error          `json:"err"`
+}
+
+// Failed implements Failer.
+func (r UsageResponse) Failed() error {
+	return r.Err
+}
+
+// InventoryResponse collects the response parameters for the Inventory method.
+type InventoryResponse struct {
+	Inventory []registry.InventoryCount `json:"inventory"`
+	Err       error                     `json:"err"`
+}
+
+// Failed implements Failer.
+func (r InventoryResponse) Failed() error {
+	return r.Err
+}
+
+// GrowthResponse collects the response parameters for the Growth method.
+type GrowthResponse struct {
+	Growth []registry.Growth `json:"growth"`
+	Err    error             `json:"err"`
+}
+
+// Failed implements Failer.
+func (r GrowthResponse) Failed() error {
+	return r.Err
+}
+
+// OrphanedNodesResponse collects the response parameters for the OrphanedNodes method.
+type OrphanedNodesResponse struct {
+	Nodes []registry.Node `json:"nodes"`
+	Err   error           `json:"err"`
+}
+
+// Failed implements Failer.
+func (r OrphanedNodesResponse) Failed() error {
+	return r.Err
+}
+
+// WatchResponse collects the response parameters for the Watch method.
+type WatchResponse struct {
+	Events <-chan registry.Event `json:"-"`
+	Err    error                 `json:"err"`
+}
+
+// Failed implements Failer.
+func (r WatchResponse) Failed() error {
+	return r.Err
+}
@@ -0,0 +1,237 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"github.com/piusalfred/registry"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	eventStreamType = "text/event-stream"
+
+	// watchKeepAlive is how often idle streams are written to, so that
+	// proxies do not close them
+	watchKeepAlive = 15 * time.Second
+
+	watchWriteTimeout = 10 * time.Second
+)
+
+var (
+	ErrInvalidCursor     = errors.New("cursor must be the timestamp of an event")
+	ErrStreamUnsupported = errors.New("response writer does not support streaming")
+)
+
+var upgrader = websocket.Upgrader{}
+
+type httpRequestCtxKey struct{}
+
+// withHTTPRequest keeps the request in the context, the watch response is
+// written to a websocket when the request asks for one
+func withHTTPRequest(ctx context.Context, r *http.Request) context.Context {
+	return context.WithValue(ctx, httpRequestCtxKey{}, r)
+}
+
+// decodeWatchRequest is a transport/http.DecodeRequestFunc that decodes the
+// filters and cursor of a watch request from its query. Browsers resume an
+// EventSource with the Last-Event-ID header instead of the cursor.
+func decodeWatchRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	q := r.URL.Query()
+	req := WatchRequest{
+		Entities: values(q["entity"]),
+		Regions:  values(q["region"]),
+		Names:    values(q["name"]),
+	}
+
+	cursor := q.Get("cursor")
+	if cursor == "" {
+		cursor = r.Header.Get("Last-Event-ID")
+	}
+
+	if cursor != "" {
+		n, err := strconv.ParseInt(cursor, 10, 64)
+		if err != nil || n < 0 {
+			return nil, ErrInvalidCursor
+		}
+		req.Cursor = time.Duration(n)
+	}
+
+	return req, nil
+}
+
+// values splits the comma separated values of a repeated query parameter
+func values(params []string) []string {
+	var vs []string
+	for _, param := range params {
+		for _, v := range strings.Split(param, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				vs = append(vs, v)
+			}
+		}
+	}
+
+	return vs
+}
+
+// encodeWatchResponse is a transport/http.EncodeResponseFunc that streams the
+// events as server-sent events, or as JSON messages over a websocket when the
+// request is an upgrade. It returns when the client goes away or when the
+// events channel is closed.
+func encodeWatchResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	resp := response.(WatchResponse)
+	if resp.Err != nil {
+		ErrorEncoder(ctx, resp.Err, w)
+		return nil
+	}
+
+	if r, ok := ctx.Value(httpRequestCtxKey{}).(*http.Request); ok && websocket.IsWebSocketUpgrade(r) {
+		streamWebSocket(ctx, w, r, resp.Events)
+		return nil
+	}
+
+	return streamEvents(ctx, w, resp.Events)
+}
+
+func streamEvents(ctx context.Context, w http.ResponseWriter, events <-chan registry.Event) error {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return ErrStreamUnsupported
+	}
+
+	w.Header().Set("Content-Type", eventStreamType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ticker := time.NewTicker(watchKeepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+
+			data, err := json.Marshal(event)
+			if err != nil {
+				return err
+			}
+
+			//the id is the cursor the client resumes from
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", int64(event.Timestamp), event.Name, data)
+
+		case <-ticker.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+
+		case <-ctx.Done():
+			return nil
+		}
+
+		flusher.Flush()
+	}
+}
+
+// streamWebSocket writes the events to a websocket, errors are not returned
+// since the connection is no longer an HTTP response once upgraded.
+func streamWebSocket(ctx context.Context, w http.ResponseWriter, r *http.Request, events <-chan registry.Event) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	//the client only sends control frames, reading them notices it leaving
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(watchKeepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				msg := websocket.FormatCloseMessage(websocket.CloseGoingAway, "resume from the last event")
+				conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(watchWriteTimeout))
+				return
+			}
+
+			conn.SetWriteDeadline(time.Now().Add(watchWriteTimeout))
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(watchWriteTimeout)); err != nil {
+				return
+			}
+
+		case <-closed:
+			return
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// decodeWatchResponse is a transport/http.DecodeResponseFunc that reads the
+// server-sent events of the response body until it is closed or ctx is done.
+func decodeWatchResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusOK {
+		defer r.Body.Close()
+		return nil, ErrorDecoder(r)
+	}
+
+	events := make(chan registry.Event)
+
+	go func() {
+		defer close(events)
+		defer r.Body.Close()
+
+		scanner := bufio.NewScanner(r.Body)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+		var data []byte
+		for scanner.Scan() {
+			line := scanner.Text()
+
+			switch {
+			case strings.HasPrefix(line, "data:"):
+				data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " ")...)
+
+			case line == "" && len(data) > 0:
+				var event registry.Event
+				err := json.Unmarshal(data, &event)
+				data = data[:0]
+				if err != nil {
+					continue
+				}
+
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return WatchResponse{Events: events}, nil
+}
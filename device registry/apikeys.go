@@ -0,0 +1,158 @@
+package registry
+
+import (
+	"context"
+	"github.com/piusalfred/registry/pkg/errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidAPIKey    = errors.NewKind(errors.Unauthenticated, "invalid api key")
+	ErrAPIKeyExpired    = errors.NewKind(errors.Unauthenticated, "api key has expired")
+	ErrAPIKeyRevoked    = errors.NewKind(errors.Unauthenticated, "api key has been revoked")
+	ErrInvalidKeyScope  = errors.NewKind(errors.PermissionDenied, "api key scope exceeds the permissions of its owner")
+	ErrPermissionDenied = errors.NewKind(errors.PermissionDenied, "permission denied")
+)
+
+const apiKeySeparator = "."
+
+type apiKeyCtxKey struct{}
+
+// APIKey lets scripts and services access the registry on behalf of a user,
+// a key can not do more than its owner. Role is one of the user groups and
+// Region, when set, limits the key to the nodes of a single region.
+type APIKey struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Owner   string `json:"owner"`
+	Role    int    `json:"role"`
+	Region  string `json:"region,omitempty"`
+	Org     string `json:"org,omitempty"` //organization of the owner
+	Key     string `json:"key,omitempty"` //plain key, only available on creation
+	Hash    string `json:"-"`
+	Created string `json:"created"`
+	Expires string `json:"expires,omitempty"`
+	Revoked string `json:"revoked,omitempty"`
+}
+
+// CreateAPIKey generates a key for owner, role and region can not exceed the
+// ones of the owner. A zero ttl creates a key that never expires.
+func CreateAPIKey(provider UUIDProvider, owner User, name string, role int, region string, ttl time.Duration) (APIKey, error) {
+	if err := CheckKeyScope(owner, role, region); err != nil {
+		return APIKey{}, err
+	}
+
+	id, err := provider.ID()
+	if err != nil {
+		return APIKey{}, err
+	}
+
+	secret, err := randomSecret(32)
+	if err != nil {
+		return APIKey{}, err
+	}
+
+	now := time.Now()
+	key := APIKey{
+		ID:      id,
+		Name:    name,
+		Owner:   owner.ID,
+		Role:    role,
+		Region:  region,
+		Org:     owner.Org,
+		Key:     id + apiKeySeparator + secret,
+		Hash:    hashSecret(secret),
+		Created: now.Format(time.RFC3339),
+	}
+
+	if ttl > 0 {
+		key.Expires = now.Add(ttl).Format(time.RFC3339)
+	}
+
+	return key, nil
+}
+
+// CheckKeyScope fails with ErrInvalidKeyScope when a key of role, limited
+// to region when it is not empty, has rights owner does not have
+func CheckKeyScope(owner User, role int, region string) error {
+	if role < int(Admin) || role > int(OrgAdmin) {
+		return ErrInvalidKeyScope
+	}
+
+	group := UserGroup(owner.Group)
+	if !group.Includes(UserGroup(role)) {
+		return ErrInvalidKeyScope
+	}
+
+	if (group == RegionAdmin || group == RegionUser) && region != owner.Region {
+		return ErrInvalidKeyScope
+	}
+
+	return nil
+}
+
+// SplitAPIKey returns the id and the secret parts of a plain api key.
+func SplitAPIKey(key string) (id string, secret string, err error) {
+	parts := strings.SplitN(key, apiKeySeparator, 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", ErrInvalidAPIKey
+	}
+
+	return parts[0], parts[1], nil
+}
+
+// Valid returns an error if the key can not be used at time t.
+func (k APIKey) Valid(t time.Time) error {
+	if k.Revoked != "" {
+		return ErrAPIKeyRevoked
+	}
+
+	if k.Expires == "" {
+		return nil
+	}
+
+	expires, err := time.Parse(time.RFC3339, k.Expires)
+	if err != nil {
+		return errors.Wrap(ErrInvalidAPIKey, err)
+	}
+
+	if !t.Before(expires) {
+		return ErrAPIKeyExpired
+	}
+
+	return nil
+}
+
+// Allows reports whether the key is scoped to the region itself, or to no
+// region. Keys of a region also access the entities of its sub-regions, the
+// service checks those against the region tree.
+func (k APIKey) Allows(region string) bool {
+	return k.Region == "" || k.Region == region
+}
+
+// ReadOnly reports whether the key can only be used to fetch entities.
+func (k APIKey) ReadOnly() bool {
+	return k.Role == int(RegionUser)
+}
+
+// WithAPIKey returns a copy of ctx carrying the authenticated api key.
+func WithAPIKey(ctx context.Context, key APIKey) context.Context {
+	return context.WithValue(ctx, apiKeyCtxKey{}, key)
+}
+
+// APIKeyFromContext returns the api key the request was authenticated with.
+func APIKeyFromContext(ctx context.Context) (APIKey, bool) {
+	key, ok := ctx.Value(apiKeyCtxKey{}).(APIKey)
+	return key, ok
+}
+
+// checkRole fails when ctx carries an api key whose role does not include
+// the rights of group, requests without a key are not restricted.
+func checkRole(ctx context.Context, group UserGroup) error {
+	if key, ok := APIKeyFromContext(ctx); ok && !UserGroup(key.Role).Includes(group) {
+		return ErrPermissionDenied
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+// Package argon2id hashes passwords with Argon2id. Hashes are encoded in the
+// PHC string format, $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>, so that
+// they carry the parameters they were generated with.
+package argon2id
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"github.com/piusalfred/registry"
+	"github.com/piusalfred/registry/pkg/errors"
+	"golang.org/x/crypto/argon2"
+	"strings"
+)
+
+const prefix = "$argon2id$"
+
+var (
+	errHashPassword    = errors.New("Generate hash from password failed")
+	errComparePassword = errors.New("Compare hash and password failed")
+	errInvalidHash     = errors.New("invalid argon2id hash")
+	errMismatch        = errors.New("hash and password do not match")
+)
+
+// Params are the cost parameters of the hashes
+type Params struct {
+	// Memory is the memory used in KiB.
+	Memory uint32
+	// Iterations is the number of passes over the memory.
+	Iterations uint32
+	// Parallelism is the number of threads used.
+	Parallelism uint8
+	// SaltLength is the length of the random salt in bytes.
+	SaltLength uint32
+	// KeyLength is the length of the derived key in bytes.
+	KeyLength uint32
+}
+
+// DefaultParams follow the recommendation of RFC 9106 for memory constrained
+// environments.
+var DefaultParams = Params{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 2,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+var _ registry.Hasher = (*argon2idHasher)(nil)
+
+type argon2idHasher struct {
+	params   Params
+	fallback registry.Hasher
+}
+
+// New instantiates an Argon2id-based hasher generating hashes with params.
+// Hashes that are not Argon2id hashes are compared with fallback, if any, so
+// that users hashed with an older algorithm can still log in and have their
+// hash replaced.
+func New(params Params, fallback registry.Hasher) registry.Hasher {
+	if params.Memory == 0 || params.Iterations == 0 || params.Parallelism == 0 {
+		params = DefaultParams
+	}
+	if params.SaltLength == 0 {
+		params.SaltLength = DefaultParams.SaltLength
+	}
+	if params.KeyLength == 0 {
+		params.KeyLength = DefaultParams.KeyLength
+	}
+
+	return &argon2idHasher{params: params, fallback: fallback}
+}
+
+func (ah *argon2idHasher) Hash(pwd string) (string, error) {
+	salt := make([]byte, ah.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", errors.Wrap(errHashPassword, err)
+	}
+
+	p := ah.params
+	key := argon2.IDKey([]byte(pwd), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+
+	return encode(p, salt, key), nil
+}
+
+func (ah *argon2idHasher) Compare(plain, hashed string) error {
+	if !strings.HasPrefix(hashed, prefix) && ah.fallback != nil {
+		return ah.fallback.Compare(plain, hashed)
+	}
+
+	p, salt, key, err := decode(hashed)
+	if err != nil {
+		return errors.Wrap(errComparePassword, err)
+	}
+
+	other := argon2.IDKey([]byte(plain), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		return errors.Wrap(errComparePassword, errMismatch)
+	}
+
+	return nil
+}
+
+// NeedsRehash reports whether hashed is not an Argon2id hash or has weaker
+// parameters than the hasher.
+func (ah *argon2idHasher) NeedsRehash(hashed string) bool {
+	p, salt, key, err := decode(hashed)
+	if err != nil {
+		return true
+	}
+
+	return p.Memory < ah.params.Memory ||
+		p.Iterations < ah.params.Iterations ||
+		p.Parallelism < ah.params.Parallelism ||
+		uint32(len(salt)) < ah.params.SaltLength ||
+		uint32(len(key)) < ah.params.KeyLength
+}
+
+func encode(p Params, salt, key []byte) string {
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", prefix, argon2.Version,
+		p.Memory, p.Iterations, p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key))
+}
+
+func decode(hashed string) (p Params, salt, key []byte, err error) {
+	//"", "argon2id", "v=19", "m=..,t=..,p=..", salt, key
+	parts := strings.Split(hashed, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return p, nil, nil, errInvalidHash
+	}
+
+	var version int
+	if _, err = fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, errInvalidHash
+	}
+
+	if _, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Iterations, &p.Parallelism); err != nil {
+		return p, nil, nil, errInvalidHash
+	}
+
+	if salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil || len(salt) == 0 {
+		return p, nil, nil, errInvalidHash
+	}
+
+	if key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(key) == 0 {
+		return p, nil, nil, errInvalidHash
+	}
+
+	if p.Memory == 0 || p.Iterations == 0 || p.Parallelism == 0 {
+		return p, nil, nil, errInvalidHash
+	}
+
+	p.SaltLength = uint32(len(salt))
+	p.KeyLength = uint32(len(key))
+
+	return p, salt, key, nil
+}
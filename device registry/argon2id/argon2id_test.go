@@ -0,0 +1,42 @@
+package argon2id_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/piusalfred/registry/argon2id"
+	"github.com/piusalfred/registry/bcrypt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// weak keeps the tests fast, it is not meant to be used
+var weak = argon2id.Params{Memory: 1024, Iterations: 1, Parallelism: 1}
+
+func TestHasher(t *testing.T) {
+	legacy := bcrypt.NewWithCost(4)
+	hasher := argon2id.New(weak, legacy)
+
+	hash, err := hasher.Hash("s3cret pass")
+	require.Nil(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"), "unexpected hash %s", hash)
+	assert.False(t, hasher.NeedsRehash(hash), "fresh hash needs a rehash")
+
+	other, err := hasher.Hash("s3cret pass")
+	require.Nil(t, err)
+	assert.NotEqual(t, hash, other, "hashes of the same password share a salt")
+
+	assert.Nil(t, hasher.Compare("s3cret pass", hash))
+	assert.NotNil(t, hasher.Compare("wrong pass", hash))
+	assert.NotNil(t, hasher.Compare("s3cret pass", "$argon2id$v=19$m=0,t=1,p=1$AAAA$AAAA"))
+
+	stronger := argon2id.New(argon2id.Params{Memory: 2048, Iterations: 1, Parallelism: 1}, nil)
+	assert.True(t, stronger.NeedsRehash(hash), "hash with less memory does not need a rehash")
+	assert.Nil(t, stronger.Compare("s3cret pass", hash), "hash with other parameters no longer compares")
+
+	old, err := legacy.Hash("s3cret pass")
+	require.Nil(t, err)
+	assert.True(t, hasher.NeedsRehash(old), "bcrypt hash does not need a rehash")
+	assert.Nil(t, hasher.Compare("s3cret pass", old), "bcrypt hash not compared with the fallback")
+	assert.NotNil(t, stronger.Compare("s3cret pass", old), "bcrypt hash compared without a fallback")
+}
@@ -0,0 +1,290 @@
+// Package backup reads and writes registry archives, snapshots of the
+// registry database that can be restored into another database.
+//
+// An archive is a gzipped tarball with two entries, registry.json holding
+// the data and manifest.json describing it. The manifest records the
+// archive version and the sha256 checksum of registry.json which is
+// verified before anything is restored.
+//
+// Archives hold secrets, the password hashes of users, the hashes of api
+// keys and node keys and the firmware blobs, and must be stored as such.
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/piusalfred/registry"
+	"github.com/piusalfred/registry/pkg/errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+)
+
+// Version is the version of the archives written by this package, newer
+// archives are rejected. Version 1 archives only hold organizations, node
+// types, regions, users, nodes and events.
+const Version = 2
+
+const (
+	manifestFile = "manifest.json"
+	dataFile     = "registry.json"
+)
+
+var (
+	ErrInvalidArchive     = errors.NewKind(errors.Invalid, "invalid registry archive")
+	ErrUnsupportedVersion = errors.NewKind(errors.Invalid, "unsupported registry archive version")
+	ErrChecksumMismatch   = errors.NewKind(errors.Invalid, "registry archive checksum mismatch")
+	ErrInvalidPolicy      = errors.NewKind(errors.Invalid, "invalid conflict policy, use skip, overwrite or fail")
+)
+
+// Policy is what a restore does with rows already in the database
+type Policy string
+
+const (
+	// Skip keeps the rows in the database
+	Skip Policy = "skip"
+
+	// Overwrite replaces the rows in the database with those of the archive
+	Overwrite Policy = "overwrite"
+
+	// Fail aborts the restore, nothing is restored
+	Fail Policy = "fail"
+)
+
+func ParsePolicy(s string) (Policy, error) {
+	switch p := Policy(s); p {
+	case Skip, Overwrite, Fail:
+		return p, nil
+	default:
+		return "", ErrInvalidPolicy
+	}
+}
+
+// Data is the content of an archive, every table of the registry but the
+// idempotency keys, which are only kept for the replay window.
+type Data struct {
+	Organizations []registry.Organization `json:"organizations"`
+	NodeTypes     []registry.NodeType     `json:"node_types"`
+	Regions       []registry.Region       `json:"regions"`
+	Users         []registry.User         `json:"users"`
+	Nodes         []registry.Node         `json:"nodes"`
+	Events        []registry.Event        `json:"events"`
+	Firmware      []registry.Firmware     `json:"firmware"`
+	Campaigns     []registry.Campaign     `json:"campaigns"`
+	Rollouts      []registry.Rollout      `json:"rollouts"`
+	Schemas       []registry.Schema       `json:"schemas"`
+	Quotas        []registry.Quota        `json:"quotas"`
+	APIKeys       []APIKey                `json:"api_keys"`
+	Certificates  []registry.Certificate  `json:"certificates"`
+	ClaimCodes    []registry.ClaimCode    `json:"claim_codes"`
+	Claims        []registry.Claim        `json:"claims"`
+	Credentials   []Credentials           `json:"node_credentials"`
+}
+
+// APIKey is an api key with the hash of its secret, which the api never
+// encodes
+type APIKey struct {
+	registry.APIKey
+	Hash string `json:"hash"`
+}
+
+// Credentials is the hash of the key a node authenticates with
+type Credentials struct {
+	Node    string `json:"node"`
+	Hash    string `json:"hash"`
+	Created string `json:"created"`
+}
+
+// Manifest describes an archive
+type Manifest struct {
+	Version  int            `json:"version"`
+	Created  string         `json:"created"`
+	Checksum string         `json:"checksum"`
+	Counts   map[string]int `json:"counts"`
+}
+
+// Result is the outcome of restoring the rows of a table
+type Result struct {
+	Table    string `json:"table"`
+	Restored int    `json:"restored"`
+	Skipped  int    `json:"skipped"`
+}
+
+// Store is a registry database that can be backed up and restored
+type Store interface {
+	// Snapshot reads the whole registry in a single transaction
+	Snapshot(ctx context.Context) (Data, error)
+
+	// Restore writes data in a single transaction, rows that already
+	// exist are handled according to policy
+	Restore(ctx context.Context, data Data, policy Policy) ([]Result, error)
+}
+
+// Write writes data to w as an archive and returns its manifest
+func Write(w io.Writer, data Data) (Manifest, error) {
+	content, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	sum := sha256.Sum256(content)
+	manifest := Manifest{
+		Version:  Version,
+		Created:  time.Now().Format(time.RFC3339),
+		Checksum: hex.EncodeToString(sum[:]),
+		Counts: map[string]int{
+			"organizations":    len(data.Organizations),
+			"node_types":       len(data.NodeTypes),
+			"regions":          len(data.Regions),
+			"users":            len(data.Users),
+			"nodes":            len(data.Nodes),
+			"events":           len(data.Events),
+			"firmware":         len(data.Firmware),
+			"campaigns":        len(data.Campaigns),
+			"rollouts":         len(data.Rollouts),
+			"schemas":          len(data.Schemas),
+			"quotas":           len(data.Quotas),
+			"api_keys":         len(data.APIKeys),
+			"certificates":     len(data.Certificates),
+			"claim_codes":      len(data.ClaimCodes),
+			"claims":           len(data.Claims),
+			"node_credentials": len(data.Credentials),
+		},
+	}
+
+	m, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+
+	for _, f := range []struct {
+		name    string
+		content []byte
+	}{{manifestFile, m}, {dataFile, content}} {
+		hdr := &tar.Header{
+			Name:    f.name,
+			Mode:    0600,
+			Size:    int64(len(f.content)),
+			ModTime: time.Now(),
+		}
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			return Manifest{}, err
+		}
+
+		if _, err := tw.Write(f.content); err != nil {
+			return Manifest{}, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return Manifest{}, err
+	}
+
+	if err := gw.Close(); err != nil {
+		return Manifest{}, err
+	}
+
+	return manifest, nil
+}
+
+// Read reads an archive written by Write, the checksum of the data is
+// verified before it is decoded.
+func Read(r io.Reader) (Manifest, Data, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return Manifest{}, Data{}, errors.Wrap(ErrInvalidArchive, err)
+	}
+	defer gr.Close()
+
+	files := make(map[string][]byte)
+	tr := tar.NewReader(gr)
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return Manifest{}, Data{}, errors.Wrap(ErrInvalidArchive, err)
+		}
+
+		content, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return Manifest{}, Data{}, errors.Wrap(ErrInvalidArchive, err)
+		}
+
+		files[hdr.Name] = content
+	}
+
+	m, ok := files[manifestFile]
+	if !ok {
+		return Manifest{}, Data{}, errors.Wrap(ErrInvalidArchive, errors.New("missing "+manifestFile))
+	}
+
+	content, ok := files[dataFile]
+	if !ok {
+		return Manifest{}, Data{}, errors.Wrap(ErrInvalidArchive, errors.New("missing "+dataFile))
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(m, &manifest); err != nil {
+		return Manifest{}, Data{}, errors.Wrap(ErrInvalidArchive, err)
+	}
+
+	if manifest.Version < 1 || manifest.Version > Version {
+		return Manifest{}, Data{}, errors.Wrap(ErrUnsupportedVersion,
+			errors.New(fmt.Sprintf("archive version %d, supported up to %d", manifest.Version, Version)))
+	}
+
+	sum := sha256.Sum256(content)
+	if !strings.EqualFold(hex.EncodeToString(sum[:]), manifest.Checksum) {
+		return Manifest{}, Data{}, ErrChecksumMismatch
+	}
+
+	var data Data
+	dec := json.NewDecoder(bytes.NewReader(content))
+	if err := dec.Decode(&data); err != nil {
+		return Manifest{}, Data{}, errors.Wrap(ErrInvalidArchive, err)
+	}
+
+	return manifest, data, nil
+}
+
+// InsertQuery returns the statement restoring a row of table, the first
+// keys columns are its primary key. The statement uses $N placeholders.
+func InsertQuery(table string, columns []string, keys int, policy Policy) string {
+	params := make([]string, len(columns))
+	for i := range columns {
+		params[i] = fmt.Sprintf("$%d", i+1)
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+		table, strings.Join(columns, ", "), strings.Join(params, ","))
+
+	switch policy {
+	case Skip:
+		query += " ON CONFLICT DO NOTHING"
+
+	case Overwrite:
+		set := make([]string, 0, len(columns)-keys)
+		for _, c := range columns[keys:] {
+			set = append(set, fmt.Sprintf("%s = excluded.%s", c, c))
+		}
+		query += fmt.Sprintf(" ON CONFLICT (%s) DO UPDATE SET %s",
+			strings.Join(columns[:keys], ", "), strings.Join(set, ", "))
+	}
+
+	return query + ";"
+}
@@ -0,0 +1,129 @@
+package backup_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"github.com/piusalfred/registry"
+	"github.com/piusalfred/registry/backup"
+	"github.com/piusalfred/registry/pkg/errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var data = backup.Data{
+	Regions: []registry.Region{{ID: "AA001", Name: "CoICT", Desc: "CoICT Campus"}},
+	Users:   []registry.User{{ID: "u1", Name: "Pius", Email: "pius@igrid.io", Password: "$2a$10$hash", Group: 1, Region: "AA001"}},
+	Nodes:   []registry.Node{{UUID: "n1", Addr: "89-19-60-34-8B-C3", Type: 1, Region: "AA001"}},
+	Events:  []registry.Event{{UUID: "e1", Name: "create_node", Timestamp: 42}},
+}
+
+// archive writes the manifest and data files given as a registry archive
+func archive(t *testing.T, manifest backup.Manifest, content []byte) []byte {
+	m, err := json.Marshal(manifest)
+	assert.Nil(t, err)
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+
+	for name, b := range map[string][]byte{"manifest.json": m, "registry.json": content} {
+		assert.Nil(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(b))}))
+		_, err := tw.Write(b)
+		assert.Nil(t, err)
+	}
+
+	assert.Nil(t, tw.Close())
+	assert.Nil(t, gw.Close())
+
+	return buf.Bytes()
+}
+
+func TestReadWrite(t *testing.T) {
+	var buf bytes.Buffer
+
+	written, err := backup.Write(&buf, data)
+	assert.Nil(t, err)
+	assert.Equal(t, backup.Version, written.Version)
+	assert.Equal(t, 1, written.Counts["users"])
+
+	content, err := json.MarshalIndent(data, "", "  ")
+	assert.Nil(t, err)
+
+	tampered := bytes.Replace(content, []byte("CoICT Campus"), []byte("CoICT Kampus"), 1)
+	newer := written
+	newer.Version = backup.Version + 1
+
+	cases := []struct {
+		desc    string
+		archive []byte
+		err     error
+	}{
+		{
+			desc:    "read written archive",
+			archive: buf.Bytes(),
+			err:     nil,
+		},
+		{
+			desc:    "read archive with modified data",
+			archive: archive(t, written, tampered),
+			err:     backup.ErrChecksumMismatch,
+		},
+		{
+			desc:    "read archive of a newer version",
+			archive: archive(t, newer, content),
+			err:     backup.ErrUnsupportedVersion,
+		},
+		{
+			desc:    "read a file that is not an archive",
+			archive: content,
+			err:     backup.ErrInvalidArchive,
+		},
+	}
+
+	for _, tc := range cases {
+		manifest, got, err := backup.Read(bytes.NewReader(tc.archive))
+		if tc.err != nil {
+			assert.True(t, errors.Contains(err, tc.err), "%s: expected %v got %v", tc.desc, tc.err, err)
+			continue
+		}
+
+		assert.Nil(t, err, tc.desc)
+		assert.Equal(t, written, manifest, tc.desc)
+		assert.Equal(t, data, got, tc.desc)
+	}
+}
+
+func TestInsertQuery(t *testing.T) {
+	columns := []string{"id", "name", "description"}
+
+	cases := []struct {
+		policy backup.Policy
+		query  string
+	}{
+		{
+			policy: backup.Fail,
+			query:  "INSERT INTO regions (id, name, description) VALUES ($1,$2,$3);",
+		},
+		{
+			policy: backup.Skip,
+			query:  "INSERT INTO regions (id, name, description) VALUES ($1,$2,$3) ON CONFLICT DO NOTHING;",
+		},
+		{
+			policy: backup.Overwrite,
+			query: "INSERT INTO regions (id, name, description) VALUES ($1,$2,$3) " +
+				"ON CONFLICT (id) DO UPDATE SET name = excluded.name, description = excluded.description;",
+		},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.query, backup.InsertQuery("regions", columns, 1, tc.policy), string(tc.policy))
+	}
+
+	//tables with a composite primary key
+	query := "INSERT INTO rollouts (campaign, node, status) VALUES ($1,$2,$3) " +
+		"ON CONFLICT (campaign, node) DO UPDATE SET status = excluded.status;"
+	assert.Equal(t, query, backup.InsertQuery("rollouts", []string{"campaign", "node", "status"}, 2, backup.Overwrite))
+}
@@ -6,7 +6,8 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
-const cost int = 10
+// DefaultCost is the cost of the hashes generated by New
+const DefaultCost int = 10
 
 var (
 	errHashPassword    = errors.New("Generate hash from password failed")
@@ -15,15 +16,26 @@ var (
 
 var _ registry.Hasher = (*bcryptHasher)(nil)
 
-type bcryptHasher struct{}
+type bcryptHasher struct {
+	cost int
+}
 
 // New instantiates a bcrypt-based hasher implementation.
 func New() registry.Hasher {
-	return &bcryptHasher{}
+	return NewWithCost(DefaultCost)
+}
+
+// NewWithCost instantiates a bcrypt-based hasher generating hashes of cost,
+// hashes of a lower cost need to be rehashed.
+func NewWithCost(cost int) registry.Hasher {
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		cost = DefaultCost
+	}
+	return &bcryptHasher{cost: cost}
 }
 
 func (bh *bcryptHasher) Hash(pwd string) (string, error) {
-	hash, err := bcrypt.GenerateFromPassword([]byte(pwd), cost)
+	hash, err := bcrypt.GenerateFromPassword([]byte(pwd), bh.cost)
 	if err != nil {
 		return "", errors.Wrap(errHashPassword, err)
 	}
@@ -38,3 +50,8 @@ func (bh *bcryptHasher) Compare(plain, hashed string) error {
 	}
 	return nil
 }
+
+func (bh *bcryptHasher) NeedsRehash(hashed string) bool {
+	cost, err := bcrypt.Cost([]byte(hashed))
+	return err != nil || cost < bh.cost
+}
@@ -0,0 +1,255 @@
+package ca
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"github.com/piusalfred/registry"
+	"github.com/piusalfred/registry/pkg/errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"time"
+)
+
+// DefaultTTL is the validity of a CA generated by Generate
+const DefaultTTL = 10 * 365 * 24 * time.Hour
+
+// crlTTL is how long a generated CRL is valid before clients should fetch
+// a fresh one
+const crlTTL = 24 * time.Hour
+
+var (
+	errLoadingCA      = errors.New("could not load the certificate authority")
+	errGeneratingCA   = errors.New("could not generate the certificate authority")
+	errUnsupportedKey = errors.New("unsupported certificate authority private key")
+)
+
+var _ registry.CertificateAuthority = (*authority)(nil)
+
+type authority struct {
+	cert *x509.Certificate
+	key  crypto.Signer
+}
+
+// New instantiates a CertificateAuthority from the PEM encoded CA certificate
+// and private key.
+func New(certPEM, keyPEM []byte) (registry.CertificateAuthority, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, errors.Wrap(errLoadingCA, errors.New("no PEM certificate found"))
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(errLoadingCA, err)
+	}
+
+	block, _ = pem.Decode(keyPEM)
+	if block == nil {
+		return nil, errors.Wrap(errLoadingCA, errors.New("no PEM private key found"))
+	}
+
+	key, err := parseKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(errLoadingCA, err)
+	}
+
+	return &authority{
+		cert: cert,
+		key:  key,
+	}, nil
+}
+
+// Load reads the CA certificate and key from certFile and keyFile, if both
+// files do not exist a new self-signed CA is generated and written to them.
+func Load(certFile, keyFile string) (registry.CertificateAuthority, error) {
+	_, certErr := os.Stat(certFile)
+	_, keyErr := os.Stat(keyFile)
+
+	if os.IsNotExist(certErr) && os.IsNotExist(keyErr) {
+		certPEM, keyPEM, err := Generate("registry CA", DefaultTTL)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := ioutil.WriteFile(certFile, certPEM, 0644); err != nil {
+			return nil, errors.Wrap(errGeneratingCA, err)
+		}
+
+		if err := ioutil.WriteFile(keyFile, keyPEM, 0600); err != nil {
+			return nil, errors.Wrap(errGeneratingCA, err)
+		}
+
+		return New(certPEM, keyPEM)
+	}
+
+	certPEM, err := ioutil.ReadFile(certFile)
+	if err != nil {
+		return nil, errors.Wrap(errLoadingCA, err)
+	}
+
+	keyPEM, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return nil, errors.Wrap(errLoadingCA, err)
+	}
+
+	return New(certPEM, keyPEM)
+}
+
+// Generate creates a self-signed CA certificate and its private key, both
+// PEM encoded.
+func Generate(cn string, ttl time.Duration) (certPEM []byte, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, errors.Wrap(errGeneratingCA, err)
+	}
+
+	serial, err := newSerial()
+	if err != nil {
+		return nil, nil, errors.Wrap(errGeneratingCA, err)
+	}
+
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             now,
+		NotAfter:              now.Add(ttl),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	if err != nil {
+		return nil, nil, errors.Wrap(errGeneratingCA, err)
+	}
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, errors.Wrap(errGeneratingCA, err)
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+
+	return certPEM, keyPEM, nil
+}
+
+func (a *authority) Sign(csr []byte, cn string, ttl time.Duration) (registry.Certificate, error) {
+	block, _ := pem.Decode(csr)
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return registry.Certificate{}, registry.ErrInvalidCSR
+	}
+
+	req, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return registry.Certificate{}, errors.Wrap(registry.ErrInvalidCSR, err)
+	}
+
+	if err := req.CheckSignature(); err != nil {
+		return registry.Certificate{}, errors.Wrap(registry.ErrInvalidCSR, err)
+	}
+
+	serial, err := newSerial()
+	if err != nil {
+		return registry.Certificate{}, errors.Wrap(registry.ErrSigningCertificate, err)
+	}
+
+	now := time.Now()
+	expires := now.Add(ttl)
+	if expires.After(a.cert.NotAfter) {
+		expires = a.cert.NotAfter
+	}
+
+	//only the public key is taken from the request, the subject is always
+	//the node identity
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    now,
+		NotAfter:     expires,
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, a.cert, req.PublicKey, a.key)
+	if err != nil {
+		return registry.Certificate{}, errors.Wrap(registry.ErrSigningCertificate, err)
+	}
+
+	return registry.Certificate{
+		Serial:  hex.EncodeToString(serial.Bytes()),
+		Node:    cn,
+		PEM:     string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		Issued:  now.Format(time.RFC3339),
+		Expires: expires.Format(time.RFC3339),
+	}, nil
+}
+
+func (a *authority) CRL(revoked []registry.Certificate) ([]byte, error) {
+	var entries []pkix.RevokedCertificate
+
+	for _, cert := range revoked {
+		b, err := hex.DecodeString(cert.Serial)
+		if err != nil {
+			return nil, errors.Wrap(registry.ErrCreatingRevocations, err)
+		}
+
+		at, err := time.Parse(time.RFC3339, cert.Revoked)
+		if err != nil {
+			return nil, errors.Wrap(registry.ErrCreatingRevocations, err)
+		}
+
+		entries = append(entries, pkix.RevokedCertificate{
+			SerialNumber:   new(big.Int).SetBytes(b),
+			RevocationTime: at,
+		})
+	}
+
+	now := time.Now()
+	tmpl := &x509.RevocationList{
+		RevokedCertificates: entries,
+		Number:              big.NewInt(now.Unix()),
+		ThisUpdate:          now,
+		NextUpdate:          now.Add(crlTTL),
+	}
+
+	der, err := x509.CreateRevocationList(rand.Reader, tmpl, a.cert, a.key)
+	if err != nil {
+		return nil, errors.Wrap(registry.ErrCreatingRevocations, err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der}), nil
+}
+
+func parseKey(der []byte) (crypto.Signer, error) {
+	if key, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		if signer, ok := key.(crypto.Signer); ok {
+			return signer, nil
+		}
+		return nil, errUnsupportedKey
+	}
+
+	if key, err := x509.ParseECPrivateKey(der); err == nil {
+		return key, nil
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+
+	return nil, errUnsupportedKey
+}
+
+// newSerial returns a random 128 bit serial number
+func newSerial() (*big.Int, error) {
+	limit := new(big.Int).Lsh(big.NewInt(1), 128)
+	return rand.Int(rand.Reader, limit)
+}
@@ -0,0 +1,183 @@
+package ca_test
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"github.com/piusalfred/registry"
+	"github.com/piusalfred/registry/ca"
+	"github.com/piusalfred/registry/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const node = "f3f204c7-962b-440f-bd7b-5ed7d83eb874"
+
+func newAuthority(t *testing.T) (registry.CertificateAuthority, *x509.Certificate) {
+	certPEM, keyPEM, err := ca.Generate("test CA", time.Hour)
+	require.Nil(t, err)
+
+	authority, err := ca.New(certPEM, keyPEM)
+	require.Nil(t, err)
+
+	block, _ := pem.Decode(certPEM)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	require.Nil(t, err)
+
+	return authority, cert
+}
+
+// newCSR returns a PEM encoded CSR of a new key with the common name cn
+func newCSR(t *testing.T, cn string) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{Subject: pkix.Name{CommonName: cn}}, key)
+	require.Nil(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})
+}
+
+func parseCertificate(t *testing.T, c registry.Certificate) *x509.Certificate {
+	block, _ := pem.Decode([]byte(c.PEM))
+	require.NotNil(t, block)
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	require.Nil(t, err)
+
+	return cert
+}
+
+func TestSign(t *testing.T) {
+	authority, caCert := newAuthority(t)
+
+	c, err := authority.Sign(newCSR(t, "another-node"), node, 30*time.Minute)
+	require.Nil(t, err)
+	assert.Equal(t, node, c.Node)
+
+	cert := parseCertificate(t, c)
+	assert.Equal(t, node, cert.Subject.CommonName, "common name of the csr kept")
+	assert.Equal(t, c.Serial, hex.EncodeToString(cert.SerialNumber.Bytes()))
+	assert.Equal(t, []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}, cert.ExtKeyUsage)
+	assert.Nil(t, cert.CheckSignatureFrom(caCert))
+
+	//certificates do not outlive their CA
+	c, err = authority.Sign(newCSR(t, node), node, 24*time.Hour)
+	require.Nil(t, err)
+	assert.False(t, parseCertificate(t, c).NotAfter.After(caCert.NotAfter), "certificate outlives its CA")
+}
+
+func TestSignInvalidCSR(t *testing.T) {
+	authority, _ := newAuthority(t)
+
+	block, _ := pem.Decode(newCSR(t, "device"))
+
+	//a request whose subject changed after it was signed
+	tampered := bytes.Replace(block.Bytes, []byte("device"), []byte("server"), 1)
+
+	cases := []struct {
+		desc string
+		csr  []byte
+	}{
+		{desc: "not PEM", csr: []byte("csr")},
+		{desc: "certificate", csr: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: block.Bytes})},
+		{desc: "malformed", csr: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: []byte("csr")})},
+		{desc: "bad signature", csr: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: tampered})},
+	}
+
+	for _, tc := range cases {
+		_, err := authority.Sign(tc.csr, node, time.Minute)
+		assert.True(t, errors.Contains(err, registry.ErrInvalidCSR), "%s: %v", tc.desc, err)
+	}
+}
+
+func TestSerials(t *testing.T) {
+	authority, _ := newAuthority(t)
+
+	serials := map[string]bool{}
+	for i := 0; i < 50; i++ {
+		c, err := authority.Sign(newCSR(t, node), node, time.Minute)
+		require.Nil(t, err)
+
+		assert.False(t, serials[c.Serial], "serial %s issued twice", c.Serial)
+		serials[c.Serial] = true
+	}
+}
+
+func TestCRL(t *testing.T) {
+	authority, caCert := newAuthority(t)
+
+	var revoked []registry.Certificate
+	for i := 0; i < 3; i++ {
+		c, err := authority.Sign(newCSR(t, node), node, time.Minute)
+		require.Nil(t, err)
+
+		c.Revoked = time.Now().Add(-time.Duration(i) * time.Minute).Format(time.RFC3339)
+		revoked = append(revoked, c)
+	}
+
+	crlPEM, err := authority.CRL(revoked)
+	require.Nil(t, err)
+
+	block, _ := pem.Decode(crlPEM)
+	require.NotNil(t, block)
+	assert.Equal(t, "X509 CRL", block.Type)
+
+	crl, err := x509.ParseDERCRL(block.Bytes)
+	require.Nil(t, err)
+	assert.Nil(t, caCert.CheckCRLSignature(crl))
+	assert.True(t, crl.TBSCertList.NextUpdate.After(time.Now()))
+
+	entries := crl.TBSCertList.RevokedCertificates
+	require.Len(t, entries, len(revoked))
+	for i, entry := range entries {
+		assert.Equal(t, revoked[i].Serial, hex.EncodeToString(entry.SerialNumber.Bytes()))
+		assert.Equal(t, revoked[i].Revoked, entry.RevocationTime.Local().Format(time.RFC3339))
+	}
+
+	_, err = authority.CRL([]registry.Certificate{{Serial: "serial", Revoked: time.Now().Format(time.RFC3339)}})
+	assert.True(t, errors.Contains(err, registry.ErrCreatingRevocations), "invalid serial accepted: %v", err)
+}
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ca")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	certFile, keyFile := filepath.Join(dir, "ca.crt"), filepath.Join(dir, "ca.key")
+
+	//a CA is generated the first time and loaded afterwards
+	authority, err := ca.Load(certFile, keyFile)
+	require.Nil(t, err)
+
+	c, err := authority.Sign(newCSR(t, node), node, time.Minute)
+	require.Nil(t, err)
+
+	certPEM, err := ioutil.ReadFile(certFile)
+	require.Nil(t, err)
+	block, _ := pem.Decode(certPEM)
+	caCert, err := x509.ParseCertificate(block.Bytes)
+	require.Nil(t, err)
+
+	loaded, err := ca.Load(certFile, keyFile)
+	require.Nil(t, err)
+
+	c2, err := loaded.Sign(newCSR(t, node), node, time.Minute)
+	require.Nil(t, err)
+	assert.Nil(t, parseCertificate(t, c).CheckSignatureFrom(caCert))
+	assert.Nil(t, parseCertificate(t, c2).CheckSignatureFrom(caCert))
+
+	require.Nil(t, os.Remove(keyFile))
+	_, err = ca.Load(certFile, keyFile)
+	assert.NotNil(t, err, "CA loaded without its key")
+}
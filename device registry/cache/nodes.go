@@ -0,0 +1,283 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"github.com/piusalfred/registry"
+	"github.com/piusalfred/registry/pkg/errors"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultSize        = 10000
+	DefaultTTL         = 30 * time.Second
+	DefaultNegativeTTL = 5 * time.Second
+)
+
+// Config specifies the behaviour of the nodes cache
+type Config struct {
+	// Size is the maximum number of cached lookups, the least recently
+	// used ones are evicted first
+	Size int
+
+	// TTL is how long a node is served from the cache
+	TTL time.Duration
+
+	// NegativeTTL is how long a lookup of a missing node is remembered,
+	// zero disables negative caching
+	NegativeTTL time.Duration
+
+	// NotFound is the error the repository returns for missing nodes
+	NotFound error
+}
+
+// Stats are the hit and miss counters of the cache
+type Stats struct {
+	Hits         uint64 `json:"hits"`
+	NegativeHits uint64 `json:"negative_hits"`
+	Misses       uint64 `json:"misses"`
+	Evictions    uint64 `json:"evictions"`
+	Size         int    `json:"size"`
+}
+
+// NodeRepository is a registry.NodeRepository that caches node lookups
+type NodeRepository interface {
+	registry.NodeRepository
+
+	// Stats returns the cache counters since it was created
+	Stats() Stats
+
+	// Invalidate drops the cached lookups of the nodes known by ids, it is
+	// called after nodes are written without going through the cache
+	Invalidate(ids ...string)
+
+	// Flush drops every cached lookup, e.g after a restore of the database
+	Flush()
+}
+
+var _ NodeRepository = (*nodesCache)(nil)
+
+type entry struct {
+	key     string
+	id      string
+	node    registry.Node
+	err     error
+	expires time.Time
+}
+
+type nodesCache struct {
+	repo  registry.NodeRepository
+	cfg   Config
+	mu    sync.Mutex
+	ll    *list.List
+	items map[string]*list.Element
+	stats Stats
+
+	//gen is incremented by every invalidation, a lookup that started before
+	//one may have read the node before it was written and is not cached
+	gen uint64
+}
+
+// NewNodeRepository wraps repo with a read-through LRU cache of Get calls.
+// Cached lookups are dropped when the node is added, updated or deleted
+// through the returned repository, nodes written by other processes are
+// served from the cache until their lookups expire or the cache is flushed.
+func NewNodeRepository(repo registry.NodeRepository, cfg Config) NodeRepository {
+	if cfg.Size <= 0 {
+		cfg.Size = DefaultSize
+	}
+
+	if cfg.TTL <= 0 {
+		cfg.TTL = DefaultTTL
+	}
+
+	return &nodesCache{
+		repo:  repo,
+		cfg:   cfg,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+func (c *nodesCache) Get(ctx context.Context, id string) (registry.Node, error) {
+	//lookups are scoped to the organization, so is the cache
+	key := registry.OrgFromContext(ctx) + "/" + id
+
+	e, gen, ok := c.lookup(key)
+	if ok {
+		return e.node, e.err
+	}
+
+	node, err := c.repo.Get(ctx, id)
+
+	switch {
+	case err == nil:
+		c.store(&entry{key: key, id: id, node: node, expires: time.Now().Add(c.cfg.TTL)}, gen)
+
+	case c.cfg.NegativeTTL > 0 && c.cfg.NotFound != nil && errors.Contains(err, c.cfg.NotFound):
+		c.store(&entry{key: key, id: id, err: err, expires: time.Now().Add(c.cfg.NegativeTTL)}, gen)
+	}
+
+	return node, err
+}
+
+func (c *nodesCache) Add(ctx context.Context, node registry.Node) error {
+	err := c.repo.Add(ctx, node)
+
+	//a previous lookup may have cached the node as missing
+	c.Invalidate(node.UUID, node.Addr)
+
+	return err
+}
+
+func (c *nodesCache) Delete(ctx context.Context, id string) error {
+	err := c.repo.Delete(ctx, id)
+	c.Invalidate(id)
+
+	return err
+}
+
+func (c *nodesCache) List(ctx context.Context) ([]registry.Node, error) {
+	return c.repo.List(ctx)
+}
+
+func (c *nodesCache) Slaves(ctx context.Context, id string) ([]registry.Node, error) {
+	return c.repo.Slaves(ctx, id)
+}
+
+func (c *nodesCache) Update(ctx context.Context, id string, node registry.Node) (registry.Node, error) {
+	n, err := c.repo.Update(ctx, id, node)
+	c.Invalidate(id, n.UUID, n.Addr)
+
+	return n, err
+}
+
+func (c *nodesCache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Size = c.ll.Len()
+
+	return stats
+}
+
+func (c *nodesCache) Flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gen++
+	c.ll.Init()
+	c.items = make(map[string]*list.Element)
+}
+
+// lookup returns the cached entry of key, or the generation a miss has to
+// be stored with
+func (c *nodesCache) lookup(key string) (*entry, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		c.stats.Misses++
+		return nil, c.gen, false
+	}
+
+	e := el.Value.(*entry)
+	if time.Now().After(e.expires) {
+		c.remove(el)
+		c.stats.Misses++
+		return nil, c.gen, false
+	}
+
+	c.ll.MoveToFront(el)
+	if e.err != nil {
+		c.stats.NegativeHits++
+	} else {
+		c.stats.Hits++
+	}
+
+	return e, c.gen, true
+}
+
+// store caches e unless the cache has been invalidated since generation gen
+func (c *nodesCache) store(e *entry, gen uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if gen != c.gen {
+		return
+	}
+
+	if el, ok := c.items[e.key]; ok {
+		el.Value = e
+		c.ll.MoveToFront(el)
+		return
+	}
+
+	c.items[e.key] = c.ll.PushFront(e)
+
+	for c.ll.Len() > c.cfg.Size {
+		c.remove(c.ll.Back())
+		c.stats.Evictions++
+	}
+}
+
+// Invalidate drops every cached lookup of the node known by any of ids, a
+// node can be looked up by its uuid or its address in any organization so
+// all entries are checked. Writes are rare compared to lookups.
+func (c *nodesCache) Invalidate(ids ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gen++
+
+	matches := func(s string) bool {
+		if s == "" {
+			return false
+		}
+		for _, id := range ids {
+			if id == s {
+				return true
+			}
+		}
+		return false
+	}
+
+	for el := c.ll.Front(); el != nil; {
+		next := el.Next()
+		e := el.Value.(*entry)
+		if matches(e.id) || matches(e.node.UUID) || matches(e.node.Addr) {
+			c.remove(el)
+		}
+		el = next
+	}
+}
+
+func (c *nodesCache) remove(el *list.Element) {
+	c.ll.Remove(el)
+	delete(c.items, el.Value.(*entry).key)
+}
+
+type claimsCache struct {
+	registry.ClaimRepository
+	nodes NodeRepository
+}
+
+// NewClaimRepository wraps repo so that the nodes it adds when devices are
+// provisioned are dropped from the nodes cache, a lookup of the device
+// address may have cached it as missing.
+func NewClaimRepository(repo registry.ClaimRepository, nodes NodeRepository) registry.ClaimRepository {
+	return &claimsCache{
+		ClaimRepository: repo,
+		nodes:           nodes,
+	}
+}
+
+func (c *claimsCache) Provision(ctx context.Context, code string, node registry.Node, keyHash string) error {
+	err := c.ClaimRepository.Provision(ctx, code, node, keyHash)
+	c.nodes.Invalidate(node.UUID, node.Addr)
+
+	return err
+}
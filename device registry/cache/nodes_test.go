@@ -0,0 +1,268 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/piusalfred/registry"
+	"github.com/piusalfred/registry/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errNotFound = errors.NewKind(errors.NotFound, "node not found")
+
+// memNodes is an in memory registry.NodeRepository that counts lookups, a
+// lookup blocks on gate when it is set
+type memNodes struct {
+	mu    sync.Mutex
+	nodes map[string]registry.Node
+	gets  int
+	gate  chan struct{}
+}
+
+func newMemNodes(nodes ...registry.Node) *memNodes {
+	m := &memNodes{nodes: map[string]registry.Node{}}
+	for _, n := range nodes {
+		m.nodes[n.UUID] = n
+	}
+
+	return m
+}
+
+func (m *memNodes) Get(_ context.Context, id string) (registry.Node, error) {
+	m.mu.Lock()
+	m.gets++
+	node, ok := m.nodes[id]
+	gate := m.gate
+	m.mu.Unlock()
+
+	if gate != nil {
+		<-gate
+	}
+
+	if !ok {
+		return registry.Node{}, errNotFound
+	}
+
+	return node, nil
+}
+
+func (m *memNodes) Add(_ context.Context, node registry.Node) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nodes[node.UUID] = node
+
+	return nil
+}
+
+func (m *memNodes) Delete(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.nodes, id)
+
+	return nil
+}
+
+func (m *memNodes) List(_ context.Context) ([]registry.Node, error) {
+	return nil, nil
+}
+
+func (m *memNodes) Slaves(_ context.Context, _ string) ([]registry.Node, error) {
+	return nil, nil
+}
+
+func (m *memNodes) Update(_ context.Context, id string, node registry.Node) (registry.Node, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	node.UUID = id
+	m.nodes[id] = node
+
+	return node, nil
+}
+
+func (m *memNodes) lookups() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.gets
+}
+
+func TestGet(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemNodes(registry.Node{UUID: "meter", Name: "meter"})
+	c := NewNodeRepository(repo, Config{TTL: time.Minute, NegativeTTL: time.Minute, NotFound: errNotFound})
+
+	for i := 0; i < 3; i++ {
+		node, err := c.Get(ctx, "meter")
+		require.Nil(t, err)
+		assert.Equal(t, "meter", node.Name)
+	}
+	assert.Equal(t, 1, repo.lookups(), "cached node looked up again")
+
+	//lookups are scoped to the organization of the request
+	_, err := c.Get(registry.WithAPIKey(ctx, registry.APIKey{Org: "org"}), "meter")
+	require.Nil(t, err)
+	assert.Equal(t, 2, repo.lookups(), "node cached for another organization")
+
+	assert.Equal(t, Stats{Hits: 2, Misses: 2, Size: 2}, c.Stats())
+}
+
+func TestNegativeCache(t *testing.T) {
+	ctx := context.Background()
+
+	repo := newMemNodes()
+	c := NewNodeRepository(repo, Config{TTL: time.Minute, NegativeTTL: time.Minute, NotFound: errNotFound})
+
+	for i := 0; i < 2; i++ {
+		_, err := c.Get(ctx, "meter")
+		assert.True(t, errors.Contains(err, errNotFound))
+	}
+	assert.Equal(t, 1, repo.lookups(), "missing node looked up again")
+	assert.Equal(t, uint64(1), c.Stats().NegativeHits)
+
+	require.Nil(t, c.Add(ctx, registry.Node{UUID: "meter"}))
+	_, err := c.Get(ctx, "meter")
+	assert.Nil(t, err, "added node still cached as missing")
+
+	//without a negative ttl missing nodes are always looked up
+	repo = newMemNodes()
+	c = NewNodeRepository(repo, Config{TTL: time.Minute, NotFound: errNotFound})
+	for i := 0; i < 2; i++ {
+		_, _ = c.Get(ctx, "meter")
+	}
+	assert.Equal(t, 2, repo.lookups(), "missing node cached without a negative ttl")
+}
+
+func TestTTL(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemNodes(registry.Node{UUID: "meter"})
+	c := NewNodeRepository(repo, Config{TTL: 20 * time.Millisecond})
+
+	_, err := c.Get(ctx, "meter")
+	require.Nil(t, err)
+	_, err = c.Get(ctx, "meter")
+	require.Nil(t, err)
+	assert.Equal(t, 1, repo.lookups())
+
+	time.Sleep(30 * time.Millisecond)
+
+	_, err = c.Get(ctx, "meter")
+	require.Nil(t, err)
+	assert.Equal(t, 2, repo.lookups(), "expired node served from the cache")
+}
+
+func TestEviction(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemNodes(registry.Node{UUID: "a"}, registry.Node{UUID: "b"}, registry.Node{UUID: "c"})
+	c := NewNodeRepository(repo, Config{Size: 2, TTL: time.Minute})
+
+	for _, id := range []string{"a", "b", "a", "c"} {
+		_, err := c.Get(ctx, id)
+		require.Nil(t, err)
+	}
+
+	//b is the least recently used node when c is added
+	assert.Equal(t, Stats{Hits: 1, Misses: 3, Evictions: 1, Size: 2}, c.Stats())
+
+	gets := repo.lookups()
+	_, _ = c.Get(ctx, "a")
+	_, _ = c.Get(ctx, "c")
+	assert.Equal(t, gets, repo.lookups(), "recently used node evicted")
+
+	_, _ = c.Get(ctx, "b")
+	assert.Equal(t, gets+1, repo.lookups(), "evicted node served from the cache")
+}
+
+func TestInvalidate(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemNodes(registry.Node{UUID: "meter", Addr: "6F-5E-42-8B-36-B9", Name: "meter"})
+	c := NewNodeRepository(repo, Config{TTL: time.Minute, NegativeTTL: time.Minute, NotFound: errNotFound})
+
+	_, err := c.Get(ctx, "meter")
+	require.Nil(t, err)
+
+	_, err = c.Update(ctx, "meter", registry.Node{Name: "renamed"})
+	require.Nil(t, err)
+
+	node, err := c.Get(ctx, "meter")
+	require.Nil(t, err)
+	assert.Equal(t, "renamed", node.Name, "updated node served from the cache")
+
+	require.Nil(t, c.Delete(ctx, "meter"))
+	_, err = c.Get(ctx, "meter")
+	assert.True(t, errors.Contains(err, errNotFound), "deleted node served from the cache")
+
+	//nodes written behind the cache
+	_ = repo.Add(ctx, registry.Node{UUID: "meter"})
+	c.Invalidate("meter")
+	_, err = c.Get(ctx, "meter")
+	assert.Nil(t, err, "invalidated node still cached as missing")
+
+	_ = repo.Delete(ctx, "meter")
+	c.Flush()
+	_, err = c.Get(ctx, "meter")
+	assert.True(t, errors.Contains(err, errNotFound), "node served from a flushed cache")
+}
+
+// TestStaleFill checks that a lookup that read a node before it was updated
+// does not cache it after the update invalidated its entry
+func TestStaleFill(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemNodes(registry.Node{UUID: "meter", Name: "meter"})
+	c := NewNodeRepository(repo, Config{TTL: time.Minute})
+
+	gate := make(chan struct{})
+	repo.gate = gate
+
+	done := make(chan registry.Node)
+	go func() {
+		node, _ := c.Get(ctx, "meter")
+		done <- node
+	}()
+
+	//wait for the lookup to read the node before it is updated
+	for repo.lookups() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	_, err := c.Update(ctx, "meter", registry.Node{Name: "renamed"})
+	require.Nil(t, err)
+
+	repo.mu.Lock()
+	repo.gate = nil
+	repo.mu.Unlock()
+	close(gate)
+
+	assert.Equal(t, "meter", (<-done).Name)
+
+	node, err := c.Get(ctx, "meter")
+	require.Nil(t, err)
+	assert.Equal(t, "renamed", node.Name, "node read before the update cached")
+}
+
+type memClaims struct {
+	registry.ClaimRepository
+	nodes *memNodes
+}
+
+func (m memClaims) Provision(ctx context.Context, _ string, node registry.Node, _ string) error {
+	return m.nodes.Add(ctx, node)
+}
+
+func TestProvision(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemNodes()
+	c := NewNodeRepository(repo, Config{TTL: time.Minute, NegativeTTL: time.Minute, NotFound: errNotFound})
+	claims := NewClaimRepository(memClaims{nodes: repo}, c)
+
+	_, err := c.Get(ctx, "6F-5E-42-8B-36-B9")
+	require.True(t, errors.Contains(err, errNotFound))
+
+	require.Nil(t, claims.Provision(ctx, "code", registry.Node{UUID: "6F-5E-42-8B-36-B9"}, "hash"))
+
+	_, err = c.Get(ctx, "6F-5E-42-8B-36-B9")
+	assert.Nil(t, err, "provisioned node still cached as missing")
+}
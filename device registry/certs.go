@@ -0,0 +1,41 @@
+package registry
+
+import (
+	"github.com/piusalfred/registry/pkg/errors"
+	"time"
+)
+
+var (
+	ErrInvalidCSR          = errors.NewKind(errors.Invalid, "invalid certificate signing request")
+	ErrSigningCertificate  = errors.New("could not sign certificate")
+	ErrCertificateRevoked  = errors.NewKind(errors.Conflict, "certificate has already been revoked")
+	ErrCreatingRevocations = errors.New("could not create certificate revocation list")
+)
+
+// DefaultCertificateTTL is the validity of node certificates when no ttl
+// is specified.
+const DefaultCertificateTTL = 365 * 24 * time.Hour
+
+// Certificate is an x509 certificate issued by the registry CA to a node,
+// the subject common name of the certificate is the node uuid.
+type Certificate struct {
+	Serial  string `json:"serial"`
+	Node    string `json:"node"`
+	PEM     string `json:"certificate,omitempty"`
+	Issued  string `json:"issued"`
+	Expires string `json:"expires"`
+	Revoked string `json:"revoked,omitempty"`
+	Org     string `json:"org,omitempty"`
+}
+
+// CertificateAuthority specifies an API for issuing and revoking node
+// identities.
+type CertificateAuthority interface {
+	// Sign issues a certificate for the PEM encoded csr, the subject common
+	// name of the certificate is always cn.
+	Sign(csr []byte, cn string, ttl time.Duration) (Certificate, error)
+
+	// CRL returns the PEM encoded certificate revocation list of the
+	// revoked certificates.
+	CRL(revoked []Certificate) ([]byte, error)
+}
@@ -0,0 +1,145 @@
+package registry
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/hex"
+	"github.com/piusalfred/registry/pkg/errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrClaimCodeExpired = errors.NewKind(errors.Conflict, "claim code has expired")
+	ErrClaimCodeUsed    = errors.NewKind(errors.Conflict, "claim code has already been used")
+	ErrInvalidClaimCode = errors.NewKind(errors.Invalid, "invalid claim code")
+	ErrInvalidClaimTTL  = errors.NewKind(errors.Invalid, "claim code ttl must be positive")
+	ErrGeneratingSecret = errors.New("error generating random secret")
+	ErrInvalidNodeKey   = errors.NewKind(errors.Unauthenticated, "invalid node credentials")
+)
+
+// DefaultClaimCodeTTL is how long a claim code is valid when no ttl is given.
+const DefaultClaimCodeTTL = 24 * time.Hour
+
+const claimCodeGroupLength = 4
+
+var claimCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+type ClaimResult string
+
+const (
+	ClaimAccepted ClaimResult = "accepted"
+	ClaimRejected ClaimResult = "rejected"
+)
+
+// ClaimCode is a single-use code generated by an admin that lets a device
+// register itself in a certain region as a node of a certain type.
+type ClaimCode struct {
+	Code    string `json:"code"`
+	Region  string `json:"region"`
+	Type    int    `json:"type"`
+	Created string `json:"created"`
+	Expires string `json:"expires"`
+	Node    string `json:"node,omitempty"` //the node that redeemed this code
+}
+
+// Claim is the audit record of a device attempt to redeem a claim code.
+type Claim struct {
+	ID      string      `json:"id"`
+	Code    string      `json:"code"`
+	Addr    string      `json:"addr"`
+	Node    string      `json:"node,omitempty"`
+	Result  ClaimResult `json:"result"`
+	Err     string      `json:"err,omitempty"`
+	Created string      `json:"created"`
+}
+
+// Credentials are issued to a device once it has been provisioned, the key
+// is shown only once and only its hash is kept by the registry.
+type Credentials struct {
+	NodeID string `json:"node_id"`
+	Key    string `json:"key"`
+}
+
+func CreateClaimCode(region string, typ int, ttl time.Duration) (ClaimCode, error) {
+	if ttl <= 0 {
+		return ClaimCode{}, ErrInvalidClaimTTL
+	}
+
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return ClaimCode{}, errors.Wrap(ErrGeneratingSecret, err)
+	}
+
+	now := time.Now()
+
+	return ClaimCode{
+		Code:    formatClaimCode(claimCodeEncoding.EncodeToString(b)),
+		Region:  region,
+		Type:    typ,
+		Created: now.Format(time.RFC3339),
+		Expires: now.Add(ttl).Format(time.RFC3339),
+	}, nil
+}
+
+// Usable returns an error if the claim code can not be redeemed at time t.
+func (c ClaimCode) Usable(t time.Time) error {
+	if c.Node != "" {
+		return ErrClaimCodeUsed
+	}
+
+	expires, err := time.Parse(time.RFC3339, c.Expires)
+	if err != nil {
+		return errors.Wrap(ErrInvalidClaimCode, err)
+	}
+
+	if !t.Before(expires) {
+		return ErrClaimCodeExpired
+	}
+
+	return nil
+}
+
+// NormalizeClaimCode makes codes typed by humans comparable to the generated
+// ones, case and separators do not matter.
+func NormalizeClaimCode(code string) string {
+	code = strings.ToUpper(code)
+	code = strings.NewReplacer("-", "", " ", "").Replace(code)
+	return formatClaimCode(code)
+}
+
+func formatClaimCode(code string) string {
+	var groups []string
+	for len(code) > claimCodeGroupLength {
+		groups = append(groups, code[:claimCodeGroupLength])
+		code = code[claimCodeGroupLength:]
+	}
+	groups = append(groups, code)
+
+	return strings.Join(groups, "-")
+}
+
+// randomSecret returns a hex encoded random secret of n bytes.
+func randomSecret(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(ErrGeneratingSecret, err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// hashSecret returns the hex encoded SHA-256 digest of a secret generated by
+// randomSecret. The secrets are random so unlike passwords they do not need
+// a slow hash.
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// secretMatches reports whether secret is the one hashed by hashSecret.
+func secretMatches(hash string, secret string) bool {
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(hashSecret(secret))) == 1
+}
@@ -17,6 +17,7 @@ package cmd
 
 import (
 	"context"
+	"github.com/piusalfred/registry"
 	"github.com/spf13/cobra"
 )
 
@@ -33,17 +34,22 @@ func NewAddCmd(cli CLI) *cobra.Command {
 	usersCmd.Flags().StringP("email", "e", "", "email address")
 	usersCmd.Flags().StringP("password", "p", "", "passphrase")
 	usersCmd.Flags().StringP("region", "r", "", "region-id")
+	usersCmd.Flags().String("org", "", "organization id")
 
 	regionsCmd := &cobra.Command{
 		Use:   "regions",
-		Short: "regions --id <id> --name <name> --desc <description>",
-		Long:  `add new region to the system`,
+		Short: "regions --id <id> --name <name> --desc <description> [--parent <region-id>] [--boundary <geojson-file>]",
+		Long: `add new region to the system, the boundary is a GeoJSON Polygon or
+MultiPolygon, or a Feature with such a geometry`,
 		Run:   cli.RegionsCmd(context.Background(), Add),
 	}
 
 	regionsCmd.Flags().StringP("id", "i", "", "region id")
 	regionsCmd.Flags().StringP("name", "n", "", "region name")
 	regionsCmd.Flags().StringP("desc", "d", "", "region description")
+	regionsCmd.Flags().String("org", "", "organization owning the region")
+	regionsCmd.Flags().String("parent", "", "region this region is part of")
+	regionsCmd.Flags().String("boundary", "", "GeoJSON file of the area the nodes of the region are located in")
 
 	nodesCmd := &cobra.Command{
 		Use:     "nodes",
@@ -62,17 +68,99 @@ func NewAddCmd(cli CLI) *cobra.Command {
 	nodesCmd.Flags().StringP("long", "g", "", "longitude")
 	nodesCmd.Flags().StringP("master", "m", "", "master node")
 	nodesCmd.Flags().IntP("type", "t", 0, "the type of the node")
+	nodesCmd.Flags().String("firmware", "", "firmware version the node runs")
+	nodesCmd.Flags().StringToString("labels", nil, "labels of the node, e.g. site=substation-4,feeder=f2")
+
+	typesCmd := &cobra.Command{
+		Use:     "types",
+		Short:   "types --id <id> --name <name> --desc <description> --caps <publish,subscribe,command>",
+		Long:    `add new node type to the catalogue of node types`,
+		Example: "regctl add types --id 4 --name meter --desc \"smart meter\" --caps publish",
+		Run:     cli.TypesCmd(context.Background(), Add),
+	}
+
+	typesCmd.Flags().IntP("id", "i", 0, "node type id")
+	typesCmd.Flags().StringP("name", "n", "", "node type name")
+	typesCmd.Flags().StringP("desc", "d", "", "node type description")
+	typesCmd.Flags().StringSliceP("caps", "c", nil, "capabilities (publish, subscribe, command)")
+
+	claimsCmd := &cobra.Command{
+		Use:     "claims",
+		Short:   "claims --region <region-id> --type <type-id> --ttl <duration>",
+		Long:    `generate a single-use claim code a device can use to provision itself`,
+		Example: "regctl add claims --region AA001 --type 1 --ttl 48h",
+		Run:     cli.ClaimsCmd(context.Background(), Add),
+	}
+
+	claimsCmd.Flags().StringP("region", "r", "", "region the device will be registered in")
+	claimsCmd.Flags().IntP("type", "t", 0, "node type of the device")
+	claimsCmd.Flags().Duration("ttl", registry.DefaultClaimCodeTTL, "how long the code is valid")
+
+	orgsCmd := &cobra.Command{
+		Use:     "orgs",
+		Short:   "orgs --name <name> --desc <description>",
+		Long:    `add a new organization (tenant), only the global admin can add organizations`,
+		Example: "regctl add orgs --name tanesco --desc \"Tanzania Electric Supply Company\"",
+		Run:     cli.OrgsCmd(context.Background(), Add),
+	}
+
+	orgsCmd.Flags().StringP("name", "n", "", "organization name")
+	orgsCmd.Flags().StringP("desc", "d", "", "organization description")
+
+	firmwareCmd := &cobra.Command{
+		Use:     "firmware",
+		Short:   "firmware --version <version> --type <type-id> (--url <url> | --file <path>) [--checksum <sha256>]",
+		Long:    `add a firmware release of a node type, stored in the registry with --file or downloaded by the nodes from --url`,
+		Example: "regctl add firmware --version 2.1.0 --type 4 --file meter-2.1.0.bin",
+		Run:     cli.FirmwareCmd(context.Background(), Add),
+	}
+
+	firmwareCmd.Flags().StringP("version", "v", "", "firmware version")
+	firmwareCmd.Flags().IntP("type", "t", 0, "node type the firmware is built for")
+	firmwareCmd.Flags().String("url", "", "where the nodes download the firmware")
+	firmwareCmd.Flags().StringP("file", "f", "", "firmware image to store in the registry")
+	firmwareCmd.Flags().String("checksum", "", "hex encoded sha256 of the image, computed from --file when empty")
+	firmwareCmd.Flags().String("org", "", "organization owning the release")
+
+	campaignsCmd := &cobra.Command{
+		Use:     "campaigns",
+		Short:   "campaigns --name <name> --firmware <firmware-id> [--region <region-id> [--subregions]] [--labels k=v,..] [--rollout <percent>]",
+		Long:    `start rolling a firmware out to the nodes of its type in a region with the labels, --rollout percent of them are enrolled right away`,
+		Example: "regctl add campaigns --name meters-2.1 --firmware 0f3c.. --region dsm --subregions --rollout 10",
+		Run:     cli.CampaignsCmd(context.Background(), Add),
+	}
+
+	campaignsCmd.Flags().StringP("name", "n", "", "campaign name")
+	campaignsCmd.Flags().String("firmware", "", "id of the firmware release to roll out")
+	campaignsCmd.Flags().String("region", "", "only target the nodes of the region")
+	campaignsCmd.Flags().Bool("subregions", false, "target the nodes of the sub-regions of --region too")
+	campaignsCmd.Flags().StringToString("labels", nil, "only target the nodes with these labels")
+	campaignsCmd.Flags().Int("rollout", 0, "percentage of the targeted nodes to enroll, all of them when 0")
+
+	schemasCmd := &cobra.Command{
+		Use:     "schemas",
+		Short:   "schemas (--type <type-id> | --node <node-id>) --file <path> [--kind telemetry|commands]",
+		Long:    `add the next version of the JSON Schema of the telemetry a node type, or a single node, publishes or of the commands it accepts`,
+		Example: "regctl add schemas --type 1 --kind telemetry --file sensor-telemetry.json",
+		Run:     cli.SchemasCmd(context.Background(), Add),
+	}
+
+	schemasCmd.Flags().IntP("type", "t", 0, "node type the schema describes")
+	schemasCmd.Flags().StringP("node", "n", "", "node the schema describes, instead of its type")
+	schemasCmd.Flags().StringP("kind", "k", "telemetry", "what the schema describes, telemetry or commands")
+	schemasCmd.Flags().StringP("file", "f", "", "JSON Schema document")
+	schemasCmd.Flags().String("org", "", "organization owning the schema of a node type")
 
 	addCmd := &cobra.Command{
 		Use:   "add",
-		Short: "add (users |nodes |regions)",
-		Long:  `add a new entity to the network (users |nodes |regions )`,
+		Short: "add (users |nodes |regions |types |claims |orgs |firmware |campaigns |schemas)",
+		Long:  `add a new entity to the network (users |nodes |regions |types |claims |orgs |firmware |campaigns |schemas)`,
 		Run: func(cmd *cobra.Command, args []string) {
 			logUsage(cmd.Short)
 		},
 	}
 
-	addCmd.AddCommand(usersCmd, regionsCmd, nodesCmd)
+	addCmd.AddCommand(usersCmd, regionsCmd, nodesCmd, typesCmd, claimsCmd, orgsCmd, firmwareCmd, campaignsCmd, schemasCmd)
 
 	return addCmd
 }
@@ -0,0 +1,69 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"github.com/spf13/cobra"
+)
+
+func NewAPIKeysCmd(cli CLI) *cobra.Command {
+
+	createCmd := &cobra.Command{
+		Use:     "create",
+		Short:   "create --owner <user-id> --name <name> [--role <group>] [--region <region-id>] [--ttl <duration>]",
+		Long:    `create an api key owned by a user, the key is shown only once`,
+		Example: "regctl apikeys create --owner 0b0c6a56-3f3c-4d1e-9d7a-1c6b4b8f2e51 --name provisioning --role 2 --region AA001 --ttl 720h",
+		Run:     cli.APIKeysCmd(context.Background(), Add),
+	}
+
+	createCmd.Flags().StringP("owner", "u", "", "id of the user owning the key")
+	createCmd.Flags().StringP("name", "n", "", "name of the key")
+	createCmd.Flags().IntP("role", "g", 3, "role of the key (1 admin, 2 region admin, 3 region user, 4 organization admin)")
+	createCmd.Flags().StringP("region", "r", "", "region the key is limited to")
+	createCmd.Flags().Duration("ttl", 0, "how long the key is valid, 0 for no expiry")
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "list [--owner <user-id>]",
+		Long:  `list the api keys, the keys themselves are never shown`,
+		Run:   cli.APIKeysCmd(context.Background(), List),
+	}
+
+	listCmd.Flags().StringP("owner", "u", "", "only list the keys of this user")
+
+	revokeCmd := &cobra.Command{
+		Use:   "revoke",
+		Short: "revoke --id <key-id>",
+		Long:  `revoke an api key, it can not be used anymore`,
+		Run:   cli.APIKeysCmd(context.Background(), Delete),
+	}
+
+	revokeCmd.Flags().StringP("id", "i", "", "api key id")
+
+	apiKeysCmd := &cobra.Command{
+		Use:   "apikeys",
+		Short: "apikeys (create |list |revoke)",
+		Long:  `manage the api keys scripts and services use to access the registry`,
+		Run: func(cmd *cobra.Command, args []string) {
+			logUsage(cmd.Short)
+		},
+	}
+
+	apiKeysCmd.AddCommand(createCmd, listCmd, revokeCmd)
+
+	return apiKeysCmd
+}
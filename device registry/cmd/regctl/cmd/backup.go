@@ -0,0 +1,197 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/piusalfred/registry/backup"
+	"github.com/piusalfred/registry/postgres"
+	"github.com/piusalfred/registry/sqlite"
+	"github.com/piusalfred/registry/store"
+	"github.com/spf13/cobra"
+	"os"
+	"time"
+)
+
+var (
+	dbBackend string
+	dbPath    string
+)
+
+func NewBackupCmd() *cobra.Command {
+
+	backupCmd := &cobra.Command{
+		Use:     "backup",
+		Short:   "backup [--out <archive>]",
+		Long:    `write a consistent archive of every table of the registry database but the idempotency keys, the archive holds password, api key and node key hashes`,
+		Example: "regctl backup --out registry.tar.gz",
+		Run: func(cmd *cobra.Command, args []string) {
+			out, _ := cmd.Flags().GetString("out")
+			if out == "" {
+				out = fmt.Sprintf("registry-%s.tar.gz", time.Now().Format("20060102-150405"))
+			}
+
+			if dbBackend == "sqlite" {
+				if _, err := os.Stat(dbPath); err != nil {
+					logError(err)
+					os.Exit(1)
+				}
+			}
+
+			db, store, err := openBackupStore()
+			if err != nil {
+				logError(err)
+				os.Exit(1)
+			}
+			defer db.Close()
+
+			data, err := store.Snapshot(context.Background())
+			if err != nil {
+				logError(err)
+				os.Exit(1)
+			}
+
+			f, err := os.OpenFile(out, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+			if err != nil {
+				logError(err)
+				os.Exit(1)
+			}
+
+			manifest, err := backup.Write(f, data)
+			if err == nil {
+				err = f.Close()
+			}
+
+			if err != nil {
+				f.Close()
+				os.Remove(out)
+				logError(err)
+				os.Exit(1)
+			}
+
+			logJSON(manifest)
+			logCreated(out)
+		},
+	}
+
+	backupCmd.Flags().String("out", "", "archive file, registry-<timestamp>.tar.gz by default")
+	addBackendFlags(backupCmd)
+
+	return backupCmd
+}
+
+func NewRestoreCmd() *cobra.Command {
+
+	restoreCmd := &cobra.Command{
+		Use:     "restore",
+		Short:   "restore --in <archive> [--on-conflict skip|overwrite|fail]",
+		Long:    `verify a registry archive and load it into the registry database in a single transaction, send SIGHUP to running regsvc instances so that they drop the nodes they cached`,
+		Example: "regctl restore --in registry.tar.gz --on-conflict overwrite",
+		Run: func(cmd *cobra.Command, args []string) {
+			in, _ := cmd.Flags().GetString("in")
+			onConflict, _ := cmd.Flags().GetString("on-conflict")
+
+			if in == "" {
+				logUsage(cmd.Short)
+				return
+			}
+
+			policy, err := backup.ParsePolicy(onConflict)
+			if err != nil {
+				logError(err)
+				os.Exit(1)
+			}
+
+			f, err := os.Open(in)
+			if err != nil {
+				logError(err)
+				os.Exit(1)
+			}
+
+			manifest, data, err := backup.Read(f)
+			f.Close()
+			if err != nil {
+				logError(err)
+				os.Exit(1)
+			}
+
+			db, store, err := openBackupStore()
+			if err != nil {
+				logError(err)
+				os.Exit(1)
+			}
+			defer db.Close()
+
+			results, err := store.Restore(context.Background(), data, policy)
+			if err != nil {
+				logError(err)
+				os.Exit(1)
+			}
+
+			logJSON(struct {
+				Archive backup.Manifest `json:"archive"`
+				Results []backup.Result `json:"results"`
+			}{manifest, results})
+			logOK()
+		},
+	}
+
+	restoreCmd.Flags().StringP("in", "i", "", "archive file written by regctl backup")
+	restoreCmd.Flags().String("on-conflict", string(backup.Skip), "what to do with rows already in the database, skip, overwrite or fail")
+	addBackendFlags(restoreCmd)
+
+	return restoreCmd
+}
+
+// addBackendFlags adds the flags selecting the registry database to cmd
+func addBackendFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&dbBackend, "backend", "postgres", "storage backend of regsvc, postgres or sqlite")
+	cmd.Flags().StringVar(&dbPath, "path", "registry.db", "SQLite database file")
+	addDBFlags(cmd)
+}
+
+func openBackupStore() (*sql.DB, backup.Store, error) {
+	switch dbBackend {
+	case "postgres":
+		db, err := dbConnect(dbConfig{
+			Hostname: hostname,
+			Username: dbuser,
+			Password: dbpass,
+			DBName:   dbname,
+			SSLMode:  sslmode,
+			Port:     dbport,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return db, store.NewBackupStore(postgres.New(db)), nil
+
+	case "sqlite":
+		db, err := sqlite.Connect(dbPath)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return db.DB, store.NewBackupStore(db), nil
+
+	default:
+		return nil, nil, errors.New("unknown backend " + dbBackend + ", use postgres or sqlite")
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"github.com/piusalfred/registry"
+	"github.com/spf13/cobra"
+)
+
+func NewCertsCmd(cli CLI) *cobra.Command {
+
+	issueCmd := &cobra.Command{
+		Use:     "issue",
+		Short:   "issue --node <node-id> --csr <csr-file> [--ttl <duration>] [--out <cert-file>]",
+		Long:    `sign the certificate signing request of a node, the certificate common name is the node uuid`,
+		Example: "regctl certs issue --node 6f1c3ef0-5c4b-4bd3-a4c7-6c2a1f0b6b1e --csr node.csr --out node.crt",
+		Run:     cli.CertsCmd(context.Background(), Add),
+	}
+
+	issueCmd.Flags().StringP("node", "n", "", "node id or address")
+	issueCmd.Flags().StringP("csr", "c", "", "PEM encoded certificate signing request file")
+	issueCmd.Flags().Duration("ttl", registry.DefaultCertificateTTL, "how long the certificate is valid")
+	issueCmd.Flags().String("out", "", "file to write the PEM encoded certificate to")
+
+	revokeCmd := &cobra.Command{
+		Use:   "revoke",
+		Short: "revoke --serial <serial>",
+		Long:  `revoke a node certificate, it is added to the certificate revocation list`,
+		Run:   cli.CertsCmd(context.Background(), Delete),
+	}
+
+	revokeCmd.Flags().StringP("serial", "s", "", "certificate serial")
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "list [--crl]",
+		Long:  `list all issued certificates or print the PEM encoded certificate revocation list`,
+		Run:   cli.CertsCmd(context.Background(), List),
+	}
+
+	listCmd.Flags().Bool("crl", false, "print the certificate revocation list")
+
+	certsCmd := &cobra.Command{
+		Use:   "certs",
+		Short: "certs (issue |revoke |list)",
+		Long:  `manage the certificates nodes use to authenticate themselves`,
+		Run: func(cmd *cobra.Command, args []string) {
+			logUsage(cmd.Short)
+		},
+	}
+
+	certsCmd.AddCommand(issueCmd, revokeCmd, listCmd)
+
+	return certsCmd
+}
@@ -2,10 +2,20 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	kithttp "github.com/go-kit/kit/transport/http"
 	"github.com/piusalfred/registry"
 	"github.com/piusalfred/registry/api"
 	"github.com/piusalfred/registry/pkg/errors"
 	"github.com/spf13/cobra"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 )
 
 var (
@@ -26,22 +36,33 @@ type CLI interface {
 	UsersCmd(ctx context.Context, reqType ReqType) func(cmd *cobra.Command, args []string)
 	NodesCmd(ctx context.Context, reqType ReqType) func(cmd *cobra.Command, args []string)
 	RegionsCmd(ctx context.Context, reqType ReqType) func(cmd *cobra.Command, args []string)
+	TypesCmd(ctx context.Context, reqType ReqType) func(cmd *cobra.Command, args []string)
+	ClaimsCmd(ctx context.Context, reqType ReqType) func(cmd *cobra.Command, args []string)
+	CertsCmd(ctx context.Context, reqType ReqType) func(cmd *cobra.Command, args []string)
+	APIKeysCmd(ctx context.Context, reqType ReqType) func(cmd *cobra.Command, args []string)
+	OrgsCmd(ctx context.Context, reqType ReqType) func(cmd *cobra.Command, args []string)
+	FirmwareCmd(ctx context.Context, reqType ReqType) func(cmd *cobra.Command, args []string)
+	CampaignsCmd(ctx context.Context, reqType ReqType) func(cmd *cobra.Command, args []string)
+	SchemasCmd(ctx context.Context, reqType ReqType) func(cmd *cobra.Command, args []string)
+	QuotasCmd(ctx context.Context, reqType ReqType) func(cmd *cobra.Command, args []string)
+	ReportCmd(ctx context.Context, report string) func(cmd *cobra.Command, args []string)
+	WatchCmd(ctx context.Context) func(cmd *cobra.Command, args []string)
 }
 
 type list struct {
-	endpoints api.Endpoints
+	endpoints *api.Endpoints
 }
 
 func (l list) UsersCmd(ctx context.Context, reqType ReqType) func(cmd *cobra.Command, args []string) {
 	switch reqType {
 	case List:
 		return func(cmd *cobra.Command, args []string) {
-			users, err := l.endpoints.ListUser(ctx)
+			users, err := l.endpoints.ListUser(ctx, regionFilter(cmd))
 			if err != nil {
 				logError(err)
 			}
 
-			logJSON(users)
+			logOutput(users)
 		}
 
 	case Get:
@@ -59,7 +80,7 @@ func (l list) UsersCmd(ctx context.Context, reqType ReqType) func(cmd *cobra.Com
 				return
 			}
 
-			logJSON(user)
+			logOutput(user)
 		}
 
 	case Add:
@@ -68,6 +89,7 @@ func (l list) UsersCmd(ctx context.Context, reqType ReqType) func(cmd *cobra.Com
 			email, err := cmd.Flags().GetString("email")
 			password, err := cmd.Flags().GetString("password")
 			region, err := cmd.Flags().GetString("region")
+			org, err := cmd.Flags().GetString("org")
 
 			if err != nil || name == "" || email == "" ||
 				password == "" || region == "" {
@@ -80,6 +102,7 @@ func (l list) UsersCmd(ctx context.Context, reqType ReqType) func(cmd *cobra.Com
 				Email:    email,
 				Password: password,
 				Region:   region,
+				Org:      org,
 			}
 
 			err = l.endpoints.AddUser(ctx, user)
@@ -135,7 +158,7 @@ func (l list) UsersCmd(ctx context.Context, reqType ReqType) func(cmd *cobra.Com
 			}
 
 			logCreated("new user created")
-			logJSON(up)
+			logOutput(up)
 		}
 
 	default:
@@ -151,12 +174,12 @@ func (l list) NodesCmd(ctx context.Context, reqType ReqType) func(cmd *cobra.Com
 	switch reqType {
 	case List:
 		return func(cmd *cobra.Command, args []string) {
-			nodes, err := l.endpoints.ListNodes(ctx)
+			nodes, err := l.endpoints.ListNodes(ctx, regionFilter(cmd))
 			if err != nil {
 				logError(err)
 			}
 
-			logJSON(nodes)
+			logOutput(nodes)
 		}
 
 	case Delete:
@@ -193,7 +216,7 @@ func (l list) NodesCmd(ctx context.Context, reqType ReqType) func(cmd *cobra.Com
 				return
 			}
 
-			logJSON(node)
+			logOutput(node)
 		}
 
 	case Add:
@@ -206,6 +229,8 @@ func (l list) NodesCmd(ctx context.Context, reqType ReqType) func(cmd *cobra.Com
 			long, err := cmd.Flags().GetString("long")
 			master, err := cmd.Flags().GetString("master")
 			typ, err := cmd.Flags().GetInt("type")
+			firmware, _ := cmd.Flags().GetString("firmware")
+			labels, _ := cmd.Flags().GetStringToString("labels")
 
 			if err != nil || name == "" || addr == "" ||
 				latd == "" || region == "" || long == "" {
@@ -221,6 +246,9 @@ func (l list) NodesCmd(ctx context.Context, reqType ReqType) func(cmd *cobra.Com
 				Latd:   latd,
 				Long:   long,
 				Master: master,
+
+				Firmware: firmware,
+				Labels:   labels,
 			}
 
 			err = l.endpoints.AddNode(ctx, node)
@@ -250,7 +278,53 @@ func (l list) RegionsCmd(ctx context.Context, reqType ReqType) func(cmd *cobra.C
 				logError(err)
 			}
 
-			logJSON(regions)
+			logOutput(regions)
+		}
+
+	case Get:
+		return func(cmd *cobra.Command, args []string) {
+			id, _ := cmd.Flags().GetString("id")
+			if id != "" {
+				ancestors, _ := cmd.Flags().GetBool("ancestors")
+
+				var regions []registry.Region
+				var err error
+				if ancestors {
+					regions, err = l.endpoints.RegionAncestors(ctx, id)
+				} else {
+					regions, err = l.endpoints.RegionSubtree(ctx, id)
+				}
+
+				if err != nil {
+					logError(err)
+					return
+				}
+
+				logOutput(regions)
+				return
+			}
+
+			latd, err := cmd.Flags().GetString("latitude")
+			long, err := cmd.Flags().GetString("longitude")
+
+			if err != nil || latd == "" || long == "" {
+				logUsage(cmd.Short)
+				return
+			}
+
+			lat, lon, err := registry.ParseLocation(latd, long)
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			regions, err := l.endpoints.SuggestRegion(ctx, lat, lon)
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			logOutput(regions)
 		}
 
 	case Add:
@@ -258,26 +332,59 @@ func (l list) RegionsCmd(ctx context.Context, reqType ReqType) func(cmd *cobra.C
 			name, err := cmd.Flags().GetString("name")
 			id, err := cmd.Flags().GetString("id")
 			description, err := cmd.Flags().GetString("desc")
+			org, err := cmd.Flags().GetString("org")
+			parent, err := cmd.Flags().GetString("parent")
+			boundaryFile, err := cmd.Flags().GetString("boundary")
 
 			if err != nil || name == "" || id == "" || description == "" {
 				logUsage(cmd.Short)
 			}
 
 			region := registry.Region{
-				ID:   id,
-				Name: name,
-				Desc: description,
+				ID:     id,
+				Name:   name,
+				Desc:   description,
+				Org:    org,
+				Parent: parent,
+			}
+
+			if boundaryFile != "" {
+				region.Boundary, err = readBoundary(boundaryFile)
+				if err != nil {
+					logError(err)
+					return
+				}
 			}
 
 			err = l.endpoints.AddRegion(context.Background(), region)
 
 			if err != nil {
 				logError(err)
+				return
 			}
 
 			logCreated("new region added")
 		}
 
+	case Update:
+		return func(cmd *cobra.Command, args []string) {
+			id, err := cmd.Flags().GetString("id")
+			parent, err := cmd.Flags().GetString("parent")
+
+			if err != nil || id == "" {
+				logUsage(cmd.Short)
+				return
+			}
+
+			region, err := l.endpoints.MoveRegion(ctx, id, parent)
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			logOutput(region)
+		}
+
 	default:
 		return func(cmd *cobra.Command, args []string) {
 			logError(ErrWTF)
@@ -285,11 +392,1060 @@ func (l list) RegionsCmd(ctx context.Context, reqType ReqType) func(cmd *cobra.C
 	}
 }
 
-func cli(addr, port string) (CLI, error) {
-	endpoints, err := api.MakeClientEndpoints(addr + port)
+// regionFilter reads the --region and --subregions flags of the list commands
+func regionFilter(cmd *cobra.Command) registry.RegionFilter {
+	region, _ := cmd.Flags().GetString("region")
+	subregions, _ := cmd.Flags().GetBool("subregions")
+	return registry.RegionFilter{Region: region, Subregions: subregions}
+}
 
+// readBoundary reads the GeoJSON geometry of file, the geometry of a Feature
+// is used as is
+func readBoundary(file string) (*registry.Geometry, error) {
+	data, err := ioutil.ReadFile(file)
 	if err != nil {
 		return nil, err
 	}
-	return list{endpoints: endpoints}, nil
+
+	var geojson struct {
+		registry.Geometry
+		Feature *registry.Geometry `json:"geometry"`
+	}
+	if err := json.Unmarshal(data, &geojson); err != nil {
+		return nil, err
+	}
+
+	if geojson.Type == "Feature" && geojson.Feature != nil {
+		return geojson.Feature, nil
+	}
+
+	return &geojson.Geometry, nil
+}
+
+func (l list) TypesCmd(ctx context.Context, reqType ReqType) func(cmd *cobra.Command, args []string) {
+	switch reqType {
+	case List:
+		return func(cmd *cobra.Command, args []string) {
+			types, err := l.endpoints.ListNodeTypes(ctx)
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			logOutput(types)
+		}
+
+	case Get:
+		return func(cmd *cobra.Command, args []string) {
+			id, err := cmd.Flags().GetInt("id")
+
+			if err != nil || id <= 0 {
+				logUsage(cmd.Short)
+				return
+			}
+
+			nodeType, err := l.endpoints.GetNodeType(ctx, id)
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			logOutput(nodeType)
+		}
+
+	case Add:
+		return func(cmd *cobra.Command, args []string) {
+			id, err := cmd.Flags().GetInt("id")
+			name, err := cmd.Flags().GetString("name")
+			description, err := cmd.Flags().GetString("desc")
+			caps, err := cmd.Flags().GetStringSlice("caps")
+
+			if err != nil || id <= 0 || name == "" {
+				logUsage(cmd.Short)
+				return
+			}
+
+			nodeType := registry.NodeType{
+				ID:           id,
+				Name:         name,
+				Desc:         description,
+				Capabilities: toCapabilities(caps),
+			}
+
+			err = l.endpoints.AddNodeType(ctx, nodeType)
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			logCreated("new node type added")
+		}
+
+	case Delete:
+		return func(cmd *cobra.Command, args []string) {
+			id, err := cmd.Flags().GetInt("id")
+
+			if err != nil || id <= 0 {
+				logUsage(cmd.Short)
+				return
+			}
+
+			err = l.endpoints.DeleteNodeType(ctx, id)
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			logOK()
+		}
+
+	case Update:
+		return func(cmd *cobra.Command, args []string) {
+			id, err := cmd.Flags().GetInt("id")
+			name, err := cmd.Flags().GetString("name")
+			description, err := cmd.Flags().GetString("desc")
+			caps, err := cmd.Flags().GetStringSlice("caps")
+
+			if err != nil || id <= 0 || name == "" {
+				logUsage(cmd.Example)
+				return
+			}
+
+			nodeType := registry.NodeType{
+				ID:           id,
+				Name:         name,
+				Desc:         description,
+				Capabilities: toCapabilities(caps),
+			}
+
+			up, err := l.endpoints.UpdateNodeType(ctx, id, nodeType)
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			logOutput(up)
+		}
+
+	default:
+		return func(cmd *cobra.Command, args []string) {
+			logUsage(cmd.Short)
+		}
+	}
+}
+
+func (l list) ClaimsCmd(ctx context.Context, reqType ReqType) func(cmd *cobra.Command, args []string) {
+	switch reqType {
+	case List:
+		return func(cmd *cobra.Command, args []string) {
+			audit, err := cmd.Flags().GetBool("audit")
+			if err != nil {
+				logUsage(cmd.Short)
+				return
+			}
+
+			if audit {
+				claims, err := l.endpoints.ListClaims(ctx)
+				if err != nil {
+					logError(err)
+					return
+				}
+
+				logOutput(claims)
+				return
+			}
+
+			codes, err := l.endpoints.ListClaimCodes(ctx)
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			logOutput(codes)
+		}
+
+	case Add:
+		return func(cmd *cobra.Command, args []string) {
+			region, err := cmd.Flags().GetString("region")
+			typ, err := cmd.Flags().GetInt("type")
+			ttl, err := cmd.Flags().GetDuration("ttl")
+
+			if err != nil || region == "" || typ <= 0 {
+				logUsage(cmd.Short)
+				return
+			}
+
+			code, err := l.endpoints.CreateClaimCode(ctx, region, typ, ttl)
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			logCreated("new claim code")
+			logOutput(code)
+		}
+
+	default:
+		return func(cmd *cobra.Command, args []string) {
+			logUsage(cmd.Short)
+		}
+	}
+}
+
+func (l list) CertsCmd(ctx context.Context, reqType ReqType) func(cmd *cobra.Command, args []string) {
+	switch reqType {
+	case List:
+		return func(cmd *cobra.Command, args []string) {
+			crl, err := cmd.Flags().GetBool("crl")
+			if err != nil {
+				logUsage(cmd.Short)
+				return
+			}
+
+			if crl {
+				list, err := l.endpoints.RevocationList(ctx)
+				if err != nil {
+					logError(err)
+					return
+				}
+
+				fmt.Print(string(list))
+				return
+			}
+
+			certs, err := l.endpoints.ListCertificates(ctx)
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			logOutput(certs)
+		}
+
+	case Add:
+		return func(cmd *cobra.Command, args []string) {
+			node, err := cmd.Flags().GetString("node")
+			csrFile, err := cmd.Flags().GetString("csr")
+			ttl, err := cmd.Flags().GetDuration("ttl")
+			out, err := cmd.Flags().GetString("out")
+
+			if err != nil || node == "" || csrFile == "" {
+				logUsage(cmd.Short)
+				return
+			}
+
+			csr, err := ioutil.ReadFile(csrFile)
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			cert, err := l.endpoints.IssueCertificate(ctx, node, string(csr), ttl)
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			if out != "" {
+				if err := ioutil.WriteFile(out, []byte(cert.PEM), 0644); err != nil {
+					logError(err)
+					return
+				}
+				cert.PEM = ""
+			}
+
+			logCreated(fmt.Sprintf("certificate %s", cert.Serial))
+			logOutput(cert)
+		}
+
+	case Delete:
+		return func(cmd *cobra.Command, args []string) {
+			serial, err := cmd.Flags().GetString("serial")
+
+			if err != nil || serial == "" {
+				logUsage(cmd.Short)
+				return
+			}
+
+			err = l.endpoints.RevokeCertificate(ctx, serial)
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			logOK()
+		}
+
+	default:
+		return func(cmd *cobra.Command, args []string) {
+			logUsage(cmd.Short)
+		}
+	}
+}
+
+func (l list) APIKeysCmd(ctx context.Context, reqType ReqType) func(cmd *cobra.Command, args []string) {
+	switch reqType {
+	case List:
+		return func(cmd *cobra.Command, args []string) {
+			owner, err := cmd.Flags().GetString("owner")
+			if err != nil {
+				logUsage(cmd.Short)
+				return
+			}
+
+			keys, err := l.endpoints.ListAPIKeys(ctx, owner)
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			logOutput(keys)
+		}
+
+	case Add:
+		return func(cmd *cobra.Command, args []string) {
+			owner, err := cmd.Flags().GetString("owner")
+			name, err := cmd.Flags().GetString("name")
+			role, err := cmd.Flags().GetInt("role")
+			region, err := cmd.Flags().GetString("region")
+			ttl, err := cmd.Flags().GetDuration("ttl")
+
+			if err != nil || owner == "" || name == "" {
+				logUsage(cmd.Short)
+				return
+			}
+
+			key, err := l.endpoints.CreateAPIKey(ctx, owner, name, role, region, ttl)
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			logCreated(fmt.Sprintf("api key %s, store the key now it will not be shown again", key.ID))
+			logOutput(key)
+		}
+
+	case Delete:
+		return func(cmd *cobra.Command, args []string) {
+			id, err := cmd.Flags().GetString("id")
+
+			if err != nil || id == "" {
+				logUsage(cmd.Short)
+				return
+			}
+
+			err = l.endpoints.RevokeAPIKey(ctx, id)
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			logOK()
+		}
+
+	default:
+		return func(cmd *cobra.Command, args []string) {
+			logUsage(cmd.Short)
+		}
+	}
+}
+
+func (l list) OrgsCmd(ctx context.Context, reqType ReqType) func(cmd *cobra.Command, args []string) {
+	switch reqType {
+	case List:
+		return func(cmd *cobra.Command, args []string) {
+			orgs, err := l.endpoints.ListOrganizations(ctx)
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			logOutput(orgs)
+		}
+
+	case Get:
+		return func(cmd *cobra.Command, args []string) {
+			id, err := cmd.Flags().GetString("id")
+
+			if err != nil || id == "" {
+				logUsage(cmd.Short)
+				return
+			}
+
+			org, err := l.endpoints.GetOrganization(ctx, id)
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			logOutput(org)
+		}
+
+	case Add:
+		return func(cmd *cobra.Command, args []string) {
+			name, err := cmd.Flags().GetString("name")
+			desc, err := cmd.Flags().GetString("desc")
+
+			if err != nil || name == "" {
+				logUsage(cmd.Short)
+				return
+			}
+
+			org, err := l.endpoints.AddOrganization(ctx, registry.Organization{
+				Name: name,
+				Desc: desc,
+			})
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			logCreated("new organization")
+			logOutput(org)
+		}
+
+	case Delete:
+		return func(cmd *cobra.Command, args []string) {
+			id, err := cmd.Flags().GetString("id")
+
+			if err != nil || id == "" {
+				logUsage(cmd.Short)
+				return
+			}
+
+			err = l.endpoints.DeleteOrganization(ctx, id)
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			logOK()
+		}
+
+	default:
+		return func(cmd *cobra.Command, args []string) {
+			logUsage(cmd.Short)
+		}
+	}
+}
+
+func (l list) FirmwareCmd(ctx context.Context, reqType ReqType) func(cmd *cobra.Command, args []string) {
+	switch reqType {
+	case List:
+		return func(cmd *cobra.Command, args []string) {
+			releases, err := l.endpoints.ListFirmware(ctx)
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			logOutput(releases)
+		}
+
+	case Get:
+		return func(cmd *cobra.Command, args []string) {
+			id, err := cmd.Flags().GetString("id")
+			out, _ := cmd.Flags().GetString("out")
+
+			if err != nil || id == "" {
+				logUsage(cmd.Short)
+				return
+			}
+
+			fw, err := l.endpoints.GetFirmware(ctx, id)
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			if out == "" {
+				logOutput(fw)
+				return
+			}
+
+			if len(fw.Blob) == 0 {
+				logError(errors.New("the firmware is not stored in the registry, download it from " + fw.URL))
+				return
+			}
+
+			if err = ioutil.WriteFile(out, fw.Blob, 0644); err != nil {
+				logError(err)
+				return
+			}
+
+			logOK()
+		}
+
+	case Add:
+		return func(cmd *cobra.Command, args []string) {
+			version, err := cmd.Flags().GetString("version")
+			typ, err := cmd.Flags().GetInt("type")
+			url, err := cmd.Flags().GetString("url")
+			file, err := cmd.Flags().GetString("file")
+			checksum, err := cmd.Flags().GetString("checksum")
+			org, err := cmd.Flags().GetString("org")
+
+			if err != nil || version == "" || typ == 0 || (url == "" && file == "") {
+				logUsage(cmd.Short)
+				return
+			}
+
+			fw := registry.Firmware{
+				Version:  version,
+				Type:     typ,
+				URL:      url,
+				Checksum: checksum,
+				Org:      org,
+			}
+
+			if file != "" {
+				fw.Blob, err = ioutil.ReadFile(file)
+				if err != nil {
+					logError(err)
+					return
+				}
+			}
+
+			fw, err = l.endpoints.AddFirmware(ctx, fw)
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			logCreated("new firmware release")
+			logOutput(fw)
+		}
+
+	case Delete:
+		return func(cmd *cobra.Command, args []string) {
+			id, err := cmd.Flags().GetString("id")
+
+			if err != nil || id == "" {
+				logUsage(cmd.Short)
+				return
+			}
+
+			if err = l.endpoints.DeleteFirmware(ctx, id); err != nil {
+				logError(err)
+				return
+			}
+
+			logOK()
+		}
+
+	default:
+		return func(cmd *cobra.Command, args []string) {
+			logUsage(cmd.Short)
+		}
+	}
+}
+
+func (l list) CampaignsCmd(ctx context.Context, reqType ReqType) func(cmd *cobra.Command, args []string) {
+	switch reqType {
+	case List:
+		return func(cmd *cobra.Command, args []string) {
+			campaigns, err := l.endpoints.ListCampaigns(ctx)
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			logOutput(campaigns)
+		}
+
+	case Get:
+		return func(cmd *cobra.Command, args []string) {
+			id, err := cmd.Flags().GetString("id")
+			nodes, _ := cmd.Flags().GetBool("nodes")
+
+			if err != nil || id == "" {
+				logUsage(cmd.Short)
+				return
+			}
+
+			if nodes {
+				rollouts, err := l.endpoints.ListRollouts(ctx, id)
+				if err != nil {
+					logError(err)
+					return
+				}
+
+				logOutput(rollouts)
+				return
+			}
+
+			campaign, err := l.endpoints.GetCampaign(ctx, id)
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			logOutput(campaign)
+		}
+
+	case Add:
+		return func(cmd *cobra.Command, args []string) {
+			name, err := cmd.Flags().GetString("name")
+			firmware, err := cmd.Flags().GetString("firmware")
+			rollout, err := cmd.Flags().GetInt("rollout")
+			labels, err := cmd.Flags().GetStringToString("labels")
+
+			if err != nil || name == "" || firmware == "" {
+				logUsage(cmd.Short)
+				return
+			}
+
+			filter := regionFilter(cmd)
+			campaign, err := l.endpoints.CreateCampaign(ctx, registry.Campaign{
+				Name:     name,
+				Firmware: firmware,
+				Rollout:  rollout,
+				Target: registry.CampaignTarget{
+					Region:     filter.Region,
+					Subregions: filter.Subregions,
+					Labels:     labels,
+				},
+			})
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			logCreated("new campaign")
+			logOutput(campaign)
+		}
+
+	case Update:
+		return func(cmd *cobra.Command, args []string) {
+			id, err := cmd.Flags().GetString("id")
+			rollout, err := cmd.Flags().GetInt("rollout")
+			node, err := cmd.Flags().GetString("node")
+			status, err := cmd.Flags().GetString("status")
+			msg, err := cmd.Flags().GetString("err")
+
+			if err != nil || id == "" || (node == "") == (rollout == 0) || (node != "" && status == "") {
+				logUsage(cmd.Short)
+				return
+			}
+
+			if node != "" {
+				r, err := l.endpoints.ReportRollout(ctx, id, node, registry.RolloutStatus(status), msg)
+				if err != nil {
+					logError(err)
+					return
+				}
+
+				logOutput(r)
+				return
+			}
+
+			campaign, err := l.endpoints.AdvanceCampaign(ctx, id, rollout)
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			logOutput(campaign)
+		}
+
+	default:
+		return func(cmd *cobra.Command, args []string) {
+			logUsage(cmd.Short)
+		}
+	}
+}
+
+func (l list) SchemasCmd(ctx context.Context, reqType ReqType) func(cmd *cobra.Command, args []string) {
+	switch reqType {
+	case List:
+		return func(cmd *cobra.Command, args []string) {
+			typ, err := cmd.Flags().GetInt("type")
+			node, err := cmd.Flags().GetString("node")
+			kind, err := cmd.Flags().GetString("kind")
+
+			if err != nil {
+				logUsage(cmd.Short)
+				return
+			}
+
+			schemas, err := l.endpoints.ListSchemas(ctx, registry.SchemaFilter{
+				Type: typ,
+				Node: node,
+				Kind: registry.SchemaKind(kind),
+			})
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			logOutput(schemas)
+		}
+
+	case Get:
+		return func(cmd *cobra.Command, args []string) {
+			id, err := cmd.Flags().GetString("id")
+			node, err := cmd.Flags().GetString("node")
+			kind, err := cmd.Flags().GetString("kind")
+			version, err := cmd.Flags().GetInt("version")
+			validate, err := cmd.Flags().GetString("validate")
+
+			if err != nil || (id == "") == (node == "") || (validate != "" && node == "") {
+				logUsage(cmd.Short)
+				return
+			}
+
+			if id != "" {
+				schema, err := l.endpoints.GetSchema(ctx, id)
+				if err != nil {
+					logError(err)
+					return
+				}
+
+				logOutput(schema)
+				return
+			}
+
+			if validate == "" {
+				schema, err := l.endpoints.NodeSchema(ctx, node, registry.SchemaKind(kind), version)
+				if err != nil {
+					logError(err)
+					return
+				}
+
+				logOutput(schema)
+				return
+			}
+
+			payload, err := ioutil.ReadFile(validate)
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			v, err := l.endpoints.ValidatePayload(ctx, node, registry.SchemaKind(kind), version, payload)
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			logOutput(v)
+		}
+
+	case Add:
+		return func(cmd *cobra.Command, args []string) {
+			typ, err := cmd.Flags().GetInt("type")
+			node, err := cmd.Flags().GetString("node")
+			kind, err := cmd.Flags().GetString("kind")
+			file, err := cmd.Flags().GetString("file")
+			org, err := cmd.Flags().GetString("org")
+
+			if err != nil || (typ == 0) == (node == "") || file == "" {
+				logUsage(cmd.Short)
+				return
+			}
+
+			document, err := ioutil.ReadFile(file)
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			schema, err := l.endpoints.AddSchema(ctx, registry.Schema{
+				Type:     typ,
+				Node:     node,
+				Kind:     registry.SchemaKind(kind),
+				Document: document,
+				Org:      org,
+			})
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			logCreated("new schema version")
+			logOutput(schema)
+		}
+
+	default:
+		return func(cmd *cobra.Command, args []string) {
+			logUsage(cmd.Short)
+		}
+	}
+}
+
+func (l list) QuotasCmd(ctx context.Context, reqType ReqType) func(cmd *cobra.Command, args []string) {
+	//quotas and usage are of a region or of an organization
+	scope := func(cmd *cobra.Command) (registry.QuotaScope, string, bool) {
+		region, _ := cmd.Flags().GetString("region")
+		org, _ := cmd.Flags().GetString("org")
+
+		switch {
+		case region != "" && org == "":
+			return registry.QuotaRegion, region, true
+		case org != "" && region == "":
+			return registry.QuotaOrg, org, true
+		}
+
+		return "", "", false
+	}
+
+	switch reqType {
+	case List:
+		return func(cmd *cobra.Command, args []string) {
+			quotas, err := l.endpoints.ListQuotas(ctx)
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			logOutput(quotas)
+		}
+
+	case Get:
+		return func(cmd *cobra.Command, args []string) {
+			scope, id, ok := scope(cmd)
+			if !ok {
+				logUsage(cmd.Short)
+				return
+			}
+
+			usage, err := l.endpoints.Usage(ctx, scope, id)
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			logOutput(usage)
+		}
+
+	case Update:
+		return func(cmd *cobra.Command, args []string) {
+			scope, id, ok := scope(cmd)
+			users, err := cmd.Flags().GetInt("users")
+			nodes, err := cmd.Flags().GetStringToInt("nodes")
+
+			if err != nil || !ok {
+				logUsage(cmd.Short)
+				return
+			}
+
+			quota := registry.Quota{Scope: scope, ID: id, Users: users, Nodes: registry.NodeLimits{}}
+			for typ, limit := range nodes {
+				t, err := strconv.Atoi(typ)
+				if err != nil {
+					logError(errors.New("node limits are given as <type-id>=<max>, e.g. 1=100"))
+					return
+				}
+
+				quota.Nodes[t] = limit
+			}
+
+			quota, err = l.endpoints.SetQuota(ctx, quota)
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			logOutput(quota)
+		}
+
+	default:
+		return func(cmd *cobra.Command, args []string) {
+			logUsage(cmd.Short)
+		}
+	}
+}
+
+func (l list) ReportCmd(ctx context.Context, report string) func(cmd *cobra.Command, args []string) {
+	switch report {
+	case reportInventory:
+		return func(cmd *cobra.Command, args []string) {
+			by, err := cmd.Flags().GetStringSlice("by")
+			if err != nil {
+				logUsage(cmd.Short)
+				return
+			}
+
+			dims, err := registry.ParseDimensions(by)
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			inventory, err := l.endpoints.Inventory(ctx, regionFilter(cmd), dims)
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			logOutput(inventory)
+		}
+
+	case reportGrowth:
+		return func(cmd *cobra.Command, args []string) {
+			period, err := cmd.Flags().GetString("period")
+			since, err := cmd.Flags().GetString("since")
+
+			if err != nil {
+				logUsage(cmd.Short)
+				return
+			}
+
+			var start time.Time
+			if since != "" {
+				if start, err = time.Parse("2006-01-02", since); err != nil {
+					logError(api.ErrInvalidSince)
+					return
+				}
+			}
+
+			growth, err := l.endpoints.Growth(ctx, regionFilter(cmd), registry.GrowthPeriod(period), start)
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			logOutput(growth)
+		}
+
+	case reportOrphans:
+		return func(cmd *cobra.Command, args []string) {
+			nodes, err := l.endpoints.OrphanedNodes(ctx, regionFilter(cmd))
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			logOutput(nodes)
+		}
+
+	default:
+		return func(cmd *cobra.Command, args []string) {
+			logUsage(cmd.Short)
+		}
+	}
+}
+
+func (l list) WatchCmd(ctx context.Context) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		entities, err := cmd.Flags().GetStringSlice("entity")
+		regions, err := cmd.Flags().GetStringSlice("region")
+		names, err := cmd.Flags().GetStringSlice("name")
+		cursor, err := cmd.Flags().GetInt64("cursor")
+
+		if err != nil {
+			logUsage(cmd.Short)
+			return
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		go func() {
+			c := make(chan os.Signal, 1)
+			signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+			<-c
+			cancel()
+		}()
+
+		printEvent, err := eventPrinter(os.Stdout)
+		if err != nil {
+			logError(err)
+			return
+		}
+
+		filter := registry.EventFilter{Entities: entities, Regions: regions, Names: names}
+		err = watch(ctx, l.endpoints, filter, time.Duration(cursor), printEvent)
+
+		if err != nil {
+			logError(err)
+			os.Exit(1)
+		}
+	}
+}
+
+// watch streams the events matching filter to fn until ctx is done. The
+// stream is resumed from the last event received when it ends, regsvc ends
+// it when it restarts or when the watcher falls behind.
+func watch(ctx context.Context, s registry.Service, filter registry.EventFilter, cursor time.Duration, fn func(registry.Event)) error {
+	backoff := time.Second
+	connected := false
+
+	for ctx.Err() == nil {
+		events, err := s.Watch(ctx, filter, cursor)
+
+		//errors of the first request are reported, they are unlikely to
+		//go away by themselves
+		if err != nil && !connected {
+			return err
+		}
+
+		if err == nil {
+			connected = true
+			backoff = time.Second
+
+			for event := range events {
+				fn(event)
+				cursor = event.Timestamp
+			}
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+		}
+
+		if backoff < 30*time.Second {
+			backoff *= 2
+		}
+	}
+
+	return nil
+}
+
+func toCapabilities(caps []string) []registry.Capability {
+	var capabilities []registry.Capability
+	for _, c := range caps {
+		capabilities = append(capabilities, registry.Capability(c))
+	}
+
+	return capabilities
+}
+
+// endpoints are shared by the commands, they are created by connect
+var endpoints = &api.Endpoints{}
+
+func cli() CLI {
+	return list{endpoints: endpoints}
+}
+
+// connect creates the endpoints of the commands for the regsvc at server
+func connect(server string, tls tlsConfig) error {
+	options := []kithttp.ClientOption{kithttp.ClientBefore(setAPIKey)}
+
+	client, err := tls.client()
+	if err != nil {
+		return err
+	}
+	if client != nil {
+		options = append(options, kithttp.SetClient(client))
+	}
+
+	e, err := api.MakeClientEndpoints(server, options...)
+	if err != nil {
+		return err
+	}
+
+	*endpoints = e
+	return nil
+}
+
+// setAPIKey authenticates requests with the key given by --key or by the
+// context in use
+func setAPIKey(ctx context.Context, r *http.Request) context.Context {
+	if apiKey != "" {
+		r.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	return ctx
 }
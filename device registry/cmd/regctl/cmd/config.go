@@ -0,0 +1,336 @@
+package cmd
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"github.com/mitchellh/go-homedir"
+	"github.com/piusalfred/registry/pkg/errors"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+var (
+	errUnknownContext = errors.New("no such context in the config file")
+	errInvalidCA      = errors.New("no PEM certificate found in the CA file")
+)
+
+// config is the content of the config file, ~/.regctl.yaml by default
+type config struct {
+	CurrentContext string                   `yaml:"current-context,omitempty"`
+	Contexts       map[string]regctlContext `yaml:"contexts,omitempty"`
+}
+
+// regctlContext is a regsvc instance and the credentials used to reach it
+type regctlContext struct {
+	Server string    `yaml:"server"`
+	APIKey string    `yaml:"api-key,omitempty"`
+	TLS    tlsConfig `yaml:"tls,omitempty"`
+}
+
+type tlsConfig struct {
+	CA                 string `yaml:"ca,omitempty"`
+	Cert               string `yaml:"cert,omitempty"`
+	Key                string `yaml:"key,omitempty"`
+	InsecureSkipVerify bool   `yaml:"insecure-skip-verify,omitempty"`
+}
+
+// client returns the http client connecting with t, nil when t is empty and
+// the default client does
+func (t tlsConfig) client() (*http.Client, error) {
+	if t == (tlsConfig{}) {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{InsecureSkipVerify: t.InsecureSkipVerify}
+
+	if t.CA != "" {
+		pem, err := ioutil.ReadFile(t.CA)
+		if err != nil {
+			return nil, err
+		}
+
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, errInvalidCA
+		}
+	}
+
+	if t.Cert != "" {
+		cert, err := tls.LoadX509KeyPair(t.Cert, t.Key)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return &http.Client{Transport: &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: cfg,
+	}}, nil
+}
+
+// configPath returns the path of the config file given by --config, or the
+// default one
+func configPath() (string, error) {
+	if cfgFile != "" {
+		return cfgFile, nil
+	}
+
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, ".regctl.yaml"), nil
+}
+
+// loadConfig reads the config file at path, a missing file is an empty
+// config
+func loadConfig(path string) (config, error) {
+	var cfg config
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return cfg, errors.Wrap(errors.New("invalid config file "+path), err)
+	}
+
+	return cfg, nil
+}
+
+// save writes cfg to path, only the user can read it since it holds api
+// keys
+func (cfg config) save(path string) error {
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".regctl-*.yaml")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// contextRow is a line of get-contexts, keys are never printed
+type contextRow struct {
+	Current string `json:"current"`
+	Name    string `json:"name"`
+	Server  string `json:"server"`
+	APIKey  bool   `json:"api_key"`
+	TLS     bool   `json:"tls"`
+}
+
+func NewConfigCmd() *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "config (get-contexts |use-context |set-context |delete-context)",
+		Long: `manage the contexts of the config file, a context is a regsvc instance
+and the api key and TLS settings used to reach it. Commands use the current
+context unless --context names another one, --address, --port and --key
+override it.`,
+		Annotations: map[string]string{offline: "true"},
+		Run: func(cmd *cobra.Command, args []string) {
+			logUsage(cmd.Short)
+		},
+	}
+
+	getCmd := &cobra.Command{
+		Use:   "get-contexts",
+		Short: "regctl config get-contexts",
+		Long:  "list the contexts of the config file",
+		Run: withConfig(func(cmd *cobra.Command, args []string, cfg *config) error {
+			names := make([]string, 0, len(cfg.Contexts))
+			for name := range cfg.Contexts {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			rows := make([]contextRow, 0, len(names))
+			for _, name := range names {
+				c := cfg.Contexts[name]
+				row := contextRow{
+					Name:   name,
+					Server: c.Server,
+					APIKey: c.APIKey != "",
+					TLS:    c.TLS != (tlsConfig{}),
+				}
+				if name == cfg.CurrentContext {
+					row.Current = "*"
+				}
+				rows = append(rows, row)
+			}
+
+			//a table reads best unless another format was asked for
+			if !cmd.Flags().Changed("output") {
+				outputFormat = outputTable
+			}
+
+			logOutput(rows)
+			return nil
+		}),
+	}
+
+	useCmd := &cobra.Command{
+		Use:   "use-context <name>",
+		Short: "regctl config use-context <name>",
+		Long:  "make the context called name the current context",
+		Args:  cobra.ExactArgs(1),
+		Run: withConfig(func(cmd *cobra.Command, args []string, cfg *config) error {
+			if _, ok := cfg.Contexts[args[0]]; !ok {
+				return errors.Wrap(errUnknownContext, errors.New(args[0]))
+			}
+
+			cfg.CurrentContext = args[0]
+			return nil
+		}),
+	}
+
+	setCmd := &cobra.Command{
+		Use:   "set-context <name>",
+		Short: "regctl config set-context <name> [--server <url>] [--api-key-stdin] [--ca <file>] [--cert <file> --cert-key <file>]",
+		Long: `create the context called name, or update the settings given by flags.
+The api key is read from the standard input so that it does not end up in the
+shell history, e.g. regctl config set-context prod --api-key-stdin < prod.key`,
+		Args: cobra.ExactArgs(1),
+		Run: withConfig(func(cmd *cobra.Command, args []string, cfg *config) error {
+			if cfg.Contexts == nil {
+				cfg.Contexts = make(map[string]regctlContext)
+			}
+
+			c := cfg.Contexts[args[0]]
+			flags := cmd.Flags()
+
+			if flags.Changed("server") {
+				c.Server, _ = flags.GetString("server")
+			}
+			if flags.Changed("ca") {
+				c.TLS.CA, _ = flags.GetString("ca")
+			}
+			if flags.Changed("cert") {
+				c.TLS.Cert, _ = flags.GetString("cert")
+			}
+			if flags.Changed("cert-key") {
+				c.TLS.Key, _ = flags.GetString("cert-key")
+			}
+			if flags.Changed("insecure-skip-verify") {
+				c.TLS.InsecureSkipVerify, _ = flags.GetBool("insecure-skip-verify")
+			}
+
+			if stdin, _ := flags.GetBool("api-key-stdin"); stdin {
+				key, err := bufio.NewReader(os.Stdin).ReadString('\n')
+				if err != nil && key == "" {
+					return err
+				}
+				c.APIKey = strings.TrimSpace(key)
+			}
+
+			if c.Server == "" {
+				c.Server = defaultAddress + defaultPort
+			}
+
+			cfg.Contexts[args[0]] = c
+			if use, _ := flags.GetBool("use"); use || cfg.CurrentContext == "" {
+				cfg.CurrentContext = args[0]
+			}
+
+			return nil
+		}),
+	}
+
+	setCmd.Flags().String("server", "", "address of regsvc, e.g. https://registry.example.com:8443")
+	setCmd.Flags().Bool("api-key-stdin", false, "read the api key from the standard input")
+	setCmd.Flags().String("ca", "", "CA certificate file used to verify regsvc")
+	setCmd.Flags().String("cert", "", "client certificate file")
+	setCmd.Flags().String("cert-key", "", "client certificate private key file")
+	setCmd.Flags().Bool("insecure-skip-verify", false, "do not verify the certificate of regsvc")
+	setCmd.Flags().Bool("use", false, "make it the current context")
+
+	deleteCmd := &cobra.Command{
+		Use:   "delete-context <name>",
+		Short: "regctl config delete-context <name>",
+		Long:  "delete the context called name",
+		Args:  cobra.ExactArgs(1),
+		Run: withConfig(func(cmd *cobra.Command, args []string, cfg *config) error {
+			if _, ok := cfg.Contexts[args[0]]; !ok {
+				return errors.Wrap(errUnknownContext, errors.New(args[0]))
+			}
+
+			delete(cfg.Contexts, args[0])
+			if cfg.CurrentContext == args[0] {
+				cfg.CurrentContext = ""
+			}
+
+			return nil
+		}),
+	}
+
+	configCmd.AddCommand(getCmd, useCmd, setCmd, deleteCmd)
+
+	return configCmd
+}
+
+// withConfig runs fn with the config file and saves the changes fn makes
+func withConfig(fn func(cmd *cobra.Command, args []string, cfg *config) error) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		path, err := configPath()
+		if err != nil {
+			logError(err)
+			os.Exit(1)
+		}
+
+		cfg, err := loadConfig(path)
+		if err != nil {
+			logError(err)
+			os.Exit(1)
+		}
+
+		before := fmt.Sprintf("%+v", cfg)
+		if err := fn(cmd, args, &cfg); err != nil {
+			logError(err)
+			os.Exit(1)
+		}
+
+		if fmt.Sprintf("%+v", cfg) == before {
+			return
+		}
+
+		if err := cfg.save(path); err != nil {
+			logError(err)
+			os.Exit(1)
+		}
+
+		logOK()
+	}
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/piusalfred/registry/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigPath(t *testing.T) {
+	cfgFile = "/etc/regctl.yaml"
+	path, err := configPath()
+	require.Nil(t, err)
+	assert.Equal(t, cfgFile, path)
+
+	cfgFile = ""
+	path, err = configPath()
+	require.Nil(t, err)
+	assert.Equal(t, ".regctl.yaml", filepath.Base(path))
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	cfg, err := loadConfig(filepath.Join(dir, "missing.yaml"))
+	require.Nil(t, err, "missing config file")
+	assert.Equal(t, config{}, cfg)
+
+	path := filepath.Join(dir, "regctl.yaml")
+	data := `current-context: prod
+contexts:
+  prod:
+    server: https://registry.example.com
+    api-key: secret
+    tls:
+      ca: ca.pem
+  dev:
+    server: http://localhost:8080
+`
+	require.Nil(t, ioutil.WriteFile(path, []byte(data), 0600))
+
+	cfg, err = loadConfig(path)
+	require.Nil(t, err)
+	assert.Equal(t, config{
+		CurrentContext: "prod",
+		Contexts: map[string]regctlContext{
+			"prod": {Server: "https://registry.example.com", APIKey: "secret", TLS: tlsConfig{CA: "ca.pem"}},
+			"dev":  {Server: "http://localhost:8080"},
+		},
+	}, cfg)
+
+	require.Nil(t, ioutil.WriteFile(path, []byte("contexts: [prod"), 0600))
+	_, err = loadConfig(path)
+	assert.NotNil(t, err, "invalid config file")
+}
+
+func TestSaveConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "regctl.yaml")
+
+	cfg := config{
+		CurrentContext: "prod",
+		Contexts: map[string]regctlContext{
+			"prod": {Server: "https://registry.example.com", APIKey: "secret", TLS: tlsConfig{InsecureSkipVerify: true}},
+		},
+	}
+	require.Nil(t, cfg.save(path))
+
+	info, err := os.Stat(path)
+	require.Nil(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "config file readable by others")
+
+	saved, err := loadConfig(path)
+	require.Nil(t, err)
+	assert.Equal(t, cfg, saved)
+
+	//saving again replaces the file and leaves no temporary files behind
+	cfg.CurrentContext = ""
+	require.Nil(t, cfg.save(path))
+
+	saved, err = loadConfig(path)
+	require.Nil(t, err)
+	assert.Equal(t, cfg, saved)
+
+	files, err := ioutil.ReadDir(filepath.Dir(path))
+	require.Nil(t, err)
+	assert.Len(t, files, 1)
+}
+
+func TestTLSClient(t *testing.T) {
+	dir := t.TempDir()
+
+	invalid := filepath.Join(dir, "invalid.pem")
+	require.Nil(t, ioutil.WriteFile(invalid, []byte("not a certificate"), 0600))
+
+	client, err := tlsConfig{}.client()
+	require.Nil(t, err)
+	assert.Nil(t, client, "client created without tls settings")
+
+	client, err = tlsConfig{InsecureSkipVerify: true}.client()
+	require.Nil(t, err)
+	require.NotNil(t, client)
+	assert.True(t, client.Transport.(*http.Transport).TLSClientConfig.InsecureSkipVerify)
+
+	_, err = tlsConfig{CA: invalid}.client()
+	assert.True(t, errors.Contains(err, errInvalidCA), "%v", err)
+
+	_, err = tlsConfig{CA: filepath.Join(dir, "missing.pem")}.client()
+	assert.NotNil(t, err, "missing CA file")
+
+	_, err = tlsConfig{Cert: invalid, Key: invalid}.client()
+	assert.NotNil(t, err, "invalid client certificate")
+}
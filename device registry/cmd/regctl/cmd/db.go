@@ -1,15 +1,12 @@
 package cmd
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	_ "github.com/lib/pq"
+	"github.com/piusalfred/registry/postgres"
 	"github.com/spf13/cobra"
-	"io/ioutil"
-	"log"
 	"os"
-	"path/filepath"
-	"strings"
 )
 
 var (
@@ -42,14 +39,9 @@ func NewDBCmd() *cobra.Command {
 	initCmd := &cobra.Command{
 		Use:   "init",
 		Short: "regctl db init",
-		Long:  "initialize databases for regsvc",
+		Long:  "create the tables of regsvc and their seed rows and migrate the tables of an older schema, existing rows are kept",
 		Run: func(cmd *cobra.Command, args []string) {
 
-			wd, err := os.Getwd()
-			if err != nil {
-				log.Println(err)
-			}
-
 			cfg := dbConfig{
 				Hostname: hostname,
 				Username: dbuser,
@@ -65,26 +57,14 @@ func NewDBCmd() *cobra.Command {
 				return
 			}
 
-			path := filepath.Join(wd, "data.sql")
-
-			c, ioErr := ioutil.ReadFile(path)
-
-			if ioErr != nil {
-				logError(ioErr)
+			//the schema and migrations regsvc applies to sqlite databases on start
+			err = postgres.New(db).Migrate(context.Background())
+			if err != nil {
+				logError(err)
+				os.Exit(1)
 				return
 			}
 
-			requests := strings.Split(string(c), ";")
-
-			for _, request := range requests {
-				_, err := db.Exec(request)
-				if err != nil {
-					logError(err)
-					os.Exit(1)
-					return
-				}
-			}
-
 		},
 	}
 
@@ -161,18 +141,23 @@ func NewDBCmd() *cobra.Command {
 		},
 	}
 
-	dbCmd.PersistentFlags().StringVar(&hostname, "hostname", "localhost", "registry db hostname")
-	dbCmd.PersistentFlags().StringVar(&dbuser, "dbuser", "postgres", "database user")
-	dbCmd.PersistentFlags().StringVar(&dbpass, "dbpass", "postgres", "database password")
-	dbCmd.PersistentFlags().StringVar(&dbname, "dbname", "postgres", "database name")
-	dbCmd.PersistentFlags().StringVar(&sslmode, "sslmode", "disable", "ssl mode")
-	dbCmd.PersistentFlags().IntVar(&dbport, "dbport", 5432, "database port")
+	addDBFlags(dbCmd)
 
 	dbCmd.AddCommand(initCmd, testCmd, pingCmd)
 
 	return dbCmd
 }
 
+// addDBFlags adds the flags of the registry postgres database to cmd
+func addDBFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&hostname, "hostname", "localhost", "registry db hostname")
+	cmd.PersistentFlags().StringVar(&dbuser, "dbuser", "postgres", "database user")
+	cmd.PersistentFlags().StringVar(&dbpass, "dbpass", "postgres", "database password")
+	cmd.PersistentFlags().StringVar(&dbname, "dbname", "postgres", "database name")
+	cmd.PersistentFlags().StringVar(&sslmode, "sslmode", "disable", "ssl mode")
+	cmd.PersistentFlags().IntVar(&dbport, "dbport", 5432, "database port")
+}
+
 type dbConfig struct {
 	Hostname string
 	Username string
@@ -25,16 +25,43 @@ func NewDeleteCmd(cli CLI) *cobra.Command {
 
 	nodesCmd.Flags().String("id", "", "node id")
 
+	typesCmd := &cobra.Command{
+		Use:   "types",
+		Short: "delete types --id <id>",
+		Long:  "delete node type by specifying id",
+		Run:   cli.TypesCmd(context.Background(), Delete),
+	}
+
+	typesCmd.Flags().Int("id", 0, "node type id")
+
+	orgsCmd := &cobra.Command{
+		Use:   "orgs",
+		Short: "delete orgs --id <id>",
+		Long:  "delete an organization that no longer owns regions or users",
+		Run:   cli.OrgsCmd(context.Background(), Delete),
+	}
+
+	orgsCmd.Flags().String("id", "", "organization id")
+
+	firmwareCmd := &cobra.Command{
+		Use:   "firmware",
+		Short: "delete firmware --id <id>",
+		Long:  "delete a firmware release no campaign rolls out",
+		Run:   cli.FirmwareCmd(context.Background(), Delete),
+	}
+
+	firmwareCmd.Flags().String("id", "", "firmware id")
+
 	deleteCmd := &cobra.Command{
 		Use:   "delete",
-		Short: "delete (users |nodes |regions) <id>",
+		Short: "delete (users |nodes |regions |types |orgs |firmware) <id>",
 		Long:  "delete by specifying id of the entity",
 		Run: func(cmd *cobra.Command, args []string) {
 			logUsage(cmd.Short)
 		},
 	}
 
-	deleteCmd.AddCommand(usersCmd, nodesCmd)
+	deleteCmd.AddCommand(usersCmd, nodesCmd, typesCmd, orgsCmd, firmwareCmd)
 
 	return deleteCmd
 }
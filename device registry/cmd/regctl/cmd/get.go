@@ -24,14 +24,91 @@ func NewGetCmd(cli CLI) *cobra.Command {
 
 	nodesCmd.Flags().String("id", "", "user id")
 
+	regionsCmd := &cobra.Command{
+		Use:     "regions",
+		Short:   "regctl get regions (--id <region-id> [--ancestors] | --latitude <lat> --longitude <long>)",
+		Long:    "get a region and its sub-regions, the ancestors of a region, or the regions whose boundary contains a location, the smallest first",
+		Example: "regctl get regions --id dsm --ancestors",
+		Run:     cli.RegionsCmd(context.Background(), Get),
+	}
+
+	regionsCmd.Flags().String("latitude", "", "latitude in decimal degrees")
+	regionsCmd.Flags().String("longitude", "", "longitude in decimal degrees")
+	regionsCmd.Flags().String("id", "", "region id")
+	regionsCmd.Flags().Bool("ancestors", false, "get the ancestors of --id instead of its sub-regions")
+
+	typesCmd := &cobra.Command{
+		Use:   "types",
+		Short: "regctl get types --id <type-id>",
+		Long:  "get a node type by specifying its id",
+		Run:   cli.TypesCmd(context.Background(), Get),
+	}
+
+	typesCmd.Flags().Int("id", 0, "node type id")
+
+	orgsCmd := &cobra.Command{
+		Use:   "orgs",
+		Short: "regctl get orgs --id <org-id>",
+		Long:  "get an organization by specifying its id",
+		Run:   cli.OrgsCmd(context.Background(), Get),
+	}
+
+	orgsCmd.Flags().String("id", "", "organization id")
+
+	firmwareCmd := &cobra.Command{
+		Use:     "firmware",
+		Short:   "regctl get firmware --id <firmware-id> [--out <path>]",
+		Long:    "get a firmware release, or save the image stored in the registry to --out",
+		Example: "regctl get firmware --id 0f3c.. --out meter-2.1.0.bin",
+		Run:     cli.FirmwareCmd(context.Background(), Get),
+	}
+
+	firmwareCmd.Flags().String("id", "", "firmware id")
+	firmwareCmd.Flags().String("out", "", "file to save the firmware image to")
+
+	campaignsCmd := &cobra.Command{
+		Use:   "campaigns",
+		Short: "regctl get campaigns --id <campaign-id> [--nodes]",
+		Long:  "get a campaign, or with --nodes the rollout status of its nodes",
+		Run:   cli.CampaignsCmd(context.Background(), Get),
+	}
+
+	campaignsCmd.Flags().String("id", "", "campaign id")
+	campaignsCmd.Flags().Bool("nodes", false, "list the rollout status of the enrolled nodes")
+
+	schemasCmd := &cobra.Command{
+		Use:     "schemas",
+		Short:   "regctl get schemas (--id <schema-id> | --node <node-id> [--kind <kind>] [--version <version>] [--validate <path>])",
+		Long:    "get a schema, or the schema of a node and validate a sample payload against it with --validate",
+		Example: "regctl get schemas --node 7f1d.. --kind telemetry --validate sample.json",
+		Run:     cli.SchemasCmd(context.Background(), Get),
+	}
+
+	schemasCmd.Flags().String("id", "", "schema id")
+	schemasCmd.Flags().StringP("node", "n", "", "node whose schema to get, its own or the one of its type")
+	schemasCmd.Flags().StringP("kind", "k", "telemetry", "telemetry or commands")
+	schemasCmd.Flags().IntP("version", "v", 0, "schema version, the latest when 0")
+	schemasCmd.Flags().String("validate", "", "sample payload to validate against the schema")
+
+	usageCmd := &cobra.Command{
+		Use:     "usage",
+		Short:   "regctl get usage (--region <region-id> | --org <org-id>)",
+		Long:    "get the users and nodes by node type of a region or an organization against its quota",
+		Example: "regctl get usage --region AA001",
+		Run:     cli.QuotasCmd(context.Background(), Get),
+	}
+
+	usageCmd.Flags().String("region", "", "region id")
+	usageCmd.Flags().String("org", "", "organization id")
+
 	getCmd := &cobra.Command{
 		Use:   "get",
-		Short: "get (users |nodes |regions) <id>",
+		Short: "get (users |nodes |regions |types |orgs |firmware |campaigns |schemas |usage) <id>",
 		Long:  "get a certain entity by specifying its id",
 		Run: func(cmd *cobra.Command, args []string) {
 			logUsage(cmd.Short)
 		},
 	}
-	getCmd.AddCommand(usersCmd, nodesCmd)
+	getCmd.AddCommand(usersCmd, nodesCmd, regionsCmd, typesCmd, orgsCmd, firmwareCmd, campaignsCmd, schemasCmd, usageCmd)
 	return getCmd
 }
@@ -28,6 +28,9 @@ func NewListCmd(cli CLI) *cobra.Command {
 		Run:   cli.UsersCmd(context.Background(), List),
 	}
 
+	usersCmd.Flags().String("region", "", "only list the users of the region")
+	usersCmd.Flags().Bool("subregions", false, "include the users of the sub-regions of --region")
+
 	// usersCmd represents the users command
 	regionsCmd := &cobra.Command{
 		Use:   "regions",
@@ -44,16 +47,74 @@ func NewListCmd(cli CLI) *cobra.Command {
 		Run:   cli.NodesCmd(context.Background(), List),
 	}
 
+	nodesCmd.Flags().String("region", "", "only list the nodes of the region")
+	nodesCmd.Flags().Bool("subregions", false, "include the nodes of the sub-regions of --region")
+
+	typesCmd := &cobra.Command{
+		Use:   "types",
+		Short: "list node types",
+		Long:  `list all node types in the catalogue`,
+		Run:   cli.TypesCmd(context.Background(), List),
+	}
+
+	claimsCmd := &cobra.Command{
+		Use:   "claims",
+		Short: "list claims [--audit]",
+		Long:  `list all claim codes, or with --audit every provisioning attempt`,
+		Run:   cli.ClaimsCmd(context.Background(), List),
+	}
+
+	claimsCmd.Flags().Bool("audit", false, "list provisioning attempts instead of claim codes")
+
+	orgsCmd := &cobra.Command{
+		Use:   "orgs",
+		Short: "list orgs",
+		Long:  `list all organizations visible to the api key in use`,
+		Run:   cli.OrgsCmd(context.Background(), List),
+	}
+
+	firmwareCmd := &cobra.Command{
+		Use:   "firmware",
+		Short: "list firmware",
+		Long:  `list all firmware releases, without their images`,
+		Run:   cli.FirmwareCmd(context.Background(), List),
+	}
+
+	campaignsCmd := &cobra.Command{
+		Use:   "campaigns",
+		Short: "list campaigns",
+		Long:  `list all campaigns with the number of their nodes by rollout status`,
+		Run:   cli.CampaignsCmd(context.Background(), List),
+	}
+
+	schemasCmd := &cobra.Command{
+		Use:   "schemas",
+		Short: "list schemas [--type <type-id>] [--node <node-id>] [--kind <kind>]",
+		Long:  `list all versions of the schemas of node types and nodes`,
+		Run:   cli.SchemasCmd(context.Background(), List),
+	}
+
+	schemasCmd.Flags().IntP("type", "t", 0, "only list the schemas of the node type")
+	schemasCmd.Flags().StringP("node", "n", "", "only list the schemas of the node")
+	schemasCmd.Flags().StringP("kind", "k", "", "only list schemas of the kind, telemetry or commands")
+
+	quotasCmd := &cobra.Command{
+		Use:   "quotas",
+		Short: "list quotas",
+		Long:  `list the quotas of the regions and organizations`,
+		Run:   cli.QuotasCmd(context.Background(), List),
+	}
+
 	listCmd := &cobra.Command{
 		Use:   "list",
-		Short: "list (users |nodes |regions )",
-		Long:  `this command list all the available (users | nodes | regions)`,
+		Short: "list (users |nodes |regions |types |claims |orgs |firmware |campaigns |schemas |quotas)",
+		Long:  `this command list all the available (users | nodes | regions | types | claims | orgs | firmware | campaigns | schemas | quotas)`,
 		Run: func(cmd *cobra.Command, args []string) {
 			logUsage(cmd.Short)
 		},
 	}
 
-	listCmd.AddCommand(usersCmd, regionsCmd, nodesCmd)
+	listCmd.AddCommand(usersCmd, regionsCmd, nodesCmd, typesCmd, claimsCmd, orgsCmd, firmwareCmd, campaignsCmd, schemasCmd, quotasCmd)
 
 	return listCmd
 }
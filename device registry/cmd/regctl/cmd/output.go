@@ -0,0 +1,572 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/piusalfred/registry"
+	"github.com/piusalfred/registry/pkg/errors"
+	"gopkg.in/yaml.v2"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+)
+
+// output formats accepted by -o
+const (
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+	outputTable = "table"
+	outputWide  = "wide"
+	outputCSV   = "csv"
+)
+
+var (
+	errUnknownOutput = errors.New("unknown output format, use json, yaml, table, wide or csv")
+	errJSONPath      = errors.New("invalid jsonpath expression")
+)
+
+// set by the persistent flags of the root command
+var (
+	outputFormat string
+	outputTmpl   string
+	outputPath   string
+)
+
+// column is a column of the table and csv output, key is the JSON name of
+// the field it shows. Wide columns are only shown by -o wide and csv.
+type column struct {
+	header string
+	key    string
+	wide   bool
+}
+
+// columns are the columns of the entities, the columns of the others are
+// their top level fields.
+var columns = map[reflect.Type][]column{
+	reflect.TypeOf(registry.User{}): {
+		{header: "ID", key: "id"},
+		{header: "NAME", key: "name"},
+		{header: "EMAIL", key: "email"},
+		{header: "GROUP", key: "group"},
+		{header: "REGION", key: "region"},
+		{header: "ORG", key: "org", wide: true},
+		{header: "CREATED", key: "created", wide: true},
+	},
+	reflect.TypeOf(registry.Node{}): {
+		{header: "UUID", key: "uuid"},
+		{header: "NAME", key: "name"},
+		{header: "ADDR", key: "addr"},
+		{header: "TYPE", key: "type"},
+		{header: "REGION", key: "region"},
+		{header: "LATITUDE", key: "latitude", wide: true},
+		{header: "LONGITUDE", key: "longitude", wide: true},
+		{header: "MASTER", key: "master", wide: true},
+		{header: "FIRMWARE", key: "firmware", wide: true},
+		{header: "LABELS", key: "labels", wide: true},
+		{header: "ORG", key: "org", wide: true},
+		{header: "CREATED", key: "created", wide: true},
+	},
+	reflect.TypeOf(registry.Region{}): {
+		{header: "ID", key: "id"},
+		{header: "NAME", key: "name"},
+		{header: "DESCRIPTION", key: "description"},
+		{header: "PARENT", key: "parent"},
+		{header: "ORG", key: "org", wide: true},
+	},
+	reflect.TypeOf(registry.Firmware{}): {
+		{header: "ID", key: "id"},
+		{header: "VERSION", key: "version"},
+		{header: "TYPE", key: "type"},
+		{header: "URL", key: "url"},
+		{header: "CHECKSUM", key: "checksum", wide: true},
+		{header: "ORG", key: "org", wide: true},
+		{header: "CREATED", key: "created", wide: true},
+	},
+	reflect.TypeOf(registry.Campaign{}): {
+		{header: "ID", key: "id"},
+		{header: "NAME", key: "name"},
+		{header: "FIRMWARE", key: "firmware"},
+		{header: "ROLLOUT", key: "rollout"},
+		{header: "STATUS", key: "status"},
+		{header: "TARGET", key: "target", wide: true},
+		{header: "ORG", key: "org", wide: true},
+		{header: "CREATED", key: "created", wide: true},
+	},
+	reflect.TypeOf(registry.Schema{}): {
+		{header: "ID", key: "id"},
+		{header: "KIND", key: "kind"},
+		{header: "TYPE", key: "type"},
+		{header: "NODE", key: "node"},
+		{header: "VERSION", key: "version"},
+		{header: "ORG", key: "org", wide: true},
+		{header: "CREATED", key: "created", wide: true},
+	},
+	reflect.TypeOf(registry.Quota{}): {
+		{header: "SCOPE", key: "scope"},
+		{header: "ID", key: "id"},
+		{header: "USERS", key: "users"},
+		{header: "NODES", key: "nodes"},
+		{header: "ORG", key: "org", wide: true},
+	},
+	reflect.TypeOf(registry.Event{}): {
+		{header: "TIMESTAMP", key: "timestamp"},
+		{header: "NAME", key: "name"},
+		{header: "SUBJECT", key: "subject"},
+		{header: "REGION", key: "region"},
+		{header: "ACTOR", key: "actor"},
+		{header: "UUID", key: "uuid", wide: true},
+		{header: "ORG", key: "org", wide: true},
+		{header: "RESULT", key: "result", wide: true},
+	},
+}
+
+// logOutput prints v in the format selected by -o, --template or --jsonpath
+func logOutput(v interface{}) {
+	if err := printOutput(os.Stdout, v); err != nil {
+		logError(err)
+	}
+}
+
+func printOutput(w io.Writer, v interface{}) error {
+	switch {
+	case outputTmpl != "":
+		return printTemplate(w, outputTmpl, v)
+	case outputPath != "":
+		return printJSONPath(w, outputPath, v)
+	}
+
+	switch outputFormat {
+	case "", outputJSON:
+		return writeJSON(w, v)
+
+	case outputYAML:
+		data, err := generic(v)
+		if err != nil {
+			return err
+		}
+
+		out, err := yaml.Marshal(data)
+		if err != nil {
+			return err
+		}
+
+		_, err = w.Write(out)
+		return err
+
+	case outputTable, outputWide:
+		tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+		if err := printTable(tw, v, true); err != nil {
+			return err
+		}
+		return tw.Flush()
+
+	case outputCSV:
+		cw := csv.NewWriter(w)
+		if err := printCSV(cw, v, true); err != nil {
+			return err
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+
+	return errUnknownOutput
+}
+
+// eventPrinter returns the function printing the events received by watch,
+// one line per event in the json, table, wide and csv formats.
+func eventPrinter(w io.Writer) (func(registry.Event), error) {
+	fail := func(err error) {
+		if err != nil {
+			logError(err)
+		}
+	}
+
+	switch {
+	case outputTmpl != "" || outputPath != "":
+		return func(event registry.Event) {
+			fail(printOutput(w, event))
+		}, nil
+	}
+
+	switch outputFormat {
+	case "", outputJSON:
+		return func(event registry.Event) {
+			b, err := json.Marshal(event)
+			if err != nil {
+				fail(err)
+				return
+			}
+			fmt.Fprintln(w, string(b))
+		}, nil
+
+	case outputYAML:
+		return func(event registry.Event) {
+			fmt.Fprintln(w, "---")
+			fail(printOutput(w, event))
+		}, nil
+
+	case outputTable, outputWide:
+		//the widths of the rows to come are unknown, cells are padded to a
+		//minimum width instead
+		tw := tabwriter.NewWriter(w, 12, 0, 3, ' ', 0)
+		header := true
+		return func(event registry.Event) {
+			fail(printTable(tw, event, header))
+			fail(tw.Flush())
+			header = false
+		}, nil
+
+	case outputCSV:
+		cw := csv.NewWriter(w)
+		header := true
+		return func(event registry.Event) {
+			fail(printCSV(cw, event, header))
+			cw.Flush()
+			header = false
+		}, nil
+	}
+
+	return nil, errUnknownOutput
+}
+
+func printTable(w io.Writer, v interface{}, header bool) error {
+	cols := columnsOf(v, outputFormat == outputWide)
+
+	rows, err := rowsOf(v, cols)
+	if err != nil {
+		return err
+	}
+
+	if header {
+		headers := make([]string, len(cols))
+		for i, c := range cols {
+			headers[i] = c.header
+		}
+		fmt.Fprintln(w, strings.Join(headers, "\t"))
+	}
+
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+
+	return nil
+}
+
+func printCSV(w *csv.Writer, v interface{}, header bool) error {
+	cols := columnsOf(v, true)
+
+	rows, err := rowsOf(v, cols)
+	if err != nil {
+		return err
+	}
+
+	if header {
+		headers := make([]string, len(cols))
+		for i, c := range cols {
+			headers[i] = strings.ToLower(c.header)
+		}
+		if err := w.Write(headers); err != nil {
+			return err
+		}
+	}
+
+	return w.WriteAll(rows)
+}
+
+func printTemplate(w io.Writer, text string, v interface{}) error {
+	t, err := template.New("output").Funcs(template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+	}).Parse(text)
+	if err != nil {
+		return err
+	}
+
+	data, err := generic(v)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return err
+	}
+
+	if buf.Len() > 0 && buf.Bytes()[buf.Len()-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+func printJSONPath(w io.Writer, expr string, v interface{}) error {
+	data, err := generic(v)
+	if err != nil {
+		return err
+	}
+
+	values, err := jsonPath(expr, data)
+	if err != nil {
+		return err
+	}
+
+	cells := make([]string, len(values))
+	for i, value := range values {
+		cells[i] = cell(value)
+	}
+
+	_, err = fmt.Fprintln(w, strings.Join(cells, " "))
+	return err
+}
+
+// columnsOf returns the columns of the entities of v, v is an entity or a
+// slice of entities.
+func columnsOf(v interface{}, wide bool) []column {
+	t := reflect.TypeOf(v)
+	for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice) {
+		t = t.Elem()
+	}
+
+	cols, ok := columns[t]
+	if !ok {
+		cols = fieldColumns(t)
+	}
+
+	if wide {
+		return cols
+	}
+
+	var narrow []column
+	for _, c := range cols {
+		if !c.wide {
+			narrow = append(narrow, c)
+		}
+	}
+
+	return narrow
+}
+
+// fieldColumns returns a column per top level field of the struct t
+func fieldColumns(t reflect.Type) []column {
+	if t == nil || t.Kind() != reflect.Struct {
+		return []column{{header: "VALUE"}}
+	}
+
+	var cols []column
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		key := strings.Split(f.Tag.Get("json"), ",")[0]
+		if key == "-" {
+			continue
+		}
+		if key == "" {
+			key = f.Name
+		}
+
+		cols = append(cols, column{header: strings.ToUpper(key), key: key})
+	}
+
+	return cols
+}
+
+// rowsOf returns the cells of the columns of the entities of v
+func rowsOf(v interface{}, cols []column) ([][]string, error) {
+	data, err := generic(v)
+	if err != nil {
+		return nil, err
+	}
+
+	items, ok := data.([]interface{})
+	if !ok && data != nil {
+		items = []interface{}{data}
+	}
+
+	rows := make([][]string, 0, len(items))
+	for _, item := range items {
+		row := make([]string, len(cols))
+		for i, c := range cols {
+			if m, ok := item.(map[string]interface{}); ok && c.key != "" {
+				row[i] = cell(m[c.key])
+				continue
+			}
+			row[i] = cell(item)
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// generic returns v as decoded from its JSON encoding, so that fields are
+// known by their JSON names.
+func generic(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	var data interface{}
+	if err := dec.Decode(&data); err != nil {
+		return nil, err
+	}
+
+	return yamlSafe(data), nil
+}
+
+// yamlSafe turns the json.Numbers of data into ints or floats, yaml would
+// quote them as strings otherwise.
+func yamlSafe(data interface{}) interface{} {
+	switch d := data.(type) {
+	case json.Number:
+		if n, err := d.Int64(); err == nil {
+			return n
+		}
+		if f, err := d.Float64(); err == nil {
+			return f
+		}
+		return d.String()
+	case []interface{}:
+		for i := range d {
+			d[i] = yamlSafe(d[i])
+		}
+	case map[string]interface{}:
+		for k := range d {
+			d[k] = yamlSafe(d[k])
+		}
+	}
+
+	return data
+}
+
+// cell formats a value decoded by generic
+func cell(v interface{}) string {
+	switch c := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return c
+	case []interface{}:
+		cells := make([]string, len(c))
+		for i := range c {
+			cells[i] = cell(c[i])
+		}
+		return strings.Join(cells, ",")
+	case map[string]interface{}:
+		b, _ := json.Marshal(c)
+		return string(b)
+	}
+
+	return fmt.Sprint(v)
+}
+
+// jsonPath evaluates a subset of JSONPath on data: fields (.name or
+// ['name']), indexes ([0], [-1]) and wildcards ([*] or .*). The expression
+// may be wrapped in braces and start with $, like {[*].uuid} or $[0].name.
+func jsonPath(expr string, data interface{}) ([]interface{}, error) {
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, "{") && strings.HasSuffix(expr, "}") {
+		expr = expr[1 : len(expr)-1]
+	}
+	expr = strings.TrimPrefix(expr, "$")
+
+	values := []interface{}{data}
+	for expr != "" {
+		var step string
+		switch {
+		case strings.HasPrefix(expr, "["):
+			end := strings.Index(expr, "]")
+			if end < 0 {
+				return nil, errJSONPath
+			}
+			step, expr = expr[1:end], expr[end+1:]
+
+		case strings.HasPrefix(expr, "."):
+			expr = expr[1:]
+			end := strings.IndexAny(expr, ".[")
+			if end < 0 {
+				end = len(expr)
+			}
+			step, expr = expr[:end], expr[end:]
+			if step == "" {
+				return nil, errJSONPath
+			}
+			if step != "*" {
+				step = "'" + step + "'"
+			}
+
+		default:
+			return nil, errJSONPath
+		}
+
+		next, err := jsonPathStep(step, values)
+		if err != nil {
+			return nil, err
+		}
+		values = next
+	}
+
+	return values, nil
+}
+
+func jsonPathStep(step string, values []interface{}) ([]interface{}, error) {
+	var next []interface{}
+
+	for _, value := range values {
+		switch {
+		case step == "*":
+			switch v := value.(type) {
+			case []interface{}:
+				next = append(next, v...)
+			case map[string]interface{}:
+				keys := make([]string, 0, len(v))
+				for k := range v {
+					keys = append(keys, k)
+				}
+				sort.Strings(keys)
+				for _, k := range keys {
+					next = append(next, v[k])
+				}
+			}
+
+		case len(step) >= 2 && (step[0] == '\'' || step[0] == '"') && step[len(step)-1] == step[0]:
+			if m, ok := value.(map[string]interface{}); ok {
+				if v, ok := m[step[1:len(step)-1]]; ok {
+					next = append(next, v)
+				}
+			}
+
+		default:
+			i, err := strconv.Atoi(step)
+			if err != nil {
+				return nil, errJSONPath
+			}
+			if l, ok := value.([]interface{}); ok {
+				if i < 0 {
+					i += len(l)
+				}
+				if i >= 0 && i < len(l) {
+					next = append(next, l[i])
+				}
+			}
+		}
+	}
+
+	return next, nil
+}
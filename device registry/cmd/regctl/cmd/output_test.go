@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"text/tabwriter"
+
+	"github.com/piusalfred/registry"
+	"github.com/piusalfred/registry/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var nodes = []registry.Node{
+	{UUID: "n1", Name: "meter", Addr: "6F-5E-42-8B-36-B9", Type: 1, Region: "AA001", Labels: registry.Labels{"site": "lab"}},
+	{UUID: "n2", Name: "pump", Addr: "3A-3E-71-A3-96-F4", Type: 2, Region: "AA002", Master: "n1"},
+}
+
+// withOutput sets the output flags for the duration of the test
+func withOutput(t *testing.T, format, tmpl, path string) {
+	outputFormat, outputTmpl, outputPath = format, tmpl, path
+	t.Cleanup(func() { outputFormat, outputTmpl, outputPath = "", "", "" })
+}
+
+func TestJSONPath(t *testing.T) {
+	data, err := generic(nodes)
+	require.Nil(t, err)
+
+	cases := []struct {
+		expr   string
+		values []interface{}
+		err    error
+	}{
+		{expr: "{[*].uuid}", values: []interface{}{"n1", "n2"}},
+		{expr: "$[0].name", values: []interface{}{"meter"}},
+		{expr: "[-1]['addr']", values: []interface{}{"3A-3E-71-A3-96-F4"}},
+		{expr: "[0].labels.*", values: []interface{}{"lab"}},
+		{expr: "[*].master", values: []interface{}{"n1"}},
+		{expr: "[2].uuid", values: nil},
+		{expr: "[0].unknown", values: nil},
+		{expr: "[0", err: errJSONPath},
+		{expr: "[x]", err: errJSONPath},
+		{expr: "uuid", err: errJSONPath},
+		{expr: "[0]..uuid", err: errJSONPath},
+	}
+
+	for _, tc := range cases {
+		values, err := jsonPath(tc.expr, data)
+		if tc.err != nil {
+			assert.True(t, errors.Contains(err, tc.err), "%s: %v", tc.expr, err)
+			continue
+		}
+
+		assert.Nil(t, err, tc.expr)
+		assert.Equal(t, tc.values, values, tc.expr)
+	}
+}
+
+func TestPrintTable(t *testing.T) {
+	cases := []struct {
+		desc   string
+		format string
+		v      interface{}
+		header bool
+		out    string
+	}{
+		{
+			desc:   "entities",
+			format: outputTable,
+			v:      nodes,
+			header: true,
+			out: "UUID   NAME    ADDR                TYPE   REGION\n" +
+				"n1     meter   6F-5E-42-8B-36-B9   1      AA001\n" +
+				"n2     pump    3A-3E-71-A3-96-F4   2      AA002\n",
+		},
+		{
+			desc:   "wide columns",
+			format: outputWide,
+			v:      nodes[1],
+			header: true,
+			out: "UUID   NAME   ADDR                TYPE   REGION   LATITUDE   LONGITUDE   MASTER   FIRMWARE   LABELS   ORG   CREATED\n" +
+				"n2     pump   3A-3E-71-A3-96-F4   2      AA002                           n1                                 \n",
+		},
+		{
+			desc:   "top level fields of other values",
+			format: outputTable,
+			v:      []contextRow{{Current: "*", Name: "prod", Server: "https://registry", APIKey: true}},
+			header: true,
+			out: "CURRENT   NAME   SERVER             API_KEY   TLS\n" +
+				"*         prod   https://registry   true      false\n",
+		},
+		{
+			desc:   "without a header",
+			format: outputTable,
+			v:      nodes[0],
+			out:    "n1   meter   6F-5E-42-8B-36-B9   1   AA001\n",
+		},
+		{
+			desc:   "values that are not structs",
+			format: outputTable,
+			v:      []string{"AA001", "AA002"},
+			header: true,
+			out:    "VALUE\nAA001\nAA002\n",
+		},
+	}
+
+	for _, tc := range cases {
+		withOutput(t, tc.format, "", "")
+
+		var buf bytes.Buffer
+		tw := tabwriter.NewWriter(&buf, 0, 0, 3, ' ', 0)
+		require.Nil(t, printTable(tw, tc.v, tc.header), tc.desc)
+		require.Nil(t, tw.Flush(), tc.desc)
+
+		assert.Equal(t, tc.out, buf.String(), tc.desc)
+	}
+}
+
+func TestPrintCSV(t *testing.T) {
+	cases := []struct {
+		desc   string
+		v      interface{}
+		header bool
+		out    string
+	}{
+		{
+			desc:   "wide columns are always printed",
+			v:      nodes,
+			header: true,
+			out: "uuid,name,addr,type,region,latitude,longitude,master,firmware,labels,org,created\n" +
+				"n1,meter,6F-5E-42-8B-36-B9,1,AA001,,,,,\"{\"\"site\"\":\"\"lab\"\"}\",,\n" +
+				"n2,pump,3A-3E-71-A3-96-F4,2,AA002,,,n1,,,,\n",
+		},
+		{
+			desc: "without a header",
+			v:    registry.Region{ID: "AA001", Name: "CoICT", Desc: "CoICT, Kijitonyama"},
+			out:  "AA001,CoICT,\"CoICT, Kijitonyama\",,\n",
+		},
+	}
+
+	for _, tc := range cases {
+		var buf bytes.Buffer
+		cw := csv.NewWriter(&buf)
+		require.Nil(t, printCSV(cw, tc.v, tc.header), tc.desc)
+		cw.Flush()
+
+		assert.Equal(t, tc.out, buf.String(), tc.desc)
+	}
+}
+
+func TestPrintOutput(t *testing.T) {
+	region := registry.Region{ID: "AA001", Name: "CoICT"}
+
+	cases := []struct {
+		desc   string
+		format string
+		tmpl   string
+		path   string
+		out    string
+		err    error
+	}{
+		{desc: "yaml", format: outputYAML, out: "description: \"\"\nid: AA001\nname: CoICT\n"},
+		{desc: "template", tmpl: "{{.id}} {{json .name}}", out: "AA001 \"CoICT\"\n"},
+		{desc: "jsonpath", path: "{.name}", out: "CoICT\n"},
+		{desc: "unknown format", format: "xml", err: errUnknownOutput},
+	}
+
+	for _, tc := range cases {
+		withOutput(t, tc.format, tc.tmpl, tc.path)
+
+		var buf bytes.Buffer
+		err := printOutput(&buf, region)
+		if tc.err != nil {
+			assert.True(t, errors.Contains(err, tc.err), "%s: %v", tc.desc, err)
+			continue
+		}
+
+		assert.Nil(t, err, tc.desc)
+		assert.Equal(t, tc.out, buf.String(), tc.desc)
+	}
+
+	//json is written to w, not to the standard output
+	withOutput(t, outputJSON, "", "")
+
+	var buf bytes.Buffer
+	require.Nil(t, printOutput(&buf, region))
+
+	var got registry.Region
+	require.Nil(t, json.Unmarshal(buf.Bytes(), &got), buf.String())
+	assert.Equal(t, region, got)
+}
@@ -0,0 +1,74 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"github.com/spf13/cobra"
+)
+
+// reports of regctl report
+const (
+	reportInventory = "inventory"
+	reportGrowth    = "growth"
+	reportOrphans   = "orphans"
+)
+
+func NewReportCmd(cli CLI) *cobra.Command {
+
+	reportCmd := &cobra.Command{
+		Use:   "report",
+		Short: "report [command]",
+		Long:  `statistics of the nodes of the registry, use -o table or -o csv to print them as a table or a csv file`,
+	}
+
+	inventoryCmd := &cobra.Command{
+		Use:     "inventory",
+		Short:   "inventory [--region <region> [--subregions]] [--by region,type,status]",
+		Long:    `count the nodes by region, node type and status, status is pending, active, expired or revoked depending on the certificates of the node`,
+		Example: "regctl report inventory --by region,status -o table",
+		Run:     cli.ReportCmd(context.Background(), reportInventory),
+	}
+
+	inventoryCmd.Flags().StringSlice("by", nil, "dimensions to count the nodes by, region, type or status, all of them by default")
+
+	growthCmd := &cobra.Command{
+		Use:     "growth",
+		Short:   "growth [--region <region> [--subregions]] [--period day|week|month] [--since <date>]",
+		Long:    `the nodes added in each period since a date, from the first node by default, and the total at the end of each period`,
+		Example: "regctl report growth --period week --since 2020-01-01 -o csv",
+		Run:     cli.ReportCmd(context.Background(), reportGrowth),
+	}
+
+	growthCmd.Flags().String("period", "month", "length of the periods, day, week or month")
+	growthCmd.Flags().String("since", "", "first day of the report, like 2006-01-02")
+
+	orphansCmd := &cobra.Command{
+		Use:     "orphans",
+		Short:   "orphans [--region <region> [--subregions]]",
+		Long:    `list the nodes whose master node does not exist`,
+		Example: "regctl report orphans --region AA001 -o table",
+		Run:     cli.ReportCmd(context.Background(), reportOrphans),
+	}
+
+	for _, cmd := range []*cobra.Command{inventoryCmd, growthCmd, orphansCmd} {
+		cmd.Flags().String("region", "", "region of the nodes, all the regions by default")
+		cmd.Flags().Bool("subregions", false, "include the nodes of the sub-regions of --region")
+		reportCmd.AddCommand(cmd)
+	}
+
+	return reportCmd
+}
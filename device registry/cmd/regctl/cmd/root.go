@@ -16,19 +16,25 @@ limitations under the License.
 package cmd
 
 import (
-	"fmt"
 	"github.com/spf13/cobra"
 	"os"
+)
+
+const (
+	defaultAddress = "http://localhost"
+	defaultPort    = ":8080"
 
-	"github.com/mitchellh/go-homedir"
-	"github.com/spf13/viper"
+	// offline annotates the commands that do not talk to regsvc
+	offline = "offline"
 )
 
 var cfgFile string
+var contextName string
 var address string
 var port string
 var uuid string
 var password string
+var apiKey string
 
 var longDesc = `
 regctl is a swiss knife command-line tool for igrid system. It is mostly
@@ -40,7 +46,7 @@ used for management of nodes, users and regions.
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "regctl",
-	Short: "regctl [add |get |list |delete |update] [users |nodes |regions]",
+	Short: "regctl [add |get |list |delete |update] [users |nodes |regions |types |orgs]",
 	Long:  longDesc,
 	/*Run: func(cmd *cobra.Command, args []string) {
 		logUsage(cmd.Short)
@@ -60,23 +66,36 @@ func Execute() {
 
 func init() {
 
-	cobra.OnInitialize(initConfig)
+	//config commands must work when the current context cannot be used
+	rootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		for c := cmd; c != nil; c = c.Parent() {
+			if c.Annotations[offline] != "" {
+				return
+			}
+		}
+		initConfig()
+	}
 
 	// Here you will define your flags and configuration settings.
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
 
-	rootCmd.PersistentFlags().StringVar(&address, "address", "http://localhost", "the address of regsvc")
+	rootCmd.PersistentFlags().StringVar(&address, "address", defaultAddress, "the address of regsvc, overrides the context")
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.regctl.yaml)")
-	rootCmd.PersistentFlags().StringVar(&port, "port", ":8080", "regsvc port")
+	rootCmd.PersistentFlags().StringVar(&contextName, "context", "", "context of the config file to use instead of the current one")
+	rootCmd.PersistentFlags().StringVar(&port, "port", defaultPort, "regsvc port, overrides the context")
 	rootCmd.PersistentFlags().StringVar(&uuid, "uuid", "", "user unique identifier")
 	rootCmd.PersistentFlags().StringVar(&password, "password", "", "user password")
+	rootCmd.PersistentFlags().MarkDeprecated("uuid", "store an api key in a context with regctl config set-context")
+	rootCmd.PersistentFlags().MarkDeprecated("password", "store an api key in a context with regctl config set-context")
+	rootCmd.PersistentFlags().StringVar(&apiKey, "key", os.Getenv("REGCTL_API_KEY"), "api key (default is $REGCTL_API_KEY)")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", outputJSON, "output format, one of json|yaml|table|wide|csv")
+	rootCmd.PersistentFlags().StringVar(&outputTmpl, "template", "", "go template applied to the output, e.g. '{{range .}}{{.id}} {{end}}'")
+	rootCmd.PersistentFlags().StringVar(&outputPath, "jsonpath", "", "jsonpath expression selecting from the output, e.g. '{[*].uuid}'")
 
-	cli, err := cli(address, port)
-
-	if err != nil {
-		logError(err)
-	}
+	//the endpoints are created by initConfig, once the flags and the config
+	//file have been read
+	cli := cli()
 
 	addCmd := NewAddCmd(cli)
 	listCmd := NewListCmd(cli)
@@ -84,32 +103,58 @@ func init() {
 	deleteCmd := NewDeleteCmd(cli)
 	updateCmd := NewUpdateCmd(cli)
 	dbCmd := NewDBCmd()
-
-	rootCmd.AddCommand(addCmd, listCmd, getCmd, deleteCmd, updateCmd, dbCmd)
+	certsCmd := NewCertsCmd(cli)
+	apiKeysCmd := NewAPIKeysCmd(cli)
+	backupCmd := NewBackupCmd()
+	restoreCmd := NewRestoreCmd()
+	watchCmd := NewWatchCmd(cli)
+	reportCmd := NewReportCmd(cli)
+	configCmd := NewConfigCmd()
+
+	rootCmd.AddCommand(addCmd, listCmd, getCmd, deleteCmd, updateCmd, dbCmd, certsCmd, apiKeysCmd,
+		backupCmd, restoreCmd, watchCmd, reportCmd, configCmd)
 }
 
-// initConfig reads in config file and ENV variables if set.
+// initConfig resolves the regsvc instance and the api key of the command,
+// flags override the context selected by --context or the current context
+// of the config file.
 func initConfig() {
-	if cfgFile != "" {
-		// Use config file from the flag.
-		viper.SetConfigFile(cfgFile)
-	} else {
-		// Find home directory.
-		home, err := homedir.Dir()
-		if err != nil {
-			logError(err)
-			os.Exit(1)
-		}
+	path, err := configPath()
+	if err != nil {
+		logError(err)
+		os.Exit(1)
+	}
 
-		// Search config in home directory with name ".regctl" (without extension).
-		viper.AddConfigPath(home)
-		viper.SetConfigName(".regctl")
+	cfg, err := loadConfig(path)
+	if err != nil {
+		logError(err)
+		os.Exit(1)
 	}
 
-	viper.AutomaticEnv() // read in environment variables that match
+	name := contextName
+	if name == "" {
+		name = cfg.CurrentContext
+	}
+
+	current, ok := cfg.Contexts[name]
+	if contextName != "" && !ok {
+		logError(errUnknownContext)
+		os.Exit(1)
+	}
 
-	// If a config file is found, read it in.
-	if err := viper.ReadInConfig(); err == nil {
-		fmt.Println("Using config file:", viper.ConfigFileUsed())
+	flags := rootCmd.PersistentFlags()
+	server := address + port
+	if ok && !flags.Changed("address") && !flags.Changed("port") {
+		server = current.Server
+	}
+
+	//--key and $REGCTL_API_KEY win over the key of the context
+	if ok && apiKey == "" {
+		apiKey = current.APIKey
+	}
+
+	if err := connect(server, current.TLS); err != nil {
+		logError(err)
+		os.Exit(1)
 	}
 }
@@ -19,6 +19,59 @@ func NewUpdateCmd(cli CLI) *cobra.Command {
 	usersCmd.Flags().IntP("group", "g", 0, "new user group")
 	usersCmd.Flags().StringP("region", "r", "", "new region-id")
 
+	typesCmd := &cobra.Command{
+		Use:     "types",
+		Short:   "update types (name | description | capabilities)",
+		Long:    "used to update the name, description or capabilities of a node type",
+		Example: "regctl update types --id 4 -n meter -c publish,subscribe",
+		Run:     cli.TypesCmd(context.Background(), Update),
+	}
+
+	typesCmd.Flags().Int("id", 0, "node type id")
+	typesCmd.Flags().StringP("name", "n", "", "node type name")
+	typesCmd.Flags().StringP("desc", "d", "", "node type description")
+	typesCmd.Flags().StringSliceP("caps", "c", nil, "capabilities (publish, subscribe, command)")
+
+	regionsCmd := &cobra.Command{
+		Use:     "regions",
+		Short:   "update regions --id <region-id> --parent <region-id>",
+		Long:    "move a region under another region, an empty parent makes it a root region",
+		Example: "regctl update regions --id kariakoo --parent ilala",
+		Run:     cli.RegionsCmd(context.Background(), Update),
+	}
+
+	regionsCmd.Flags().String("id", "", "region id")
+	regionsCmd.Flags().String("parent", "", "new parent region id")
+
+	campaignsCmd := &cobra.Command{
+		Use:   "campaigns",
+		Short: "update campaigns --id <campaign-id> (--rollout <percent> | --node <node-id> --status <status> [--err <message>])",
+		Long: `advance the rollout of a campaign to a larger percentage of its nodes, or
+report the rollout status of a node: pending, downloading, applied or failed`,
+		Example: "regctl update campaigns --id 7d1a.. --rollout 50",
+		Run:     cli.CampaignsCmd(context.Background(), Update),
+	}
+
+	campaignsCmd.Flags().String("id", "", "campaign id")
+	campaignsCmd.Flags().Int("rollout", 0, "percentage of the targeted nodes to enroll")
+	campaignsCmd.Flags().String("node", "", "node reporting its rollout status")
+	campaignsCmd.Flags().String("status", "", "rollout status of --node")
+	campaignsCmd.Flags().String("err", "", "why the node failed to apply the firmware")
+
+	quotasCmd := &cobra.Command{
+		Use:   "quotas",
+		Short: "update quotas (--region <region-id> | --org <org-id>) [--users <max>] [--nodes <type-id>=<max>,..]",
+		Long: `replace the quota of a region or of an organization, limits left out or
+set to 0 are unlimited`,
+		Example: "regctl update quotas --region AA001 --users 20 --nodes 1=500,3=10",
+		Run:     cli.QuotasCmd(context.Background(), Update),
+	}
+
+	quotasCmd.Flags().String("region", "", "region the quota limits")
+	quotasCmd.Flags().String("org", "", "organization the quota limits")
+	quotasCmd.Flags().Int("users", 0, "maximum number of users")
+	quotasCmd.Flags().StringToInt("nodes", nil, "maximum number of nodes by node type id")
+
 	updateCmd := &cobra.Command{
 		Use:     "update",
 		Short:   "update (user |node |region)",
@@ -29,7 +82,7 @@ func NewUpdateCmd(cli CLI) *cobra.Command {
 		},
 	}
 
-	updateCmd.AddCommand(usersCmd)
+	updateCmd.AddCommand(usersCmd, regionsCmd, typesCmd, campaignsCmd, quotasCmd)
 
 	return updateCmd
 }
@@ -5,24 +5,37 @@ import (
 	"fmt"
 	"github.com/fatih/color"
 	prettyjson "github.com/hokaccha/go-prettyjson"
+	"io"
+	"os"
 )
 
 func logJSON(iList ...interface{}) {
 	for _, i := range iList {
-		m, err := json.Marshal(i)
-		if err != nil {
+		if err := writeJSON(os.Stdout, i); err != nil {
 			logError(err)
 			return
 		}
+	}
+}
 
-		pj, err := prettyjson.Format(m)
-		if err != nil {
-			logError(err)
-			return
-		}
+// writeJSON writes v to w as indented JSON, colored unless w is not the
+// standard output or colors are disabled
+func writeJSON(w io.Writer, v interface{}) error {
+	m, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
 
-		fmt.Printf("\n%s\n\n", string(pj))
+	f := prettyjson.NewFormatter()
+	f.DisabledColor = color.NoColor || w != io.Writer(os.Stdout)
+
+	pj, err := f.Format(m)
+	if err != nil {
+		return err
 	}
+
+	_, err = fmt.Fprintf(w, "\n%s\n\n", string(pj))
+	return err
 }
 
 func logUsage(u string) {
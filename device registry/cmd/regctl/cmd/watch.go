@@ -0,0 +1,40 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"github.com/spf13/cobra"
+)
+
+func NewWatchCmd(cli CLI) *cobra.Command {
+
+	watchCmd := &cobra.Command{
+		Use:   "watch",
+		Short: "watch [--entity <entity>] [--region <region>] [--name <event>] [--cursor <timestamp>]",
+		Long: `stream the changes made to the registry, one JSON event per line, until interrupted. The stream is
+resumed from the last event received when regsvc restarts, --cursor replays the events after a timestamp`,
+		Example: "regctl watch --entity node --region AA001",
+		Run:     cli.WatchCmd(context.Background()),
+	}
+
+	watchCmd.Flags().StringSlice("entity", nil, "entities to watch, node, user, region or certificate")
+	watchCmd.Flags().StringSlice("region", nil, "regions to watch")
+	watchCmd.Flags().StringSlice("name", nil, "events to watch, like create_node or revoke_certificate")
+	watchCmd.Flags().Int64("cursor", 0, "timestamp of the last event received, the events after it are replayed")
+
+	return watchCmd
+}
@@ -1,24 +1,55 @@
 package main
 
 import (
-	"database/sql"
+	"context"
+	"expvar"
 	"flag"
 	"fmt"
 	"github.com/go-kit/kit/log"
-	"github.com/piusalfred/igrid/logger"
+	"github.com/gorilla/mux"
 	"github.com/piusalfred/registry"
 	"github.com/piusalfred/registry/api"
+	"github.com/piusalfred/registry/argon2id"
 	"github.com/piusalfred/registry/bcrypt"
+	"github.com/piusalfred/registry/ca"
+	"github.com/piusalfred/registry/cache"
+	"github.com/piusalfred/registry/feed"
+	"github.com/piusalfred/registry/logger"
+	"github.com/piusalfred/registry/pkg/errors"
 	"github.com/piusalfred/registry/postgres"
+	"github.com/piusalfred/registry/sqlite"
+	"github.com/piusalfred/registry/store"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
 func main() {
 	var (
 		httpAddr = flag.String("http.addr", ":8080", "HTTP listen address")
+		dbBack   = flag.String("db.backend", "postgres", "storage backend, postgres or sqlite")
+		dbPath   = flag.String("db.path", "registry.db", "SQLite database file, created if it does not exist")
+		caCert   = flag.String("ca.cert", "ca.crt", "CA certificate file, generated if it does not exist")
+		caKey    = flag.String("ca.key", "ca.key", "CA private key file, generated if it does not exist")
+		authReq  = flag.Bool("auth.required", false, "reject requests without an api key, without it they are only served until the first key is created")
+		cacheSz  = flag.Int("cache.size", cache.DefaultSize, "maximum number of cached node lookups")
+		cacheTTL = flag.Duration("cache.ttl", cache.DefaultTTL, "how long node lookups are cached")
+		cacheNeg = flag.Duration("cache.negative-ttl", cache.DefaultNegativeTTL, "how long missing nodes are cached, 0 to disable")
+		readyTO  = flag.Duration("ready.timeout", 2*time.Second, "how long the readiness checks may take")
+		feedBuf  = flag.Int("feed.buffer", feed.DefaultBuffer, "number of events a watcher may fall behind by before it has to resume")
+		idemWin  = flag.Duration("idempotency.window", 24*time.Hour, "how long responses to requests with an Idempotency-Key are replayed, 0 to disable")
+		shutdown = flag.Duration("shutdown.timeout", 15*time.Second, "how long in-flight requests are drained on SIGTERM before the server is stopped")
+		drainDly = flag.Duration("shutdown.delay", 5*time.Second, "how long /readyz fails on SIGTERM before new connections are refused, 0 to refuse them at once")
+		logLevel = flag.String("log.level", "info", "log level, debug also records where errors were created")
+		idProv   = flag.String("id.provider", registry.UUIDv4, "how ids are generated, uuidv4 is random, uuidv7 and ulid sort by creation time")
+		geofence = flag.String("geofence", string(registry.GeofenceWarn), "what to do with nodes located outside the boundary of their region, off, warn or reject")
+		hashAlg  = flag.String("hash.algorithm", "argon2id", "password hashing algorithm, argon2id or bcrypt, other hashes are replaced on login")
+		bcCost   = flag.Int("bcrypt.cost", bcrypt.DefaultCost, "cost of the bcrypt hashes")
+		a2Memory = flag.Uint("argon2.memory", uint(argon2id.DefaultParams.Memory), "memory used by argon2id in KiB")
+		a2Iter   = flag.Uint("argon2.iterations", uint(argon2id.DefaultParams.Iterations), "number of argon2id passes over the memory")
+		a2Par    = flag.Uint("argon2.parallelism", uint(argon2id.DefaultParams.Parallelism), "number of argon2id threads")
 	)
 	flag.Parse()
 
@@ -29,55 +60,238 @@ func main() {
 		l = log.With(l, "caller", log.DefaultCaller)
 	}
 
-	log, err := logger.New(os.Stderr, "info")
+	log, err := logger.New(os.Stderr, *logLevel)
 	if err != nil {
 		panic(err)
 	}
+	errors.SetDebug(*logLevel == logger.Debug.String())
 
-	db := connectToDB(log)
-	defer db.Close()
+	db := connectToDB(log, *dbBack, *dbPath)
 
-	hasher := bcrypt.New()
+	var hasher registry.Hasher
+	switch *hashAlg {
+	case "argon2id":
+		params := argon2id.DefaultParams
+		params.Memory = uint32(*a2Memory)
+		params.Iterations = uint32(*a2Iter)
+		params.Parallelism = uint8(*a2Par)
+		hasher = argon2id.New(params, bcrypt.NewWithCost(*bcCost))
+	case "bcrypt":
+		hasher = bcrypt.NewWithCost(*bcCost)
+	default:
+		log.Error("unknown password hashing algorithm, use argon2id or bcrypt", "algorithm", *hashAlg)
+		os.Exit(1)
+	}
+
+	provider, err := registry.NewProvider(*idProv)
+	if err != nil {
+		log.Error("failed to create the id provider", "provider", *idProv, "err", err.Error())
+		os.Exit(1)
+	}
+
+	policy, err := registry.ParseGeofencePolicy(*geofence)
+	if err != nil {
+		log.Error("failed to parse the geofence policy", "policy", *geofence, "err", err.Error())
+		os.Exit(1)
+	}
+
+	//requests without a key are only served until the first key is created
+	//so that an admin can create it
+	keys := store.NewAPIKeyRepository(db)
+	if !*authReq {
+		exist, err := keysExist(context.Background(), keys)
+		if err == nil && exist {
+			err = errKeysExist
+		}
+		if err != nil {
+			log.Error("refusing to serve requests without an api key", "err", err.Error())
+			os.Exit(1)
+		}
+
+		log.Warn("requests without an api key are served until the first key is created, restart with -auth.required once it is")
+	}
+
+	nodes := cache.NewNodeRepository(store.NewNodeRepository(db), cache.Config{
+		Size:        *cacheSz,
+		TTL:         *cacheTTL,
+		NegativeTTL: *cacheNeg,
+		NotFound:    store.ErrNodeNotFound,
+	})
+	expvar.Publish("nodes_cache", expvar.Func(func() interface{} {
+		return nodes.Stats()
+	}))
 
-	provider := registry.New()
+	events := feed.New(store.NewEventStore(db), *feedBuf)
 
-	users := postgres.NewUserRepository(db)
-	nodes := postgres.NewNodeRepository(db)
-	regio := postgres.NewRegionRepository(db)
+	authority, err := ca.Load(*caCert, *caKey)
+	if err != nil {
+		log.Error("failed to load certificate authority", "err", err.Error())
+		os.Exit(1)
+	}
 
 	var s registry.Service
 	{
-		s = registry.NewService(users, nodes, regio, hasher, log, provider)
+		s = registry.NewService(store.NewUserRepository(db), nodes, store.NewRegionRepository(db),
+			store.NewNodeTypeRepository(db), cache.NewClaimRepository(store.NewClaimRepository(db), nodes), store.NewCertificateRepository(db),
+			authority, keys, store.NewOrganizationRepository(db),
+			store.NewFirmwareRepository(db), store.NewCampaignRepository(db), store.NewSchemaRepository(db),
+			store.NewQuotaRepository(db), events, hasher, log, provider, policy)
 		s = api.LoggingMiddleware(log)(s)
 	}
 
+	health := api.NewHealth(*readyTO, map[string]api.Check{
+		"database": db.PingContext,
+		"schema": func(ctx context.Context) error {
+			return store.Migrated(ctx, db.DB)
+		},
+	})
+
+	idempotency := store.NewIdempotencyRepository(db)
+
 	var h http.Handler
 	{
-		h = api.MakeHTTPHandler(s, l)
+		var middlewares []mux.MiddlewareFunc
+		if *idemWin > 0 {
+			middlewares = append(middlewares, api.Idempotent(idempotency, *idemWin))
+		}
+		h = api.MakeHTTPHandler(s, l, middlewares...)
+		if *authReq {
+			h = api.RequireAPIKey(h)
+		} else {
+			h = api.OpenAccess(func(ctx context.Context) (bool, error) {
+				return keysExist(ctx, keys)
+			})(h)
+		}
+
+		mux := http.NewServeMux()
+		mux.Handle("/debug/vars", expvar.Handler())
+		mux.HandleFunc("/healthz", health.Healthz)
+		mux.HandleFunc("/readyz", health.Readyz)
+		mux.Handle("/", h)
+		h = api.RequestID(mux)
 	}
 
-	errs := make(chan error)
+	srv := &http.Server{Addr: *httpAddr, Handler: h}
+
+	//watchers would keep the server from draining, they resume elsewhere
+	srv.RegisterOnShutdown(events.Close)
+
+	errs := make(chan error, 1)
 	go func() {
-		c := make(chan os.Signal)
+		c := make(chan os.Signal, 1)
 		signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
 		errs <- fmt.Errorf("%s", <-c)
 	}()
 
+	//nodes restored by regctl or written by other instances are served
+	//from the cache until it is flushed
 	go func() {
+		c := make(chan os.Signal, 1)
+		signal.Notify(c, syscall.SIGHUP)
+		for range c {
+			nodes.Flush()
+			log.Info("nodes cache flushed")
+		}
+	}()
+
+	//expired idempotency keys are taken over by the requests that reuse
+	//them, the others are purged in the background
+	if *idemWin > 0 {
+		go func() {
+			sweep := *idemWin
+			if sweep > time.Hour {
+				sweep = time.Hour
+			}
 
-		log.Info(fmt.Sprintf("registry started on port %v", *httpAddr))
-		//l.Log("transport", "HTTP", "addr", *httpAddr)
-		errs <- http.ListenAndServe(*httpAddr, h)
+			for range time.Tick(sweep) {
+				if err := idempotency.Purge(context.Background(), time.Now().Add(-*idemWin)); err != nil {
+					log.Warn("failed to purge idempotency keys", "err", err.Error())
+				}
+			}
+		}()
+	}
+
+	go func() {
+		log.Info("registry started", "addr", *httpAddr)
+		if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+			errs <- err
+		}
 	}()
 
-	log.Info(fmt.Sprintf("exit due to: %v", <-errs))
+	log.Info("shutting down", "reason", (<-errs).Error())
+
+	//fail readiness first and keep serving until load balancers have seen
+	//it and stopped routing to this instance, then wait for the in-flight
+	//requests up to the deadline
+	health.Drain()
+
+	if *drainDly > 0 {
+		log.Info("draining", "delay", drainDly.String())
+		time.Sleep(*drainDly)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *shutdown)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Warn("in-flight requests not drained", "timeout", shutdown.String(), "err", err.Error())
+		srv.Close()
+	}
+
+	if err := db.Close(); err != nil {
+		log.Error("failed to close the database", "err", err.Error())
+	}
+
+	log.Info("registry stopped")
 }
 
-func connectToDB(logger logger.Logger) *sql.DB {
-	db, err := postgres.Connect()
+// errKeysExist is logged when regsvc starts without -auth.required while
+// the registry has usable api keys
+var errKeysExist = errors.New("api keys exist, start regsvc with -auth.required")
+
+// keysExist reports whether a key that has not expired or been revoked
+// exists, requests without a key would bypass the restrictions of the keys
+func keysExist(ctx context.Context, keys registry.APIKeyRepository) (bool, error) {
+	all, err := keys.List(ctx, "")
 	if err != nil {
-		logger.Info(fmt.Sprintf("Failed to connect to postgres: %s", err))
+		return false, err
+	}
+
+	now := time.Now()
+	for _, key := range all {
+		if key.Valid(now) == nil {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// connectToDB opens the database of the configured storage backend
+func connectToDB(logger logger.Logger, backend, path string) *store.DB {
+	switch backend {
+	case "postgres":
+		db, err := postgres.Connect()
+		if err != nil {
+			logger.Error("failed to connect to postgres", "err", err.Error())
+			os.Exit(1)
+		}
+
+		return db
+
+	case "sqlite":
+		db, err := sqlite.Connect(path)
+		if err != nil {
+			logger.Error("failed to open sqlite database", "path", path, "err", err.Error())
+			os.Exit(1)
+		}
+
+		return db
+
+	default:
+		logger.Error("unknown storage backend, use postgres or sqlite", "backend", backend)
 		os.Exit(1)
 	}
-	return db
+
+	return nil
 }
@@ -2,6 +2,7 @@ package registry
 
 import (
 	"context"
+	"strings"
 	"time"
 )
 
@@ -17,8 +18,61 @@ const (
 	SUBSCRIBE
 	CREATE_NODE
 	LIST_NODES
+	DELETE_NODE
+	UPDATE_NODE
+	PROVISION_NODE
+	CREATE_REGION
+	ISSUE_CERTIFICATE
+	REVOKE_CERTIFICATE
+	UPDATE_REGION
+	CREATE_FIRMWARE
+	DELETE_FIRMWARE
+	CREATE_CAMPAIGN
+	UPDATE_CAMPAIGN
+	UPDATE_ROLLOUT
+	CREATE_SCHEMA
+	UPDATE_QUOTA
 )
 
+var eventNames = [...]string{
+	CREATE_USER: "create_user",
+	DELETE_USER: "delete_user",
+	UPDATE_USER: "update_user",
+	LIST_USERS:  "list_users",
+	GET_USER:    "get_user",
+	PUBLISH:     "publish",
+	SUBSCRIBE:   "subscribe",
+	CREATE_NODE: "create_node",
+	LIST_NODES:  "list_nodes",
+
+	DELETE_NODE:        "delete_node",
+	UPDATE_NODE:        "update_node",
+	PROVISION_NODE:     "provision_node",
+	CREATE_REGION:      "create_region",
+	ISSUE_CERTIFICATE:  "issue_certificate",
+	REVOKE_CERTIFICATE: "revoke_certificate",
+	UPDATE_REGION:      "update_region",
+	CREATE_FIRMWARE:    "create_firmware",
+	DELETE_FIRMWARE:    "delete_firmware",
+	CREATE_CAMPAIGN:    "create_campaign",
+	UPDATE_CAMPAIGN:    "update_campaign",
+	UPDATE_ROLLOUT:     "update_rollout",
+	CREATE_SCHEMA:      "create_schema",
+	UPDATE_QUOTA:       "update_quota",
+}
+
+// String returns the name events of this kind are saved with
+func (n EventName) String() string {
+	if n < 0 || int(n) >= len(eventNames) {
+		return "unknown"
+	}
+
+	return eventNames[n]
+}
+
+// Event records a change made to the registry. Subject is the id of the
+// changed entity and Timestamp, in nanoseconds since the epoch, orders the
+// events of the change feed.
 type Event struct {
 	UUID      string        `json:"uuid"`
 	Name      string        `json:"name"`
@@ -29,6 +83,50 @@ type Event struct {
 	Err       string        `json:"err"`
 	Timestamp time.Duration `json:"timestamp"`
 	ExecTime  time.Duration `json:"exec_time"`
+	Subject   string        `json:"subject,omitempty"`
+	Org       string        `json:"org,omitempty"`
+}
+
+// Entity is the kind of entity the event changed, node for create_node
+func (e Event) Entity() string {
+	i := strings.Index(e.Name, "_")
+	if i < 0 {
+		return ""
+	}
+
+	return e.Name[i+1:]
+}
+
+// EventFilter selects the events of the change feed, empty fields match
+// every event.
+type EventFilter struct {
+	Entities []string `json:"entities,omitempty"`
+	Regions  []string `json:"regions,omitempty"`
+	Names    []string `json:"names,omitempty"`
+	Org      string   `json:"org,omitempty"`
+}
+
+// Match reports whether event is selected by f
+func (f EventFilter) Match(event Event) bool {
+	if f.Org != "" && event.Org != f.Org {
+		return false
+	}
+
+	return matches(f.Entities, event.Entity()) && matches(f.Regions, event.Region) && matches(f.Names, event.Name)
+}
+
+func matches(values []string, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
 }
 
 type EventStore interface {
@@ -41,6 +139,16 @@ type EventStore interface {
 	ByEventName(ctx context.Context, name EventName) (events []Event, err error)
 }
 
+// EventFeed is an EventStore whose events can be watched as they are saved
+type EventFeed interface {
+	EventStore
+	// Subscribe returns the events matching filter saved after cursor, then
+	// the ones saved from now on. The channel is closed when ctx is done or
+	// when the subscriber falls behind, it can then subscribe again from the
+	// timestamp of the last event it received.
+	Subscribe(ctx context.Context, filter EventFilter, cursor time.Duration) (<-chan Event, error)
+}
+
 type eventStore struct {
 }
 
@@ -0,0 +1,222 @@
+// Package feed turns the event store of the registry into a live change
+// feed. Saved events are pushed to the subscribers whose filter they match,
+// and a subscriber that reconnects with the timestamp of the last event it
+// received is replayed the events it missed from the store.
+package feed
+
+import (
+	"context"
+	"github.com/piusalfred/registry"
+	"github.com/piusalfred/registry/pkg/errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// DefaultBuffer is the number of events a subscriber may fall behind by
+// before it is dropped
+const DefaultBuffer = 256
+
+var ErrClosed = errors.NewKind(errors.Unavailable, "change feed is closed")
+
+// Feed is a registry.EventFeed that can be closed
+type Feed interface {
+	registry.EventFeed
+
+	// Close ends the streams of the subscribers so that they resume from
+	// another instance, events are still saved once the feed is closed.
+	Close()
+}
+
+var _ Feed = (*feed)(nil)
+
+type subscriber struct {
+	filter registry.EventFilter
+	events chan registry.Event
+}
+
+// pending is an event given its timestamp and being saved
+type pending struct {
+	event registry.Event
+	done  bool
+	saved bool
+}
+
+type feed struct {
+	registry.EventStore
+	buffer int
+
+	mu      sync.Mutex
+	last    time.Duration
+	pending []*pending //in the order of their timestamps
+	subs    map[*subscriber]struct{}
+	closed  bool
+}
+
+// New returns an EventFeed saving its events to store. Events are given
+// increasing timestamps so that they can be used as the resume cursor of the
+// subscribers, this holds as long as a single feed writes to the store.
+func New(store registry.EventStore, buffer int) Feed {
+	if buffer <= 0 {
+		buffer = DefaultBuffer
+	}
+
+	return &feed{
+		EventStore: store,
+		buffer:     buffer,
+		subs:       make(map[*subscriber]struct{}),
+	}
+}
+
+// Save saves event and pushes it to the subscribers, a subscriber whose
+// buffer is full is dropped instead of slowing down the writers. The store
+// is written without holding the lock, events are pushed in the order of
+// their timestamps once the ones given a smaller timestamp are saved too.
+func (f *feed) Save(ctx context.Context, event registry.Event) error {
+	f.mu.Lock()
+	if event.Timestamp == 0 {
+		event.Timestamp = time.Duration(time.Now().UnixNano())
+	}
+
+	//events saved within the same nanosecond still get distinct cursors
+	if event.Timestamp <= f.last {
+		event.Timestamp = f.last + 1
+	}
+	f.last = event.Timestamp
+
+	p := &pending{event: event}
+	f.pending = append(f.pending, p)
+	f.mu.Unlock()
+
+	err := f.EventStore.Save(ctx, event)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p.done, p.saved = true, err == nil
+	for len(f.pending) > 0 && f.pending[0].done {
+		if f.pending[0].saved {
+			f.publish(f.pending[0].event)
+		}
+		f.pending = f.pending[1:]
+	}
+
+	return err
+}
+
+// publish pushes event to the subscribers whose filter it matches, f.mu is
+// held by the caller
+func (f *feed) publish(event registry.Event) {
+	for sub := range f.subs {
+		if !sub.filter.Match(event) {
+			continue
+		}
+
+		select {
+		case sub.events <- event:
+		default:
+			delete(f.subs, sub)
+			close(sub.events)
+		}
+	}
+}
+
+// Subscribe replays the events saved after cursor, a zero cursor only
+// returns the events saved from now on.
+func (f *feed) Subscribe(ctx context.Context, filter registry.EventFilter, cursor time.Duration) (<-chan registry.Event, error) {
+	sub := &subscriber{
+		filter: filter,
+		events: make(chan registry.Event, f.buffer),
+	}
+
+	//subscribe before reading the store so that no event saved in between
+	//is missed, the ones read twice are skipped below. Events still being
+	//saved are pushed to the subscriber once they are, they are not
+	//replayed so that an event saved out of order is not skipped.
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		return nil, ErrClosed
+	}
+	f.subs[sub] = struct{}{}
+	horizon := time.Duration(math.MaxInt64)
+	if len(f.pending) > 0 {
+		horizon = f.pending[0].event.Timestamp
+	}
+	f.mu.Unlock()
+
+	var replay []registry.Event
+	if cursor > 0 {
+		var err error
+		replay, err = f.EventStore.After(ctx, cursor+1)
+		if err != nil {
+			f.unsubscribe(sub)
+			return nil, err
+		}
+	}
+
+	out := make(chan registry.Event)
+
+	go func() {
+		defer close(out)
+		defer f.unsubscribe(sub)
+
+		last := cursor
+		send := func(event registry.Event) bool {
+			if event.Timestamp <= last {
+				return true
+			}
+
+			select {
+			case out <- event:
+				last = event.Timestamp
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for _, event := range replay {
+			if event.Timestamp >= horizon {
+				continue
+			}
+			if filter.Match(event) && !send(event) {
+				return
+			}
+		}
+
+		for {
+			select {
+			case event, ok := <-sub.events:
+				if !ok || !send(event) {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+func (f *feed) unsubscribe(sub *subscriber) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.subs[sub]; ok {
+		delete(f.subs, sub)
+		close(sub.events)
+	}
+}
+
+func (f *feed) Close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.closed = true
+	for sub := range f.subs {
+		delete(f.subs, sub)
+		close(sub.events)
+	}
+}
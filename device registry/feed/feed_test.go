@@ -0,0 +1,188 @@
+package feed_test
+
+import (
+	"context"
+	"github.com/piusalfred/registry"
+	"github.com/piusalfred/registry/feed"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memStore keeps the saved events in memory, only Save and After are used
+// by the feed
+type memStore struct {
+	registry.EventStore
+	mu     sync.Mutex
+	events []registry.Event
+}
+
+func (m *memStore) Save(_ context.Context, event registry.Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, event)
+
+	return nil
+}
+
+func (m *memStore) After(_ context.Context, start time.Duration) ([]registry.Event, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var after []registry.Event
+	for _, e := range m.events {
+		if e.Timestamp >= start {
+			after = append(after, e)
+		}
+	}
+
+	return after, nil
+}
+
+// slowStore is a memStore whose saves of the events with the subject slow
+// block until release is closed
+type slowStore struct {
+	memStore
+	saving  chan struct{}
+	release chan struct{}
+}
+
+func (s *slowStore) Save(ctx context.Context, event registry.Event) error {
+	if event.Subject == "slow" {
+		close(s.saving)
+		<-s.release
+	}
+
+	return s.memStore.Save(ctx, event)
+}
+
+func receive(t *testing.T, events <-chan registry.Event, n int) []string {
+	var subjects []string
+	for i := 0; i < n; i++ {
+		select {
+		case e, ok := <-events:
+			require.True(t, ok, "stream closed after %d events", i)
+			subjects = append(subjects, e.Subject)
+		case <-time.After(time.Second):
+			t.Fatalf("received %d events of %d", i, n)
+		}
+	}
+
+	return subjects
+}
+
+func TestSubscribe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	f := feed.New(&memStore{}, 0)
+
+	save := func(name registry.EventName, subject, region string) {
+		require.Nil(t, f.Save(ctx, registry.Event{Name: name.String(), Subject: subject, Region: region}))
+	}
+
+	save(registry.CREATE_NODE, "n1", "AA001")
+	save(registry.CREATE_USER, "u1", "AA001")
+
+	//every event is saved with a distinct, increasing timestamp
+	first, err := f.After(ctx, 0)
+	require.Nil(t, err)
+	require.Len(t, first, 2)
+	assert.True(t, first[1].Timestamp > first[0].Timestamp)
+
+	nodes, err := f.Subscribe(ctx, registry.EventFilter{Entities: []string{"node"}}, 0)
+	require.Nil(t, err)
+
+	resumed, err := f.Subscribe(ctx, registry.EventFilter{}, first[0].Timestamp)
+	require.Nil(t, err)
+
+	save(registry.DELETE_NODE, "n1", "AA002")
+	save(registry.CREATE_NODE, "n2", "AA001")
+
+	assert.Equal(t, []string{"n1", "n2"}, receive(t, nodes, 2), "live node events")
+	assert.Equal(t, []string{"u1", "n1", "n2"}, receive(t, resumed, 3), "events after the cursor")
+
+	regions, err := f.Subscribe(ctx, registry.EventFilter{Regions: []string{"AA002"}}, first[0].Timestamp)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"n1"}, receive(t, regions, 1), "events of a region")
+}
+
+func TestSlowSubscriber(t *testing.T) {
+	ctx := context.Background()
+	f := feed.New(&memStore{}, 1)
+
+	events, err := f.Subscribe(ctx, registry.EventFilter{}, 0)
+	require.Nil(t, err)
+
+	//nobody reads the stream, the subscriber is dropped once its buffer
+	//is full instead of blocking Save
+	for i := 0; i < 3; i++ {
+		require.Nil(t, f.Save(ctx, registry.Event{Name: registry.CREATE_NODE.String()}))
+	}
+
+	n := 0
+	for range events {
+		n++
+	}
+	assert.True(t, n < 3, "slow subscriber received all events")
+}
+
+func TestClose(t *testing.T) {
+	ctx := context.Background()
+	f := feed.New(&memStore{}, 0)
+
+	events, err := f.Subscribe(ctx, registry.EventFilter{}, 0)
+	require.Nil(t, err)
+
+	f.Close()
+
+	_, ok := <-events
+	assert.False(t, ok, "stream not closed")
+
+	_, err = f.Subscribe(ctx, registry.EventFilter{}, 0)
+	assert.Equal(t, feed.ErrClosed, err)
+	assert.Nil(t, f.Save(ctx, registry.Event{Name: registry.CREATE_NODE.String()}), "events not saved once closed")
+}
+
+func TestSaveOrder(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	store := &slowStore{saving: make(chan struct{}), release: make(chan struct{})}
+	f := feed.New(store, 0)
+
+	require.Nil(t, f.Save(ctx, registry.Event{Subject: "first"}))
+	first, err := f.After(ctx, 0)
+	require.Nil(t, err)
+
+	live, err := f.Subscribe(ctx, registry.EventFilter{}, 0)
+	require.Nil(t, err)
+
+	saved := make(chan error)
+	go func() { saved <- f.Save(ctx, registry.Event{Subject: "slow"}) }()
+	<-store.saving
+
+	//the feed is not locked while the store is written
+	require.Nil(t, f.Save(ctx, registry.Event{Subject: "fast"}))
+
+	//an event is not pushed before the ones saved earlier, nor replayed
+	resumed, err := f.Subscribe(ctx, registry.EventFilter{}, first[0].Timestamp)
+	require.Nil(t, err)
+
+	select {
+	case e := <-live:
+		t.Fatalf("%s pushed before the slow event was saved", e.Subject)
+	case e := <-resumed:
+		t.Fatalf("%s replayed before the slow event was saved", e.Subject)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(store.release)
+	require.Nil(t, <-saved)
+
+	assert.Equal(t, []string{"slow", "fast"}, receive(t, live, 2))
+	assert.Equal(t, []string{"slow", "fast"}, receive(t, resumed, 2))
+}
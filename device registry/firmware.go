@@ -0,0 +1,227 @@
+package registry
+
+import (
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/piusalfred/registry/pkg/errors"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrInvalidFirmware indicates a firmware release without a version, a
+	// node type or an artifact
+	ErrInvalidFirmware = errors.NewKind(errors.Invalid, "firmware must have a version, a node type and an artifact url or blob")
+
+	// ErrInvalidChecksum indicates a checksum that is not a hex encoded sha256
+	ErrInvalidChecksum = errors.NewKind(errors.Invalid, "firmware checksum must be the hex encoded sha256 of the artifact")
+
+	// ErrChecksumMismatch is returned when the blob of a firmware release
+	// does not have the checksum it was added with
+	ErrChecksumMismatch = errors.NewKind(errors.Invalid, "firmware checksum does not match its blob")
+
+	// ErrFirmwareExists is returned when a node type already has a release
+	// of the version
+	ErrFirmwareExists = errors.NewKind(errors.Conflict, "firmware version already released for the node type")
+
+	// ErrFirmwareInUse is returned when deleting a release campaigns roll out
+	ErrFirmwareInUse = errors.NewKind(errors.Conflict, "firmware is rolled out by a campaign")
+
+	// ErrInvalidCampaign indicates a campaign without a name or a firmware,
+	// or with a rollout that is not a percentage
+	ErrInvalidCampaign = errors.NewKind(errors.Invalid, "campaign must have a name, a firmware and a rollout between 1 and 100 percent")
+
+	// ErrRolloutShrink is returned when a campaign is advanced to a smaller
+	// share of nodes, enrolled nodes are never dropped
+	ErrRolloutShrink = errors.NewKind(errors.Invalid, "the rollout of a campaign can only grow")
+
+	// ErrInvalidRolloutStatus indicates a status nodes can not report
+	ErrInvalidRolloutStatus = errors.NewKind(errors.Invalid, "invalid rollout status, use pending, downloading, applied or failed")
+
+	// ErrNotEnrolled is returned when a node reports the progress of a
+	// campaign it is not enrolled in
+	ErrNotEnrolled = errors.NewKind(errors.NotFound, "node is not enrolled in the campaign")
+
+	// ErrRolloutApplied is returned when a node reports a status after it
+	// has applied the firmware of the campaign
+	ErrRolloutApplied = errors.NewKind(errors.Conflict, "firmware of the campaign already applied by the node")
+)
+
+// Firmware is a release of the firmware of a node type. Nodes download it
+// from URL, or from the registry when the artifact is stored as Blob, and
+// verify it against Checksum.
+type Firmware struct {
+	ID       string `json:"id"`
+	Version  string `json:"version"`
+	Type     int    `json:"type"`
+	Checksum string `json:"checksum"`
+	URL      string `json:"url,omitempty"`
+	Blob     []byte `json:"blob,omitempty"`
+	Created  string `json:"created"`
+	Org      string `json:"org,omitempty"`
+}
+
+// Validate checks the fields of f and sets the checksum of a blob when it
+// is not given
+func (f *Firmware) Validate() error {
+	f.Version = strings.TrimSpace(f.Version)
+	if f.Version == "" || f.Type <= 0 || (f.URL == "" && len(f.Blob) == 0) {
+		return ErrInvalidFirmware
+	}
+
+	f.Checksum = strings.ToLower(strings.TrimSpace(f.Checksum))
+	if len(f.Blob) > 0 {
+		sum := sha256.Sum256(f.Blob)
+		if f.Checksum == "" {
+			f.Checksum = hex.EncodeToString(sum[:])
+		}
+
+		if f.Checksum != hex.EncodeToString(sum[:]) {
+			return ErrChecksumMismatch
+		}
+	}
+
+	if b, err := hex.DecodeString(f.Checksum); err != nil || len(b) != sha256.Size {
+		return ErrInvalidChecksum
+	}
+
+	return nil
+}
+
+// Labels are the key value pairs nodes are tagged with, e.g. site=substation-4
+type Labels map[string]string
+
+// Matches reports whether l has every label of selector, an empty selector
+// matches all labels
+func (l Labels) Matches(selector Labels) bool {
+	for k, v := range selector {
+		if l[k] != v {
+			return false
+		}
+	}
+
+	return true
+}
+
+// String returns the labels as sorted k=v pairs separated by commas
+func (l Labels) String() string {
+	pairs := make([]string, 0, len(l))
+	for k, v := range l {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ",")
+}
+
+// Value implements driver.Valuer, labels are stored as a JSON object and
+// nodes without labels as NULL
+func (l Labels) Value() (driver.Value, error) {
+	if len(l) == 0 {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (l *Labels) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, l)
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	}
+
+	return fmt.Errorf("cannot scan %T into labels", src)
+}
+
+// RolloutStatus is where a node is in the rollout of a campaign
+type RolloutStatus string
+
+const (
+	// RolloutPending nodes have been enrolled and not yet reported
+	RolloutPending RolloutStatus = "pending"
+	// RolloutDownloading nodes are fetching the firmware
+	RolloutDownloading RolloutStatus = "downloading"
+	// RolloutApplied nodes run the firmware of the campaign
+	RolloutApplied RolloutStatus = "applied"
+	// RolloutFailed nodes could not apply the firmware, they can retry
+	RolloutFailed RolloutStatus = "failed"
+)
+
+// RolloutStatuses are the statuses in the order nodes go through them
+var RolloutStatuses = []RolloutStatus{RolloutPending, RolloutDownloading, RolloutApplied, RolloutFailed}
+
+// ParseRolloutStatus returns the status called name
+func ParseRolloutStatus(name string) (RolloutStatus, error) {
+	for _, s := range RolloutStatuses {
+		if string(s) == name {
+			return s, nil
+		}
+	}
+
+	return "", ErrInvalidRolloutStatus
+}
+
+// CampaignTarget selects the nodes of a campaign. Only nodes of the type
+// of the firmware are targeted, empty fields match every node.
+type CampaignTarget struct {
+	Region     string `json:"region,omitempty"`
+	Subregions bool   `json:"subregions,omitempty"`
+	Labels     Labels `json:"labels,omitempty"`
+}
+
+// Campaign rolls a firmware out to the nodes of its target in stages,
+// Rollout is the percentage of the targeted nodes enrolled so far.
+type Campaign struct {
+	ID       string         `json:"id"`
+	Name     string         `json:"name"`
+	Firmware string         `json:"firmware"`
+	Target   CampaignTarget `json:"target"`
+	Rollout  int            `json:"rollout"`
+	Created  string         `json:"created"`
+	Org      string         `json:"org,omitempty"`
+
+	// Status counts the enrolled nodes by rollout status
+	Status map[RolloutStatus]int `json:"status,omitempty"`
+}
+
+// Validate checks the fields of c, a zero rollout enrolls all the nodes
+func (c *Campaign) Validate() error {
+	if c.Rollout == 0 {
+		c.Rollout = 100
+	}
+
+	if strings.TrimSpace(c.Name) == "" || c.Firmware == "" || c.Rollout < 0 || c.Rollout > 100 {
+		return ErrInvalidCampaign
+	}
+
+	return nil
+}
+
+// Enrolled returns how many of total targeted nodes are enrolled at a
+// rollout of percent, at least one once the percent is positive
+func Enrolled(total, percent int) int {
+	return (total*percent + 99) / 100
+}
+
+// Rollout is the progress of a node in a campaign
+type Rollout struct {
+	Campaign string        `json:"campaign"`
+	Node     string        `json:"node"`
+	Status   RolloutStatus `json:"status"`
+	Err      string        `json:"err,omitempty"`
+	Updated  string        `json:"updated"`
+}
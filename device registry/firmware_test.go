@@ -0,0 +1,68 @@
+package registry_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/piusalfred/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFirmwareValidate(t *testing.T) {
+	blob := []byte("meter firmware 2.1.0")
+	sum := sha256.Sum256(blob)
+	checksum := hex.EncodeToString(sum[:])
+
+	fw := registry.Firmware{Version: " 2.1.0 ", Type: 4, Blob: blob}
+	assert.Nil(t, fw.Validate())
+	assert.Equal(t, "2.1.0", fw.Version)
+	assert.Equal(t, checksum, fw.Checksum, "checksum of the blob not computed")
+
+	cases := []struct {
+		desc string
+		fw   registry.Firmware
+		err  error
+	}{
+		{desc: "url", fw: registry.Firmware{Version: "1", Type: 4, URL: "https://fw.example/1.bin", Checksum: checksum}},
+		{desc: "upper case checksum", fw: registry.Firmware{Version: "1", Type: 4, Blob: blob, Checksum: "  " + strings.ToUpper(checksum)}},
+		{desc: "no version", fw: registry.Firmware{Type: 4, Blob: blob}, err: registry.ErrInvalidFirmware},
+		{desc: "no type", fw: registry.Firmware{Version: "1", Blob: blob}, err: registry.ErrInvalidFirmware},
+		{desc: "no artifact", fw: registry.Firmware{Version: "1", Type: 4, Checksum: checksum}, err: registry.ErrInvalidFirmware},
+		{desc: "url without checksum", fw: registry.Firmware{Version: "1", Type: 4, URL: "https://fw.example/1.bin"}, err: registry.ErrInvalidChecksum},
+		{desc: "md5 checksum", fw: registry.Firmware{Version: "1", Type: 4, URL: "https://fw.example/1.bin", Checksum: "9e107d9d372bb6826bd81d3542a419d6"}, err: registry.ErrInvalidChecksum},
+		{desc: "checksum of another blob", fw: registry.Firmware{Version: "1", Type: 4, Blob: []byte("other"), Checksum: checksum}, err: registry.ErrChecksumMismatch},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.err, tc.fw.Validate(), tc.desc)
+	}
+}
+
+func TestLabelsMatches(t *testing.T) {
+	labels := registry.Labels{"site": "substation-4", "feeder": "f2"}
+
+	assert.True(t, labels.Matches(nil), "empty selector")
+	assert.True(t, labels.Matches(registry.Labels{"site": "substation-4"}))
+	assert.False(t, labels.Matches(registry.Labels{"site": "substation-5"}))
+	assert.False(t, labels.Matches(registry.Labels{"phase": "a"}))
+	assert.False(t, registry.Labels(nil).Matches(registry.Labels{"site": "substation-4"}))
+	assert.Equal(t, "feeder=f2,site=substation-4", labels.String())
+}
+
+func TestCampaignRollout(t *testing.T) {
+	c := registry.Campaign{Name: "meters", Firmware: "fw"}
+	assert.Nil(t, c.Validate())
+	assert.Equal(t, 100, c.Rollout, "a zero rollout enrolls every node")
+
+	for _, rollout := range []int{-1, 101} {
+		c := registry.Campaign{Name: "meters", Firmware: "fw", Rollout: rollout}
+		assert.Equal(t, registry.ErrInvalidCampaign, c.Validate(), "rollout %d", rollout)
+	}
+
+	assert.Equal(t, 0, registry.Enrolled(0, 10))
+	assert.Equal(t, 1, registry.Enrolled(3, 10), "at least one node once the rollout starts")
+	assert.Equal(t, 5, registry.Enrolled(10, 50))
+	assert.Equal(t, 10, registry.Enrolled(10, 100))
+}
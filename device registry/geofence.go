@@ -0,0 +1,244 @@
+package registry
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"github.com/piusalfred/registry/pkg/errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidBoundary indicates a region boundary that is not a valid
+	// GeoJSON Polygon or MultiPolygon
+	ErrInvalidBoundary = errors.NewKind(errors.Invalid, "invalid region boundary, it must be a GeoJSON Polygon or MultiPolygon")
+
+	// ErrInvalidLocation indicates coordinates that are not a latitude and a
+	// longitude in decimal degrees
+	ErrInvalidLocation = errors.NewKind(errors.Invalid, "invalid location, latitude and longitude must be decimal degrees")
+
+	// ErrNodeOutsideRegion is returned when a node is located outside the
+	// boundary of its region and the geofence policy rejects it
+	ErrNodeOutsideRegion = errors.NewKind(errors.Invalid, "node is located outside the boundary of its region")
+
+	// ErrNoRegionAtLocation is returned by SuggestRegion when no region
+	// boundary contains the location
+	ErrNoRegionAtLocation = errors.NewKind(errors.NotFound, "no region contains the location")
+
+	// ErrUnknownGeofencePolicy indicates a policy name ParseGeofencePolicy
+	// does not know
+	ErrUnknownGeofencePolicy = errors.NewKind(errors.Invalid, "unknown geofence policy, use off, warn or reject")
+)
+
+// GeofencePolicy is what the service does with nodes located outside the
+// boundary of their region
+type GeofencePolicy string
+
+const (
+	// GeofenceOff does not check the location of nodes
+	GeofenceOff GeofencePolicy = "off"
+	// GeofenceWarn logs the nodes located outside their region
+	GeofenceWarn GeofencePolicy = "warn"
+	// GeofenceReject refuses to add or move nodes outside their region
+	GeofenceReject GeofencePolicy = "reject"
+)
+
+// ParseGeofencePolicy returns the policy called name
+func ParseGeofencePolicy(name string) (GeofencePolicy, error) {
+	switch p := GeofencePolicy(name); p {
+	case GeofenceOff, GeofenceWarn, GeofenceReject:
+		return p, nil
+	}
+
+	return "", ErrUnknownGeofencePolicy
+}
+
+// Geometry is a GeoJSON Polygon or MultiPolygon geometry (RFC 7946).
+// Positions are [longitude, latitude] pairs in decimal degrees, the first
+// ring of a polygon is its outer boundary and the others are holes.
+type Geometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+type position [2]float64
+
+type ring []position
+
+type polygon []ring
+
+// polygons decodes the coordinates of g, whatever its type
+func (g Geometry) polygons() ([]polygon, error) {
+	var polygons []polygon
+
+	switch g.Type {
+	case "Polygon":
+		var p polygon
+		if err := json.Unmarshal(g.Coordinates, &p); err != nil {
+			return nil, err
+		}
+		polygons = []polygon{p}
+
+	case "MultiPolygon":
+		if err := json.Unmarshal(g.Coordinates, &polygons); err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported geometry type %q", g.Type)
+	}
+
+	return polygons, nil
+}
+
+// Validate checks that g is a Polygon or MultiPolygon whose rings are closed,
+// have at least four positions and stay within the range of coordinates
+func (g Geometry) Validate() error {
+	polygons, err := g.polygons()
+	if err != nil {
+		return errors.Wrap(ErrInvalidBoundary, err)
+	}
+
+	if len(polygons) == 0 {
+		return errors.Wrap(ErrInvalidBoundary, errors.New("no polygon"))
+	}
+
+	for _, p := range polygons {
+		if len(p) == 0 {
+			return errors.Wrap(ErrInvalidBoundary, errors.New("polygon without rings"))
+		}
+
+		for _, r := range p {
+			if len(r) < 4 {
+				return errors.Wrap(ErrInvalidBoundary, errors.New("rings need at least four positions"))
+			}
+
+			if r[0] != r[len(r)-1] {
+				return errors.Wrap(ErrInvalidBoundary, errors.New("rings must end with their first position"))
+			}
+
+			for _, pos := range r {
+				if math.Abs(pos[0]) > 180 || math.Abs(pos[1]) > 90 {
+					return errors.Wrap(ErrInvalidBoundary, fmt.Errorf("position %v out of range", pos))
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Contains reports whether the location lat, long is inside g, locations
+// inside a hole are not. An invalid geometry contains nothing.
+func (g Geometry) Contains(lat, long float64) bool {
+	polygons, err := g.polygons()
+	if err != nil {
+		return false
+	}
+
+	pos := position{long, lat}
+	for _, p := range polygons {
+		if len(p) == 0 || !p[0].contains(pos) {
+			continue
+		}
+
+		inHole := false
+		for _, hole := range p[1:] {
+			if hole.contains(pos) {
+				inHole = true
+				break
+			}
+		}
+
+		if !inHole {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Area returns the planar area of g in square degrees, holes excluded. It
+// is only meant to compare the size of regions.
+func (g Geometry) Area() float64 {
+	polygons, err := g.polygons()
+	if err != nil {
+		return 0
+	}
+
+	var area float64
+	for _, p := range polygons {
+		for i, r := range p {
+			if i == 0 {
+				area += r.area()
+			} else {
+				area -= r.area()
+			}
+		}
+	}
+
+	return area
+}
+
+// contains casts a ray from pos and counts the edges of r it crosses
+func (r ring) contains(pos position) bool {
+	inside := false
+	for i, j := 0, len(r)-1; i < len(r); j, i = i, i+1 {
+		a, b := r[i], r[j]
+		if (a[1] > pos[1]) != (b[1] > pos[1]) &&
+			pos[0] < (b[0]-a[0])*(pos[1]-a[1])/(b[1]-a[1])+a[0] {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
+
+// area is the shoelace formula
+func (r ring) area() float64 {
+	var sum float64
+	for i := 0; i < len(r)-1; i++ {
+		sum += r[i][0]*r[i+1][1] - r[i+1][0]*r[i][1]
+	}
+
+	return math.Abs(sum) / 2
+}
+
+// Value implements driver.Valuer, geometries are stored as GeoJSON text
+func (g Geometry) Value() (driver.Value, error) {
+	data, err := json.Marshal(g)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (g *Geometry) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, g)
+	case string:
+		return json.Unmarshal([]byte(v), g)
+	}
+
+	return fmt.Errorf("cannot scan %T into a geometry", src)
+}
+
+// ParseLocation parses the latitude and longitude of a node
+func ParseLocation(lat, long string) (float64, float64, error) {
+	la, err := strconv.ParseFloat(strings.TrimSpace(lat), 64)
+	if err != nil || !(math.Abs(la) <= 90) {
+		return 0, 0, ErrInvalidLocation
+	}
+
+	lo, err := strconv.ParseFloat(strings.TrimSpace(long), 64)
+	if err != nil || !(math.Abs(lo) <= 180) {
+		return 0, 0, ErrInvalidLocation
+	}
+
+	return la, lo, nil
+}
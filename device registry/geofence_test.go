@@ -0,0 +1,86 @@
+package registry_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/piusalfred/registry"
+	"github.com/piusalfred/registry/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+//a square around Dar es Salaam with a hole around the harbour
+const darPolygon = `{"type": "Polygon", "coordinates": [
+	[[39.0, -7.0], [39.5, -7.0], [39.5, -6.5], [39.0, -6.5], [39.0, -7.0]],
+	[[39.28, -6.84], [39.32, -6.84], [39.32, -6.80], [39.28, -6.80], [39.28, -6.84]]
+]}`
+
+func geometry(t *testing.T, s string) registry.Geometry {
+	var g registry.Geometry
+	if err := json.Unmarshal([]byte(s), &g); err != nil {
+		t.Fatal(err)
+	}
+
+	return g
+}
+
+func TestGeometryValidate(t *testing.T) {
+	cases := []struct {
+		desc  string
+		geo   string
+		valid bool
+	}{
+		{desc: "polygon with a hole", geo: darPolygon, valid: true},
+		{
+			desc:  "multipolygon",
+			geo:   `{"type": "MultiPolygon", "coordinates": [[[[0, 0], [1, 0], [1, 1], [0, 0]]], [[[2, 2], [3, 2], [3, 3], [2, 2]]]]}`,
+			valid: true,
+		},
+		{desc: "point", geo: `{"type": "Point", "coordinates": [39.2, -6.8]}`},
+		{desc: "open ring", geo: `{"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 1], [0, 1]]]}`},
+		{desc: "too few positions", geo: `{"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [0, 0]]]}`},
+		{desc: "latitude out of range", geo: `{"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 91], [0, 0]]]}`},
+		{desc: "no polygon", geo: `{"type": "MultiPolygon", "coordinates": []}`},
+	}
+
+	for _, tc := range cases {
+		err := geometry(t, tc.geo).Validate()
+		if tc.valid {
+			assert.Nil(t, err, tc.desc)
+			continue
+		}
+
+		assert.True(t, errors.Contains(err, registry.ErrInvalidBoundary), "%s: got %v", tc.desc, err)
+	}
+}
+
+func TestGeometryContains(t *testing.T) {
+	g := geometry(t, darPolygon)
+
+	cases := []struct {
+		desc      string
+		lat, long float64
+		inside    bool
+	}{
+		{desc: "inside", lat: -6.7735, long: 39.2395, inside: true},
+		{desc: "inside the hole", lat: -6.82, long: 39.30},
+		{desc: "east of the square", lat: -6.7735, long: 39.6},
+		{desc: "latitude and longitude swapped", lat: 39.2395, long: -6.7735},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.inside, g.Contains(tc.lat, tc.long), tc.desc)
+	}
+}
+
+func TestParseLocation(t *testing.T) {
+	lat, long, err := registry.ParseLocation(" -6.7735", "39.2395 ")
+	assert.Nil(t, err)
+	assert.Equal(t, -6.7735, lat)
+	assert.Equal(t, 39.2395, long)
+
+	for _, loc := range [][2]string{{"", "39"}, {"north", "39"}, {"NaN", "39"}, {"-6", "181"}} {
+		_, _, err := registry.ParseLocation(loc[0], loc[1])
+		assert.Equal(t, registry.ErrInvalidLocation, err, "%v", loc)
+	}
+}
@@ -8,10 +8,12 @@ require (
 	github.com/gofrs/uuid v3.3.0+incompatible
 	github.com/gorilla/handlers v1.5.1
 	github.com/gorilla/mux v1.7.3
+	github.com/gorilla/websocket v1.4.2
 	github.com/hokaccha/go-prettyjson v0.0.0-20190818114111-108c894c2c0e
 	github.com/lib/pq v1.8.0
 	github.com/lightstep/lightstep-tracer-go v0.18.1 // indirect
 	github.com/mainflux/mainflux v0.11.0 // indirect
+	github.com/mattn/go-sqlite3 v1.14.22
 	github.com/mitchellh/go-homedir v1.1.0
 	github.com/oklog/oklog v0.3.2 // indirect
 	github.com/opentracing/opentracing-go v1.1.0 // indirect
@@ -24,5 +26,6 @@ require (
 	github.com/subosito/gotenv v1.2.0
 	golang.org/x/crypto v0.0.0-20200510223506-06a226fb4e37
 	golang.org/x/net v0.0.0-20200513185701-a91f0712d120
+	gopkg.in/yaml.v2 v2.2.8
 	sourcegraph.com/sourcegraph/appdash v0.0.0-20190731080439-ebfcffb1b5c0 // indirect
 )
@@ -1,7 +1,8 @@
 package registry
 
 // Hasher specifies an API for generating hashes of an arbitrary textual
-// content.
+// content. It is deliberately slow and only hashes user passwords, api keys
+// and node keys are random secrets hashed with SHA-256.
 type Hasher interface {
 	// Hash generates the hashed string from plain-text.
 	Hash(string) (string, error)
@@ -9,4 +10,9 @@ type Hasher interface {
 	// Compare compares plain-text version to the hashed one. An error should
 	// indicate failed comparison.
 	Compare(string, string) error
+
+	// NeedsRehash reports whether the hash was generated with another
+	// algorithm or weaker parameters than the ones Hash uses now, so that
+	// it can be replaced once the plain-text is known.
+	NeedsRehash(string) bool
 }
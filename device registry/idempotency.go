@@ -0,0 +1,33 @@
+package registry
+
+import (
+	"github.com/piusalfred/registry/pkg/errors"
+	"time"
+)
+
+var (
+	ErrIdempotencyKeyReused     = errors.NewKind(errors.Conflict, "idempotency key was used with a different request")
+	ErrIdempotencyKeyInProgress = errors.NewKind(errors.Conflict, "a request with this idempotency key is in progress")
+	ErrInvalidIdempotencyKey    = errors.NewKind(errors.Invalid, "idempotency key must be 1 to 255 characters")
+
+	// ErrIdempotentResponseNotStored is replayed instead of a response that
+	// holds a secret, like a new api key or the credentials of a node
+	ErrIdempotentResponseNotStored = errors.NewKind(errors.Conflict, "the request with this idempotency key was processed, its response holds a secret and is not replayed")
+)
+
+// IdempotentRequest is a request sent with an Idempotency-Key header. Key is
+// scoped to the client that sent it and Hash identifies the request, Status
+// stays zero until the response is recorded.
+type IdempotentRequest struct {
+	Key         string
+	Hash        string
+	Status      int
+	ContentType string
+	Body        []byte
+	Created     time.Time
+}
+
+// Completed reports whether the response of the request is recorded
+func (r IdempotentRequest) Completed() bool {
+	return r.Status != 0
+}
@@ -0,0 +1,110 @@
+package registry
+
+import (
+	"github.com/piusalfred/registry/pkg/errors"
+	"sort"
+)
+
+var (
+	// ErrUnknownRegion indicates a user or a node in a region that does not
+	// exist, or that belongs to another organization
+	ErrUnknownRegion = errors.NewKind(errors.Invalid, "region does not exist")
+
+	// ErrUnknownMaster indicates a master node that does not exist
+	ErrUnknownMaster = errors.NewKind(errors.Invalid, "master node does not exist")
+
+	// ErrMasterNotController is returned when the master of a node is not a
+	// controller
+	ErrMasterNotController = errors.NewKind(errors.Invalid, "master node must be a controller")
+
+	// ErrMasterOutsideRegion is returned when the master of a node is in
+	// another region
+	ErrMasterOutsideRegion = errors.NewKind(errors.Invalid, "master node must be in the region of the node")
+
+	// ErrMasterCycle is returned when a node would become its own master
+	ErrMasterCycle = errors.NewKind(errors.Invalid, "a node can not be its own master")
+
+	// ErrMasterInUse is returned when removing, or moving to another region,
+	// the master of other nodes
+	ErrMasterInUse = errors.NewKind(errors.Conflict, "node is the master of other nodes")
+
+	// ErrInvalidReference is returned by repositories when a row references
+	// one that does not exist
+	ErrInvalidReference = errors.NewKind(errors.Invalid, "reference to a region, node type, organization or node that does not exist")
+)
+
+// CheckMaster validates the master of node against nodes, the nodes of the
+// registry or at least the master of node and the masters above it. The
+// master must be a controller of the region of node and must not have node
+// as one of its own masters.
+func CheckMaster(node Node, nodes []Node) error {
+	if node.Master == "" {
+		return nil
+	}
+
+	byID := make(map[string]Node, len(nodes))
+	for _, n := range nodes {
+		byID[n.UUID] = n
+	}
+
+	master, ok := byID[node.Master]
+	switch {
+	case !ok:
+		return ErrUnknownMaster
+	case master.Type != int(Controller):
+		return ErrMasterNotController
+	case master.Region != node.Region:
+		return ErrMasterOutsideRegion
+	}
+
+	seen := map[string]bool{}
+	for id := node.Master; id != "" && !seen[id]; id = byID[id].Master {
+		if id == node.UUID {
+			return ErrMasterCycle
+		}
+		seen[id] = true
+	}
+
+	return nil
+}
+
+// Slaves returns the nodes whose master is the node id
+func Slaves(id string, nodes []Node) []Node {
+	if id == "" {
+		return nil
+	}
+
+	var slaves []Node
+	for _, n := range nodes {
+		if n.Master == id {
+			slaves = append(slaves, n)
+		}
+	}
+
+	return slaves
+}
+
+// MastersFirst sorts nodes so that masters come before the nodes they
+// control, nodes keep their order otherwise.
+func MastersFirst(nodes []Node) []Node {
+	byID := make(map[string]Node, len(nodes))
+	for _, n := range nodes {
+		byID[n.UUID] = n
+	}
+
+	depth := make(map[string]int, len(nodes))
+	for _, n := range nodes {
+		seen := map[string]bool{n.UUID: true}
+		for m, ok := byID[n.Master]; ok && !seen[m.UUID]; m, ok = byID[m.Master] {
+			seen[m.UUID] = true
+			depth[n.UUID]++
+		}
+	}
+
+	sorted := append([]Node(nil), nodes...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return depth[sorted[i].UUID] < depth[sorted[j].UUID]
+	})
+
+	return sorted
+}
@@ -0,0 +1,55 @@
+package registry_test
+
+import (
+	"testing"
+
+	"github.com/piusalfred/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckMaster(t *testing.T) {
+	controller := int(registry.Controller)
+	nodes := []registry.Node{
+		{UUID: "c1", Type: controller, Region: "AA001"},
+		{UUID: "c2", Type: controller, Region: "AA001", Master: "c1"},
+		{UUID: "c3", Type: controller, Region: "AA002"},
+		{UUID: "s1", Type: int(registry.Sensor), Region: "AA001", Master: "c2"},
+	}
+
+	cases := []struct {
+		desc string
+		node registry.Node
+		err  error
+	}{
+		{desc: "no master", node: registry.Node{UUID: "n", Region: "AA001"}},
+		{desc: "controller", node: registry.Node{UUID: "n", Region: "AA001", Master: "c2"}},
+		{desc: "unknown master", node: registry.Node{UUID: "n", Region: "AA001", Master: "c9"}, err: registry.ErrUnknownMaster},
+		{desc: "sensor master", node: registry.Node{UUID: "n", Region: "AA001", Master: "s1"}, err: registry.ErrMasterNotController},
+		{desc: "other region", node: registry.Node{UUID: "n", Region: "AA001", Master: "c3"}, err: registry.ErrMasterOutsideRegion},
+		{desc: "own master", node: registry.Node{UUID: "c1", Type: controller, Region: "AA001", Master: "c1"}, err: registry.ErrMasterCycle},
+		{desc: "cycle", node: registry.Node{UUID: "c1", Type: controller, Region: "AA001", Master: "c2"}, err: registry.ErrMasterCycle},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.err, registry.CheckMaster(tc.node, nodes), tc.desc)
+	}
+
+	assert.Equal(t, []registry.Node{nodes[3]}, registry.Slaves("c2", nodes))
+	assert.Empty(t, registry.Slaves("", nodes))
+}
+
+func TestMastersFirst(t *testing.T) {
+	nodes := []registry.Node{
+		{UUID: "s1", Master: "c2"},
+		{UUID: "c2", Master: "c1"},
+		{UUID: "o1", Master: "gone"},
+		{UUID: "c1"},
+	}
+
+	var ids []string
+	for _, n := range registry.MastersFirst(nodes) {
+		ids = append(ids, n.UUID)
+	}
+
+	assert.Equal(t, []string{"o1", "c1", "c2", "s1"}, ids)
+}
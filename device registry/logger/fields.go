@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// RequestIDKey is the key of the request id in the log lines
+const RequestIDKey = "request_id"
+
+// Redacted replaces the values of sensitive keys
+const Redacted = "[REDACTED]"
+
+// sensitive are the keys whose values are never logged, keys ending with
+// "_" followed by one of them are redacted too.
+var sensitive = map[string]bool{
+	"password":      true,
+	"passwd":        true,
+	"hash":          true,
+	"secret":        true,
+	"token":         true,
+	"key":           true,
+	"authorization": true,
+	"csr":           true,
+}
+
+type fieldsCtxKey struct{}
+
+type requestIDCtxKey struct{}
+
+// ContextWith returns a copy of ctx carrying keyvals on top of the fields ctx
+// already carries, loggers returned by WithContext add them to their lines.
+func ContextWith(ctx context.Context, keyvals ...interface{}) context.Context {
+	fields := Fields(ctx)
+	fields = append(fields[:len(fields):len(fields)], keyvals...)
+	return context.WithValue(ctx, fieldsCtxKey{}, fields)
+}
+
+// Fields returns the fields carried by ctx
+func Fields(ctx context.Context) []interface{} {
+	fields, _ := ctx.Value(fieldsCtxKey{}).([]interface{})
+	return fields
+}
+
+// WithRequestID returns a copy of ctx carrying the id of the request it
+// belongs to, the id is added to the fields of ctx.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	ctx = context.WithValue(ctx, requestIDCtxKey{}, id)
+	return ContextWith(ctx, RequestIDKey, id)
+}
+
+// RequestID returns the id of the request ctx belongs to, if any
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDCtxKey{}).(string)
+	return id
+}
+
+// Sensitive reports whether the values of key must not be logged
+func Sensitive(key string) bool {
+	key = strings.ToLower(key)
+	if sensitive[key] {
+		return true
+	}
+
+	if i := strings.LastIndexAny(key, "_-."); i >= 0 {
+		return sensitive[key[i+1:]]
+	}
+
+	return false
+}
+
+// Redact returns a copy of keyvals where the values of sensitive keys are
+// replaced by Redacted.
+func Redact(keyvals []interface{}) []interface{} {
+	redacted := make([]interface{}, len(keyvals))
+	copy(redacted, keyvals)
+
+	for i := 0; i+1 < len(redacted); i += 2 {
+		if Sensitive(fmt.Sprint(redacted[i])) {
+			redacted[i+1] = Redacted
+		}
+	}
+
+	return redacted
+}
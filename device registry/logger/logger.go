@@ -1,22 +1,29 @@
 package logger
 
 import (
+	"context"
 	"fmt"
 	"github.com/go-kit/kit/log"
 	"io"
 	"time"
 )
 
-// Logger specifies logging API.
+// Logger specifies logging API. Messages are followed by alternating keys
+// and values, the values of sensitive keys are redacted.
 type Logger interface {
 	// Debug logs any object in JSON format on debug level.
-	Debug(string)
+	Debug(msg string, keyvals ...interface{})
 	// Info logs any object in JSON format on info level.
-	Info(string)
+	Info(msg string, keyvals ...interface{})
 	// Warn logs any object in JSON format on warning level.
-	Warn(string)
+	Warn(msg string, keyvals ...interface{})
 	// Error logs any object in JSON format on error level.
-	Error(string)
+	Error(msg string, keyvals ...interface{})
+	// With returns a logger that adds keyvals to every line it logs.
+	With(keyvals ...interface{}) Logger
+	// WithContext returns a logger that adds the fields carried by ctx to
+	// every line it logs.
+	WithContext(ctx context.Context) Logger
 }
 
 var _ Logger = (*logger)(nil)
@@ -38,26 +45,45 @@ func New(out io.Writer, levelText string) (Logger, error) {
 	return &logger{l, level}, err
 }
 
-func (l logger) Debug(msg string) {
-	if Debug.isAllowed(l.level) {
-		l.kitLogger.Log("level", Debug.String(), "message", msg)
-	}
+// NewNop returns a logger that discards everything
+func NewNop() Logger {
+	return &logger{log.NewNopLogger(), Error}
 }
 
-func (l logger) Info(msg string) {
-	if Info.isAllowed(l.level) {
-		l.kitLogger.Log("level", Info.String(), "message", msg)
-	}
+func (l logger) Debug(msg string, keyvals ...interface{}) {
+	l.log(Debug, msg, keyvals)
+}
+
+func (l logger) Info(msg string, keyvals ...interface{}) {
+	l.log(Info, msg, keyvals)
+}
+
+func (l logger) Warn(msg string, keyvals ...interface{}) {
+	l.log(Warn, msg, keyvals)
 }
 
-func (l logger) Warn(msg string) {
-	if Warn.isAllowed(l.level) {
-		l.kitLogger.Log("level", Warn.String(), "message", msg)
+func (l logger) Error(msg string, keyvals ...interface{}) {
+	l.log(Error, msg, keyvals)
+}
+
+func (l logger) With(keyvals ...interface{}) Logger {
+	if len(keyvals) == 0 {
+		return &l
 	}
+
+	return &logger{log.With(l.kitLogger, Redact(keyvals)...), l.level}
 }
 
-func (l logger) Error(msg string) {
-	if Error.isAllowed(l.level) {
-		l.kitLogger.Log("level", Error.String(), "message", msg)
+func (l logger) WithContext(ctx context.Context) Logger {
+	return l.With(Fields(ctx)...)
+}
+
+func (l logger) log(level Level, msg string, keyvals []interface{}) {
+	if !level.isAllowed(l.level) {
+		return
 	}
+
+	kvs := make([]interface{}, 0, len(keyvals)+4)
+	kvs = append(kvs, "level", level.String(), "message", msg)
+	l.kitLogger.Log(append(kvs, Redact(keyvals)...)...)
 }
@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := New(&buf, "info")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	l.WithContext(ctx).With("method", "AddUser").Info("user added", "user_id", "u1", "password", "secret1", "api_key", "k1")
+	l.Debug("not logged")
+
+	var line map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("want a single JSON line, got %q: %v", buf.String(), err)
+	}
+
+	want := map[string]interface{}{
+		"level":      "info",
+		"message":    "user added",
+		RequestIDKey: "req-1",
+		"method":     "AddUser",
+		"user_id":    "u1",
+		"password":   Redacted,
+		"api_key":    Redacted,
+	}
+	for k, v := range want {
+		if line[k] != v {
+			t.Errorf("%s: want %v, got %v", k, v, line[k])
+		}
+	}
+
+	if got := RequestID(ctx); got != "req-1" {
+		t.Errorf("want request id req-1, got %q", got)
+	}
+}
@@ -1,6 +1,7 @@
 package registry
 
 import (
+	"context"
 	"fmt"
 	"github.com/piusalfred/registry/pkg/errors"
 	"regexp"
@@ -8,7 +9,7 @@ import (
 )
 
 var (
-	ErrInvalidMacAddress   = errors.New("invalid mac address")
+	ErrInvalidMacAddress   = errors.NewKind(errors.Invalid, "invalid mac address")
 	ErrGeneratingNodeToken = errors.New("error generating new node token")
 )
 
@@ -58,6 +59,10 @@ func (t Type) String() string {
 	}
 }
 
+// NoMaster is the master given to UpdateNode to remove the master of a node,
+// an empty master keeps the current one
+const NoMaster = "-"
+
 var macAddrRegex = regexp.MustCompile("^([0-9A-Fa-f]{2}[:-]){5}([0-9A-Fa-f]{2})|([0-9a-fA-F]{4}\\\\.[0-9a-fA-F]{4}\\\\.[0-9a-fA-F]{4})$")
 
 type NodesTree struct {
@@ -76,10 +81,15 @@ type Node struct {
 	Long    string `json:"longitude"`
 	Created string `json:"created"`
 	Master  string `json:"master,omitempty"`
+	Org     string `json:"org,omitempty"`
+
+	//Firmware is the firmware version the node last reported
+	Firmware string `json:"firmware,omitempty"`
+	Labels   Labels `json:"labels,omitempty"`
 }
 
-func CreateNode(provider UUIDProvider, addr, name, region,
-	lat, long, master string, typ int) (node Node, err error) {
+func CreateNode(ctx context.Context, provider UUIDProvider, types NodeTypeRepository,
+	addr, name, region, lat, long, master string, typ int) (node Node, err error) {
 	UUID, err := provider.ID()
 
 	if err != nil {
@@ -91,6 +101,10 @@ func CreateNode(provider UUIDProvider, addr, name, region,
 		return node, err
 	}
 
+	if _, err = nodeType(ctx, types, typ); err != nil {
+		return node, err
+	}
+
 	now := time.Now().Format(time.RFC3339)
 
 	node = Node{
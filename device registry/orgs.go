@@ -0,0 +1,63 @@
+package registry
+
+import (
+	"context"
+	"github.com/piusalfred/registry/pkg/errors"
+	"time"
+)
+
+var (
+	ErrInvalidOrganization = errors.NewKind(errors.Invalid, "invalid organization, name is required")
+	ErrOrganizationInUse   = errors.NewKind(errors.Conflict, "organization still owns regions or users")
+	ErrRegionOutsideOrg    = errors.NewKind(errors.PermissionDenied, "region does not belong to the organization")
+)
+
+// Organization is a tenant of the registry, a utility operating its own grid.
+// It owns regions, users and nodes, none of which are visible to the other
+// organizations. Entities without an organization belong to the network
+// owner and are only visible to the global Admin.
+type Organization struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Desc    string `json:"description,omitempty"`
+	Created string `json:"created"`
+}
+
+func CreateOrganization(provider UUIDProvider, name, desc string) (Organization, error) {
+	if name == "" {
+		return Organization{}, ErrInvalidOrganization
+	}
+
+	id, err := provider.ID()
+	if err != nil {
+		return Organization{}, err
+	}
+
+	return Organization{
+		ID:      id,
+		Name:    name,
+		Desc:    desc,
+		Created: time.Now().Format(time.RFC3339),
+	}, nil
+}
+
+// OrgFromContext returns the organization the request is scoped to, the
+// organization of the api key it was authenticated with. An empty org
+// means the request is not scoped and sees every tenant.
+func OrgFromContext(ctx context.Context) string {
+	if key, ok := APIKeyFromContext(ctx); ok {
+		return key.Org
+	}
+
+	return ""
+}
+
+// checkAdmin fails unless the request is made by the global Admin, requests
+// without an api key are not restricted.
+func checkAdmin(ctx context.Context) error {
+	if OrgFromContext(ctx) != "" {
+		return ErrPermissionDenied
+	}
+
+	return checkRole(ctx, Admin)
+}
@@ -1,5 +1,11 @@
 package errors
 
+import (
+	stderrors "errors"
+	"fmt"
+	"io"
+)
+
 // Error specifies an API that must be fullfiled by error type
 type Error interface {
 
@@ -19,6 +25,13 @@ var _ Error = (*customError)(nil)
 type customError struct {
 	msg string
 	err Error
+
+	// self is the error this one stands for, the wrapper given to Wrap or
+	// the plain error cast to an Error, so that errors.Is and errors.As
+	// still find it.
+	self  error
+	kind  Kind
+	stack []uintptr
 }
 
 func (ce *customError) Error() string {
@@ -39,19 +52,65 @@ func (ce *customError) Err() Error {
 	return ce.err
 }
 
-// Contains inspects if e2 error is contained in any layer of e1 error
+// Kind returns the kind of the error, not the one of the errors it wraps
+func (ce *customError) Kind() Kind {
+	return ce.kind
+}
+
+// Unwrap returns the wrapped error, or the plain error a cast error stands
+// for, so that the standard errors.Is and errors.As walk the whole chain.
+func (ce *customError) Unwrap() error {
+	if ce.err != nil {
+		return ce.err
+	}
+	if _, ok := ce.self.(*customError); !ok && ce.self != nil {
+		return ce.self
+	}
+	return nil
+}
+
+// Is reports whether target is the wrapper given to Wrap, or one of the
+// errors that wrapper wraps
+func (ce *customError) Is(target error) bool {
+	if ce.self == nil || target == nil {
+		return false
+	}
+	return stderrors.Is(ce.self, target)
+}
+
+// As finds the first error of the wrapper given to Wrap that matches target
+func (ce *customError) As(target interface{}) bool {
+	if ce.self == nil || ce.err == nil {
+		return false
+	}
+	return stderrors.As(ce.self, target)
+}
+
+// Format prints the stack of the error with the %+v verb when it was captured
+func (ce *customError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		io.WriteString(s, ce.Error())
+		if s.Flag('+') {
+			if frames := ce.frames(); frames != "" {
+				io.WriteString(s, "\n"+frames)
+			}
+		}
+	case 's':
+		io.WriteString(s, ce.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", ce.Error())
+	}
+}
+
+// Contains reports whether e2 is e1 or one of the errors e1 wraps, like Is.
+// Errors are compared by identity, two errors with the same message are
+// different errors.
 func Contains(e1 error, e2 error) bool {
 	if e1 == nil || e2 == nil {
 		return e2 == e1
 	}
-	ce, ok := e1.(Error)
-	if ok {
-		if ce.Msg() == e2.Error() {
-			return true
-		}
-		return Contains(ce.Err(), e2)
-	}
-	return e1.Error() == e2.Error()
+	return stderrors.Is(e1, e2)
 }
 
 // Wrap returns an Error that wrap err with wrapper
@@ -59,16 +118,19 @@ func Wrap(wrapper error, err error) error {
 	if wrapper == nil || err == nil {
 		return wrapper
 	}
+	ce := &customError{
+		msg:   wrapper.Error(),
+		err:   cast(err),
+		self:  wrapper,
+		stack: callers(),
+	}
 	if w, ok := wrapper.(Error); ok {
-		return &customError{
-			msg: w.Msg(),
-			err: cast(err),
-		}
+		ce.msg = w.Msg()
 	}
-	return &customError{
-		msg: wrapper.Error(),
-		err: cast(err),
+	if w, ok := wrapper.(*customError); ok {
+		ce.kind = w.kind
 	}
+	return ce
 }
 
 func cast(err error) Error {
@@ -79,15 +141,34 @@ func cast(err error) Error {
 		return e
 	}
 	return &customError{
-		msg: err.Error(),
-		err: nil,
+		msg:  err.Error(),
+		err:  nil,
+		self: err,
 	}
 }
 
 // New returns an Error that formats as the given text.
 func New(text string) Error {
 	return &customError{
-		msg: text,
-		err: nil,
+		msg:   text,
+		err:   nil,
+		stack: callers(),
 	}
 }
+
+// Is reports whether any error in the chain of err matches target, it is
+// the standard errors.Is.
+func Is(err, target error) bool {
+	return stderrors.Is(err, target)
+}
+
+// As finds the first error in the chain of err that matches target, it is
+// the standard errors.As.
+func As(err error, target interface{}) bool {
+	return stderrors.As(err, target)
+}
+
+// Unwrap returns the error wrapped by err, if any
+func Unwrap(err error) error {
+	return stderrors.Unwrap(err)
+}
@@ -1,9 +1,13 @@
 package errors_test
 
 import (
+	"database/sql"
+	stderrors "errors"
 	"fmt"
-	"github.com/piusalfred/igrid/pkg/errors"
+	"github.com/piusalfred/registry/pkg/errors"
+	"os"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -101,10 +105,16 @@ func TestContains(t *testing.T) {
 			contains:  true,
 		},
 		{
-			desc:      fmt.Sprintf("level %d wrapped error contains", level),
+			desc:      "res of errors.Wrap(errors.Wrap(err2, err1), err0) contains err2",
+			container: errors.Wrap(errors.Wrap(err2, err1), err0),
+			contained: err2,
+			contains:  true,
+		},
+		{
+			desc:      fmt.Sprintf("level %d wrapped error contains another error with the message of a layer", level),
 			container: wrap(level),
 			contained: errors.New(strconv.Itoa(level / 2)),
-			contains:  true,
+			contains:  false,
 		},
 		{
 			desc:      "superset wrapper error contains subset wrapper error",
@@ -179,6 +189,129 @@ func TestWrap(t *testing.T) {
 	}
 }
 
+func TestIs(t *testing.T) {
+	cases := []struct {
+		desc   string
+		err    error
+		target error
+		is     bool
+	}{
+		{
+			desc:   "wrapper is err1",
+			err:    errors.Wrap(err1, err0),
+			target: err1,
+			is:     true,
+		},
+		{
+			desc:   "wrapped is err0",
+			err:    errors.Wrap(err2, errors.Wrap(err1, err0)),
+			target: err0,
+			is:     true,
+		},
+		{
+			desc:   "wrapped standard error",
+			err:    errors.Wrap(err1, fmt.Errorf("query: %w", sql.ErrNoRows)),
+			target: sql.ErrNoRows,
+			is:     true,
+		},
+		{
+			desc:   "standard error wrapping a registry error",
+			err:    fmt.Errorf("handler: %w", errors.Wrap(err1, err0)),
+			target: err0,
+			is:     true,
+		},
+		{
+			desc:   "error with the same message is not the same error",
+			err:    errors.Wrap(err1, err0),
+			target: errors.New("0"),
+			is:     false,
+		},
+		{
+			desc:   "err0 is not err1",
+			err:    err0,
+			target: err1,
+			is:     false,
+		},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.is, errors.Is(tc.err, tc.target), fmt.Sprintf("%s: expected errors.Is(%v, %v) to be %v\n", tc.desc, tc.err, tc.target, tc.is))
+		assert.Equal(t, tc.is, stderrors.Is(tc.err, tc.target), fmt.Sprintf("%s: expected the standard errors.Is to agree\n", tc.desc))
+	}
+}
+
+func TestAs(t *testing.T) {
+	pathErr := &os.PathError{Op: "open", Path: "ca.crt", Err: os.ErrNotExist}
+
+	var target *os.PathError
+	assert.True(t, errors.As(errors.Wrap(err1, pathErr), &target), "expected to find the wrapped *os.PathError")
+	assert.Equal(t, pathErr, target, "expected the wrapped *os.PathError")
+	assert.True(t, errors.Is(errors.Wrap(err1, pathErr), os.ErrNotExist), "expected the error wrapped by *os.PathError to be found")
+
+	target = nil
+	assert.True(t, errors.As(errors.Wrap(pathErr, err0), &target), "expected to find the *os.PathError wrapper")
+	assert.False(t, errors.As(errors.Wrap(err1, err0), &target), "expected no *os.PathError")
+
+	assert.Equal(t, err0, errors.Unwrap(errors.Wrap(err1, err0)), "expected Unwrap to return the wrapped error")
+	assert.Nil(t, errors.Unwrap(err0), "expected Unwrap of an unwrapped error to be nil")
+}
+
+func TestKind(t *testing.T) {
+	errNotFound := errors.NewKind(errors.NotFound, "node not found")
+	errInvalid := errors.NewKind(errors.Invalid, "invalid node")
+
+	cases := []struct {
+		desc string
+		err  error
+		kind errors.Kind
+	}{
+		{
+			desc: "nil error",
+			err:  nil,
+			kind: errors.Unknown,
+		},
+		{
+			desc: "error without a kind",
+			err:  errors.Wrap(err1, err0),
+			kind: errors.Unknown,
+		},
+		{
+			desc: "error with a kind",
+			err:  errNotFound,
+			kind: errors.NotFound,
+		},
+		{
+			desc: "error wrapped by an error without a kind",
+			err:  fmt.Errorf("get: %w", errors.Wrap(err1, errNotFound)),
+			kind: errors.NotFound,
+		},
+		{
+			desc: "the outermost kind wins",
+			err:  errors.Wrap(errInvalid, errNotFound),
+			kind: errors.Invalid,
+		},
+	}
+
+	for _, tc := range cases {
+		kind := errors.KindOf(tc.err)
+		assert.Equal(t, tc.kind, kind, fmt.Sprintf("%s: expected kind %s got %s\n", tc.desc, tc.kind, kind))
+	}
+}
+
+func TestStack(t *testing.T) {
+	assert.Empty(t, errors.Stack(errors.Wrap(err1, err0)), "expected no stack outside of debug mode")
+
+	errors.SetDebug(true)
+	defer errors.SetDebug(false)
+
+	err := errors.Wrap(err1, err0)
+	stack := errors.Stack(err)
+	assert.True(t, strings.Contains(stack, "TestStack"), fmt.Sprintf("expected the stack to contain the caller, got %q", stack))
+	assert.True(t, strings.Contains(errors.Caller(err), "errors_test.go:"), fmt.Sprintf("expected the caller to be the test, got %q", errors.Caller(err)))
+	assert.Equal(t, err.Error()+"\n"+stack, fmt.Sprintf("%+v", err), "expected %+v to print the stack")
+	assert.Equal(t, err.Error(), fmt.Sprintf("%v", err), "expected %v to print the message only")
+}
+
 func wrap(level int) error {
 	if level == 0 {
 		return errors.New(strconv.Itoa(level))
@@ -0,0 +1,62 @@
+package errors
+
+// Kind classifies errors so that transports can map them to status codes
+// without knowing every error of the registry.
+type Kind int
+
+const (
+	// Unknown is the kind of errors created without one.
+	Unknown Kind = iota
+	// Invalid errors are caused by malformed or missing input.
+	Invalid
+	// Unauthenticated errors are caused by missing or bad credentials.
+	Unauthenticated
+	// PermissionDenied errors are caused by callers acting outside their scope.
+	PermissionDenied
+	// NotFound errors are caused by entities that do not exist.
+	NotFound
+	// Conflict errors are caused by the current state of an entity.
+	Conflict
+	// Unavailable errors are transient, the request can be retried.
+	Unavailable
+	// Internal errors are bugs or failures of a dependency.
+	Internal
+)
+
+var kinds = map[Kind]string{
+	Unknown:          "unknown",
+	Invalid:          "invalid",
+	Unauthenticated:  "unauthenticated",
+	PermissionDenied: "permission denied",
+	NotFound:         "not found",
+	Conflict:         "conflict",
+	Unavailable:      "unavailable",
+	Internal:         "internal",
+}
+
+func (k Kind) String() string {
+	return kinds[k]
+}
+
+// NewKind returns an Error of kind that formats as the given text, errors
+// wrapped by it or wrapping it keep its kind.
+func NewKind(kind Kind, text string) Error {
+	return &customError{
+		msg:   text,
+		err:   nil,
+		kind:  kind,
+		stack: callers(),
+	}
+}
+
+// KindOf returns the kind of the outermost error of the chain of err that
+// has one, Unknown when none has.
+func KindOf(err error) Kind {
+	for err != nil {
+		if k, ok := err.(interface{ Kind() Kind }); ok && k.Kind() != Unknown {
+			return k.Kind()
+		}
+		err = Unwrap(err)
+	}
+	return Unknown
+}
@@ -0,0 +1,75 @@
+package errors
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+// maxDepth bounds the number of frames captured per error
+const maxDepth = 32
+
+var debug int32
+
+// SetDebug turns the capture of the stack of the errors created by New,
+// NewKind and Wrap on or off. Capturing is off by default since sentinel
+// errors are created once and most errors never have their stack printed.
+func SetDebug(on bool) {
+	var v int32
+	if on {
+		v = 1
+	}
+	atomic.StoreInt32(&debug, v)
+}
+
+func callers() []uintptr {
+	if atomic.LoadInt32(&debug) == 0 {
+		return nil
+	}
+	pcs := make([]uintptr, maxDepth)
+	//skip runtime.Callers, callers and the constructor
+	n := runtime.Callers(3, pcs)
+	return pcs[:n]
+}
+
+// Stack returns the stack captured by the outermost error of the chain of
+// err, one "function\n\tfile:line" entry per frame, or "" when none was.
+func Stack(err error) string {
+	for err != nil {
+		if ce, ok := err.(*customError); ok && len(ce.stack) > 0 {
+			return ce.frames()
+		}
+		err = Unwrap(err)
+	}
+	return ""
+}
+
+// Caller returns the "file:line" where the outermost error of the chain of
+// err with a captured stack was created, or "" when none was.
+func Caller(err error) string {
+	for err != nil {
+		if ce, ok := err.(*customError); ok && len(ce.stack) > 0 {
+			frame, _ := runtime.CallersFrames(ce.stack).Next()
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		err = Unwrap(err)
+	}
+	return ""
+}
+
+func (ce *customError) frames() string {
+	if len(ce.stack) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	frames := runtime.CallersFrames(ce.stack)
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
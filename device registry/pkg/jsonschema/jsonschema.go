@@ -0,0 +1,576 @@
+// Package jsonschema validates JSON documents against JSON Schemas.
+//
+// It implements the validation keywords of JSON Schema 2020-12 the node
+// schemas of the registry need: type, enum, const, the numeric, string,
+// array and object keywords, and the allOf, anyOf, oneOf and not
+// combinators. References, formats and the other keywords are ignored, as
+// the specification requires for keywords a validator does not know.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Schema is a compiled JSON Schema
+type Schema struct {
+	//always is set for the boolean schemas true and false
+	always *bool
+
+	types    []string
+	enum     []interface{}
+	constant *interface{}
+
+	minimum, maximum                   *big.Float
+	exclusiveMinimum, exclusiveMaximum *big.Float
+	multipleOf                         *big.Float
+
+	minLength, maxLength *int
+	pattern              *regexp.Regexp
+
+	items              *Schema
+	minItems, maxItems *int
+	uniqueItems        bool
+
+	properties           map[string]*Schema
+	required             []string
+	additionalProperties *Schema
+	minProperties        *int
+	maxProperties        *int
+
+	allOf, anyOf, oneOf []*Schema
+	not                 *Schema
+}
+
+// Error is a keyword a document does not satisfy, Path is the JSON Pointer
+// of the value that fails it
+type Error struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (e Error) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "/"
+	}
+
+	return path + ": " + e.Message
+}
+
+var types = map[string]bool{
+	"null": true, "boolean": true, "object": true, "array": true,
+	"number": true, "string": true, "integer": true,
+}
+
+// Compile parses the JSON Schema data
+func Compile(data []byte) (*Schema, error) {
+	v, err := decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("schema is not valid JSON: %v", err)
+	}
+
+	return compile(v, "")
+}
+
+func compile(v interface{}, path string) (*Schema, error) {
+	s := &Schema{}
+
+	switch m := v.(type) {
+	case bool:
+		s.always = &m
+		return s, nil
+	case map[string]interface{}:
+		return s, s.compileKeywords(m, path)
+	}
+
+	return nil, fmt.Errorf("%s: a schema must be an object or a boolean", pointer(path))
+}
+
+func (s *Schema) compileKeywords(m map[string]interface{}, path string) (err error) {
+	if t, ok := m["type"]; ok {
+		if s.types, err = stringList(t, path+"/type"); err != nil {
+			return err
+		}
+
+		for _, name := range s.types {
+			if !types[name] {
+				return fmt.Errorf("%s/type: unknown type %q", path, name)
+			}
+		}
+	}
+
+	if e, ok := m["enum"]; ok {
+		values, ok := e.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s/enum: must be an array", path)
+		}
+		s.enum = values
+	}
+
+	if c, ok := m["const"]; ok {
+		s.constant = &c
+	}
+
+	numbers := map[string]**big.Float{
+		"minimum":          &s.minimum,
+		"maximum":          &s.maximum,
+		"exclusiveMinimum": &s.exclusiveMinimum,
+		"exclusiveMaximum": &s.exclusiveMaximum,
+		"multipleOf":       &s.multipleOf,
+	}
+	for keyword, field := range numbers {
+		if n, ok := m[keyword]; ok {
+			if *field, err = number(n, path+"/"+keyword); err != nil {
+				return err
+			}
+		}
+	}
+
+	if s.multipleOf != nil && s.multipleOf.Sign() <= 0 {
+		return fmt.Errorf("%s/multipleOf: must be greater than 0", path)
+	}
+
+	counts := map[string]**int{
+		"minLength":     &s.minLength,
+		"maxLength":     &s.maxLength,
+		"minItems":      &s.minItems,
+		"maxItems":      &s.maxItems,
+		"minProperties": &s.minProperties,
+		"maxProperties": &s.maxProperties,
+	}
+	for keyword, field := range counts {
+		if n, ok := m[keyword]; ok {
+			if *field, err = count(n, path+"/"+keyword); err != nil {
+				return err
+			}
+		}
+	}
+
+	if p, ok := m["pattern"]; ok {
+		expr, ok := p.(string)
+		if !ok {
+			return fmt.Errorf("%s/pattern: must be a string", path)
+		}
+
+		if s.pattern, err = regexp.Compile(expr); err != nil {
+			return fmt.Errorf("%s/pattern: %v", path, err)
+		}
+	}
+
+	if u, ok := m["uniqueItems"]; ok {
+		if s.uniqueItems, ok = u.(bool); !ok {
+			return fmt.Errorf("%s/uniqueItems: must be a boolean", path)
+		}
+	}
+
+	if r, ok := m["required"]; ok {
+		if _, ok := r.([]interface{}); !ok {
+			return fmt.Errorf("%s/required: must be an array of strings", path)
+		}
+
+		if s.required, err = stringList(r, path+"/required"); err != nil {
+			return err
+		}
+	}
+
+	if p, ok := m["properties"]; ok {
+		props, ok := p.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s/properties: must be an object", path)
+		}
+
+		s.properties = make(map[string]*Schema, len(props))
+		for name, sub := range props {
+			if s.properties[name], err = compile(sub, path+"/properties/"+escape(name)); err != nil {
+				return err
+			}
+		}
+	}
+
+	subschemas := map[string]**Schema{
+		"items":                &s.items,
+		"additionalProperties": &s.additionalProperties,
+		"not":                  &s.not,
+	}
+	for keyword, field := range subschemas {
+		if sub, ok := m[keyword]; ok {
+			if *field, err = compile(sub, path+"/"+keyword); err != nil {
+				return err
+			}
+		}
+	}
+
+	combinators := map[string]*[]*Schema{
+		"allOf": &s.allOf,
+		"anyOf": &s.anyOf,
+		"oneOf": &s.oneOf,
+	}
+	for keyword, field := range combinators {
+		c, ok := m[keyword]
+		if !ok {
+			continue
+		}
+
+		list, ok := c.([]interface{})
+		if !ok || len(list) == 0 {
+			return fmt.Errorf("%s/%s: must be a non empty array", path, keyword)
+		}
+
+		for i, sub := range list {
+			compiled, err := compile(sub, path+"/"+keyword+"/"+strconv.Itoa(i))
+			if err != nil {
+				return err
+			}
+			*field = append(*field, compiled)
+		}
+	}
+
+	return nil
+}
+
+// Validate returns the keywords of s the JSON document data does not
+// satisfy, none when it is valid. A document that is not JSON fails with
+// a single error.
+func (s *Schema) Validate(data []byte) []Error {
+	v, err := decode(data)
+	if err != nil {
+		return []Error{{Message: "not valid JSON: " + err.Error()}}
+	}
+
+	return s.validate(v, "")
+}
+
+func (s *Schema) validate(v interface{}, path string) []Error {
+	if s.always != nil {
+		if *s.always {
+			return nil
+		}
+		return []Error{{Path: path, Message: "no value is allowed"}}
+	}
+
+	var errs []Error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, Error{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(s.types) > 0 && !s.hasType(v) {
+		fail("expected %s, got %s", strings.Join(s.types, " or "), typeOf(v))
+		return errs
+	}
+
+	if s.enum != nil {
+		found := false
+		for _, e := range s.enum {
+			if equal(v, e) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("value is not one of the enum values")
+		}
+	}
+
+	if s.constant != nil && !equal(v, *s.constant) {
+		fail("value is not the const value")
+	}
+
+	switch value := v.(type) {
+	case json.Number:
+		errs = append(errs, s.validateNumber(value, path)...)
+
+	case string:
+		length := utf8.RuneCountInString(value)
+		if s.minLength != nil && length < *s.minLength {
+			fail("shorter than %d characters", *s.minLength)
+		}
+		if s.maxLength != nil && length > *s.maxLength {
+			fail("longer than %d characters", *s.maxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(value) {
+			fail("does not match the pattern %s", s.pattern)
+		}
+
+	case []interface{}:
+		if s.minItems != nil && len(value) < *s.minItems {
+			fail("fewer than %d items", *s.minItems)
+		}
+		if s.maxItems != nil && len(value) > *s.maxItems {
+			fail("more than %d items", *s.maxItems)
+		}
+		if s.uniqueItems {
+			for i := range value {
+				for j := i + 1; j < len(value); j++ {
+					if equal(value[i], value[j]) {
+						fail("items %d and %d are equal", i, j)
+					}
+				}
+			}
+		}
+		if s.items != nil {
+			for i, item := range value {
+				errs = append(errs, s.items.validate(item, path+"/"+strconv.Itoa(i))...)
+			}
+		}
+
+	case map[string]interface{}:
+		if s.minProperties != nil && len(value) < *s.minProperties {
+			fail("fewer than %d properties", *s.minProperties)
+		}
+		if s.maxProperties != nil && len(value) > *s.maxProperties {
+			fail("more than %d properties", *s.maxProperties)
+		}
+		for _, name := range s.required {
+			if _, ok := value[name]; !ok {
+				fail("missing required property %q", name)
+			}
+		}
+
+		names := make([]string, 0, len(value))
+		for name := range value {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			sub, ok := s.properties[name]
+			if !ok {
+				sub = s.additionalProperties
+			}
+			if sub != nil {
+				errs = append(errs, sub.validate(value[name], path+"/"+escape(name))...)
+			}
+		}
+	}
+
+	for _, sub := range s.allOf {
+		errs = append(errs, sub.validate(v, path)...)
+	}
+
+	if len(s.anyOf) > 0 && s.matching(s.anyOf, v, path) == 0 {
+		fail("value does not match any schema of anyOf")
+	}
+
+	if len(s.oneOf) > 0 {
+		if n := s.matching(s.oneOf, v, path); n != 1 {
+			fail("value matches %d schemas of oneOf instead of one", n)
+		}
+	}
+
+	if s.not != nil && len(s.not.validate(v, path)) == 0 {
+		fail("value matches the schema of not")
+	}
+
+	return errs
+}
+
+func (s *Schema) validateNumber(n json.Number, path string) []Error {
+	var errs []Error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, Error{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	f, _, err := big.ParseFloat(string(n), 10, 256, big.ToNearestEven)
+	if err != nil {
+		fail("invalid number %s", n)
+		return errs
+	}
+
+	if s.minimum != nil && f.Cmp(s.minimum) < 0 {
+		fail("less than the minimum %s", s.minimum.Text('g', -1))
+	}
+	if s.maximum != nil && f.Cmp(s.maximum) > 0 {
+		fail("greater than the maximum %s", s.maximum.Text('g', -1))
+	}
+	if s.exclusiveMinimum != nil && f.Cmp(s.exclusiveMinimum) <= 0 {
+		fail("not greater than the exclusive minimum %s", s.exclusiveMinimum.Text('g', -1))
+	}
+	if s.exclusiveMaximum != nil && f.Cmp(s.exclusiveMaximum) >= 0 {
+		fail("not less than the exclusive maximum %s", s.exclusiveMaximum.Text('g', -1))
+	}
+	if s.multipleOf != nil {
+		q := new(big.Float).Quo(f, s.multipleOf)
+		if !q.IsInt() {
+			fail("not a multiple of %s", s.multipleOf.Text('g', -1))
+		}
+	}
+
+	return errs
+}
+
+// matching returns how many of schemas v is valid against
+func (s *Schema) matching(schemas []*Schema, v interface{}, path string) int {
+	n := 0
+	for _, sub := range schemas {
+		if len(sub.validate(v, path)) == 0 {
+			n++
+		}
+	}
+
+	return n
+}
+
+func (s *Schema) hasType(v interface{}) bool {
+	actual := typeOf(v)
+	for _, t := range s.types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// typeOf returns the JSON Schema type of the decoded value v, numbers
+// without a fractional part are integers
+func typeOf(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	case json.Number:
+		f, _, err := big.ParseFloat(string(value), 10, 256, big.ToNearestEven)
+		if err == nil && f.IsInt() {
+			return "integer"
+		}
+		return "number"
+	}
+
+	return fmt.Sprintf("%T", v)
+}
+
+// equal compares decoded JSON values, numbers are compared by value
+func equal(a, b interface{}) bool {
+	switch x := a.(type) {
+	case json.Number:
+		y, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		fx, _, errx := big.ParseFloat(string(x), 10, 256, big.ToNearestEven)
+		fy, _, erry := big.ParseFloat(string(y), 10, 256, big.ToNearestEven)
+		return errx == nil && erry == nil && fx.Cmp(fy) == 0
+
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equal(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, v := range x {
+			w, ok := y[k]
+			if !ok || !equal(v, w) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return a == b
+}
+
+// decode decodes a single JSON value, keeping numbers exact
+func decode(data []byte) (interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	if d.More() {
+		return nil, fmt.Errorf("unexpected data after the JSON value")
+	}
+
+	return v, nil
+}
+
+func stringList(v interface{}, path string) ([]string, error) {
+	if s, ok := v.(string); ok {
+		return []string{s}, nil
+	}
+
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: must be a string or an array of strings", pointer(path))
+	}
+
+	strs := make([]string, 0, len(list))
+	for _, item := range list {
+		s, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s: must be an array of strings", pointer(path))
+		}
+		strs = append(strs, s)
+	}
+
+	return strs, nil
+}
+
+func number(v interface{}, path string) (*big.Float, error) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return nil, fmt.Errorf("%s: must be a number", pointer(path))
+	}
+
+	f, _, err := big.ParseFloat(string(n), 10, 256, big.ToNearestEven)
+	if err != nil {
+		return nil, fmt.Errorf("%s: must be a number", pointer(path))
+	}
+
+	return f, nil
+}
+
+func count(v interface{}, path string) (*int, error) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return nil, fmt.Errorf("%s: must be a non negative integer", pointer(path))
+	}
+
+	i, err := strconv.Atoi(string(n))
+	if err != nil || i < 0 {
+		return nil, fmt.Errorf("%s: must be a non negative integer", pointer(path))
+	}
+
+	return &i, nil
+}
+
+// escape escapes a property name for a JSON Pointer (RFC 6901)
+func escape(name string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+}
+
+func pointer(path string) string {
+	if path == "" {
+		return "/"
+	}
+
+	return path
+}
@@ -0,0 +1,92 @@
+package jsonschema_test
+
+import (
+	"testing"
+
+	"github.com/piusalfred/registry/pkg/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const meter = `{
+	"type": "object",
+	"required": ["voltage", "ts"],
+	"properties": {
+		"voltage": {"type": "number", "minimum": 0, "exclusiveMaximum": 500},
+		"phase": {"enum": ["a", "b", "c"]},
+		"ts": {"type": "integer"},
+		"tags": {"type": "array", "items": {"type": "string", "pattern": "^[a-z]+$"}, "uniqueItems": true, "maxItems": 3}
+	},
+	"additionalProperties": false
+}`
+
+func TestValidate(t *testing.T) {
+	s, err := jsonschema.Compile([]byte(meter))
+	require.Nil(t, err)
+
+	cases := []struct {
+		desc    string
+		payload string
+		paths   []string
+	}{
+		{desc: "valid", payload: `{"voltage": 230.5, "phase": "a", "ts": 1700000000, "tags": ["grid"]}`},
+		{desc: "integer written as a float", payload: `{"voltage": 230, "ts": 1.7e9}`},
+		{desc: "missing required", payload: `{"voltage": 230}`, paths: []string{""}},
+		{desc: "wrong type", payload: `{"voltage": "230", "ts": 1}`, paths: []string{"/voltage"}},
+		{desc: "out of range", payload: `{"voltage": 500, "ts": 1}`, paths: []string{"/voltage"}},
+		{desc: "not in enum", payload: `{"voltage": 1, "ts": 1, "phase": "d"}`, paths: []string{"/phase"}},
+		{desc: "fractional integer", payload: `{"voltage": 1, "ts": 1.5}`, paths: []string{"/ts"}},
+		{desc: "additional property", payload: `{"voltage": 1, "ts": 1, "current": 3}`, paths: []string{"/current"}},
+		{desc: "bad items", payload: `{"voltage": 1, "ts": 1, "tags": ["ok", "NO", "ok"]}`, paths: []string{"/tags", "/tags/1"}},
+		{desc: "not an object", payload: `[1, 2]`, paths: []string{""}},
+		{desc: "not json", payload: `{"voltage": `, paths: []string{""}},
+	}
+
+	for _, tc := range cases {
+		var paths []string
+		for _, e := range s.Validate([]byte(tc.payload)) {
+			paths = append(paths, e.Path)
+		}
+
+		assert.Equal(t, tc.paths, paths, tc.desc)
+	}
+}
+
+func TestCombinators(t *testing.T) {
+	s, err := jsonschema.Compile([]byte(`{
+		"oneOf": [
+			{"type": "object", "required": ["on"], "properties": {"on": {"const": true}}},
+			{"type": "object", "required": ["level"], "properties": {"level": {"type": "integer", "multipleOf": 10}}}
+		],
+		"not": {"required": ["debug"]}
+	}`))
+	require.Nil(t, err)
+
+	assert.Empty(t, s.Validate([]byte(`{"on": true}`)))
+	assert.Empty(t, s.Validate([]byte(`{"level": 30}`)))
+	assert.NotEmpty(t, s.Validate([]byte(`{"level": 35}`)), "not a multiple")
+	assert.NotEmpty(t, s.Validate([]byte(`{"on": true, "level": 30}`)), "matches both schemas of oneOf")
+	assert.NotEmpty(t, s.Validate([]byte(`{"on": true, "debug": 1}`)), "matches the schema of not")
+}
+
+func TestCompile(t *testing.T) {
+	for _, schema := range []string{`true`, `false`, `{}`, meter} {
+		_, err := jsonschema.Compile([]byte(schema))
+		assert.Nil(t, err, schema)
+	}
+
+	for _, schema := range []string{
+		`"object"`,
+		`{"type": "float"}`,
+		`{"required": "voltage"}`,
+		`{"pattern": "("}`,
+		`{"minLength": -1}`,
+		`{"properties": {"voltage": 1}}`,
+		`{"anyOf": []}`,
+		`{"multipleOf": 0}`,
+		`{"type": "object"} {}`,
+	} {
+		_, err := jsonschema.Compile([]byte(schema))
+		assert.NotNil(t, err, schema)
+	}
+}
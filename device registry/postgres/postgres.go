@@ -1,16 +1,12 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	_ "github.com/lib/pq"
-	"github.com/piusalfred/registry"
-)
-
-var (
-	_ registry.UserRepository   = (*userRepo)(nil)
-	_ registry.NodeRepository   = (*nodesRepo)(nil)
-	_ registry.RegionRepository = (*regionsRepo)(nil)
+	"github.com/lib/pq"
+	"github.com/piusalfred/registry/store"
+	"time"
 )
 
 const (
@@ -22,8 +18,7 @@ const (
 	sslmode  = "disable"
 )
 
-// Connect creates a connection to the PostgreSQL instance and applies any
-func Connect() (*sql.DB, error) {
+func Connect() (*store.DB, error) {
 	url := fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=%s", hostname, port, user, dbname, password, sslmode)
 
 	db, err := sql.Open("postgres", url)
@@ -37,7 +32,73 @@ func Connect() (*sql.DB, error) {
 		return nil, err
 	}
 
-	return db, nil
+	return New(db), nil
+}
+
+// New returns db with the postgres dialect
+func New(db *sql.DB) *store.DB {
+	return store.New(db, dialect{})
+}
+
+// foreignKeyViolation is the postgres error code returned when a row is
+// still referenced by another table
+const foreignKeyViolation = "23503"
+
+// dialect is the postgres dialect, the queries and the schema of the sql
+// package are written for postgres so they are used as they are
+type dialect struct{}
+
+func (dialect) Rebind(query string) string {
+	return query
+}
+
+func (dialect) Timestamp(t time.Time) time.Time {
+	return t
+}
+
+func (dialect) Array(s []string) interface{} {
+	return pq.Array(s)
+}
+
+func (dialect) ScanArray(s *[]string) interface{} {
+	return pq.Array(s)
+}
+
+func (dialect) ForeignKeyViolation(err error) bool {
+	pqErr, ok := err.(*pq.Error)
+	return ok && pqErr.Code == foreignKeyViolation
+}
+
+func (dialect) SnapshotOptions() *sql.TxOptions {
+	return &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+}
+
+func (dialect) Schema(schema string) string {
+	return schema
+}
+
+// foreignKeyExists reports whether a foreign key constraint of the table $1
+// includes the column $2
+const foreignKeyExists = `SELECT exists (SELECT 1 FROM pg_constraint c
+    JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = ANY (c.conkey)
+    WHERE c.contype = 'f' AND c.conrelid = $1::regclass AND a.attname = $2);`
+
+func (dialect) AddForeignKey(ctx context.Context, db *sql.DB, table, column, ref string) error {
+	var exists bool
+	err := db.QueryRowContext(ctx, foreignKeyExists, table, column).Scan(&exists)
+	if err != nil || exists {
+		return err
+	}
+
+	stmt := fmt.Sprintf("ALTER TABLE %s ADD FOREIGN KEY (%s) REFERENCES %s (id);", table, column, ref)
+	_, err = db.ExecContext(ctx, stmt)
+	return err
+}
+
+func (dialect) AlterColumnType(ctx context.Context, db *sql.DB, table, column, typ string) error {
+	stmt := fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s;", table, column, typ)
+	_, err := db.ExecContext(ctx, stmt)
+	return err
 }
 
 /*func (p postgresRepo) rowExists(query string, args ...interface{}) bool {
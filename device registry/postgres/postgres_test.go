@@ -0,0 +1,51 @@
+package postgres_test
+
+import (
+	"context"
+	"github.com/piusalfred/registry/postgres"
+	"github.com/piusalfred/registry/repotest"
+	"github.com/piusalfred/registry/store"
+	"os"
+	"testing"
+)
+
+// TestRepositories runs against a database on localhost, see Connect. It is
+// skipped when postgres is not running, unless CI is set.
+func TestRepositories(t *testing.T) {
+	db, err := postgres.Connect()
+	if err != nil {
+		if os.Getenv("CI") != "" {
+			t.Fatalf("postgres is not available: %v", err)
+		}
+		t.Skipf("postgres is not available: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	if err := db.Migrate(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Migrated(ctx, db.DB); err != nil {
+		t.Fatal(err)
+	}
+
+	repotest.Run(t, repotest.Repositories{
+		Users:   store.NewUserRepository(db),
+		Nodes:   store.NewNodeRepository(db),
+		Regions: store.NewRegionRepository(db),
+		Events:  store.NewEventStore(db),
+
+		Types:  store.NewNodeTypeRepository(db),
+		Claims: store.NewClaimRepository(db),
+		Certs:  store.NewCertificateRepository(db),
+		Keys:   store.NewAPIKeyRepository(db),
+		Orgs:   store.NewOrganizationRepository(db),
+
+		Idempotency: store.NewIdempotencyRepository(db),
+		Firmware:    store.NewFirmwareRepository(db),
+		Campaigns:   store.NewCampaignRepository(db),
+		Schemas:     store.NewSchemaRepository(db),
+		Quotas:      store.NewQuotaRepository(db),
+	})
+}
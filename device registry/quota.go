@@ -0,0 +1,146 @@
+package registry
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"github.com/piusalfred/registry/pkg/errors"
+)
+
+var (
+	// ErrInvalidQuota indicates a quota of an unknown scope, without the id
+	// of the region or organization it limits, or with negative limits
+	ErrInvalidQuota = errors.NewKind(errors.Invalid, "quota must limit a region or an organization with positive limits")
+
+	// ErrQuotaExceeded is returned when adding a user or a node would go
+	// over the quota of its region or of its organization
+	ErrQuotaExceeded = errors.NewKind(errors.Conflict, "quota exceeded")
+)
+
+// QuotaScope is what a quota limits
+type QuotaScope string
+
+const (
+	// QuotaRegion quotas limit the users and nodes of a region, those of
+	// its sub-regions are not counted
+	QuotaRegion QuotaScope = "region"
+	// QuotaOrg quotas limit the users and nodes of an organization
+	QuotaOrg QuotaScope = "org"
+)
+
+// NodeLimits are the maximum number of nodes by node type id
+type NodeLimits map[int]int
+
+// Value implements driver.Valuer, limits are stored as a JSON object and
+// no limits as NULL
+func (l NodeLimits) Value() (driver.Value, error) {
+	if len(l) == 0 {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (l *NodeLimits) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, l)
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	}
+
+	return fmt.Errorf("cannot scan %T into node limits", src)
+}
+
+// Quota limits the users and the nodes of each type a region, or an
+// organization, can have. Zero limits and node types without a limit are
+// unlimited.
+type Quota struct {
+	Scope QuotaScope `json:"scope"`
+	ID    string     `json:"id"`
+	Users int        `json:"users,omitempty"`
+	Nodes NodeLimits `json:"nodes,omitempty"`
+	Org   string     `json:"org,omitempty"`
+}
+
+// Validate checks the fields of q
+func (q Quota) Validate() error {
+	if (q.Scope != QuotaRegion && q.Scope != QuotaOrg) || q.ID == "" || q.Users < 0 {
+		return ErrInvalidQuota
+	}
+
+	for typ, limit := range q.Nodes {
+		if typ <= 0 || limit < 0 {
+			return ErrInvalidQuota
+		}
+	}
+
+	return nil
+}
+
+// Consumption is how much of a limit is used, a zero Limit is unlimited
+type Consumption struct {
+	Used  int `json:"used"`
+	Limit int `json:"limit,omitempty"`
+}
+
+// Exceeded reports whether adding n more would go over the limit
+func (c Consumption) Exceeded(n int) bool {
+	return c.Limit > 0 && c.Used+n > c.Limit
+}
+
+// Usage is the consumption of a region or an organization against its quota,
+// Nodes has the node types with a limit or with nodes
+type Usage struct {
+	Scope QuotaScope          `json:"scope"`
+	ID    string              `json:"id"`
+	Users Consumption         `json:"users"`
+	Nodes map[int]Consumption `json:"nodes"`
+}
+
+// CountUsage counts users users and nodes, the number of nodes by node type
+// id, against the limits of q. They must be those of the region or
+// organization of q.
+func CountUsage(q Quota, users int, nodes map[int]int) Usage {
+	u := Usage{
+		Scope: q.Scope,
+		ID:    q.ID,
+		Users: Consumption{Used: users, Limit: q.Users},
+		Nodes: map[int]Consumption{},
+	}
+
+	for typ, limit := range q.Nodes {
+		u.Nodes[typ] = Consumption{Limit: limit}
+	}
+
+	for typ, n := range nodes {
+		c := u.Nodes[typ]
+		c.Used += n
+		u.Nodes[typ] = c
+	}
+
+	return u
+}
+
+// Check fails with ErrQuotaExceeded when adding users users and a node of
+// type typ, when it is not 0, goes over the limits of u
+func (u Usage) Check(users int, typ int) error {
+	if users > 0 && u.Users.Exceeded(users) {
+		return errors.Wrap(ErrQuotaExceeded, fmt.Errorf("%s %s allows at most %d users", u.Scope, u.ID, u.Users.Limit))
+	}
+
+	if c := u.Nodes[typ]; typ != 0 && c.Exceeded(1) {
+		return errors.Wrap(ErrQuotaExceeded, fmt.Errorf("%s %s allows at most %d nodes of type %d", u.Scope, u.ID, c.Limit, typ))
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package registry_test
+
+import (
+	"testing"
+
+	"github.com/piusalfred/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuotaValidate(t *testing.T) {
+	cases := []struct {
+		desc  string
+		quota registry.Quota
+		err   error
+	}{
+		{desc: "region", quota: registry.Quota{Scope: registry.QuotaRegion, ID: "AA001", Users: 10, Nodes: registry.NodeLimits{1: 100}}},
+		{desc: "unlimited org", quota: registry.Quota{Scope: registry.QuotaOrg, ID: "tanesco"}},
+		{desc: "unknown scope", quota: registry.Quota{Scope: "site", ID: "AA001"}, err: registry.ErrInvalidQuota},
+		{desc: "no id", quota: registry.Quota{Scope: registry.QuotaRegion}, err: registry.ErrInvalidQuota},
+		{desc: "negative users", quota: registry.Quota{Scope: registry.QuotaRegion, ID: "AA001", Users: -1}, err: registry.ErrInvalidQuota},
+		{desc: "negative nodes", quota: registry.Quota{Scope: registry.QuotaRegion, ID: "AA001", Nodes: registry.NodeLimits{1: -1}}, err: registry.ErrInvalidQuota},
+		{desc: "no node type", quota: registry.Quota{Scope: registry.QuotaRegion, ID: "AA001", Nodes: registry.NodeLimits{0: 1}}, err: registry.ErrInvalidQuota},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.err, tc.quota.Validate(), tc.desc)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	quota := registry.Quota{Scope: registry.QuotaRegion, ID: "AA001", Users: 2, Nodes: registry.NodeLimits{1: 2, 3: 1}}
+	u := registry.CountUsage(quota, 2, map[int]int{1: 1, 2: 2})
+
+	assert.Equal(t, registry.Consumption{Used: 2, Limit: 2}, u.Users)
+	assert.Equal(t, map[int]registry.Consumption{
+		1: {Used: 1, Limit: 2},
+		2: {Used: 2},
+		3: {Limit: 1},
+	}, u.Nodes)
+
+	assert.True(t, u.Users.Exceeded(1), "user over the limit")
+	assert.False(t, u.Nodes[1].Exceeded(1))
+	assert.False(t, u.Nodes[2].Exceeded(100), "node type without a limit")
+	assert.True(t, u.Nodes[3].Exceeded(2))
+}
@@ -1,7 +1,20 @@
 package registry
 
+import "github.com/piusalfred/registry/pkg/errors"
+
+// ErrRegionInUse is returned when removing a region that still has users,
+// nodes or sub-regions
+var ErrRegionInUse = errors.NewKind(errors.Conflict, "region still has users, nodes or sub-regions")
+
 type Region struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
 	Desc string `json:"description"`
+	Org  string `json:"org,omitempty"`
+	//Parent is the region this one is part of, regions without a parent
+	//are roots
+	Parent string `json:"parent,omitempty"`
+	//Boundary is the area the nodes of the region are located in, nodes of
+	//regions without a boundary can be located anywhere
+	Boundary *Geometry `json:"boundary,omitempty"`
 }
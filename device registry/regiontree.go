@@ -0,0 +1,163 @@
+package registry
+
+import (
+	"github.com/piusalfred/registry/pkg/errors"
+	"sort"
+)
+
+var (
+	// ErrRegionCycle is returned when a region would become its own ancestor
+	ErrRegionCycle = errors.NewKind(errors.Invalid, "a region can not be its own ancestor")
+
+	// ErrUnknownParent indicates a parent region that does not exist or
+	// belongs to another organization
+	ErrUnknownParent = errors.NewKind(errors.Invalid, "parent region not found in the organization of the region")
+)
+
+// RegionFilter selects the entities of a region, and of its sub-regions when
+// Subregions is set. The zero value selects the entities of every region.
+type RegionFilter struct {
+	Region     string `json:"region,omitempty"`
+	Subregions bool   `json:"subregions,omitempty"`
+}
+
+// RegionTree indexes regions by their parent to walk the hierarchy, e.g.
+// region → zone → feeder. Regions whose parent is not in the tree are roots.
+type RegionTree struct {
+	regions  map[string]Region
+	children map[string][]string
+}
+
+// NewRegionTree builds the tree of regions
+func NewRegionTree(regions []Region) RegionTree {
+	t := RegionTree{
+		regions:  make(map[string]Region, len(regions)),
+		children: make(map[string][]string),
+	}
+
+	for _, r := range regions {
+		t.regions[r.ID] = r
+	}
+
+	for _, r := range regions {
+		parent := r.Parent
+		if _, ok := t.regions[parent]; !ok {
+			parent = ""
+		}
+		t.children[parent] = append(t.children[parent], r.ID)
+	}
+
+	for _, ids := range t.children {
+		sort.Strings(ids)
+	}
+
+	return t
+}
+
+// Get returns the region id of the tree
+func (t RegionTree) Get(id string) (Region, bool) {
+	r, ok := t.regions[id]
+	return r, ok
+}
+
+// Ancestors returns the parent of the region id, the parent of the parent
+// and so on up to the root
+func (t RegionTree) Ancestors(id string) []Region {
+	var ancestors []Region
+
+	//seen stops at cycles, the service never creates them
+	seen := map[string]bool{id: true}
+	r, ok := t.regions[id]
+	for ok {
+		parent, found := t.regions[r.Parent]
+		if !found || seen[parent.ID] {
+			break
+		}
+
+		seen[parent.ID] = true
+		ancestors = append(ancestors, parent)
+		r = parent
+	}
+
+	return ancestors
+}
+
+// Subtree returns the region id followed by its descendants, every region
+// comes before its children
+func (t RegionTree) Subtree(id string) []Region {
+	if _, ok := t.regions[id]; !ok {
+		return nil
+	}
+
+	var regions []Region
+
+	seen := map[string]bool{}
+	var walk func(id string)
+	walk = func(id string) {
+		if seen[id] {
+			return
+		}
+		seen[id] = true
+
+		regions = append(regions, t.regions[id])
+		for _, child := range t.children[id] {
+			walk(child)
+		}
+	}
+	walk(id)
+
+	return regions
+}
+
+// Within reports whether the region id is ancestor or one of its
+// descendants
+func (t RegionTree) Within(id, ancestor string) bool {
+	if id == ancestor {
+		return true
+	}
+
+	for _, r := range t.Ancestors(id) {
+		if r.ID == ancestor {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Sorted returns all the regions, every region comes before its children
+func (t RegionTree) Sorted() []Region {
+	regions := make([]Region, 0, len(t.regions))
+	for _, root := range t.children[""] {
+		regions = append(regions, t.Subtree(root)...)
+	}
+
+	//regions of a cycle have no root, they are not left out
+	if len(regions) < len(t.regions) {
+		sorted := regionIDs(regions)
+
+		var rest []string
+		for id := range t.regions {
+			if !sorted[id] {
+				rest = append(rest, id)
+			}
+		}
+		sort.Strings(rest)
+
+		for _, id := range rest {
+			regions = append(regions, t.regions[id])
+		}
+	}
+
+	return regions
+}
+
+// regionIDs returns the set of the ids of regions
+func regionIDs(regions []Region) map[string]bool {
+	ids := make(map[string]bool, len(regions))
+	for _, r := range regions {
+		ids[r.ID] = true
+	}
+
+	return ids
+}
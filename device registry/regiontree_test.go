@@ -0,0 +1,59 @@
+package registry_test
+
+import (
+	"testing"
+
+	"github.com/piusalfred/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+//a region split in zones, the zones in feeders
+var hierarchy = []registry.Region{
+	{ID: "f2", Parent: "z1"},
+	{ID: "dsm"},
+	{ID: "z2", Parent: "dsm"},
+	{ID: "f1", Parent: "z1"},
+	{ID: "z1", Parent: "dsm"},
+	{ID: "arusha"},
+	{ID: "lost", Parent: "gone"},
+}
+
+func ids(regions []registry.Region) []string {
+	ids := make([]string, 0, len(regions))
+	for _, r := range regions {
+		ids = append(ids, r.ID)
+	}
+
+	return ids
+}
+
+func TestRegionTree(t *testing.T) {
+	tree := registry.NewRegionTree(hierarchy)
+
+	assert.Equal(t, []string{"dsm", "z1", "f1", "f2", "z2"}, ids(tree.Subtree("dsm")))
+	assert.Equal(t, []string{"z2"}, ids(tree.Subtree("z2")))
+	assert.Nil(t, tree.Subtree("gone"))
+
+	assert.Equal(t, []string{"z1", "dsm"}, ids(tree.Ancestors("f2")))
+	assert.Empty(t, tree.Ancestors("dsm"))
+	assert.Empty(t, tree.Ancestors("lost"), "regions with an unknown parent are roots")
+
+	assert.True(t, tree.Within("f1", "dsm"))
+	assert.True(t, tree.Within("dsm", "dsm"))
+	assert.False(t, tree.Within("dsm", "f1"))
+	assert.False(t, tree.Within("f1", "z2"))
+
+	assert.Equal(t, []string{"arusha", "dsm", "z1", "f1", "f2", "z2", "lost"}, ids(tree.Sorted()))
+}
+
+func TestRegionTreeCycle(t *testing.T) {
+	tree := registry.NewRegionTree([]registry.Region{
+		{ID: "a", Parent: "b"},
+		{ID: "b", Parent: "a"},
+		{ID: "c"},
+	})
+
+	assert.Equal(t, []string{"b"}, ids(tree.Ancestors("a")))
+	assert.Equal(t, []string{"a", "b"}, ids(tree.Subtree("a")))
+	assert.Equal(t, []string{"c", "a", "b"}, ids(tree.Sorted()))
+}
@@ -1,24 +1,45 @@
 package registry
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type Repository interface {
 }
 
+// UserRepository stores the users. It enforces the quotas of
+// QuotaRepository: the quota is checked in the transaction that writes the
+// user, so that concurrent writes can not go over it. The repotest package
+// checks every backend for it.
 type UserRepository interface {
 	Get(ctx context.Context, id string) (User, error)
+	// Add fails with ErrQuotaExceeded when the user goes over the quota of
+	// its region or of its organization
 	Add(ctx context.Context, user User) error
 	Delete(ctx context.Context, id string) error
 	List(ctx context.Context) ([]User, error)
+	// Update fails with ErrQuotaExceeded when the user moved to another
+	// region goes over its quota
 	Update(ctx context.Context, id string, user User) (User, error)
+	// UpdatePassword replaces the password hash of the user with id
+	UpdatePassword(ctx context.Context, id, hash string) error
 }
 
+// NodeRepository stores the nodes, it enforces the quotas of
+// QuotaRepository the way UserRepository does
 type NodeRepository interface {
 	Get(ctx context.Context, id string) (Node, error)
+	// Add fails with ErrQuotaExceeded when the node goes over the quota of
+	// its region or of its organization
 	Add(ctx context.Context, user Node) error
 	Delete(ctx context.Context, id string) error
 	List(ctx context.Context) ([]Node, error)
+	// Update fails with ErrQuotaExceeded when the node moved to another
+	// region goes over its quota
 	Update(ctx context.Context, id string, user Node) (Node, error)
+	// Slaves returns the nodes whose master is the node id
+	Slaves(ctx context.Context, id string) ([]Node, error)
 }
 
 type RegionRepository interface {
@@ -28,3 +49,115 @@ type RegionRepository interface {
 	List(ctx context.Context) ([]Region, error)
 	Update(ctx context.Context, id string, user Region) (Region, error)
 }
+
+type NodeTypeRepository interface {
+	Get(ctx context.Context, id int) (NodeType, error)
+	Add(ctx context.Context, nodeType NodeType) error
+	Delete(ctx context.Context, id int) error
+	List(ctx context.Context) ([]NodeType, error)
+	Update(ctx context.Context, id int, nodeType NodeType) (NodeType, error)
+}
+
+type ClaimRepository interface {
+	AddCode(ctx context.Context, code ClaimCode) error
+	GetCode(ctx context.Context, code string) (ClaimCode, error)
+	ListCodes(ctx context.Context) ([]ClaimCode, error)
+	// Provision marks the code as used by node, adds node and saves the hash
+	// of its key in a single transaction. It fails with ErrClaimCodeUsed if
+	// another device has redeemed the code first or the code has expired.
+	Provision(ctx context.Context, code string, node Node, keyHash string) error
+	SaveClaim(ctx context.Context, claim Claim) error
+	ListClaims(ctx context.Context) ([]Claim, error)
+	// Credentials returns the key hash saved for node
+	Credentials(ctx context.Context, node string) (string, error)
+}
+
+type CertificateRepository interface {
+	Add(ctx context.Context, cert Certificate) error
+	Get(ctx context.Context, serial string) (Certificate, error)
+	List(ctx context.Context) ([]Certificate, error)
+	Revoke(ctx context.Context, serial string, at time.Time) error
+	// RevokeNode revokes all the valid certificates of the node.
+	RevokeNode(ctx context.Context, node string, at time.Time) error
+	Revoked(ctx context.Context) ([]Certificate, error)
+}
+
+type APIKeyRepository interface {
+	Add(ctx context.Context, key APIKey) error
+	Get(ctx context.Context, id string) (APIKey, error)
+	// List returns the keys of owner or all the keys if owner is empty.
+	List(ctx context.Context, owner string) ([]APIKey, error)
+	Revoke(ctx context.Context, id string, at time.Time) error
+}
+
+// OrganizationRepository stores the tenants, like the other repositories
+// its queries are scoped to the organization of the request context.
+type OrganizationRepository interface {
+	Add(ctx context.Context, org Organization) error
+	Get(ctx context.Context, id string) (Organization, error)
+	List(ctx context.Context) ([]Organization, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// FirmwareRepository is the catalogue of firmware releases, List leaves out
+// the blobs.
+type FirmwareRepository interface {
+	Add(ctx context.Context, fw Firmware) error
+	Get(ctx context.Context, id string) (Firmware, error)
+	List(ctx context.Context) ([]Firmware, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// CampaignRepository stores the campaigns and the rollout of each of the
+// nodes they enrolled.
+type CampaignRepository interface {
+	Add(ctx context.Context, campaign Campaign) error
+	Get(ctx context.Context, id string) (Campaign, error)
+	List(ctx context.Context) ([]Campaign, error)
+	// SetRollout changes the percentage of targeted nodes of the campaign
+	SetRollout(ctx context.Context, id string, percent int) error
+	// Enroll adds a pending rollout for each of the nodes
+	Enroll(ctx context.Context, campaign string, nodes []string, at time.Time) error
+	Rollouts(ctx context.Context, campaign string) ([]Rollout, error)
+	// UpdateRollout saves the status a node reported, it fails with a not
+	// found error if the node is not enrolled in the campaign
+	UpdateRollout(ctx context.Context, rollout Rollout) error
+}
+
+// SchemaRepository stores the versions of the node schemas. Schemas without
+// an organization are shared by all of them, Get and List return them to
+// every request.
+type SchemaRepository interface {
+	Add(ctx context.Context, schema Schema) error
+	Get(ctx context.Context, id string) (Schema, error)
+	List(ctx context.Context) ([]Schema, error)
+}
+
+// QuotaRepository stores the quotas of regions and organizations, Set
+// replaces the quota of the same scope and id. The user and node
+// repositories enforce them when they add or move rows, the service does not
+// check them itself.
+type QuotaRepository interface {
+	Set(ctx context.Context, quota Quota) error
+	Get(ctx context.Context, scope QuotaScope, id string) (Quota, error)
+	List(ctx context.Context) ([]Quota, error)
+	// Usage counts the users and nodes of the region or organization id
+	// against its quota, those without a quota are unlimited
+	Usage(ctx context.Context, scope QuotaScope, id string) (Usage, error)
+}
+
+// IdempotencyRepository records the responses of requests sent with an
+// Idempotency-Key so that retries replay them instead of running again.
+type IdempotencyRepository interface {
+	// Reserve records req unless its key is taken by a request created
+	// after expired, in which case it returns the request recorded with the
+	// key and false. Keys of requests created before expired are taken over.
+	Reserve(ctx context.Context, req IdempotentRequest, expired time.Time) (IdempotentRequest, bool, error)
+	// Complete records the response of a reserved request.
+	Complete(ctx context.Context, req IdempotentRequest) error
+	// Release removes a key so that the request can be retried.
+	Release(ctx context.Context, key string) error
+	// Purge removes the keys created before t, it is run periodically so
+	// that expired keys do not pile up.
+	Purge(ctx context.Context, before time.Time) error
+}
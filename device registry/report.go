@@ -0,0 +1,270 @@
+package registry
+
+import (
+	"github.com/piusalfred/registry/pkg/errors"
+	"sort"
+	"time"
+)
+
+var (
+	// ErrUnknownDimension indicates an inventory grouping other than region,
+	// type or status
+	ErrUnknownDimension = errors.NewKind(errors.Invalid, "unknown inventory dimension, use region, type or status")
+
+	// ErrUnknownPeriod indicates a growth period other than day, week or month
+	ErrUnknownPeriod = errors.NewKind(errors.Invalid, "unknown growth period, use day, week or month")
+)
+
+// NodeState is where a node is in its lifecycle, derived from the
+// certificates issued to it
+type NodeState string
+
+const (
+	// NodePending nodes have not been issued a certificate yet
+	NodePending NodeState = "pending"
+	// NodeActive nodes have a certificate that is neither expired nor revoked
+	NodeActive NodeState = "active"
+	// NodeExpired nodes let their last certificate expire
+	NodeExpired NodeState = "expired"
+	// NodeRevoked nodes had their last certificate revoked
+	NodeRevoked NodeState = "revoked"
+)
+
+// NodeStates returns the state of each node with certificates at now, nodes
+// missing from it are pending
+func NodeStates(certs []Certificate, now time.Time) map[string]NodeState {
+	states := map[string]NodeState{}
+	last := map[string]string{}
+
+	for _, c := range certs {
+		expires, err := time.Parse(time.RFC3339, c.Expires)
+		if c.Revoked == "" && err == nil && expires.After(now) {
+			states[c.Node] = NodeActive
+			continue
+		}
+
+		if states[c.Node] == NodeActive || c.Issued < last[c.Node] {
+			continue
+		}
+
+		//the last certificate issued decides between expired and revoked
+		last[c.Node] = c.Issued
+		states[c.Node] = NodeExpired
+		if c.Revoked != "" {
+			states[c.Node] = NodeRevoked
+		}
+	}
+
+	return states
+}
+
+// Dimension is what the inventory groups nodes by
+type Dimension string
+
+const (
+	ByRegion Dimension = "region"
+	ByType   Dimension = "type"
+	ByStatus Dimension = "status"
+)
+
+// Dimensions are all the dimensions, the inventory groups by all of them
+// unless told otherwise
+var Dimensions = []Dimension{ByRegion, ByType, ByStatus}
+
+// ParseDimensions returns the dimensions called names, all of them when
+// names is empty
+func ParseDimensions(names []string) ([]Dimension, error) {
+	if len(names) == 0 {
+		return Dimensions, nil
+	}
+
+	dims := make([]Dimension, 0, len(names))
+	for _, name := range names {
+		switch d := Dimension(name); d {
+		case ByRegion, ByType, ByStatus:
+			dims = append(dims, d)
+		default:
+			return nil, ErrUnknownDimension
+		}
+	}
+
+	return dims, nil
+}
+
+// InventoryCount is the number of nodes with the same values of the
+// dimensions of the inventory, the other fields are left empty
+type InventoryCount struct {
+	Region   string    `json:"region,omitempty"`
+	Type     int       `json:"type,omitempty"`
+	TypeName string    `json:"type_name,omitempty"`
+	Status   NodeState `json:"status,omitempty"`
+	Nodes    int       `json:"nodes"`
+}
+
+// Inventory counts nodes by the dimensions in by, states are the states of
+// the nodes as returned by NodeStates. Counts are sorted by region, type and
+// status.
+func Inventory(nodes []Node, states map[string]NodeState, by []Dimension) []InventoryCount {
+	group := map[Dimension]bool{}
+	for _, d := range by {
+		group[d] = true
+	}
+
+	counts := map[InventoryCount]int{}
+	for _, n := range nodes {
+		key := InventoryCount{}
+		if group[ByRegion] {
+			key.Region = n.Region
+		}
+		if group[ByType] {
+			key.Type = n.Type
+		}
+		if group[ByStatus] {
+			key.Status = states[n.UUID]
+			if key.Status == "" {
+				key.Status = NodePending
+			}
+		}
+
+		counts[key]++
+	}
+
+	inventory := make([]InventoryCount, 0, len(counts))
+	for key, n := range counts {
+		key.Nodes = n
+		inventory = append(inventory, key)
+	}
+
+	sort.Slice(inventory, func(i, j int) bool {
+		a, b := inventory[i], inventory[j]
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Status < b.Status
+	})
+
+	return inventory
+}
+
+// GrowthPeriod is the length of the periods of a growth report
+type GrowthPeriod string
+
+const (
+	Daily   GrowthPeriod = "day"
+	Weekly  GrowthPeriod = "week"
+	Monthly GrowthPeriod = "month"
+)
+
+// ParseGrowthPeriod returns the period called name, month when it is empty
+func ParseGrowthPeriod(name string) (GrowthPeriod, error) {
+	switch p := GrowthPeriod(name); p {
+	case "":
+		return Monthly, nil
+	case Daily, Weekly, Monthly:
+		return p, nil
+	}
+
+	return "", ErrUnknownPeriod
+}
+
+// start returns the UTC start of the period t is in, weeks start on Monday
+func (p GrowthPeriod) start(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch p {
+	case Weekly:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case Monthly:
+		return day.AddDate(0, 0, 1-day.Day())
+	}
+
+	return day
+}
+
+func (p GrowthPeriod) next(t time.Time) time.Time {
+	switch p {
+	case Weekly:
+		return t.AddDate(0, 0, 7)
+	case Monthly:
+		return t.AddDate(0, 1, 0)
+	}
+
+	return t.AddDate(0, 0, 1)
+}
+
+func (p GrowthPeriod) label(t time.Time) string {
+	if p == Monthly {
+		return t.Format("2006-01")
+	}
+
+	return t.Format("2006-01-02")
+}
+
+// Growth is the number of nodes added in a period, named after the day it
+// starts or its month, and the number of nodes at its end
+type Growth struct {
+	Period string `json:"period"`
+	Added  int    `json:"added"`
+	Total  int    `json:"total"`
+}
+
+// NodeGrowth returns the growth of nodes in every period from the one since
+// is in to the one now is in, from the period of the oldest node when since
+// is zero. Nodes added before since count in the totals.
+func NodeGrowth(nodes []Node, period GrowthPeriod, since, now time.Time) []Growth {
+	var created []time.Time
+	for _, n := range nodes {
+		if t, err := time.Parse(time.RFC3339, n.Created); err == nil {
+			created = append(created, t)
+		}
+	}
+
+	if len(created) == 0 && since.IsZero() {
+		return []Growth{}
+	}
+
+	sort.Slice(created, func(i, j int) bool { return created[i].Before(created[j]) })
+	if since.IsZero() {
+		since = created[0]
+	}
+
+	var growth []Growth
+	total, i := 0, 0
+	for start := period.start(since); !start.After(now); start = period.next(start) {
+		end := period.next(start)
+
+		g := Growth{Period: period.label(start)}
+		for ; i < len(created) && created[i].Before(end); i++ {
+			if !created[i].Before(start) {
+				g.Added++
+			}
+			total++
+		}
+
+		g.Total = total
+		growth = append(growth, g)
+	}
+
+	return growth
+}
+
+// Orphans returns the nodes with a master that is not one of all
+func Orphans(nodes []Node, all []Node) []Node {
+	exists := make(map[string]bool, len(all))
+	for _, n := range all {
+		exists[n.UUID] = true
+	}
+
+	orphans := []Node{}
+	for _, n := range nodes {
+		if n.Master != "" && !exists[n.Master] {
+			orphans = append(orphans, n)
+		}
+	}
+
+	return orphans
+}
@@ -0,0 +1,87 @@
+package registry_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/piusalfred/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodeStates(t *testing.T) {
+	now := time.Date(2020, 6, 15, 0, 0, 0, 0, time.UTC)
+	certs := []registry.Certificate{
+		{Node: "active", Issued: "2020-01-01T00:00:00Z", Expires: "2021-01-01T00:00:00Z"},
+		{Node: "active", Issued: "2019-01-01T00:00:00Z", Expires: "2020-01-01T00:00:00Z", Revoked: "2019-06-01T00:00:00Z"},
+		{Node: "expired", Issued: "2019-01-01T00:00:00Z", Expires: "2020-01-01T00:00:00Z"},
+		{Node: "revoked", Issued: "2019-01-01T00:00:00Z", Expires: "2020-01-01T00:00:00Z"},
+		{Node: "revoked", Issued: "2020-01-01T00:00:00Z", Expires: "2021-01-01T00:00:00Z", Revoked: "2020-02-01T00:00:00Z"},
+	}
+
+	assert.Equal(t, map[string]registry.NodeState{
+		"active":  registry.NodeActive,
+		"expired": registry.NodeExpired,
+		"revoked": registry.NodeRevoked,
+	}, registry.NodeStates(certs, now))
+}
+
+func TestInventory(t *testing.T) {
+	nodes := []registry.Node{
+		{UUID: "n1", Region: "AA002", Type: 1},
+		{UUID: "n2", Region: "AA001", Type: 1},
+		{UUID: "n3", Region: "AA001", Type: 3},
+		{UUID: "n4", Region: "AA001", Type: 1},
+	}
+	states := map[string]registry.NodeState{"n2": registry.NodeActive}
+
+	assert.Equal(t, []registry.InventoryCount{
+		{Region: "AA001", Type: 1, Status: registry.NodeActive, Nodes: 1},
+		{Region: "AA001", Type: 1, Status: registry.NodePending, Nodes: 1},
+		{Region: "AA001", Type: 3, Status: registry.NodePending, Nodes: 1},
+		{Region: "AA002", Type: 1, Status: registry.NodePending, Nodes: 1},
+	}, registry.Inventory(nodes, states, registry.Dimensions))
+
+	assert.Equal(t, []registry.InventoryCount{
+		{Region: "AA001", Nodes: 3},
+		{Region: "AA002", Nodes: 1},
+	}, registry.Inventory(nodes, states, []registry.Dimension{registry.ByRegion}))
+
+	_, err := registry.ParseDimensions([]string{"region", "colour"})
+	assert.Equal(t, registry.ErrUnknownDimension, err)
+}
+
+func TestNodeGrowth(t *testing.T) {
+	nodes := []registry.Node{
+		{Created: "2020-01-20T10:00:00Z"},
+		{Created: "2020-03-02T00:00:00Z"},
+		{Created: "2020-03-31T23:59:59Z"},
+	}
+	now := time.Date(2020, 4, 10, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, []registry.Growth{
+		{Period: "2020-01", Added: 1, Total: 1},
+		{Period: "2020-02", Added: 0, Total: 1},
+		{Period: "2020-03", Added: 2, Total: 3},
+		{Period: "2020-04", Added: 0, Total: 3},
+	}, registry.NodeGrowth(nodes, registry.Monthly, time.Time{}, now))
+
+	//2020-03-02 is a Monday, the nodes added before since are in the totals
+	since := time.Date(2020, 3, 4, 0, 0, 0, 0, time.UTC)
+	now = time.Date(2020, 3, 10, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, []registry.Growth{
+		{Period: "2020-03-02", Added: 1, Total: 2},
+		{Period: "2020-03-09", Added: 0, Total: 2},
+	}, registry.NodeGrowth(nodes, registry.Weekly, since, now))
+}
+
+func TestOrphans(t *testing.T) {
+	all := []registry.Node{
+		{UUID: "controller"},
+		{UUID: "n1", Master: "controller"},
+		{UUID: "n2", Master: "deleted"},
+		{UUID: "n3"},
+	}
+
+	assert.Equal(t, []registry.Node{all[2]}, registry.Orphans(all, all))
+	assert.Equal(t, []registry.Node{}, registry.Orphans(all[:2], all))
+}
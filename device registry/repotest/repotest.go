@@ -0,0 +1,1166 @@
+// Package repotest is the contract every storage backend of the registry
+// is tested against. Backends run the same tests on their repositories so
+// that regsvc behaves the same whichever one it is configured with.
+//
+// The tests expect the schema and seed rows of sql.Schema, they only
+// add rows with fresh ids so they can run against a shared database.
+package repotest
+
+import (
+	"context"
+	"github.com/piusalfred/registry"
+	"github.com/piusalfred/registry/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+// seedRegion is a region of the seed rows, nodes and users are added to it
+const seedRegion = "AA001"
+
+// Repositories are the repositories of the backend under test
+type Repositories struct {
+	Users   registry.UserRepository
+	Nodes   registry.NodeRepository
+	Regions registry.RegionRepository
+	Events  registry.EventStore
+
+	Types  registry.NodeTypeRepository
+	Claims registry.ClaimRepository
+	Certs  registry.CertificateRepository
+	Keys   registry.APIKeyRepository
+	Orgs   registry.OrganizationRepository
+
+	Idempotency registry.IdempotencyRepository
+	Firmware    registry.FirmwareRepository
+	Campaigns   registry.CampaignRepository
+	Schemas     registry.SchemaRepository
+	Quotas      registry.QuotaRepository
+}
+
+// Run runs the repository contract tests against repos
+func Run(t *testing.T, repos Repositories) {
+	t.Run("users", func(t *testing.T) { testUsers(t, repos.Users) })
+	t.Run("nodes", func(t *testing.T) { testNodes(t, repos.Nodes) })
+	t.Run("regions", func(t *testing.T) { testRegions(t, repos.Regions) })
+	t.Run("events", func(t *testing.T) { testEvents(t, repos.Events) })
+	t.Run("idempotency", func(t *testing.T) { testIdempotency(t, repos.Idempotency) })
+	t.Run("firmware", func(t *testing.T) { testFirmware(t, repos) })
+	t.Run("schemas", func(t *testing.T) { testSchemas(t, repos) })
+	t.Run("quotas", func(t *testing.T) { testQuotas(t, repos) })
+	t.Run("quota enforcement", func(t *testing.T) { testQuotaUsage(t, repos) })
+	t.Run("types", func(t *testing.T) { testNodeTypes(t, repos.Types) })
+	t.Run("claims", func(t *testing.T) { testClaims(t, repos) })
+	t.Run("certificates", func(t *testing.T) { testCertificates(t, repos.Certs) })
+	t.Run("keys", func(t *testing.T) { testAPIKeys(t, repos) })
+	t.Run("organizations", func(t *testing.T) { testOrganizations(t, repos) })
+}
+
+// scoped returns a context of a request made by another organization,
+// entities without an organization must not be visible to it
+func scoped() context.Context {
+	return registry.WithAPIKey(context.Background(), registry.APIKey{Org: "other"})
+}
+
+func newID(t *testing.T) string {
+	id, err := registry.New().ID()
+	require.Nil(t, err)
+
+	return id
+}
+
+func testUsers(t *testing.T, repo registry.UserRepository) {
+	ctx := context.Background()
+
+	user := registry.User{
+		ID:       newID(t),
+		Name:     "Contract Test",
+		Email:    "contract@test.com",
+		Password: "hash",
+		Group:    int(registry.RegionUser),
+		Region:   seedRegion,
+		Created:  time.Now().Format(time.RFC3339),
+	}
+
+	require.Nil(t, repo.Add(ctx, user))
+
+	got, err := repo.Get(ctx, user.ID)
+	require.Nil(t, err)
+	assert.Equal(t, user.Name, got.Name)
+	assert.Equal(t, user.Email, got.Email)
+	assert.Equal(t, user.Password, got.Password)
+	assert.Equal(t, user.Group, got.Group)
+	assert.Equal(t, user.Region, got.Region)
+	assert.NotEmpty(t, got.Created)
+
+	_, err = repo.Get(scoped(), user.ID)
+	assert.NotNil(t, err, "user without an organization visible to a tenant")
+
+	users, err := repo.List(ctx)
+	require.Nil(t, err)
+	assert.Contains(t, userIDs(users), user.ID)
+
+	users, err = repo.List(scoped())
+	require.Nil(t, err)
+	assert.NotContains(t, userIDs(users), user.ID)
+
+	got, err = repo.Update(ctx, user.ID, registry.User{Region: "AA002", Group: int(registry.RegionAdmin)})
+	require.Nil(t, err)
+	assert.Equal(t, "AA002", got.Region)
+	assert.Equal(t, int(registry.RegionAdmin), got.Group)
+
+	require.Nil(t, repo.UpdatePassword(ctx, user.ID, "rehashed"))
+	got, err = repo.Get(ctx, user.ID)
+	require.Nil(t, err)
+	assert.Equal(t, "rehashed", got.Password)
+
+	assert.NotNil(t, repo.UpdatePassword(scoped(), user.ID, "hash"), "password of a user outside the organization updated")
+	assert.NotNil(t, repo.UpdatePassword(ctx, newID(t), "hash"), "password of an unknown user updated")
+
+	require.Nil(t, repo.Delete(ctx, user.ID))
+
+	_, err = repo.Get(ctx, user.ID)
+	assert.NotNil(t, err, "deleted user still found")
+
+	_, err = repo.Get(ctx, newID(t))
+	assert.NotNil(t, err, "unknown user found")
+}
+
+func userIDs(users []registry.User) []string {
+	ids := make([]string, 0, len(users))
+	for _, u := range users {
+		ids = append(ids, u.ID)
+	}
+
+	return ids
+}
+
+func testNodes(t *testing.T, repo registry.NodeRepository) {
+	ctx := context.Background()
+
+	master := registry.Node{
+		UUID:    newID(t),
+		Addr:    newID(t),
+		Name:    "contract controller",
+		Type:    int(registry.Controller),
+		Region:  seedRegion,
+		Latd:    "-6.7735",
+		Long:    "39.2395",
+		Created: time.Now().Format(time.RFC3339),
+	}
+
+	require.Nil(t, repo.Add(ctx, master))
+
+	node := registry.Node{
+		UUID:    newID(t),
+		Addr:    newID(t),
+		Name:    "contract node",
+		Type:    1,
+		Region:  seedRegion,
+		Latd:    "-6.7735",
+		Long:    "39.2395",
+		Created: time.Now().Format(time.RFC3339),
+		Master:  master.UUID,
+
+		Firmware: "1.0.0",
+		Labels:   registry.Labels{"site": "substation-4"},
+	}
+
+	require.Nil(t, repo.Add(ctx, node))
+
+	got, err := repo.Get(ctx, node.UUID)
+	require.Nil(t, err)
+	assert.Equal(t, node, got)
+
+	got, err = repo.Get(ctx, node.Addr)
+	require.Nil(t, err, "node not found by its address")
+	assert.Equal(t, node.UUID, got.UUID)
+
+	_, err = repo.Get(scoped(), node.UUID)
+	assert.NotNil(t, err, "node without an organization visible to a tenant")
+
+	moved := node
+	moved.Name = "moved contract node"
+	moved.Latd = "-6.8161"
+	moved.Long = "39.2803"
+	moved.Firmware = "1.1.0"
+	moved.Labels = nil
+	got, err = repo.Update(ctx, node.UUID, moved)
+	require.Nil(t, err)
+	assert.Equal(t, moved, got)
+
+	_, err = repo.Update(scoped(), node.UUID, node)
+	assert.NotNil(t, err, "node without an organization updated by a tenant")
+
+	_, err = repo.Update(ctx, newID(t), node)
+	assert.NotNil(t, err, "unknown node updated")
+
+	dup := node
+	dup.UUID = newID(t)
+	assert.NotNil(t, repo.Add(ctx, dup), "node added with a duplicate address")
+
+	orphan := node
+	orphan.UUID, orphan.Addr, orphan.Master = newID(t), newID(t), newID(t)
+	assert.Equal(t, registry.ErrInvalidReference, repo.Add(ctx, orphan), "node added with an unknown master")
+
+	unknown := node
+	unknown.UUID, unknown.Addr, unknown.Region = newID(t), newID(t), "ZZ999"
+	assert.Equal(t, registry.ErrInvalidReference, repo.Add(ctx, unknown), "node added to an unknown region")
+
+	nodes, err := repo.List(ctx)
+	require.Nil(t, err)
+	assert.Contains(t, nodeIDs(nodes), node.UUID)
+
+	nodes, err = repo.List(scoped())
+	require.Nil(t, err)
+	assert.NotContains(t, nodeIDs(nodes), node.UUID)
+
+	slaves, err := repo.Slaves(ctx, master.UUID)
+	require.Nil(t, err)
+	assert.Equal(t, []string{node.UUID}, nodeIDs(slaves))
+
+	slaves, err = repo.Slaves(ctx, node.UUID)
+	require.Nil(t, err)
+	assert.Empty(t, slaves)
+
+	slaves, err = repo.Slaves(scoped(), master.UUID)
+	require.Nil(t, err)
+	assert.Empty(t, slaves, "nodes without an organization visible to a tenant")
+
+	assert.Equal(t, registry.ErrMasterInUse, repo.Delete(ctx, master.UUID), "master of a node deleted")
+
+	require.Nil(t, repo.Delete(ctx, node.UUID))
+	require.Nil(t, repo.Delete(ctx, master.UUID))
+
+	_, err = repo.Get(ctx, node.UUID)
+	assert.NotNil(t, err, "deleted node still found")
+}
+
+func nodeIDs(nodes []registry.Node) []string {
+	ids := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		ids = append(ids, n.UUID)
+	}
+
+	return ids
+}
+
+func testRegions(t *testing.T, repo registry.RegionRepository) {
+	ctx := context.Background()
+
+	region := registry.Region{
+		ID:   newID(t),
+		Name: "Contract",
+		Desc: "contract test region",
+	}
+
+	require.Nil(t, repo.Add(ctx, region))
+	assert.NotNil(t, repo.Add(ctx, region), "region added twice")
+
+	got, err := repo.Get(ctx, region.ID)
+	require.Nil(t, err)
+	assert.Equal(t, region, got)
+
+	_, err = repo.Get(scoped(), region.ID)
+	assert.NotNil(t, err, "region without an organization visible to a tenant")
+
+	regions, err := repo.List(ctx)
+	require.Nil(t, err)
+
+	ids := make([]string, 0, len(regions))
+	for _, r := range regions {
+		ids = append(ids, r.ID)
+	}
+	assert.Contains(t, ids, region.ID)
+	assert.Contains(t, ids, seedRegion)
+
+	_, err = repo.Get(ctx, newID(t))
+	assert.NotNil(t, err, "unknown region found")
+
+	fenced := registry.Region{
+		ID:   newID(t),
+		Name: "Fenced",
+		Desc: "contract test region with a boundary",
+		Boundary: &registry.Geometry{
+			Type:        "Polygon",
+			Coordinates: []byte(`[[[39,-7],[39.5,-7],[39.5,-6.5],[39,-6.5],[39,-7]]]`),
+		},
+	}
+
+	require.Nil(t, repo.Add(ctx, fenced))
+
+	got, err = repo.Get(ctx, fenced.ID)
+	require.Nil(t, err)
+	assert.Equal(t, fenced, got)
+
+	zone := registry.Region{
+		ID:     newID(t),
+		Name:   "Zone",
+		Desc:   "contract test sub-region",
+		Parent: region.ID,
+	}
+
+	require.Nil(t, repo.Add(ctx, zone))
+	assert.NotNil(t, repo.Add(ctx, registry.Region{ID: newID(t), Name: "Orphan", Parent: newID(t)}), "region added under an unknown parent")
+
+	got, err = repo.Get(ctx, zone.ID)
+	require.Nil(t, err)
+	assert.Equal(t, zone, got)
+
+	zone.Parent = fenced.ID
+	got, err = repo.Update(ctx, zone.ID, zone)
+	require.Nil(t, err)
+	assert.Equal(t, zone, got)
+
+	zone.Parent = ""
+	got, err = repo.Update(ctx, zone.ID, zone)
+	require.Nil(t, err)
+	assert.Equal(t, "", got.Parent)
+
+	_, err = repo.Update(ctx, newID(t), zone)
+	assert.Equal(t, errors.NotFound, errors.KindOf(err), "unknown region updated")
+
+	err = repo.Delete(ctx, seedRegion)
+	assert.True(t, errors.Contains(err, registry.ErrRegionInUse), "region with users and nodes deleted: %v", err)
+
+	err = repo.Delete(scoped(), zone.ID)
+	assert.Equal(t, errors.NotFound, errors.KindOf(err), "region outside the organization deleted")
+
+	require.Nil(t, repo.Delete(ctx, zone.ID))
+	require.Nil(t, repo.Delete(ctx, fenced.ID))
+	require.Nil(t, repo.Delete(ctx, region.ID))
+
+	_, err = repo.Get(ctx, zone.ID)
+	assert.Equal(t, errors.NotFound, errors.KindOf(err), "deleted region still found")
+
+	err = repo.Delete(ctx, zone.ID)
+	assert.Equal(t, errors.NotFound, errors.KindOf(err), "region deleted twice")
+}
+
+func testEvents(t *testing.T, store registry.EventStore) {
+	ctx := context.Background()
+
+	//timestamps are after those of any previous run on the same database
+	base := time.Duration(time.Now().UnixNano())
+	actor := newID(t)
+	subject := newID(t)
+
+	var events []registry.Event
+	for i, name := range []registry.EventName{registry.CREATE_USER, registry.CREATE_NODE, registry.CREATE_USER} {
+		event := registry.Event{
+			UUID:      newID(t),
+			Name:      name.String(),
+			Region:    seedRegion,
+			Actor:     actor,
+			Action:    "contract",
+			Result:    "ok",
+			Timestamp: base + time.Duration(i),
+			ExecTime:  time.Millisecond,
+			Subject:   subject,
+			Org:       "org",
+		}
+
+		require.Nil(t, store.Save(ctx, event))
+		events = append(events, event)
+	}
+
+	cases := []struct {
+		desc   string
+		pull   func(ctx context.Context) ([]registry.Event, error)
+		want   []registry.Event
+		shared bool
+	}{
+		{
+			desc:   "pull all events",
+			pull:   func(ctx context.Context) ([]registry.Event, error) { return store.Pull(ctx) },
+			want:   events,
+			shared: true,
+		},
+		{
+			desc: "events between two timestamps",
+			pull: func(ctx context.Context) ([]registry.Event, error) { return store.Between(ctx, base, base+2) },
+			want: events[:2],
+		},
+		{
+			desc:   "events before a timestamp",
+			pull:   func(ctx context.Context) ([]registry.Event, error) { return store.Before(ctx, base+1) },
+			want:   events[:1],
+			shared: true,
+		},
+		{
+			desc: "events after a timestamp",
+			pull: func(ctx context.Context) ([]registry.Event, error) { return store.After(ctx, base+1) },
+			want: events[1:],
+		},
+		{
+			desc: "events of an actor",
+			pull: func(ctx context.Context) ([]registry.Event, error) { return store.ByID(ctx, actor) },
+			want: events,
+		},
+		{
+			desc: "events of a subject",
+			pull: func(ctx context.Context) ([]registry.Event, error) { return store.ByID(ctx, subject) },
+			want: events,
+		},
+		{
+			desc: "event by its uuid",
+			pull: func(ctx context.Context) ([]registry.Event, error) { return store.ByID(ctx, events[1].UUID) },
+			want: events[1:2],
+		},
+		{
+			desc: "events by name",
+			pull: func(ctx context.Context) ([]registry.Event, error) {
+				return store.ByEventName(ctx, registry.CREATE_USER)
+			},
+			want:   []registry.Event{events[0], events[2]},
+			shared: true,
+		},
+	}
+
+	org := registry.WithAPIKey(ctx, registry.APIKey{Org: "org"})
+
+	for _, tc := range cases {
+		got, err := tc.pull(ctx)
+		require.Nil(t, err, tc.desc)
+
+		//events saved by previous runs are returned too, only ours are
+		//known so the others are dropped
+		if tc.shared {
+			got = ofActor(got, actor)
+		}
+		assert.Equal(t, tc.want, got, tc.desc)
+
+		got, err = tc.pull(org)
+		require.Nil(t, err, tc.desc)
+		assert.Equal(t, tc.want, ofActor(got, actor), "%s of the organization", tc.desc)
+
+		got, err = tc.pull(scoped())
+		require.Nil(t, err, tc.desc)
+		assert.Empty(t, ofActor(got, actor), "%s of another organization", tc.desc)
+	}
+}
+
+func ofActor(events []registry.Event, actor string) []registry.Event {
+	var filtered []registry.Event
+	for _, e := range events {
+		if e.Actor == actor {
+			filtered = append(filtered, e)
+		}
+	}
+
+	return filtered
+}
+
+func testIdempotency(t *testing.T, repo registry.IdempotencyRepository) {
+	ctx := context.Background()
+	expired := time.Now().Add(-time.Hour)
+
+	req := registry.IdempotentRequest{
+		Key:     newID(t),
+		Hash:    "hash",
+		Created: time.Now(),
+	}
+
+	_, reserved, err := repo.Reserve(ctx, req, expired)
+	require.Nil(t, err)
+	assert.True(t, reserved, "new key not reserved")
+
+	recorded, reserved, err := repo.Reserve(ctx, req, expired)
+	require.Nil(t, err)
+	assert.False(t, reserved, "key reserved twice")
+	assert.False(t, recorded.Completed(), "response recorded before completion")
+
+	req.Status = 201
+	req.ContentType = "application/json"
+	req.Body = []byte(`{"id":"1"}`)
+	require.Nil(t, repo.Complete(ctx, req))
+
+	recorded, reserved, err = repo.Reserve(ctx, req, expired)
+	require.Nil(t, err)
+	assert.False(t, reserved, "completed key reserved again")
+	assert.Equal(t, req.Hash, recorded.Hash)
+	assert.Equal(t, req.Status, recorded.Status)
+	assert.Equal(t, req.ContentType, recorded.ContentType)
+	assert.Equal(t, req.Body, recorded.Body)
+
+	require.Nil(t, repo.Release(ctx, req.Key))
+	_, reserved, err = repo.Reserve(ctx, req, expired)
+	require.Nil(t, err)
+	assert.True(t, reserved, "released key not reserved")
+
+	require.Nil(t, repo.Purge(ctx, time.Now().Add(time.Minute)))
+	_, reserved, err = repo.Reserve(ctx, req, expired)
+	require.Nil(t, err)
+	assert.True(t, reserved, "purged key not reserved")
+
+	//an expired key is taken over by a request with another body
+	req.Status = 201
+	require.Nil(t, repo.Complete(ctx, req))
+
+	later := registry.IdempotentRequest{Key: req.Key, Hash: "other hash", Created: time.Now()}
+	_, reserved, err = repo.Reserve(ctx, later, time.Now().Add(time.Minute))
+	require.Nil(t, err)
+	assert.True(t, reserved, "expired key not taken over")
+
+	recorded, reserved, err = repo.Reserve(ctx, req, expired)
+	require.Nil(t, err)
+	assert.False(t, reserved, "key reserved twice")
+	assert.Equal(t, later.Hash, recorded.Hash)
+	assert.False(t, recorded.Completed(), "response of the expired request kept")
+}
+
+func testFirmware(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	fw := registry.Firmware{
+		ID:      newID(t),
+		Version: "2.1.0",
+		Type:    1,
+		Blob:    []byte("contract firmware image"),
+		Created: time.Now().Format(time.RFC3339),
+	}
+	require.Nil(t, fw.Validate())
+	require.Nil(t, repos.Firmware.Add(ctx, fw))
+
+	got, err := repos.Firmware.Get(ctx, fw.ID)
+	require.Nil(t, err)
+	assert.Equal(t, fw, got)
+
+	_, err = repos.Firmware.Get(scoped(), fw.ID)
+	assert.NotNil(t, err, "firmware without an organization visible to a tenant")
+
+	releases, err := repos.Firmware.List(ctx)
+	require.Nil(t, err)
+	for _, r := range releases {
+		assert.Nil(t, r.Blob, "firmware listed with its blob")
+	}
+
+	node := registry.Node{
+		UUID:    newID(t),
+		Addr:    newID(t),
+		Name:    "rollout node",
+		Type:    1,
+		Region:  seedRegion,
+		Created: time.Now().Format(time.RFC3339),
+	}
+	require.Nil(t, repos.Nodes.Add(ctx, node))
+	defer repos.Nodes.Delete(ctx, node.UUID)
+
+	campaign := registry.Campaign{
+		ID:       newID(t),
+		Name:     "contract campaign",
+		Firmware: fw.ID,
+		Target: registry.CampaignTarget{
+			Region:     seedRegion,
+			Subregions: true,
+			Labels:     registry.Labels{"site": "substation-4"},
+		},
+		Rollout: 10,
+		Created: time.Now().Format(time.RFC3339),
+	}
+	require.Nil(t, repos.Campaigns.Add(ctx, campaign))
+
+	gotCampaign, err := repos.Campaigns.Get(ctx, campaign.ID)
+	require.Nil(t, err)
+	assert.Equal(t, campaign, gotCampaign)
+
+	require.Nil(t, repos.Campaigns.SetRollout(ctx, campaign.ID, 50))
+	gotCampaign, err = repos.Campaigns.Get(ctx, campaign.ID)
+	require.Nil(t, err)
+	assert.Equal(t, 50, gotCampaign.Rollout)
+
+	assert.NotNil(t, repos.Campaigns.SetRollout(scoped(), campaign.ID, 100), "campaign without an organization advanced by a tenant")
+
+	now := time.Now()
+	require.Nil(t, repos.Campaigns.Enroll(ctx, campaign.ID, []string{node.UUID}, now))
+	require.Nil(t, repos.Campaigns.Enroll(ctx, campaign.ID, []string{node.UUID}, now), "enrolling a node twice failed")
+
+	rollouts, err := repos.Campaigns.Rollouts(ctx, campaign.ID)
+	require.Nil(t, err)
+	require.Len(t, rollouts, 1)
+	assert.Equal(t, registry.RolloutPending, rollouts[0].Status)
+
+	failed := registry.Rollout{
+		Campaign: campaign.ID,
+		Node:     node.UUID,
+		Status:   registry.RolloutFailed,
+		Err:      "checksum mismatch",
+		Updated:  now.Format(time.RFC3339),
+	}
+	require.Nil(t, repos.Campaigns.UpdateRollout(ctx, failed))
+
+	rollouts, err = repos.Campaigns.Rollouts(ctx, campaign.ID)
+	require.Nil(t, err)
+	require.Len(t, rollouts, 1)
+	assert.Equal(t, failed, rollouts[0])
+
+	unknown := failed
+	unknown.Node = newID(t)
+	assert.Equal(t, errors.NotFound, errors.KindOf(repos.Campaigns.UpdateRollout(ctx, unknown)), "rollout of a node not enrolled updated")
+
+	rollouts, err = repos.Campaigns.Rollouts(scoped(), campaign.ID)
+	require.Nil(t, err)
+	assert.Empty(t, rollouts, "rollouts of a campaign without an organization visible to a tenant")
+
+	_, err = repos.Firmware.Get(ctx, newID(t))
+	assert.Equal(t, errors.NotFound, errors.KindOf(err))
+	assert.Equal(t, errors.NotFound, errors.KindOf(repos.Firmware.Delete(ctx, newID(t))))
+}
+
+func testSchemas(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	shared := registry.Schema{
+		ID:       newID(t),
+		Type:     1,
+		Kind:     registry.SchemaTelemetry,
+		Version:  1,
+		Document: []byte(`{"type": "object", "required": ["temperature"]}`),
+		Created:  time.Now().Format(time.RFC3339),
+	}
+	require.Nil(t, shared.Validate())
+	require.Nil(t, repos.Schemas.Add(ctx, shared))
+
+	got, err := repos.Schemas.Get(ctx, shared.ID)
+	require.Nil(t, err)
+	assert.Equal(t, shared, got)
+
+	got, err = repos.Schemas.Get(scoped(), shared.ID)
+	require.Nil(t, err, "schema without an organization not shared with a tenant")
+	assert.Equal(t, shared, got)
+
+	node := registry.Node{
+		UUID:    newID(t),
+		Addr:    newID(t),
+		Name:    "schema node",
+		Type:    1,
+		Region:  seedRegion,
+		Created: time.Now().Format(time.RFC3339),
+	}
+	require.Nil(t, repos.Nodes.Add(ctx, node))
+
+	own := registry.Schema{
+		ID:       newID(t),
+		Node:     node.UUID,
+		Kind:     registry.SchemaCommands,
+		Version:  1,
+		Document: []byte(`true`),
+		Created:  time.Now().Format(time.RFC3339),
+	}
+	require.Nil(t, repos.Schemas.Add(ctx, own))
+
+	schemas, err := repos.Schemas.List(ctx)
+	require.Nil(t, err)
+	assert.Contains(t, schemas, shared)
+	assert.Contains(t, schemas, own)
+
+	require.Nil(t, repos.Nodes.Delete(ctx, node.UUID))
+	_, err = repos.Schemas.Get(ctx, own.ID)
+	assert.Equal(t, errors.NotFound, errors.KindOf(err), "schema of a deleted node kept")
+}
+
+func testQuotas(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	repo := repos.Quotas
+
+	_, err := repo.Get(ctx, registry.QuotaRegion, seedRegion)
+	assert.Equal(t, errors.NotFound, errors.KindOf(err), "quota of a region without one")
+
+	quota := registry.Quota{Scope: registry.QuotaRegion, ID: seedRegion, Users: 5, Nodes: registry.NodeLimits{1: 100, 3: 2}}
+	require.Nil(t, repo.Set(ctx, quota))
+
+	got, err := repo.Get(ctx, registry.QuotaRegion, seedRegion)
+	require.Nil(t, err)
+	assert.Equal(t, quota, got)
+
+	_, err = repo.Get(ctx, registry.QuotaOrg, seedRegion)
+	assert.Equal(t, errors.NotFound, errors.KindOf(err), "quota of another scope with the same id")
+
+	_, err = repo.Get(scoped(), registry.QuotaRegion, seedRegion)
+	assert.NotNil(t, err, "quota without an organization visible to a tenant")
+
+	quota.Users, quota.Nodes = 0, nil
+	require.Nil(t, repo.Set(ctx, quota), "quota not replaced")
+
+	quotas, err := repo.List(ctx)
+	require.Nil(t, err)
+	assert.Contains(t, quotas, quota)
+}
+
+// testQuotaUsage checks that the user and node repositories enforce the
+// quotas of regions and organizations when they add or move rows, and that
+// concurrent additions can not go over them. The seed region is left
+// unlimited afterwards.
+func testQuotaUsage(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	//nodes of a fresh type are only added by this test
+	nodeType := registry.NodeType{ID: 2000000 + int(time.Now().UnixNano()%1000000), Name: newID(t)}
+	require.Nil(t, repos.Types.Add(ctx, nodeType))
+	defer repos.Types.Delete(ctx, nodeType.ID)
+
+	before, err := repos.Quotas.Usage(ctx, registry.QuotaRegion, seedRegion)
+	require.Nil(t, err)
+	assert.Zero(t, before.Users.Limit, "limit of an unlimited region")
+	assert.NotZero(t, before.Users.Used, "users of the seed region not counted")
+	assert.NotZero(t, before.Nodes[int(registry.Controller)].Used, "nodes of the seed region not counted")
+
+	quota := registry.Quota{Scope: registry.QuotaRegion, ID: seedRegion, Users: before.Users.Used + 1, Nodes: registry.NodeLimits{nodeType.ID: 2}}
+	require.Nil(t, repos.Quotas.Set(ctx, quota))
+	defer func() {
+		require.Nil(t, repos.Quotas.Set(ctx, registry.Quota{Scope: registry.QuotaRegion, ID: seedRegion}))
+	}()
+
+	newUser := func(region string) registry.User {
+		return registry.User{ID: newID(t), Name: "Quota Test", Email: "quota@test.com", Password: "hash",
+			Group: int(registry.RegionUser), Region: region, Created: time.Now().Format(time.RFC3339)}
+	}
+
+	user := newUser(seedRegion)
+	require.Nil(t, repos.Users.Add(ctx, user))
+	defer repos.Users.Delete(ctx, user.ID)
+
+	err = repos.Users.Add(ctx, newUser(seedRegion))
+	assert.True(t, errors.Contains(err, registry.ErrQuotaExceeded), "user added over the quota: %v", err)
+
+	other := newUser("AA002")
+	require.Nil(t, repos.Users.Add(ctx, other))
+	defer repos.Users.Delete(ctx, other.ID)
+
+	_, err = repos.Users.Update(ctx, other.ID, registry.User{Region: seedRegion})
+	assert.True(t, errors.Contains(err, registry.ErrQuotaExceeded), "user moved over the quota: %v", err)
+
+	//concurrent additions are checked one after the other
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		added []string
+	)
+	for i := 0; i < 6; i++ {
+		node := registry.Node{UUID: newID(t), Addr: newID(t), Name: "quota node", Type: nodeType.ID, Region: seedRegion,
+			Latd: "-6.7735", Long: "39.2395", Created: time.Now().Format(time.RFC3339)}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			err := repos.Nodes.Add(ctx, node)
+			if err == nil {
+				mu.Lock()
+				added = append(added, node.UUID)
+				mu.Unlock()
+				return
+			}
+			assert.True(t, errors.Contains(err, registry.ErrQuotaExceeded), "%v", err)
+		}()
+	}
+	wg.Wait()
+
+	defer func() {
+		for _, id := range added {
+			require.Nil(t, repos.Nodes.Delete(ctx, id))
+		}
+	}()
+	assert.Len(t, added, 2, "nodes added over the quota")
+
+	usage, err := repos.Quotas.Usage(ctx, registry.QuotaRegion, seedRegion)
+	require.Nil(t, err)
+	assert.Equal(t, registry.Consumption{Used: before.Users.Used + 1, Limit: quota.Users}, usage.Users)
+	assert.Equal(t, registry.Consumption{Used: 2, Limit: 2}, usage.Nodes[nodeType.ID])
+	assert.Equal(t, before.Nodes[int(registry.Controller)], usage.Nodes[int(registry.Controller)])
+
+	moved := registry.Node{UUID: newID(t), Addr: newID(t), Name: "quota node", Type: nodeType.ID, Region: "AA002",
+		Latd: "-6.7735", Long: "39.2395", Created: time.Now().Format(time.RFC3339)}
+	require.Nil(t, repos.Nodes.Add(ctx, moved))
+	defer repos.Nodes.Delete(ctx, moved.UUID)
+
+	moved.Region = seedRegion
+	_, err = repos.Nodes.Update(ctx, moved.UUID, moved)
+	assert.True(t, errors.Contains(err, registry.ErrQuotaExceeded), "node moved over the quota: %v", err)
+
+	//the quota of an organization covers all of its regions
+	org := registry.Organization{ID: newID(t), Name: "Quota Utility", Created: time.Now().Format(time.RFC3339)}
+	require.Nil(t, repos.Orgs.Add(ctx, org))
+	defer repos.Orgs.Delete(ctx, org.ID)
+
+	region := registry.Region{ID: newID(t), Name: "Quota", Desc: "region of the quota organization", Org: org.ID}
+	require.Nil(t, repos.Regions.Add(ctx, region))
+	defer repos.Regions.Delete(ctx, region.ID)
+
+	require.Nil(t, repos.Quotas.Set(ctx, registry.Quota{Scope: registry.QuotaOrg, ID: org.ID, Users: 1}))
+	defer func() {
+		require.Nil(t, repos.Quotas.Set(ctx, registry.Quota{Scope: registry.QuotaOrg, ID: org.ID}))
+	}()
+
+	member := newUser(region.ID)
+	member.Org = org.ID
+	require.Nil(t, repos.Users.Add(ctx, member))
+	defer repos.Users.Delete(ctx, member.ID)
+
+	member = newUser(region.ID)
+	member.Org = org.ID
+	err = repos.Users.Add(ctx, member)
+	assert.True(t, errors.Contains(err, registry.ErrQuotaExceeded), "user added over the quota of its organization: %v", err)
+}
+
+func testNodeTypes(t *testing.T, repo registry.NodeTypeRepository) {
+	ctx := context.Background()
+
+	//ids of the seed rows are small, fresh ones are taken far above them
+	nodeType := registry.NodeType{
+		ID:           1000 + int(time.Now().UnixNano()%1000000),
+		Name:         newID(t),
+		Desc:         "contract test node type",
+		Capabilities: []registry.Capability{registry.Publish, registry.Command},
+	}
+
+	require.Nil(t, repo.Add(ctx, nodeType))
+	assert.NotNil(t, repo.Add(ctx, nodeType), "node type added twice")
+
+	got, err := repo.Get(ctx, nodeType.ID)
+	require.Nil(t, err)
+	assert.Equal(t, nodeType, got)
+
+	seed, err := repo.Get(ctx, int(registry.Controller))
+	require.Nil(t, err)
+	assert.Equal(t, []registry.Capability{registry.Publish, registry.Subscribe, registry.Command}, seed.Capabilities)
+
+	types, err := repo.List(ctx)
+	require.Nil(t, err)
+	assert.Contains(t, types, nodeType)
+
+	nodeType.Desc = "updated"
+	nodeType.Capabilities = nil
+	got, err = repo.Update(ctx, nodeType.ID, nodeType)
+	require.Nil(t, err)
+	assert.Equal(t, "updated", got.Desc)
+	assert.Empty(t, got.Capabilities)
+
+	_, err = repo.Update(ctx, nodeType.ID+1000000, nodeType)
+	assert.Equal(t, errors.NotFound, errors.KindOf(err), "unknown node type updated")
+
+	err = repo.Delete(ctx, int(registry.Sensor))
+	assert.True(t, errors.Contains(err, registry.ErrNodeTypeInUse), "node type of the seed nodes deleted: %v", err)
+
+	require.Nil(t, repo.Delete(ctx, nodeType.ID))
+
+	_, err = repo.Get(ctx, nodeType.ID)
+	assert.Equal(t, errors.NotFound, errors.KindOf(err), "deleted node type still found")
+
+	err = repo.Delete(ctx, nodeType.ID)
+	assert.Equal(t, errors.NotFound, errors.KindOf(err), "node type deleted twice")
+}
+
+func testClaims(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	repo := repos.Claims
+
+	code, err := registry.CreateClaimCode(seedRegion, int(registry.Sensor), time.Hour)
+	require.Nil(t, err)
+	require.Nil(t, repo.AddCode(ctx, code))
+
+	got, err := repo.GetCode(ctx, code.Code)
+	require.Nil(t, err)
+	assert.Equal(t, code, got)
+
+	_, err = repo.GetCode(ctx, newID(t))
+	assert.Equal(t, errors.NotFound, errors.KindOf(err), "unknown claim code found")
+
+	codes, err := repo.ListCodes(ctx)
+	require.Nil(t, err)
+	assert.Contains(t, codes, code)
+
+	codes, err = repo.ListCodes(scoped())
+	require.Nil(t, err)
+	assert.NotContains(t, codes, code)
+
+	newNode := func(region string) registry.Node {
+		return registry.Node{
+			UUID:    newID(t),
+			Addr:    newID(t),
+			Name:    "provisioned node",
+			Type:    int(registry.Sensor),
+			Region:  region,
+			Latd:    "-6.7735",
+			Long:    "39.2395",
+			Created: time.Now().Format(time.RFC3339),
+		}
+	}
+
+	//a node that can not be added leaves the code unredeemed
+	invalid := newNode(newID(t))
+	err = repo.Provision(ctx, code.Code, invalid, "hash")
+	assert.True(t, errors.Contains(err, registry.ErrInvalidReference), "node of an unknown region provisioned: %v", err)
+
+	got, err = repo.GetCode(ctx, code.Code)
+	require.Nil(t, err)
+	assert.Empty(t, got.Node, "claim code redeemed by a node that was not added")
+
+	_, err = repo.Credentials(ctx, invalid.UUID)
+	assert.Equal(t, errors.NotFound, errors.KindOf(err), "credentials saved for a node that was not added")
+
+	node := newNode(seedRegion)
+	require.Nil(t, repo.Provision(ctx, code.Code, node, "hash"))
+
+	got, err = repo.GetCode(ctx, code.Code)
+	require.Nil(t, err)
+	assert.Equal(t, node.UUID, got.Node)
+
+	_, err = repos.Nodes.Get(ctx, node.UUID)
+	assert.Nil(t, err, "provisioned node not added")
+
+	hash, err := repo.Credentials(ctx, node.UUID)
+	require.Nil(t, err)
+	assert.Equal(t, "hash", hash)
+
+	reused := newNode(seedRegion)
+	err = repo.Provision(ctx, code.Code, reused, "hash")
+	assert.True(t, errors.Contains(err, registry.ErrClaimCodeUsed), "claim code redeemed twice: %v", err)
+
+	_, err = repos.Nodes.Get(ctx, reused.UUID)
+	assert.NotNil(t, err, "node added with a used claim code")
+
+	expired := registry.ClaimCode{
+		Code:    newID(t),
+		Region:  seedRegion,
+		Type:    int(registry.Sensor),
+		Created: time.Now().Add(-2 * time.Hour).Format(time.RFC3339),
+		Expires: time.Now().Add(-time.Hour).Format(time.RFC3339),
+	}
+	require.Nil(t, repo.AddCode(ctx, expired))
+	assert.NotNil(t, repo.Provision(ctx, expired.Code, newNode(seedRegion), "hash"), "expired claim code redeemed")
+
+	//devices racing for the same code, only one of them is added
+	racing, err := registry.CreateClaimCode(seedRegion, int(registry.Sensor), time.Hour)
+	require.Nil(t, err)
+	require.Nil(t, repo.AddCode(ctx, racing))
+
+	nodes := make([]registry.Node, 8)
+	errs := make([]error, len(nodes))
+
+	var wg sync.WaitGroup
+	for i := range nodes {
+		nodes[i] = newNode(seedRegion)
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = repo.Provision(ctx, racing.Code, nodes[i], "hash")
+		}(i)
+	}
+	wg.Wait()
+
+	added := 0
+	for i, err := range errs {
+		_, getErr := repos.Nodes.Get(ctx, nodes[i].UUID)
+		if err == nil {
+			added++
+			assert.Nil(t, getErr, "provisioned node not added")
+			continue
+		}
+
+		assert.True(t, errors.Contains(err, registry.ErrClaimCodeUsed), "unexpected provisioning error: %v", err)
+		assert.NotNil(t, getErr, "node added with a used claim code")
+	}
+	assert.Equal(t, 1, added, "claim code redeemed by %d devices", added)
+
+	claim := registry.Claim{
+		ID:      newID(t),
+		Code:    code.Code,
+		Addr:    newID(t),
+		Node:    node.UUID,
+		Result:  registry.ClaimAccepted,
+		Created: time.Now().Format(time.RFC3339),
+	}
+	require.Nil(t, repo.SaveClaim(ctx, claim))
+
+	claims, err := repo.ListClaims(ctx)
+	require.Nil(t, err)
+	assert.Contains(t, claims, claim)
+
+	claims, err = repo.ListClaims(scoped())
+	require.Nil(t, err)
+	assert.NotContains(t, claims, claim)
+}
+
+func testCertificates(t *testing.T, repo registry.CertificateRepository) {
+	ctx := context.Background()
+
+	now := time.Now()
+	node := newID(t)
+
+	newCert := func() registry.Certificate {
+		return registry.Certificate{
+			Serial:  newID(t),
+			Node:    node,
+			PEM:     "-----BEGIN CERTIFICATE-----",
+			Issued:  now.Format(time.RFC3339),
+			Expires: now.Add(time.Hour).Format(time.RFC3339),
+		}
+	}
+
+	cert, other := newCert(), newCert()
+	require.Nil(t, repo.Add(ctx, cert))
+	require.Nil(t, repo.Add(ctx, other))
+	assert.NotNil(t, repo.Add(ctx, cert), "certificate added twice")
+
+	got, err := repo.Get(ctx, cert.Serial)
+	require.Nil(t, err)
+	assert.Equal(t, cert, got)
+
+	_, err = repo.Get(scoped(), cert.Serial)
+	assert.Equal(t, errors.NotFound, errors.KindOf(err), "certificate without an organization visible to a tenant")
+
+	certs, err := repo.List(ctx)
+	require.Nil(t, err)
+	for _, c := range certs {
+		assert.Empty(t, c.PEM, "certificate listed with its pem")
+	}
+
+	require.Nil(t, repo.Revoke(ctx, cert.Serial, now))
+
+	err = repo.Revoke(ctx, cert.Serial, now)
+	assert.True(t, errors.Contains(err, registry.ErrCertificateRevoked), "certificate revoked twice: %v", err)
+
+	err = repo.Revoke(ctx, newID(t), now)
+	assert.Equal(t, errors.NotFound, errors.KindOf(err), "unknown certificate revoked")
+
+	require.Nil(t, repo.RevokeNode(ctx, node, now))
+
+	got, err = repo.Get(ctx, other.Serial)
+	require.Nil(t, err)
+	assert.NotEmpty(t, got.Revoked, "certificate of the node not revoked")
+
+	revoked, err := repo.Revoked(ctx)
+	require.Nil(t, err)
+
+	serials := make([]string, 0, len(revoked))
+	for _, c := range revoked {
+		serials = append(serials, c.Serial)
+	}
+	assert.Contains(t, serials, cert.Serial)
+	assert.Contains(t, serials, other.Serial)
+}
+
+func testAPIKeys(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	owner := registry.User{
+		ID:      newID(t),
+		Name:    "Key Owner",
+		Email:   "owner@test.com",
+		Group:   int(registry.RegionAdmin),
+		Region:  seedRegion,
+		Created: time.Now().Format(time.RFC3339),
+	}
+	require.Nil(t, repos.Users.Add(ctx, owner))
+
+	key := registry.APIKey{
+		ID:      newID(t),
+		Name:    "contract",
+		Owner:   owner.ID,
+		Role:    int(registry.RegionAdmin),
+		Region:  seedRegion,
+		Hash:    "hash",
+		Created: time.Now().Format(time.RFC3339),
+		Expires: time.Now().Add(time.Hour).Format(time.RFC3339),
+	}
+	require.Nil(t, repos.Keys.Add(ctx, key))
+
+	assert.NotNil(t, repos.Keys.Add(ctx, registry.APIKey{ID: newID(t), Owner: newID(t),
+		Created: key.Created}), "key of an unknown owner added")
+
+	got, err := repos.Keys.Get(ctx, key.ID)
+	require.Nil(t, err)
+	assert.Equal(t, key, got)
+
+	_, err = repos.Keys.Get(scoped(), key.ID)
+	assert.Equal(t, errors.NotFound, errors.KindOf(err), "key of a user without an organization visible to a tenant")
+
+	keys, err := repos.Keys.List(ctx, owner.ID)
+	require.Nil(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, key.ID, keys[0].ID)
+	assert.Empty(t, keys[0].Hash, "key listed with its hash")
+
+	require.Nil(t, repos.Keys.Revoke(ctx, key.ID, time.Now()))
+
+	err = repos.Keys.Revoke(ctx, key.ID, time.Now())
+	assert.True(t, errors.Contains(err, registry.ErrAPIKeyRevoked), "key revoked twice: %v", err)
+
+	got, err = repos.Keys.Get(ctx, key.ID)
+	require.Nil(t, err)
+	assert.NotEmpty(t, got.Revoked)
+
+	//keys are removed with their owner
+	require.Nil(t, repos.Users.Delete(ctx, owner.ID))
+
+	_, err = repos.Keys.Get(ctx, key.ID)
+	assert.Equal(t, errors.NotFound, errors.KindOf(err), "key of a deleted user still found")
+}
+
+func testOrganizations(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	org := registry.Organization{
+		ID:      newID(t),
+		Name:    "Contract Utility",
+		Desc:    "contract test organization",
+		Created: time.Now().Format(time.RFC3339),
+	}
+	require.Nil(t, repos.Orgs.Add(ctx, org))
+	assert.NotNil(t, repos.Orgs.Add(ctx, org), "organization added twice")
+
+	tenant := registry.WithAPIKey(ctx, registry.APIKey{Org: org.ID})
+
+	got, err := repos.Orgs.Get(tenant, org.ID)
+	require.Nil(t, err)
+	assert.Equal(t, org, got)
+
+	_, err = repos.Orgs.Get(scoped(), org.ID)
+	assert.Equal(t, errors.NotFound, errors.KindOf(err), "organization visible to another tenant")
+
+	orgs, err := repos.Orgs.List(tenant)
+	require.Nil(t, err)
+	assert.Equal(t, []registry.Organization{org}, orgs)
+
+	region := registry.Region{ID: newID(t), Name: "Tenant", Desc: "region of the organization", Org: org.ID}
+	require.Nil(t, repos.Regions.Add(ctx, region))
+
+	gotRegion, err := repos.Regions.Get(tenant, region.ID)
+	require.Nil(t, err)
+	assert.Equal(t, region, gotRegion)
+
+	_, err = repos.Regions.Get(scoped(), region.ID)
+	assert.NotNil(t, err, "region visible to another tenant")
+
+	//the ids of added regions are longer than the ones of the seed regions
+	node := registry.Node{
+		UUID:    newID(t),
+		Addr:    newID(t),
+		Name:    "tenant node",
+		Type:    int(registry.Sensor),
+		Region:  region.ID,
+		Latd:    "-6.7735",
+		Long:    "39.2395",
+		Created: time.Now().Format(time.RFC3339),
+		Org:     org.ID,
+	}
+	require.Nil(t, repos.Nodes.Add(ctx, node))
+
+	gotNode, err := repos.Nodes.Get(tenant, node.UUID)
+	require.Nil(t, err)
+	assert.Equal(t, node, gotNode)
+
+	err = repos.Orgs.Delete(ctx, org.ID)
+	assert.True(t, errors.Contains(err, registry.ErrOrganizationInUse), "organization with regions deleted: %v", err)
+
+	require.Nil(t, repos.Nodes.Delete(tenant, node.UUID))
+	require.Nil(t, repos.Regions.Delete(tenant, region.ID))
+	require.Nil(t, repos.Orgs.Delete(ctx, org.ID))
+
+	err = repos.Orgs.Delete(ctx, org.ID)
+	assert.Equal(t, errors.NotFound, errors.KindOf(err), "organization deleted twice")
+}
@@ -0,0 +1,129 @@
+package registry
+
+import (
+	"encoding/json"
+	"github.com/piusalfred/registry/pkg/errors"
+	"github.com/piusalfred/registry/pkg/jsonschema"
+)
+
+var (
+	// ErrInvalidSchema indicates a schema without a node type or a node to
+	// describe, or whose document is not a JSON Schema
+	ErrInvalidSchema = errors.NewKind(errors.Invalid, "schema must describe either a node type or a node with a JSON Schema document")
+
+	// ErrUnknownSchemaKind indicates a kind ParseSchemaKind does not know
+	ErrUnknownSchemaKind = errors.NewKind(errors.Invalid, "unknown schema kind, use telemetry or commands")
+
+	// ErrSchemaNotFound is returned when neither a node nor its type have a
+	// schema of the kind, or not of the requested version
+	ErrSchemaNotFound = errors.NewKind(errors.NotFound, "no schema of the kind for the node")
+)
+
+// SchemaKind is what a schema describes
+type SchemaKind string
+
+const (
+	// SchemaTelemetry describes the payloads a node publishes
+	SchemaTelemetry SchemaKind = "telemetry"
+	// SchemaCommands describes the commands a node accepts
+	SchemaCommands SchemaKind = "commands"
+)
+
+// ParseSchemaKind returns the kind called name, telemetry when name is empty
+func ParseSchemaKind(name string) (SchemaKind, error) {
+	switch k := SchemaKind(name); k {
+	case "":
+		return SchemaTelemetry, nil
+	case SchemaTelemetry, SchemaCommands:
+		return k, nil
+	}
+
+	return "", ErrUnknownSchemaKind
+}
+
+// Schema is a version of the JSON Schema of the payloads of a node type, or
+// of a single node whose payloads differ from the ones of its type. Adding
+// a schema for the same node type or node and kind adds its next version.
+type Schema struct {
+	ID       string          `json:"id"`
+	Type     int             `json:"type,omitempty"`
+	Node     string          `json:"node,omitempty"`
+	Kind     SchemaKind      `json:"kind"`
+	Version  int             `json:"version"`
+	Document json.RawMessage `json:"schema"`
+	Created  string          `json:"created"`
+	Org      string          `json:"org,omitempty"`
+}
+
+// Validate checks the fields of s and compiles its document
+func (s *Schema) Validate() error {
+	kind, err := ParseSchemaKind(string(s.Kind))
+	if err != nil {
+		return err
+	}
+	s.Kind = kind
+
+	if (s.Type == 0) == (s.Node == "") || s.Type < 0 {
+		return ErrInvalidSchema
+	}
+
+	if _, err := jsonschema.Compile(s.Document); err != nil {
+		return errors.Wrap(ErrInvalidSchema, err)
+	}
+
+	return nil
+}
+
+// Describes reports whether s is a schema of the kind for the node type typ,
+// or for the node when node is not empty
+func (s Schema) Describes(kind SchemaKind, typ int, node string) bool {
+	if s.Kind != kind {
+		return false
+	}
+
+	if node != "" {
+		return s.Node == node
+	}
+
+	return s.Node == "" && s.Type == typ
+}
+
+// SchemaFilter selects schemas, zero fields match every schema
+type SchemaFilter struct {
+	Type int        `json:"type,omitempty"`
+	Node string     `json:"node,omitempty"`
+	Kind SchemaKind `json:"kind,omitempty"`
+}
+
+// Matches reports whether s is selected by f
+func (f SchemaFilter) Matches(s Schema) bool {
+	return (f.Type == 0 || s.Type == f.Type) &&
+		(f.Node == "" || s.Node == f.Node) &&
+		(f.Kind == "" || s.Kind == f.Kind)
+}
+
+// Validation is the result of validating a payload against the schema of a
+// node, Errors lists the keywords an invalid payload does not satisfy
+type Validation struct {
+	Schema  string             `json:"schema"`
+	Version int                `json:"version"`
+	Valid   bool               `json:"valid"`
+	Errors  []jsonschema.Error `json:"errors,omitempty"`
+}
+
+// ValidatePayload validates payload against the document of s
+func (s Schema) ValidatePayload(payload []byte) (Validation, error) {
+	compiled, err := jsonschema.Compile(s.Document)
+	if err != nil {
+		return Validation{}, errors.Wrap(ErrInvalidSchema, err)
+	}
+
+	errs := compiled.Validate(payload)
+
+	return Validation{
+		Schema:  s.ID,
+		Version: s.Version,
+		Valid:   len(errs) == 0,
+		Errors:  errs,
+	}, nil
+}
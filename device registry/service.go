@@ -2,12 +2,16 @@ package registry
 
 import (
 	"context"
+	"encoding/json"
 	"github.com/piusalfred/registry/logger"
 	"github.com/piusalfred/registry/pkg/errors"
+	"math"
+	"sort"
+	"time"
 )
 
 var (
-	ErrBadBodyRequest = errors.New("bad request body, make sure all details are there")
+	ErrBadBodyRequest = errors.NewKind(errors.Invalid, "bad request body, make sure all details are there")
 )
 
 // Service describes the service.
@@ -20,8 +24,9 @@ type Service interface {
 
 	AddUser(ctx context.Context, user User) error
 
-	//ListUser returns all the list of all available users
-	ListUser(ctx context.Context) ([]User, error)
+	//ListUser returns all the list of all available users, or those of the
+	//regions selected by filter
+	ListUser(ctx context.Context, filter RegionFilter) ([]User, error)
 
 	DeleteUser(ctx context.Context, id string) error
 
@@ -34,25 +39,196 @@ type Service interface {
 	//token is a generated token/password if a user is admin
 	GetNode(ctx context.Context, id string) (Node, error)
 
-	//ListUser returns all the list of all available users
-	ListNodes(ctx context.Context) ([]Node, error)
+	//ListNodes returns the nodes the request can access, narrowed to the
+	//regions selected by filter
+	ListNodes(ctx context.Context, filter RegionFilter) ([]Node, error)
 
 	DeleteNode(ctx context.Context, id string) error
 
+	//UpdateNode changes the fields of the node that are not empty, a master
+	//of NoMaster removes its master
 	UpdateNode(ctx context.Context, id string, user Node) (Node, error)
 
 	AddRegion(ctx context.Context, region Region) error
 
 	ListRegions(ctx context.Context) ([]Region, error)
+
+	//MoveRegion makes parent the parent of the region id, an empty parent
+	//makes it a root region. A region can not be moved under itself or one
+	//of its descendants
+	MoveRegion(ctx context.Context, id, parent string) (Region, error)
+
+	//RegionSubtree returns the region id followed by its descendants, every
+	//region comes before its children
+	RegionSubtree(ctx context.Context, id string) ([]Region, error)
+
+	//RegionAncestors returns the parent of the region id, its parent and so
+	//on up to the root region
+	RegionAncestors(ctx context.Context, id string) ([]Region, error)
+
+	//SuggestRegion returns the regions whose boundary contains the location,
+	//the smallest first
+	SuggestRegion(ctx context.Context, lat, long float64) ([]Region, error)
+
+	//AddNodeType adds a new node type to the catalogue of node types
+	AddNodeType(ctx context.Context, nodeType NodeType) error
+
+	GetNodeType(ctx context.Context, id int) (NodeType, error)
+
+	ListNodeTypes(ctx context.Context) ([]NodeType, error)
+
+	DeleteNodeType(ctx context.Context, id int) error
+
+	UpdateNodeType(ctx context.Context, id int, nodeType NodeType) (NodeType, error)
+
+	//CreateClaimCode generates a single-use code that lets a device provision
+	//itself as a node of type typ in the region, the code expires after ttl
+	CreateClaimCode(ctx context.Context, region string, typ int, ttl time.Duration) (ClaimCode, error)
+
+	ListClaimCodes(ctx context.Context) ([]ClaimCode, error)
+
+	//ProvisionNode registers the node described by a device using a claim code,
+	//region and type are taken from the code. The returned credentials are
+	//only available once
+	ProvisionNode(ctx context.Context, code string, node Node) (Credentials, error)
+
+	//AuthenticateNode checks the key issued to a provisioned node and
+	//returns the node, it fails with ErrInvalidNodeKey if the key is wrong
+	AuthenticateNode(ctx context.Context, node string, key string) (Node, error)
+
+	//ListClaims returns the audit records of all provisioning attempts
+	ListClaims(ctx context.Context) ([]Claim, error)
+
+	//IssueCertificate signs the PEM encoded csr of a registered node, the
+	//certificate common name is the node uuid whatever the csr subject is.
+	//Certificates are issued and revoked by the admins of the node region
+	IssueCertificate(ctx context.Context, node string, csr string, ttl time.Duration) (Certificate, error)
+
+	RevokeCertificate(ctx context.Context, serial string) error
+
+	ListCertificates(ctx context.Context) ([]Certificate, error)
+
+	//RevocationList returns the PEM encoded CRL of all revoked certificates
+	//that have not yet expired
+	RevocationList(ctx context.Context) ([]byte, error)
+
+	//CreateAPIKey creates a named key for the owner, the plain key is only
+	//returned here. A zero ttl creates a key that does not expire
+	CreateAPIKey(ctx context.Context, owner, name string, role int, region string, ttl time.Duration) (APIKey, error)
+
+	//ListAPIKeys lists the keys of the owner, all keys if owner is empty
+	ListAPIKeys(ctx context.Context, owner string) ([]APIKey, error)
+
+	RevokeAPIKey(ctx context.Context, id string) error
+
+	//AuthAPIKey checks the plain key and returns the key details
+	AuthAPIKey(ctx context.Context, key string) (APIKey, error)
+
+	//AddOrganization creates a new tenant, only the global Admin can do it
+	AddOrganization(ctx context.Context, org Organization) (Organization, error)
+
+	GetOrganization(ctx context.Context, id string) (Organization, error)
+
+	ListOrganizations(ctx context.Context) ([]Organization, error)
+
+	//DeleteOrganization removes a tenant that no longer owns any region or user
+	DeleteOrganization(ctx context.Context, id string) error
+
+	//AddFirmware adds a firmware release to the catalogue of the organization,
+	//the checksum of a blob is computed when it is not given
+	AddFirmware(ctx context.Context, fw Firmware) (Firmware, error)
+
+	//GetFirmware returns the release with its blob, if it has one
+	GetFirmware(ctx context.Context, id string) (Firmware, error)
+
+	//ListFirmware returns the releases without their blobs
+	ListFirmware(ctx context.Context) ([]Firmware, error)
+
+	//DeleteFirmware removes a release no campaign rolls out
+	DeleteFirmware(ctx context.Context, id string) error
+
+	//CreateCampaign starts rolling a firmware out to the nodes of the target,
+	//the rollout share of them is enrolled right away
+	CreateCampaign(ctx context.Context, campaign Campaign) (Campaign, error)
+
+	GetCampaign(ctx context.Context, id string) (Campaign, error)
+
+	ListCampaigns(ctx context.Context) ([]Campaign, error)
+
+	//AdvanceCampaign enrolls nodes until percent of the targeted nodes are.
+	//Advancing to the current percent enrolls the nodes that joined the
+	//target since
+	AdvanceCampaign(ctx context.Context, id string, percent int) (Campaign, error)
+
+	//ListRollouts returns the progress of the nodes enrolled in the campaign
+	ListRollouts(ctx context.Context, campaign string) ([]Rollout, error)
+
+	//ReportRollout records the status a node reports for a campaign, nodes
+	//that applied the firmware now run its version
+	ReportRollout(ctx context.Context, campaign, node string, status RolloutStatus, msg string) (Rollout, error)
+
+	//AddSchema adds the next version of the schema of a node type, or of a
+	//single node, for the kind of payloads it describes
+	AddSchema(ctx context.Context, schema Schema) (Schema, error)
+
+	GetSchema(ctx context.Context, id string) (Schema, error)
+
+	ListSchemas(ctx context.Context, filter SchemaFilter) ([]Schema, error)
+
+	//NodeSchema returns the schema of the kind that applies to the node, the
+	//latest version unless version is set
+	NodeSchema(ctx context.Context, node string, kind SchemaKind, version int) (Schema, error)
+
+	//ValidatePayload validates a sample payload against the schema of the
+	//kind that applies to the node
+	ValidatePayload(ctx context.Context, node string, kind SchemaKind, version int, payload json.RawMessage) (Validation, error)
+
+	//SetQuota replaces the quota of a region, set by the admins of its
+	//organization, or of an organization, set by the global admin
+	SetQuota(ctx context.Context, quota Quota) (Quota, error)
+
+	ListQuotas(ctx context.Context) ([]Quota, error)
+
+	//Usage reports the users and nodes of a region or an organization
+	//against the limits of its quota
+	Usage(ctx context.Context, scope QuotaScope, id string) (Usage, error)
+
+	//Inventory counts the nodes of the regions selected by filter by
+	//region, type and status, or by the dimensions in by
+	Inventory(ctx context.Context, filter RegionFilter, by []Dimension) ([]InventoryCount, error)
+
+	//Growth reports the nodes added to the regions selected by filter in
+	//each period since the given time
+	Growth(ctx context.Context, filter RegionFilter, period GrowthPeriod, since time.Time) ([]Growth, error)
+
+	//OrphanedNodes returns the nodes whose master does not exist
+	OrphanedNodes(ctx context.Context, filter RegionFilter) ([]Node, error)
+
+	//Watch streams the changes made to the registry that match filter, those
+	//made after cursor are replayed first. Requests scoped to an organization
+	//or a region only see the changes made to it
+	Watch(ctx context.Context, filter EventFilter, cursor time.Duration) (<-chan Event, error)
 }
 
 type service struct {
 	Users        UserRepository
 	Nodes        NodeRepository
 	Regions      RegionRepository
+	Types        NodeTypeRepository
+	Claims       ClaimRepository
+	Certs        CertificateRepository
+	CA           CertificateAuthority
+	Keys         APIKeyRepository
+	Orgs         OrganizationRepository
+	Firmware     FirmwareRepository
+	Campaigns    CampaignRepository
+	Schemas      SchemaRepository
+	Quotas       QuotaRepository
+	Events       EventFeed
 	Hasher       Hasher
 	Logger       logger.Logger
 	UUIDProvider UUIDProvider
+	Geofence     GeofencePolicy
 }
 
 func (svc service) AuthUser(ctx context.Context, id, password string) error {
@@ -62,7 +238,30 @@ func (svc service) AuthUser(ctx context.Context, id, password string) error {
 	}
 
 	hashedPassword := user.Password
-	return svc.Hasher.Compare(hashedPassword, password)
+	if err = svc.Hasher.Compare(password, hashedPassword); err != nil {
+		return err
+	}
+
+	//the password is known now, hashes from an older algorithm or with
+	//weaker parameters are replaced without failing the login
+	if svc.Hasher.NeedsRehash(hashedPassword) {
+		svc.rehash(ctx, user.ID, password)
+	}
+
+	return nil
+}
+
+// rehash replaces the password hash of the user with id by one generated
+// with the current parameters of the hasher.
+func (svc service) rehash(ctx context.Context, id, password string) {
+	hash, err := svc.Hasher.Hash(password)
+	if err == nil {
+		err = svc.Users.UpdatePassword(ctx, id, hash)
+	}
+
+	if err != nil {
+		svc.Logger.WithContext(ctx).Warn("could not rehash password", "user_id", id, "err", err.Error())
+	}
 }
 
 func (svc service) GetUser(ctx context.Context, id string) (user User, err error) {
@@ -71,10 +270,23 @@ func (svc service) GetUser(ctx context.Context, id string) (user User, err error
 }
 func (svc service) AddUser(ctx context.Context, user User) (err error) {
 	//ONLY NAME EMAIL REGION AND PASSWORD
-	if user.Name == "" || user.Email == "" || user.Password == "" {
+	if user.Name == "" || user.Email == "" || user.Password == "" || user.Region == "" {
 		return ErrBadBodyRequest
 	}
 
+	if err = checkRole(ctx, OrgAdmin); err != nil {
+		return err
+	}
+
+	org, err := svc.orgOf(ctx, user.Org)
+	if err != nil {
+		return err
+	}
+
+	if err = svc.checkRegion(ctx, user.Region, org); err != nil {
+		return err
+	}
+
 	name := user.Name
 	mail := user.Email
 	pass := user.Password
@@ -86,20 +298,79 @@ func (svc service) AddUser(ctx context.Context, user User) (err error) {
 		return err
 	}
 
-	err = svc.Users.Add(ctx, u)
+	u.Org = org
+
+	if err = svc.Users.Add(ctx, u); err != nil {
+		return err
+	}
 
+	svc.record(ctx, CREATE_USER, u.ID, u.Region, u.Org)
 	return
 }
-func (svc service) ListUser(ctx context.Context) (users []User, err error) {
+func (svc service) ListUser(ctx context.Context, filter RegionFilter) (users []User, err error) {
 	users, err = svc.Users.List(ctx)
-	return users, err
+	if err != nil {
+		return users, err
+	}
+
+	selected, err := svc.selectRegions(ctx, filter, false)
+	if err != nil || selected == nil {
+		return users, err
+	}
+
+	var inRegions []User
+	for _, user := range users {
+		if selected[user.Region] {
+			inRegions = append(inRegions, user)
+		}
+	}
+
+	return inRegions, nil
 }
 func (svc service) DeleteUser(ctx context.Context, id string) (err error) {
-	err = svc.Users.Delete(ctx, id)
+	if err = checkRole(ctx, OrgAdmin); err != nil {
+		return err
+	}
+
+	user, err := svc.Users.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err = svc.Users.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	svc.record(ctx, DELETE_USER, user.ID, user.Region, user.Org)
 	return
 }
 func (svc service) UpdateUser(ctx context.Context, id string, user User) (u User, err error) {
+	if err = checkRole(ctx, OrgAdmin); err != nil {
+		return u, err
+	}
+
+	//nobody can grant more rights than they have
+	if user.Group != 0 {
+		if err = checkRole(ctx, UserGroup(user.Group)); err != nil {
+			return u, err
+		}
+	}
+
+	current, err := svc.Users.Get(ctx, id)
+	if err != nil {
+		return u, err
+	}
+
+	if err = svc.checkRegion(ctx, user.Region, current.Org); err != nil {
+		return u, err
+	}
+
 	u, err = svc.Users.Update(ctx, id, user)
+	if err != nil {
+		return u, err
+	}
+
+	svc.record(ctx, UPDATE_USER, u.ID, u.Region, u.Org)
 	return
 }
 
@@ -113,46 +384,1568 @@ func (svc *service) AddNode(ctx context.Context, node Node) (err error) {
 	master := node.Master
 	typ := node.Type
 
-	nodeN, err := CreateNode(svc.UUIDProvider, addr, name, regi, latd, long, master, typ)
-	err = svc.Nodes.Add(ctx, nodeN)
-	return err
+	if err = svc.checkScope(ctx, regi); err != nil {
+		return err
+	}
+
+	region, err := svc.region(ctx, regi)
+	if err != nil {
+		return err
+	}
+
+	nodeN, err := CreateNode(ctx, svc.UUIDProvider, svc.Types, addr, name, regi, latd, long, master, typ)
+	if err != nil {
+		return err
+	}
+
+	nodeN.Org = region.Org
+	nodeN.Firmware = node.Firmware
+	nodeN.Labels = node.Labels
+
+	if err = svc.checkLocation(ctx, region, nodeN); err != nil {
+		return err
+	}
+
+	if err = svc.checkMaster(ctx, nodeN); err != nil {
+		return err
+	}
+
+	if err = svc.Nodes.Add(ctx, nodeN); err != nil {
+		return err
+	}
+
+	svc.record(ctx, CREATE_NODE, nodeN.UUID, nodeN.Region, nodeN.Org)
+	return nil
 }
 func (svc *service) GetNode(ctx context.Context, id string) (node Node, err error) {
 	node, err = svc.Nodes.Get(ctx, id)
+	if err != nil {
+		return node, err
+	}
+
+	if err = svc.checkScope(ctx, node.Region); err != nil {
+		return Node{}, err
+	}
+
 	return node, err
 }
-func (svc *service) ListNodes(ctx context.Context) (nodes []Node, err error) {
+func (svc *service) ListNodes(ctx context.Context, filter RegionFilter) (nodes []Node, err error) {
 	nodes, err = svc.Nodes.List(ctx)
-	return nodes, err
+	if err != nil {
+		return nodes, err
+	}
+
+	selected, err := svc.selectRegions(ctx, filter, true)
+	if err != nil || selected == nil {
+		return nodes, err
+	}
+
+	var allowed []Node
+	for _, node := range nodes {
+		if selected[node.Region] {
+			allowed = append(allowed, node)
+		}
+	}
+
+	return allowed, nil
 }
 func (svc *service) DeleteNode(ctx context.Context, id string) (err error) {
-	err = svc.Nodes.Delete(ctx, id)
-	return err
+	node, err := svc.GetNode(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	slaves, err := svc.Nodes.Slaves(ctx, node.UUID)
+	if err != nil {
+		return err
+	}
+
+	if len(slaves) > 0 {
+		return ErrMasterInUse
+	}
+
+	//the certificates of a removed node must not be accepted anymore
+	if err = svc.Certs.RevokeNode(ctx, node.UUID, time.Now()); err != nil {
+		return err
+	}
+
+	if err = svc.Nodes.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	svc.record(ctx, DELETE_NODE, node.UUID, node.Region, node.Org)
+	return nil
 }
 func (svc *service) UpdateNode(ctx context.Context, id string, node Node) (n Node, err error) {
+	current, err := svc.GetNode(ctx, id)
+	if err != nil {
+		return n, err
+	}
+
+	//fields left empty keep their current value
+	updated := current
+	if node.Name != "" {
+		updated.Name = node.Name
+	}
+	if node.Region != "" {
+		if err = svc.checkScope(ctx, node.Region); err != nil {
+			return n, err
+		}
+		updated.Region = node.Region
+	}
+	if node.Latd != "" {
+		updated.Latd = node.Latd
+	}
+	if node.Long != "" {
+		updated.Long = node.Long
+	}
+	switch node.Master {
+	case "":
+	case NoMaster:
+		updated.Master = ""
+	default:
+		updated.Master = node.Master
+	}
+	if node.Firmware != "" {
+		updated.Firmware = node.Firmware
+	}
+	//an empty, non nil map removes the labels
+	if node.Labels != nil {
+		updated.Labels = node.Labels
+	}
 
-	n, err = svc.Nodes.Update(ctx, id, node)
-	return n, err
+	region, err := svc.region(ctx, updated.Region)
+	if err != nil {
+		return n, err
+	}
+
+	if region.Org != current.Org {
+		return n, ErrRegionOutsideOrg
+	}
+
+	if err = svc.checkLocation(ctx, region, updated); err != nil {
+		return n, err
+	}
+
+	if updated.Master != current.Master || updated.Region != current.Region {
+		if err = svc.checkMaster(ctx, updated); err != nil {
+			return n, err
+		}
+	}
+
+	//the nodes of a controller have to be in its region
+	if updated.Region != current.Region {
+		slaves, err := svc.Nodes.Slaves(ctx, current.UUID)
+		if err != nil {
+			return n, err
+		}
+
+		if len(slaves) > 0 {
+			return n, ErrMasterInUse
+		}
+	}
+
+	n, err = svc.Nodes.Update(ctx, current.UUID, updated)
+	if err != nil {
+		return n, err
+	}
+
+	svc.record(ctx, UPDATE_NODE, n.UUID, n.Region, n.Org)
+	return n, nil
 }
 func (svc *service) AddRegion(ctx context.Context, region Region) (err error) {
-	return svc.Regions.Add(ctx, region)
+	//region admins can add sub-regions to the regions they administer
+	if err = svc.checkRegionAdmin(ctx, region.Parent); err != nil {
+		return err
+	}
+
+	region.Org, err = svc.orgOf(ctx, region.Org)
+	if err != nil {
+		return err
+	}
+
+	if region.Parent != "" {
+		if region.Parent == region.ID {
+			return ErrRegionCycle
+		}
+
+		parent, err := svc.Regions.Get(ctx, region.Parent)
+		if err != nil || parent.Org != region.Org {
+			return ErrUnknownParent
+		}
+	}
+
+	if region.Boundary != nil {
+		if err = region.Boundary.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if err = svc.Regions.Add(ctx, region); err != nil {
+		return err
+	}
+
+	svc.record(ctx, CREATE_REGION, region.ID, region.ID, region.Org)
+	return nil
 }
 func (svc *service) ListRegions(ctx context.Context) (regions []Region, err error) {
 	regions, err = svc.Regions.List(ctx)
 	return
 }
+func (svc *service) MoveRegion(ctx context.Context, id, parent string) (region Region, err error) {
+	//region admins can only move regions within the ones they administer
+	if err = svc.checkRegionAdmin(ctx, id); err != nil {
+		return region, err
+	}
+
+	if err = svc.checkRegionAdmin(ctx, parent); err != nil {
+		return region, err
+	}
+
+	region, err = svc.Regions.Get(ctx, id)
+	if err != nil {
+		return region, err
+	}
+
+	if parent != "" {
+		tree, err := svc.regionTree(ctx)
+		if err != nil {
+			return Region{}, err
+		}
+
+		p, ok := tree.Get(parent)
+		if !ok || p.Org != region.Org {
+			return Region{}, ErrUnknownParent
+		}
+
+		if tree.Within(parent, id) {
+			return Region{}, ErrRegionCycle
+		}
+	}
+
+	region.Parent = parent
+	region, err = svc.Regions.Update(ctx, id, region)
+	if err != nil {
+		return region, err
+	}
+
+	svc.record(ctx, UPDATE_REGION, region.ID, region.ID, region.Org)
+	return region, nil
+}
+func (svc *service) RegionSubtree(ctx context.Context, id string) (regions []Region, err error) {
+	if _, err = svc.Regions.Get(ctx, id); err != nil {
+		return nil, err
+	}
+
+	tree, err := svc.regionTree(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return tree.Subtree(id), nil
+}
+func (svc *service) RegionAncestors(ctx context.Context, id string) (regions []Region, err error) {
+	if _, err = svc.Regions.Get(ctx, id); err != nil {
+		return nil, err
+	}
+
+	tree, err := svc.regionTree(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return tree.Ancestors(id), nil
+}
+func (svc *service) SuggestRegion(ctx context.Context, lat, long float64) (regions []Region, err error) {
+	if !(math.Abs(lat) <= 90) || !(math.Abs(long) <= 180) {
+		return nil, ErrInvalidLocation
+	}
+
+	all, err := svc.Regions.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, err := svc.selectRegions(ctx, RegionFilter{}, true)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, region := range all {
+		if allowed != nil && !allowed[region.ID] {
+			continue
+		}
+
+		if region.Boundary != nil && region.Boundary.Contains(lat, long) {
+			regions = append(regions, region)
+		}
+	}
+
+	if len(regions) == 0 {
+		return nil, ErrNoRegionAtLocation
+	}
+
+	//nested regions: the smallest one is the most specific
+	sort.SliceStable(regions, func(i, j int) bool {
+		return regions[i].Boundary.Area() < regions[j].Boundary.Area()
+	})
+
+	return regions, nil
+}
+func (svc *service) AddNodeType(ctx context.Context, nodeType NodeType) (err error) {
+	//the catalogue is shared by all the organizations
+	if err = checkAdmin(ctx); err != nil {
+		return err
+	}
+
+	if err = nodeType.Validate(); err != nil {
+		return err
+	}
+
+	return svc.Types.Add(ctx, nodeType)
+}
+func (svc *service) GetNodeType(ctx context.Context, id int) (nodeType NodeType, err error) {
+	nodeType, err = svc.Types.Get(ctx, id)
+	return
+}
+func (svc *service) ListNodeTypes(ctx context.Context) (types []NodeType, err error) {
+	types, err = svc.Types.List(ctx)
+	return
+}
+func (svc *service) DeleteNodeType(ctx context.Context, id int) (err error) {
+	if err = checkAdmin(ctx); err != nil {
+		return err
+	}
+
+	err = svc.Types.Delete(ctx, id)
+	return
+}
+func (svc *service) UpdateNodeType(ctx context.Context, id int, nodeType NodeType) (nt NodeType, err error) {
+	if err = checkAdmin(ctx); err != nil {
+		return nt, err
+	}
+
+	nodeType.ID = id
+	if err = nodeType.Validate(); err != nil {
+		return nt, err
+	}
+
+	nt, err = svc.Types.Update(ctx, id, nodeType)
+	return
+}
+func (svc *service) CreateClaimCode(ctx context.Context, region string, typ int, ttl time.Duration) (code ClaimCode, err error) {
+	if ttl == 0 {
+		ttl = DefaultClaimCodeTTL
+	}
+
+	if err = svc.checkScope(ctx, region); err != nil {
+		return code, err
+	}
+
+	if _, err = svc.Regions.Get(ctx, region); err != nil {
+		return code, err
+	}
+
+	if _, err = nodeType(ctx, svc.Types, typ); err != nil {
+		return code, err
+	}
+
+	code, err = CreateClaimCode(region, typ, ttl)
+	if err != nil {
+		return code, err
+	}
+
+	err = svc.Claims.AddCode(ctx, code)
+	return
+}
+func (svc *service) ListClaimCodes(ctx context.Context) (codes []ClaimCode, err error) {
+	codes, err = svc.Claims.ListCodes(ctx)
+	return
+}
+func (svc *service) ProvisionNode(ctx context.Context, code string, node Node) (creds Credentials, err error) {
+	code = NormalizeClaimCode(code)
+
+	defer func() {
+		svc.recordClaim(ctx, code, node.Addr, creds.NodeID, err)
+	}()
+
+	claimCode, err := svc.Claims.GetCode(ctx, code)
+	if err != nil {
+		return creds, errors.Wrap(ErrInvalidClaimCode, err)
+	}
+
+	if err = claimCode.Usable(time.Now()); err != nil {
+		return creds, err
+	}
+
+	region, err := svc.Regions.Get(ctx, claimCode.Region)
+	if err != nil {
+		return creds, err
+	}
+
+	n, err := CreateNode(ctx, svc.UUIDProvider, svc.Types, node.Addr, node.Name,
+		claimCode.Region, node.Latd, node.Long, node.Master, claimCode.Type)
+	if err != nil {
+		return creds, err
+	}
+
+	n.Org = region.Org
+	n.Firmware = node.Firmware
+	n.Labels = node.Labels
+
+	if err = svc.checkLocation(ctx, region, n); err != nil {
+		return creds, err
+	}
+
+	if err = svc.checkMaster(ctx, n); err != nil {
+		return creds, err
+	}
+
+	key, err := randomSecret(32)
+	if err != nil {
+		return creds, err
+	}
+
+	//another device may have redeemed the code in the meantime, the node is
+	//only added if this one does
+	if err = svc.Claims.Provision(ctx, code, n, hashSecret(key)); err != nil {
+		return creds, err
+	}
+
+	creds = Credentials{
+		NodeID: n.UUID,
+		Key:    key,
+	}
+
+	svc.record(ctx, PROVISION_NODE, n.UUID, n.Region, n.Org)
+	return creds, nil
+}
+func (svc *service) AuthenticateNode(ctx context.Context, node string, key string) (n Node, err error) {
+	hash, err := svc.Claims.Credentials(ctx, node)
+	if errors.KindOf(err) == errors.NotFound {
+		return n, ErrInvalidNodeKey
+	}
+	if err != nil {
+		return n, err
+	}
+
+	if !secretMatches(hash, key) {
+		return n, ErrInvalidNodeKey
+	}
+
+	return svc.Nodes.Get(ctx, node)
+}
+func (svc *service) ListClaims(ctx context.Context) (claims []Claim, err error) {
+	claims, err = svc.Claims.ListClaims(ctx)
+	return
+}
+func (svc *service) IssueCertificate(ctx context.Context, node string, csr string, ttl time.Duration) (cert Certificate, err error) {
+	if ttl == 0 {
+		ttl = DefaultCertificateTTL
+	}
+
+	n, err := svc.GetNode(ctx, node)
+	if err != nil {
+		return cert, err
+	}
+
+	if err = svc.checkRegionAdmin(ctx, n.Region); err != nil {
+		return cert, err
+	}
+
+	cert, err = svc.CA.Sign([]byte(csr), n.UUID, ttl)
+	if err != nil {
+		return cert, err
+	}
+
+	cert.Org = n.Org
+
+	if err = svc.Certs.Add(ctx, cert); err != nil {
+		return cert, err
+	}
+
+	svc.record(ctx, ISSUE_CERTIFICATE, cert.Serial, n.Region, n.Org)
+	return cert, nil
+}
+func (svc *service) RevokeCertificate(ctx context.Context, serial string) (err error) {
+	cert, err := svc.Certs.Get(ctx, serial)
+	if err != nil {
+		return err
+	}
+
+	//the node of the certificate may have been deleted since it was issued,
+	//its certificates are then revoked by the admins of the root region
+	var region string
+	if node, err := svc.Nodes.Get(ctx, cert.Node); err == nil {
+		region = node.Region
+	}
+
+	if err = svc.checkRegionAdmin(ctx, region); err != nil {
+		return err
+	}
+
+	if err = svc.Certs.Revoke(ctx, serial, time.Now()); err != nil {
+		return err
+	}
+
+	svc.record(ctx, REVOKE_CERTIFICATE, serial, region, cert.Org)
+	return nil
+}
+func (svc *service) ListCertificates(ctx context.Context) (certs []Certificate, err error) {
+	certs, err = svc.Certs.List(ctx)
+	return
+}
+func (svc *service) RevocationList(ctx context.Context) (crl []byte, err error) {
+	revoked, err := svc.Certs.Revoked(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	crl, err = svc.CA.CRL(revoked)
+	return
+}
+func (svc *service) CreateAPIKey(ctx context.Context, owner, name string, role int, region string, ttl time.Duration) (key APIKey, err error) {
+	if name == "" {
+		return key, ErrBadBodyRequest
+	}
+
+	user, err := svc.Users.Get(ctx, owner)
+	if err != nil {
+		return key, err
+	}
+
+	//a key can not be used to create a more powerful one
+	if k, ok := APIKeyFromContext(ctx); ok {
+		if !UserGroup(k.Role).Includes(UserGroup(role)) || k.Region != "" && region == "" {
+			return key, ErrInvalidKeyScope
+		}
+
+		if region != "" && svc.checkScope(ctx, region) != nil {
+			return key, ErrInvalidKeyScope
+		}
+	}
+
+	key, err = CreateAPIKey(svc.UUIDProvider, user, name, role, region, ttl)
+	if err != nil {
+		return key, err
+	}
+
+	err = svc.Keys.Add(ctx, key)
+	return
+}
+func (svc *service) ListAPIKeys(ctx context.Context, owner string) (keys []APIKey, err error) {
+	//only admin keys can see the keys of other users of their organization
+	if k, ok := APIKeyFromContext(ctx); ok && !UserGroup(k.Role).Includes(OrgAdmin) {
+		owner = k.Owner
+	}
+
+	keys, err = svc.Keys.List(ctx, owner)
+	return
+}
+func (svc *service) RevokeAPIKey(ctx context.Context, id string) (err error) {
+	if k, ok := APIKeyFromContext(ctx); ok && !UserGroup(k.Role).Includes(OrgAdmin) {
+		key, err := svc.Keys.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if key.Owner != k.Owner {
+			return ErrPermissionDenied
+		}
+	}
+
+	err = svc.Keys.Revoke(ctx, id, time.Now())
+	return
+}
+func (svc *service) AuthAPIKey(ctx context.Context, plain string) (key APIKey, err error) {
+	id, secret, err := SplitAPIKey(plain)
+	if err != nil {
+		return key, err
+	}
+
+	key, err = svc.Keys.Get(ctx, id)
+	if err != nil {
+		return APIKey{}, errors.Wrap(ErrInvalidAPIKey, err)
+	}
+
+	if !secretMatches(key.Hash, secret) {
+		return APIKey{}, ErrInvalidAPIKey
+	}
+
+	if err = key.Valid(time.Now()); err != nil {
+		return APIKey{}, err
+	}
+
+	//the owner may have been demoted or moved since the key was created,
+	//the key never has more rights than the owner has now
+	owner, err := svc.Users.Get(ctx, key.Owner)
+	if err != nil {
+		return APIKey{}, errors.Wrap(ErrInvalidAPIKey, err)
+	}
+
+	if err = CheckKeyScope(owner, key.Role, key.Region); err != nil {
+		return APIKey{}, errors.Wrap(ErrInvalidAPIKey, err)
+	}
+
+	key.Hash = ""
+	return key, nil
+}
+func (svc *service) AddOrganization(ctx context.Context, org Organization) (o Organization, err error) {
+	if err = checkAdmin(ctx); err != nil {
+		return o, err
+	}
+
+	o, err = CreateOrganization(svc.UUIDProvider, org.Name, org.Desc)
+	if err != nil {
+		return o, err
+	}
+
+	err = svc.Orgs.Add(ctx, o)
+	return
+}
+func (svc *service) GetOrganization(ctx context.Context, id string) (org Organization, err error) {
+	org, err = svc.Orgs.Get(ctx, id)
+	return
+}
+func (svc *service) ListOrganizations(ctx context.Context) (orgs []Organization, err error) {
+	orgs, err = svc.Orgs.List(ctx)
+	return
+}
+func (svc *service) DeleteOrganization(ctx context.Context, id string) (err error) {
+	if err = checkAdmin(ctx); err != nil {
+		return err
+	}
+
+	err = svc.Orgs.Delete(ctx, id)
+	return
+}
+func (svc *service) AddFirmware(ctx context.Context, fw Firmware) (f Firmware, err error) {
+	if err = checkRole(ctx, OrgAdmin); err != nil {
+		return f, err
+	}
+
+	if err = fw.Validate(); err != nil {
+		return f, err
+	}
+
+	if _, err = nodeType(ctx, svc.Types, fw.Type); err != nil {
+		return f, err
+	}
+
+	fw.Org, err = svc.orgOf(ctx, fw.Org)
+	if err != nil {
+		return f, err
+	}
+
+	releases, err := svc.Firmware.List(ctx)
+	if err != nil {
+		return f, err
+	}
+
+	for _, r := range releases {
+		if r.Type == fw.Type && r.Version == fw.Version && r.Org == fw.Org {
+			return f, ErrFirmwareExists
+		}
+	}
+
+	fw.ID, err = svc.UUIDProvider.ID()
+	if err != nil {
+		return f, err
+	}
+	fw.Created = time.Now().Format(time.RFC3339)
+
+	if err = svc.Firmware.Add(ctx, fw); err != nil {
+		return f, err
+	}
+
+	svc.record(ctx, CREATE_FIRMWARE, fw.ID, "", fw.Org)
+
+	fw.Blob = nil
+	return fw, nil
+}
+func (svc *service) GetFirmware(ctx context.Context, id string) (fw Firmware, err error) {
+	fw, err = svc.Firmware.Get(ctx, id)
+	return
+}
+func (svc *service) ListFirmware(ctx context.Context) (releases []Firmware, err error) {
+	releases, err = svc.Firmware.List(ctx)
+	return
+}
+func (svc *service) DeleteFirmware(ctx context.Context, id string) (err error) {
+	if err = checkRole(ctx, OrgAdmin); err != nil {
+		return err
+	}
+
+	fw, err := svc.Firmware.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	campaigns, err := svc.Campaigns.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range campaigns {
+		if c.Firmware == id {
+			return ErrFirmwareInUse
+		}
+	}
+
+	if err = svc.Firmware.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	svc.record(ctx, DELETE_FIRMWARE, fw.ID, "", fw.Org)
+	return nil
+}
+func (svc *service) CreateCampaign(ctx context.Context, campaign Campaign) (c Campaign, err error) {
+	//region admins can roll firmware out to the regions they administer
+	if err = svc.checkRegionAdmin(ctx, campaign.Target.Region); err != nil {
+		return c, err
+	}
+
+	if err = campaign.Validate(); err != nil {
+		return c, err
+	}
+
+	fw, err := svc.Firmware.Get(ctx, campaign.Firmware)
+	if err != nil {
+		return c, err
+	}
+
+	if err = svc.checkRegion(ctx, campaign.Target.Region, fw.Org); err != nil {
+		return c, err
+	}
+
+	if campaign.Target.Region == "" {
+		campaign.Target.Subregions = false
+	}
+
+	campaign.ID, err = svc.UUIDProvider.ID()
+	if err != nil {
+		return c, err
+	}
+	campaign.Created = time.Now().Format(time.RFC3339)
+	campaign.Org = fw.Org
+	campaign.Status = nil
+
+	if err = svc.Campaigns.Add(ctx, campaign); err != nil {
+		return c, err
+	}
+
+	if err = svc.enroll(ctx, campaign, fw); err != nil {
+		return c, err
+	}
+
+	svc.record(ctx, CREATE_CAMPAIGN, campaign.ID, campaign.Target.Region, campaign.Org)
+	return svc.GetCampaign(ctx, campaign.ID)
+}
+func (svc *service) GetCampaign(ctx context.Context, id string) (c Campaign, err error) {
+	c, err = svc.Campaigns.Get(ctx, id)
+	if err != nil {
+		return c, err
+	}
+
+	if err = svc.checkScope(ctx, c.Target.Region); err != nil {
+		return Campaign{}, err
+	}
+
+	return svc.withStatus(ctx, c)
+}
+func (svc *service) ListCampaigns(ctx context.Context) (campaigns []Campaign, err error) {
+	all, err := svc.Campaigns.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range all {
+		//region scoped keys only see the campaigns of their regions
+		if svc.checkScope(ctx, c.Target.Region) != nil {
+			continue
+		}
+
+		c, err = svc.withStatus(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+
+		campaigns = append(campaigns, c)
+	}
+
+	return campaigns, nil
+}
+func (svc *service) AdvanceCampaign(ctx context.Context, id string, percent int) (c Campaign, err error) {
+	c, err = svc.Campaigns.Get(ctx, id)
+	if err != nil {
+		return c, err
+	}
+
+	if err = svc.checkRegionAdmin(ctx, c.Target.Region); err != nil {
+		return Campaign{}, err
+	}
+
+	if percent <= 0 || percent > 100 {
+		return Campaign{}, ErrInvalidCampaign
+	}
+
+	if percent < c.Rollout {
+		return Campaign{}, ErrRolloutShrink
+	}
+
+	fw, err := svc.Firmware.Get(ctx, c.Firmware)
+	if err != nil {
+		return Campaign{}, err
+	}
+
+	if err = svc.Campaigns.SetRollout(ctx, id, percent); err != nil {
+		return Campaign{}, err
+	}
+	c.Rollout = percent
+
+	if err = svc.enroll(ctx, c, fw); err != nil {
+		return Campaign{}, err
+	}
+
+	svc.record(ctx, UPDATE_CAMPAIGN, c.ID, c.Target.Region, c.Org)
+	return svc.GetCampaign(ctx, id)
+}
+func (svc *service) ListRollouts(ctx context.Context, campaign string) (rollouts []Rollout, err error) {
+	if _, err = svc.GetCampaign(ctx, campaign); err != nil {
+		return nil, err
+	}
+
+	rollouts, err = svc.Campaigns.Rollouts(ctx, campaign)
+	return
+}
+func (svc *service) ReportRollout(ctx context.Context, campaign, node string, status RolloutStatus, msg string) (r Rollout, err error) {
+	if _, err = ParseRolloutStatus(string(status)); err != nil {
+		return r, err
+	}
+
+	n, err := svc.GetNode(ctx, node)
+	if err != nil {
+		return r, err
+	}
+
+	c, err := svc.Campaigns.Get(ctx, campaign)
+	if err != nil {
+		return r, err
+	}
+
+	rollouts, err := svc.Campaigns.Rollouts(ctx, campaign)
+	if err != nil {
+		return r, err
+	}
+
+	var current *Rollout
+	for i := range rollouts {
+		if rollouts[i].Node == n.UUID {
+			current = &rollouts[i]
+			break
+		}
+	}
+
+	if current == nil {
+		return r, ErrNotEnrolled
+	}
+
+	if current.Status == RolloutApplied {
+		return r, ErrRolloutApplied
+	}
+
+	r = Rollout{
+		Campaign: c.ID,
+		Node:     n.UUID,
+		Status:   status,
+		Updated:  time.Now().Format(time.RFC3339),
+	}
+
+	//only failures keep the message of the node
+	if status == RolloutFailed {
+		r.Err = msg
+	}
+
+	if err = svc.Campaigns.UpdateRollout(ctx, r); err != nil {
+		return Rollout{}, err
+	}
+
+	if status == RolloutApplied {
+		fw, err := svc.Firmware.Get(ctx, c.Firmware)
+		if err != nil {
+			return Rollout{}, err
+		}
+
+		n.Firmware = fw.Version
+		if _, err = svc.Nodes.Update(ctx, n.UUID, n); err != nil {
+			return Rollout{}, err
+		}
+	}
+
+	svc.record(ctx, UPDATE_ROLLOUT, n.UUID, n.Region, n.Org)
+	return r, nil
+}
+func (svc *service) AddSchema(ctx context.Context, schema Schema) (s Schema, err error) {
+	if err = schema.Validate(); err != nil {
+		return s, err
+	}
+
+	var region string
+	if schema.Node != "" {
+		n, err := svc.GetNode(ctx, schema.Node)
+		if err != nil {
+			return s, err
+		}
+
+		if err = svc.checkRegionAdmin(ctx, n.Region); err != nil {
+			return s, err
+		}
+
+		schema.Node, schema.Org, region = n.UUID, n.Org, n.Region
+	} else {
+		if err = checkRole(ctx, OrgAdmin); err != nil {
+			return s, err
+		}
+
+		if _, err = nodeType(ctx, svc.Types, schema.Type); err != nil {
+			return s, err
+		}
+
+		//schemas added by admins without an organization are shared by all
+		schema.Org, err = svc.orgOf(ctx, schema.Org)
+		if err != nil {
+			return s, err
+		}
+	}
+
+	schemas, err := svc.Schemas.List(ctx)
+	if err != nil {
+		return s, err
+	}
+
+	schema.Version = 1
+	for _, other := range schemas {
+		if other.Describes(schema.Kind, schema.Type, schema.Node) && other.Org == schema.Org && other.Version >= schema.Version {
+			schema.Version = other.Version + 1
+		}
+	}
+
+	schema.ID, err = svc.UUIDProvider.ID()
+	if err != nil {
+		return s, err
+	}
+	schema.Created = time.Now().Format(time.RFC3339)
+
+	if err = svc.Schemas.Add(ctx, schema); err != nil {
+		return s, err
+	}
+
+	svc.record(ctx, CREATE_SCHEMA, schema.ID, region, schema.Org)
+	return schema, nil
+}
+func (svc *service) GetSchema(ctx context.Context, id string) (s Schema, err error) {
+	s, err = svc.Schemas.Get(ctx, id)
+	return
+}
+func (svc *service) ListSchemas(ctx context.Context, filter SchemaFilter) (schemas []Schema, err error) {
+	if filter.Kind != "" {
+		if _, err = ParseSchemaKind(string(filter.Kind)); err != nil {
+			return nil, err
+		}
+	}
+
+	all, err := svc.Schemas.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range all {
+		if filter.Matches(s) {
+			schemas = append(schemas, s)
+		}
+	}
+
+	return schemas, nil
+}
+func (svc *service) NodeSchema(ctx context.Context, node string, kind SchemaKind, version int) (s Schema, err error) {
+	kind, err = ParseSchemaKind(string(kind))
+	if err != nil {
+		return s, err
+	}
+
+	n, err := svc.GetNode(ctx, node)
+	if err != nil {
+		return s, err
+	}
+
+	schemas, err := svc.Schemas.List(ctx)
+	if err != nil {
+		return s, err
+	}
+
+	//the schemas of the node replace the ones of its type, those of the
+	//organization of the node the shared ones
+	subjects := []func(Schema) bool{
+		func(s Schema) bool { return s.Describes(kind, 0, n.UUID) },
+		func(s Schema) bool { return s.Describes(kind, n.Type, "") && s.Org == n.Org },
+		func(s Schema) bool { return s.Describes(kind, n.Type, "") && s.Org == "" },
+	}
+
+	for _, describes := range subjects {
+		var found bool
+		for _, other := range schemas {
+			if !describes(other) {
+				continue
+			}
+
+			if version == 0 && other.Version > s.Version || other.Version == version {
+				s = other
+			}
+			found = true
+		}
+
+		if found {
+			break
+		}
+	}
+
+	if s.ID == "" {
+		return s, ErrSchemaNotFound
+	}
+
+	return s, nil
+}
+func (svc *service) ValidatePayload(ctx context.Context, node string, kind SchemaKind, version int, payload json.RawMessage) (v Validation, err error) {
+	if len(payload) == 0 {
+		return v, ErrBadBodyRequest
+	}
+
+	s, err := svc.NodeSchema(ctx, node, kind, version)
+	if err != nil {
+		return v, err
+	}
+
+	return s.ValidatePayload(payload)
+}
+func (svc *service) SetQuota(ctx context.Context, quota Quota) (q Quota, err error) {
+	if err = quota.Validate(); err != nil {
+		return q, err
+	}
+
+	var region string
+	switch quota.Scope {
+	case QuotaRegion:
+		//region admins can not raise their own quota
+		if err = checkRole(ctx, OrgAdmin); err != nil {
+			return q, err
+		}
+
+		r, err := svc.Regions.Get(ctx, quota.ID)
+		if err != nil {
+			return q, err
+		}
+		quota.Org, region = r.Org, r.ID
+
+	case QuotaOrg:
+		if err = checkAdmin(ctx); err != nil {
+			return q, err
+		}
+
+		if _, err = svc.Orgs.Get(ctx, quota.ID); err != nil {
+			return q, err
+		}
+		quota.Org = quota.ID
+	}
+
+	for typ := range quota.Nodes {
+		if _, err = nodeType(ctx, svc.Types, typ); err != nil {
+			return q, err
+		}
+	}
+
+	if err = svc.Quotas.Set(ctx, quota); err != nil {
+		return q, err
+	}
+
+	svc.record(ctx, UPDATE_QUOTA, quota.ID, region, quota.Org)
+	return quota, nil
+}
+func (svc *service) ListQuotas(ctx context.Context) (quotas []Quota, err error) {
+	quotas, err = svc.Quotas.List(ctx)
+	return
+}
+func (svc *service) Usage(ctx context.Context, scope QuotaScope, id string) (u Usage, err error) {
+	switch scope {
+	case QuotaRegion:
+		if _, err = svc.Regions.Get(ctx, id); err != nil {
+			return u, err
+		}
+
+		if err = svc.checkScope(ctx, id); err != nil {
+			return u, err
+		}
+
+	case QuotaOrg:
+		if _, err = svc.Orgs.Get(ctx, id); err != nil {
+			return u, err
+		}
+
+	default:
+		return u, ErrInvalidQuota
+	}
+
+	return svc.Quotas.Usage(ctx, scope, id)
+}
+func (svc *service) Inventory(ctx context.Context, filter RegionFilter, by []Dimension) (inventory []InventoryCount, err error) {
+	if len(by) == 0 {
+		by = Dimensions
+	}
+
+	nodes, err := svc.ListNodes(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	certs, err := svc.Certs.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	types, err := svc.Types.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[int]string, len(types))
+	for _, t := range types {
+		names[t.ID] = t.Name
+	}
+
+	inventory = Inventory(nodes, NodeStates(certs, time.Now()), by)
+	for i := range inventory {
+		inventory[i].TypeName = names[inventory[i].Type]
+	}
+
+	return inventory, nil
+}
+func (svc *service) Growth(ctx context.Context, filter RegionFilter, period GrowthPeriod, since time.Time) (growth []Growth, err error) {
+	period, err = ParseGrowthPeriod(string(period))
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, err := svc.ListNodes(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return NodeGrowth(nodes, period, since, time.Now()), nil
+}
+func (svc *service) OrphanedNodes(ctx context.Context, filter RegionFilter) (orphans []Node, err error) {
+	nodes, err := svc.ListNodes(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	//masters can be in regions the filter leaves out
+	all, err := svc.Nodes.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return Orphans(nodes, all), nil
+}
+func (svc *service) Watch(ctx context.Context, filter EventFilter, cursor time.Duration) (<-chan Event, error) {
+	filter.Org = OrgFromContext(ctx)
+
+	//region scoped keys only see the changes of their region and of its
+	//sub-regions
+	if key, ok := APIKeyFromContext(ctx); ok && key.Region != "" {
+		tree, err := svc.regionTree(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		subtree := tree.Subtree(key.Region)
+		allowed := regionIDs(subtree)
+		for _, region := range filter.Regions {
+			if !allowed[region] {
+				return nil, ErrPermissionDenied
+			}
+		}
+
+		if len(filter.Regions) == 0 {
+			filter.Regions = []string{key.Region}
+			for _, r := range subtree[1:] {
+				filter.Regions = append(filter.Regions, r.ID)
+			}
+		}
+	}
+
+	return svc.Events.Subscribe(ctx, filter, cursor)
+}
+
+// withStatus counts the nodes enrolled in the campaign c by rollout status
+func (svc *service) withStatus(ctx context.Context, c Campaign) (Campaign, error) {
+	rollouts, err := svc.Campaigns.Rollouts(ctx, c.ID)
+	if err != nil {
+		return Campaign{}, err
+	}
+
+	c.Status = make(map[RolloutStatus]int, len(RolloutStatuses))
+	for _, s := range RolloutStatuses {
+		c.Status[s] = 0
+	}
+
+	for _, r := range rollouts {
+		c.Status[r.Status]++
+	}
+
+	return c, nil
+}
+
+// enroll enrolls nodes targeted by the campaign c until its rollout share of
+// them is. Enrolled nodes stay targeted after they apply the firmware fw,
+// nodes that already run its version are never enrolled.
+func (svc *service) enroll(ctx context.Context, c Campaign, fw Firmware) error {
+	nodes, err := svc.Nodes.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	selected, err := svc.selectRegions(ctx, RegionFilter{Region: c.Target.Region, Subregions: c.Target.Subregions}, false)
+	if err != nil {
+		return err
+	}
+
+	rollouts, err := svc.Campaigns.Rollouts(ctx, c.ID)
+	if err != nil {
+		return err
+	}
+
+	enrolled := make(map[string]bool, len(rollouts))
+	for _, r := range rollouts {
+		enrolled[r.Node] = true
+	}
+
+	targeted := 0
+	var candidates []string
+	for _, n := range nodes {
+		if n.Org != c.Org || n.Type != fw.Type || !n.Labels.Matches(c.Target.Labels) {
+			continue
+		}
+
+		if selected != nil && !selected[n.Region] {
+			continue
+		}
+
+		switch {
+		case enrolled[n.UUID]:
+			targeted++
+		case n.Firmware != fw.Version:
+			targeted++
+			candidates = append(candidates, n.UUID)
+		}
+	}
+
+	want := Enrolled(targeted, c.Rollout) - (targeted - len(candidates))
+	if want <= 0 {
+		return nil
+	}
+
+	//the same nodes go first whenever the campaign is advanced
+	sort.Strings(candidates)
+	return svc.Campaigns.Enroll(ctx, c.ID, candidates[:want], time.Now())
+}
+
+// orgOf returns the organization a new entity belongs to, requests scoped to
+// an organization can only create entities in their own organization.
+func (svc *service) orgOf(ctx context.Context, org string) (string, error) {
+	if tenant := OrgFromContext(ctx); tenant != "" {
+		if org != "" && org != tenant {
+			return "", ErrPermissionDenied
+		}
+		return tenant, nil
+	}
+
+	if org == "" {
+		return "", nil
+	}
+
+	if _, err := svc.Orgs.Get(ctx, org); err != nil {
+		return "", err
+	}
+
+	return org, nil
+}
+
+// regionTree returns the tree of the regions the request of ctx can see
+func (svc *service) regionTree(ctx context.Context) (RegionTree, error) {
+	regions, err := svc.Regions.List(ctx)
+	if err != nil {
+		return RegionTree{}, err
+	}
+
+	return NewRegionTree(regions), nil
+}
+
+// checkScope fails when ctx carries an api key that is not allowed to access
+// entities of the region, keys of a region access its sub-regions too.
+// Requests without a key are not restricted.
+func (svc *service) checkScope(ctx context.Context, region string) error {
+	key, ok := APIKeyFromContext(ctx)
+	if !ok || key.Allows(region) {
+		return nil
+	}
+
+	tree, err := svc.regionTree(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !tree.Within(region, key.Region) {
+		return ErrPermissionDenied
+	}
+
+	return nil
+}
+
+// checkRegionAdmin fails unless the request of ctx administers the region,
+// organization admins administer every region and region admins their region
+// and its descendants. Only organization admins administer the root, "".
+func (svc *service) checkRegionAdmin(ctx context.Context, region string) error {
+	if checkRole(ctx, OrgAdmin) == nil {
+		return nil
+	}
+
+	if err := checkRole(ctx, RegionAdmin); err != nil {
+		return err
+	}
+
+	//checkRole only fails for requests with a key
+	key, _ := APIKeyFromContext(ctx)
+	if key.Region == "" || region == "" {
+		return ErrPermissionDenied
+	}
+
+	return svc.checkScope(ctx, region)
+}
+
+// selectRegions returns the ids of the regions filter selects, narrowed to
+// the scope of the api key of ctx when scoped is set. nil selects every
+// region.
+func (svc *service) selectRegions(ctx context.Context, filter RegionFilter, scoped bool) (map[string]bool, error) {
+	key, ok := APIKeyFromContext(ctx)
+	scoped = scoped && ok && key.Region != ""
+
+	if !scoped && filter.Region == "" {
+		return nil, nil
+	}
+
+	if filter.Region != "" {
+		if _, err := svc.Regions.Get(ctx, filter.Region); err != nil {
+			return nil, err
+		}
+	}
+
+	tree, err := svc.regionTree(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var selected map[string]bool
+	if filter.Region != "" {
+		selected = map[string]bool{filter.Region: true}
+		if filter.Subregions {
+			selected = regionIDs(tree.Subtree(filter.Region))
+		}
+	}
+
+	if !scoped {
+		return selected, nil
+	}
+
+	allowed := regionIDs(tree.Subtree(key.Region))
+	if selected == nil {
+		return allowed, nil
+	}
+
+	for id := range selected {
+		if !allowed[id] {
+			delete(selected, id)
+		}
+	}
+
+	return selected, nil
+}
+
+// checkRegion fails when the region, if given, is not one of the org regions.
+func (svc *service) checkRegion(ctx context.Context, region, org string) error {
+	if region == "" {
+		return nil
+	}
+
+	r, err := svc.region(ctx, region)
+	if err != nil {
+		return err
+	}
+
+	if r.Org != org {
+		return ErrRegionOutsideOrg
+	}
+
+	return nil
+}
+
+// region returns the region id, ErrUnknownRegion when it does not exist
+func (svc *service) region(ctx context.Context, id string) (Region, error) {
+	r, err := svc.Regions.Get(ctx, id)
+	if errors.KindOf(err) == errors.NotFound {
+		return r, ErrUnknownRegion
+	}
+
+	return r, err
+}
+
+// checkMaster validates the master of node, nodes without a master are
+// not checked. Only the master and the masters above it are looked up.
+func (svc *service) checkMaster(ctx context.Context, node Node) error {
+	if node.Master == "" {
+		return nil
+	}
+
+	//the chain ends at the top master, at a missing one or back at node
+	var chain []Node
+	seen := map[string]bool{}
+	for id := node.Master; id != "" && !seen[id]; {
+		master, err := svc.Nodes.Get(ctx, id)
+		if errors.KindOf(err) == errors.NotFound {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		chain = append(chain, master)
+		seen[id] = true
+		if id == node.UUID {
+			break
+		}
+		id = master.Master
+	}
+
+	return CheckMaster(node, chain)
+}
+
+// checkLocation applies the geofence policy to a node of region, nodes
+// without coordinates and regions without a boundary are not checked.
+func (svc *service) checkLocation(ctx context.Context, region Region, node Node) error {
+	if svc.Geofence == GeofenceOff || region.Boundary == nil {
+		return nil
+	}
+
+	if node.Latd == "" && node.Long == "" {
+		return nil
+	}
+
+	lat, long, err := ParseLocation(node.Latd, node.Long)
+	if err == nil && region.Boundary.Contains(lat, long) {
+		return nil
+	}
+
+	if err == nil {
+		err = ErrNodeOutsideRegion
+	}
+
+	if svc.Geofence == GeofenceReject {
+		return err
+	}
+
+	svc.Logger.WithContext(ctx).Warn("node located outside the boundary of its region",
+		"node_id", node.UUID, "region", region.ID, "latitude", node.Latd, "longitude", node.Long, "err", err.Error())
+	return nil
+}
+
+// recordClaim saves the audit record of a provisioning attempt, failing to
+// record it does not fail the provisioning.
+func (svc *service) recordClaim(ctx context.Context, code, addr, node string, claimErr error) {
+	id, err := svc.UUIDProvider.ID()
+	if err != nil {
+		svc.Logger.WithContext(ctx).Error("could not record claim", "addr", addr, "err", err.Error())
+		return
+	}
+
+	claim := Claim{
+		ID:      id,
+		Code:    code,
+		Addr:    addr,
+		Node:    node,
+		Result:  ClaimAccepted,
+		Created: time.Now().Format(time.RFC3339),
+	}
+
+	if claimErr != nil {
+		claim.Result = ClaimRejected
+		claim.Err = claimErr.Error()
+	}
+
+	if err = svc.Claims.SaveClaim(ctx, claim); err != nil {
+		svc.Logger.WithContext(ctx).Error("could not record claim", "addr", addr, "err", err.Error())
+	}
+}
+
+// record saves the event of a change made by the request of ctx to the
+// change feed, failing to record it does not fail the change.
+func (svc *service) record(ctx context.Context, name EventName, subject, region, org string) {
+	id, err := svc.UUIDProvider.ID()
+	if err != nil {
+		svc.Logger.WithContext(ctx).Error("could not record event", "event", name.String(), "subject", subject, "err", err.Error())
+		return
+	}
+
+	event := Event{
+		UUID:      id,
+		Name:      name.String(),
+		Region:    region,
+		Result:    "ok",
+		Timestamp: time.Duration(time.Now().UnixNano()),
+		Subject:   subject,
+		Org:       org,
+	}
+
+	if key, ok := APIKeyFromContext(ctx); ok {
+		event.Actor = key.Owner
+	}
+
+	if err = svc.Events.Save(ctx, event); err != nil {
+		svc.Logger.WithContext(ctx).Error("could not record event", "event", name.String(), "subject", subject, "err", err.Error())
+	}
+}
 
 // NewService returns a naive, stateless implementation of Service.
 func NewService(users UserRepository, nodes NodeRepository,
-	regions RegionRepository, hasher Hasher,
-	logger logger.Logger, provider UUIDProvider) Service {
+	regions RegionRepository, types NodeTypeRepository, claims ClaimRepository,
+	certs CertificateRepository, ca CertificateAuthority, keys APIKeyRepository, orgs OrganizationRepository,
+	firmware FirmwareRepository, campaigns CampaignRepository, schemas SchemaRepository, quotas QuotaRepository, events EventFeed, hasher Hasher, logger logger.Logger, provider UUIDProvider, geofence GeofencePolicy) Service {
 	return &service{
 		Users:        users,
 		Nodes:        nodes,
 		Regions:      regions,
+		Types:        types,
+		Claims:       claims,
+		Certs:        certs,
+		CA:           ca,
+		Keys:         keys,
+		Orgs:         orgs,
+		Firmware:     firmware,
+		Campaigns:    campaigns,
+		Schemas:      schemas,
+		Quotas:       quotas,
+		Events:       events,
 		Hasher:       hasher,
 		Logger:       logger,
 		UUIDProvider: provider,
+		Geofence:     geofence,
 	}
 }
 
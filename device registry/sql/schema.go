@@ -0,0 +1,391 @@
+package sql
+
+// Schema creates the tables of the registry and their seed rows. It is
+// written for postgres, other backends rewrite it with their dialect, and
+// it is applied every time the database is opened so statements must be
+// idempotent.
+const Schema = `
+create table if not exists organizations
+(
+    id          varchar(100) not null primary key,
+    name        varchar(100) not null,
+    description text,
+    created     timestamptz  not null
+);
+
+create table if not exists regions
+(
+    id          varchar(50) not null primary key,
+    name        varchar(30),
+    description text        not null,
+    org         varchar(100),
+    boundary    text,
+    parent      varchar(50),
+    foreign key (org) references organizations (id),
+    foreign key (parent) references regions (id)
+);
+
+INSERT INTO regions (id, name, description)
+VALUES ('AA004', 'Bagamoyo', 'Bagamoyo Area, iGrid Northern Zone Control Center')
+ON CONFLICT DO NOTHING;
+INSERT INTO regions (id, name, description)
+VALUES ('AA002', 'Kibamba', 'Kibamba Area, iGrid Eastern Zone Control Center')
+ON CONFLICT DO NOTHING;
+INSERT INTO regions (id, name, description)
+VALUES ('AA001', 'CoICT', 'CoICT Campus, Sayansi Kijitonyama Control Center')
+ON CONFLICT DO NOTHING;
+INSERT INTO regions (id, name, description)
+VALUES ('AA003', 'Tegeta', 'Wazo Hill Area, iGrid Western  Zone Control Center')
+ON CONFLICT DO NOTHING;
+
+create table if not exists users
+(
+    id       varchar(100) not null primary key,
+    name     varchar(100),
+    email    varchar(100) not null,
+    password varchar(100),
+    ugroup   integer,
+    region   varchar(50),
+    created  date,
+    org      varchar(100),
+    foreign key (region) references regions(id),
+    foreign key (org) references organizations (id)
+);
+
+INSERT INTO users (id, name, email, password, ugroup, region, created)
+VALUES ('ours9489ho08', 'Carma Cumo', 'ccumo0@springer.com', 'u0WKt2JRaB', 1, 'AA004', '2020-02-07')
+ON CONFLICT DO NOTHING;
+INSERT INTO users (id, name, email, password, ugroup, region, created)
+VALUES ('glut8904no20', 'Horatio Eyckelberg', 'heyckelberg1@marriott.com', 'ijBAfoa', 1, 'AA003', '2020-09-04')
+ON CONFLICT DO NOTHING;
+INSERT INTO users (id, name, email, password, ugroup, region, created)
+VALUES ('ball8000us98', 'Syd Briant', 'sbriant2@patch.com', 'qHEcPqM8atbK', 1, 'AA001', '2020-03-18')
+ON CONFLICT DO NOTHING;
+INSERT INTO users (id, name, email, password, ugroup, region, created)
+VALUES ('feet3749am67', 'Sumner Eustace', 'seustace3@edublogs.org', 'RkAlCTBl4sG', 1, 'AA002', '2020-03-12')
+ON CONFLICT DO NOTHING;
+INSERT INTO users (id, name, email, password, ugroup, region, created)
+VALUES ('tail7534am70', 'Rolph Hissie', 'rhissie6@bloglines.com', 'w4zYGXZLa', 3, 'AA004', '2020-04-13')
+ON CONFLICT DO NOTHING;
+INSERT INTO users (id, name, email, password, ugroup, region, created)
+VALUES ('itch6823am90', 'Jacqueline Jonin', 'jjonin7@flavors.me', 'pyZhCMZXAJMR', 3, 'AA003', '2020-05-10')
+ON CONFLICT DO NOTHING;
+INSERT INTO users (id, name, email, password, ugroup, region, created)
+VALUES ('pant4217so33', 'Reeva Metson', 'rmetson9@php.net', 'UWXvAss', 3, 'AA003', '2020-02-24')
+ON CONFLICT DO NOTHING;
+INSERT INTO users (id, name, email, password, ugroup, region, created)
+VALUES ('638f1cf1-e7cf-4f1a-8064-bbc1053cbf49', 'Pius Alfred', 'me.pius1102@gmail.com',
+        '$2a$10$dxLvrXCrVD9kpVZccrAXNenTeXI7N.KVrk1yWaPzVmNvwfPt4d4/6', 1, 'AA001', '2020-12-29')
+ON CONFLICT DO NOTHING;
+
+create table if not exists node_types
+(
+    id           integer     not null primary key,
+    name         varchar(50) not null unique,
+    description  text,
+    capabilities text[]      not null default '{}'
+);
+
+INSERT INTO node_types (id, name, description, capabilities)
+VALUES (1, 'sensor', 'publishes measurements to its own topic', '{publish}')
+ON CONFLICT DO NOTHING;
+INSERT INTO node_types (id, name, description, capabilities)
+VALUES (2, 'actuator', 'subscribes to its own topic for commands', '{subscribe}')
+ON CONFLICT DO NOTHING;
+INSERT INTO node_types (id, name, description, capabilities)
+VALUES (3, 'controller', 'publishes and subscribes to all topics of its region', '{publish,subscribe,command}')
+ON CONFLICT DO NOTHING;
+
+create table if not exists nodes
+(
+    id       VARCHAR(600) NOT NULL PRIMARY KEY,
+    addr     VARCHAR(60)  NOT NULL UNIQUE,
+    name     VARCHAR(50)  NOT NULL,
+    type     INT          NOT NULL,
+    region   VARCHAR(50)  NOT NULL,
+    lat      VARCHAR(50)  NOT NULL,
+    long     VARCHAR(50)  NOT NULL,
+    created  VARCHAR(60)  NOT NULL,
+    master   VARCHAR(60),
+    org      VARCHAR(100),
+    firmware VARCHAR(50),
+    labels   TEXT,
+    FOREIGN KEY (region) REFERENCES regions (id),
+    FOREIGN KEY (type) REFERENCES node_types (id),
+    FOREIGN KEY (org) REFERENCES organizations (id),
+    FOREIGN KEY (master) REFERENCES nodes (id)
+);
+
+INSERT INTO nodes (id, addr, name, type, region, lat, long, created, master)
+values ('36fe015e-758a-4599-9818-26fe1b1b0a13', '3A-3E-71-A3-96-F4', 'igrid monitor', 3, 'AA002', 45.6103043, 79.467517,
+        '2020-01-17T11:24:20Z', NULL)
+ON CONFLICT DO NOTHING;
+INSERT INTO nodes (id, addr, name, type, region, lat, long, created, master)
+values ('293d0a97-c0a2-400d-bd2b-9668bc1d3201', 'D3-6A-C0-84-09-75', 'oil sensor', 3, 'AA002', 48.6277459, 2.4381665,
+        '2020-06-30T15:08:20Z', NULL)
+ON CONFLICT DO NOTHING;
+INSERT INTO nodes (id, addr, name, type, region, lat, long, created, master)
+values ('7acc2d43-c5fb-4de6-8553-e99a8ddd1a87', 'F8-6D-62-42-8E-23', 'oil sensor', 3, 'AA003', 29.129743, 105.877112,
+        '2019-01-02T15:31:25Z', NULL)
+ON CONFLICT DO NOTHING;
+INSERT INTO nodes (id, addr, name, type, region, lat, long, created, master)
+values ('f3f204c7-962b-440f-bd7b-5ed7d83eb874', '6F-5E-42-8B-36-B9', 'igrid monitor', 3, 'AA001', 43.2916776,
+        -0.3696612, '2020-04-09T07:11:47Z', NULL)
+ON CONFLICT DO NOTHING;
+INSERT INTO nodes (id, addr, name, type, region, lat, long, created, master)
+values ('9ad69e46-4447-487c-809d-baba853a1fe5', 'BD-2E-AB-74-15-10', 'igrid monitor', 3, 'AA004', 41.2033027,
+        22.5760759, '2020-07-20T22:06:25Z', NULL)
+ON CONFLICT DO NOTHING;
+INSERT INTO nodes (id, addr, name, type, region, lat, long, created, master)
+values ('99f1773b-fb21-4ef8-9165-863e94301201', '89-19-60-34-8B-C3', 'igrid monitor', 1, 'AA004', 2.7239834,
+        101.9476452, '2019-09-24T16:45:27Z', '9ad69e46-4447-487c-809d-baba853a1fe5')
+ON CONFLICT DO NOTHING;
+INSERT INTO nodes (id, addr, name, type, region, lat, long, created, master)
+values ('833981d1-1040-4c2d-ad9f-f44e26c8d17c', '10-13-2B-C1-BD-54', 'temp sensor', 1, 'AA001', 48.015883, 37.80285,
+        '2019-09-18T02:01:25Z', 'f3f204c7-962b-440f-bd7b-5ed7d83eb874')
+ON CONFLICT DO NOTHING;
+INSERT INTO nodes (id, addr, name, type, region, lat, long, created, master)
+values ('3d33d534-568f-4260-be9f-604d78f30d08', 'FB-7C-02-35-41-56', 'temp sensor', 2, 'AA001', 44.284636, 129.459707,
+        '2019-10-19T12:05:40Z', 'f3f204c7-962b-440f-bd7b-5ed7d83eb874')
+ON CONFLICT DO NOTHING;
+INSERT INTO nodes (id, addr, name, type, region, lat, long, created, master)
+values ('9c0b50a8-50b0-4987-8cd0-56b91efa52d9', 'D0-85-C8-7C-4D-49', 'igrid monitor', 2, 'AA003', 37.5968793,
+        -1.0346774, '2020-09-28T13:20:39Z', '7acc2d43-c5fb-4de6-8553-e99a8ddd1a87')
+ON CONFLICT DO NOTHING;
+INSERT INTO nodes (id, addr, name, type, region, lat, long, created, master)
+values ('3b392372-6c7d-4eff-9d1f-4a5d21578b94', '28-CE-87-EE-09-FB', 'temp sensor', 1, 'AA002', -34.5656691,
+        -58.7176959, '2020-11-28T14:03:01Z', '36fe015e-758a-4599-9818-26fe1b1b0a13')
+ON CONFLICT DO NOTHING;
+INSERT INTO nodes (id, addr, name, type, region, lat, long, created, master)
+values ('7e1416ab-bf5d-447f-b300-5fd38e6324c6', 'E2-62-F8-58-40-A9', 'mqtt server', 1, 'AA003', 63.3063621, 18.7067796,
+        '2019-04-01T11:52:00Z', '7acc2d43-c5fb-4de6-8553-e99a8ddd1a87')
+ON CONFLICT DO NOTHING;
+INSERT INTO nodes (id, addr, name, type, region, lat, long, created, master)
+values ('9e130390-da5e-4fd5-b394-b0dcc7a55b10', 'F7-BC-A0-EF-14-29', 'oil sensor', 2, 'AA002', 52.4293273, 19.4619176,
+        '2020-06-24T17:07:55Z', '36fe015e-758a-4599-9818-26fe1b1b0a13')
+ON CONFLICT DO NOTHING;
+INSERT INTO nodes (id, addr, name, type, region, lat, long, created, master)
+values ('9ef2e08b-53b1-4e5e-a306-6b8e1b6d3664', 'B0-B5-97-6A-21-A8', 'igrid monitor', 2, 'AA001', 24.6946241,
+        70.1814258, '2019-08-07T19:15:19Z', 'f3f204c7-962b-440f-bd7b-5ed7d83eb874')
+ON CONFLICT DO NOTHING;
+INSERT INTO nodes (id, addr, name, type, region, lat, long, created, master)
+values ('1bbf45f1-908b-43ff-aedc-9b5e1f402298', '3B-B8-26-98-35-2D', 'temp sensor', 1, 'AA002', 45.8272842, 20.4615173,
+        '2019-05-03T20:37:27Z', '36fe015e-758a-4599-9818-26fe1b1b0a13')
+ON CONFLICT DO NOTHING;
+INSERT INTO nodes (id, addr, name, type, region, lat, long, created, master)
+values ('ac8c7456-3117-495a-834d-3fb794c63192', '32-8A-E1-7E-26-84', 'mqtt server', 1, 'AA001', 7.3601663, 9.0377612,
+        '2020-08-30T11:56:54Z', 'f3f204c7-962b-440f-bd7b-5ed7d83eb874')
+ON CONFLICT DO NOTHING;
+
+create table if not exists claim_codes
+(
+    code    varchar(50) not null primary key,
+    region  varchar(50) not null,
+    type    integer     not null,
+    created timestamptz not null,
+    expires timestamptz not null,
+    node    varchar(600),
+    foreign key (region) references regions (id),
+    foreign key (type) references node_types (id)
+);
+
+create table if not exists claims
+(
+    id      varchar(100) not null primary key,
+    code    varchar(50)  not null,
+    addr    varchar(60)  not null,
+    node    varchar(600),
+    result  varchar(20)  not null,
+    err     text,
+    created timestamptz  not null
+);
+
+create table if not exists node_credentials
+(
+    node    varchar(600) not null primary key,
+    key     varchar(200) not null,
+    created timestamptz  not null,
+    foreign key (node) references nodes (id) on delete cascade
+);
+
+create table if not exists certificates
+(
+    serial  varchar(64)  not null primary key,
+    node    varchar(600) not null,
+    pem     text         not null,
+    issued  timestamptz  not null,
+    expires timestamptz  not null,
+    revoked timestamptz,
+    org     varchar(100)
+);
+
+create table if not exists api_keys
+(
+    id      varchar(100) not null primary key,
+    name    varchar(100) not null,
+    owner   varchar(100) not null,
+    role    integer      not null,
+    region  varchar(50),
+    hash    varchar(200) not null,
+    created timestamptz  not null,
+    expires timestamptz,
+    revoked timestamptz,
+    foreign key (owner) references users (id) on delete cascade
+);
+
+create table if not exists events
+(
+    uuid      varchar(100) not null primary key,
+    name      varchar(50)  not null,
+    region    varchar(50)  not null default '',
+    actor     varchar(100) not null default '',
+    action    varchar(100) not null default '',
+    result    varchar(50)  not null default '',
+    err       text         not null default '',
+    ts        bigint       not null,
+    exec_time bigint       not null,
+    subject   varchar(100) not null default '',
+    org       varchar(100) not null default ''
+);
+
+create table if not exists idempotency_keys
+(
+    key          varchar(64)  not null primary key,
+    hash         varchar(64)  not null,
+    status       int          not null default 0,
+    content_type varchar(100) not null default '',
+    body         bytea,
+    created      timestamptz  not null
+);
+
+create table if not exists firmware
+(
+    id       varchar(100) not null primary key,
+    version  varchar(50)  not null,
+    type     int          not null,
+    checksum varchar(64)  not null,
+    url      text,
+    blob     bytea,
+    created  timestamptz  not null,
+    org      varchar(100),
+    foreign key (type) references node_types (id),
+    foreign key (org) references organizations (id)
+);
+
+create table if not exists campaigns
+(
+    id         varchar(100) not null primary key,
+    name       varchar(100) not null,
+    firmware   varchar(100) not null,
+    region     varchar(50),
+    subregions boolean      not null default false,
+    labels     text,
+    rollout    int          not null,
+    created    timestamptz  not null,
+    org        varchar(100),
+    foreign key (firmware) references firmware (id),
+    foreign key (region) references regions (id),
+    foreign key (org) references organizations (id)
+);
+
+create table if not exists rollouts
+(
+    campaign varchar(100) not null,
+    node     varchar(600) not null,
+    status   varchar(20)  not null,
+    err      text         not null default '',
+    updated  timestamptz  not null,
+    primary key (campaign, node),
+    foreign key (campaign) references campaigns (id) on delete cascade,
+    foreign key (node) references nodes (id) on delete cascade
+);
+
+create table if not exists schemas
+(
+    id       varchar(100) not null primary key,
+    kind     varchar(20)  not null,
+    type     int,
+    node     varchar(600),
+    version  int          not null,
+    document text         not null,
+    created  timestamptz  not null,
+    org      varchar(100),
+    foreign key (type) references node_types (id),
+    foreign key (node) references nodes (id) on delete cascade,
+    foreign key (org) references organizations (id)
+);
+
+create table if not exists quotas
+(
+    scope varchar(20)  not null,
+    id    varchar(100) not null,
+    users int          not null default 0,
+    nodes text,
+    org   varchar(100),
+    primary key (scope, id),
+    foreign key (org) references organizations (id)
+);
+
+create table if not exists schema_version
+(
+    version int not null
+);
+`
+
+// Version is the version of Schema. A database is migrated when the
+// highest version recorded in schema_version is Version.
+const Version = 2
+
+// Migrations upgrade a database created with an older Schema, Migrations[v]
+// upgrades version v to v+1. Databases created before versions were
+// recorded are at version 0. Schema is applied first and only creates the
+// missing tables, the steps of a migration are skipped when the database
+// already has them so an interrupted migration can be run again.
+var Migrations = []Migration{
+	{
+		Columns: []Column{
+			{"regions", "org", "varchar(100) references organizations (id)"},
+			{"regions", "boundary", "text"},
+			{"regions", "parent", "varchar(50) references regions (id)"},
+			{"users", "org", "varchar(100) references organizations (id)"},
+			{"nodes", "org", "VARCHAR(100) REFERENCES organizations (id)"},
+			{"nodes", "firmware", "VARCHAR(50)"},
+			{"nodes", "labels", "TEXT"},
+			{"certificates", "org", "varchar(100)"},
+			{"events", "subject", "varchar(100) not null default ''"},
+			{"events", "org", "varchar(100) not null default ''"},
+		},
+		ForeignKeys: []ForeignKey{
+			{"nodes", "type", "node_types"},
+			{"nodes", "master", "nodes"},
+		},
+	},
+	{
+		//region ids are as long as the ids of the regions table
+		Types: []Column{
+			{"nodes", "region", "VARCHAR(50)"},
+		},
+	},
+}
+
+// Migration is a step of Migrations
+type Migration struct {
+	// Columns are added to the tables that do not have them
+	Columns []Column
+
+	// ForeignKeys are added to the columns that do not have one
+	ForeignKeys []ForeignKey
+
+	// Types change the type of existing columns to their Definition
+	Types []Column
+}
+
+// Column is a column of Table, Definition is its type and constraints
+type Column struct {
+	Table, Name, Definition string
+}
+
+// ForeignKey makes Column of Table reference the id of the table References
+type ForeignKey struct {
+	Table, Column, References string
+}
@@ -1,17 +1,98 @@
 package sql
 
 const (
-	UsersSelectAll   = "SELECT * FROM users;"
-	UserSelectById   = "SELECT * FROM users WHERE id=$1;"
-	UserDelete       = "DELETE FROM users WHERE id=$1;"
-	UserInsertNew    = "INSERT INTO users (id,name,email,password,ugroup,region,created) VALUES($1,$2,$3,$4,$5,$6,$7);"
-	UserUpdateGroup  = "UPDATE users SET ugroup = $2 WHERE id = $1;"
-	UserUpdateRegion = "UPDATE users SET region = $2 WHERE id = $1;"
-	UserUpdateRandG  = "UPDATE users SET ugroup = $2, region = $3 WHERE id = $1;"
-	RegionAddNew     = "INSERT INTO regions (id, name,description) VALUES ($1,$2,$3);"
-	RegionsSelectAll = "SELECT * FROM regions;"
-	NodeDelete       = "DELETE FROM nodes WHERE id=$1;"
-	NodeGetById      = "SELECT * FROM nodes WHERE id=$1 or addr=$1;"
-	NodeGetAll       = "SELECT * FROM nodes;"
-	NodeAddNew       = "INSERT INTO nodes (id, addr, name, type, region,lat,long,created, master)VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9);"
+	UsersSelectAll        = "SELECT id, name, email, password, ugroup, region, created, coalesce(org, '') FROM users WHERE $1 = '' OR org = $1;"
+	UserSelectById        = "SELECT id, name, email, password, ugroup, region, created, coalesce(org, '') FROM users WHERE id=$1 AND ($2 = '' OR org = $2);"
+	UserSelectRegion      = "SELECT region FROM users WHERE id=$1 AND ($2 = '' OR org = $2);"
+	UserDelete            = "DELETE FROM users WHERE id=$1 AND ($2 = '' OR org = $2);"
+	UserInsertNew         = "INSERT INTO users (id,name,email,password,ugroup,region,created,org) VALUES($1,$2,$3,$4,$5,$6,$7,$8);"
+	UserUpdateGroup       = "UPDATE users SET ugroup = $2 WHERE id = $1 AND ($3 = '' OR org = $3);"
+	UserUpdateRegion      = "UPDATE users SET region = $2 WHERE id = $1 AND ($3 = '' OR org = $3);"
+	UserUpdateRandG       = "UPDATE users SET ugroup = $2, region = $3 WHERE id = $1 AND ($4 = '' OR org = $4);"
+	UserUpdatePassword    = "UPDATE users SET password = $2 WHERE id = $1 AND ($3 = '' OR org = $3);"
+	RegionAddNew          = "INSERT INTO regions (id, name,description,org,boundary,parent) VALUES ($1,$2,$3,$4,$5,$6);"
+	RegionUpdate          = "UPDATE regions SET name = $2, description = $3, boundary = $4, parent = $5 WHERE id = $1 AND ($6 = '' OR org = $6);"
+	RegionDelete          = "DELETE FROM regions WHERE id = $1 AND ($2 = '' OR org = $2);"
+	RegionsSelectAll      = "SELECT id, name, description, coalesce(org, ''), boundary, coalesce(parent, '') FROM regions WHERE $1 = '' OR org = $1;"
+	NodeDelete            = "DELETE FROM nodes WHERE id=$1 AND ($2 = '' OR org = $2);"
+	NodeGetById           = "SELECT id, addr, name, type, region, lat, long, created, coalesce(master, ''), coalesce(org, ''), coalesce(firmware, ''), labels FROM nodes WHERE (id=$1 or addr=$1) AND ($2 = '' OR org = $2);"
+	NodeGetAll            = "SELECT id, addr, name, type, region, lat, long, created, coalesce(master, ''), coalesce(org, ''), coalesce(firmware, ''), labels FROM nodes WHERE $1 = '' OR org = $1;"
+	NodeSlaves            = "SELECT id, addr, name, type, region, lat, long, created, coalesce(master, ''), coalesce(org, ''), coalesce(firmware, ''), labels FROM nodes WHERE master = $1 AND ($2 = '' OR org = $2);"
+	NodeUpdate            = "UPDATE nodes SET name = $2, region = $3, lat = $4, long = $5, master = $6, firmware = $7, labels = $8 WHERE id = $1 AND ($9 = '' OR org = $9);"
+	NodeAddNew            = "INSERT INTO nodes (id, addr, name, type, region,lat,long,created, master, org, firmware, labels)VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12);"
+	NodeTypeAddNew        = "INSERT INTO node_types (id, name, description, capabilities) VALUES ($1,$2,$3,$4);"
+	NodeTypeGetById       = "SELECT id, name, description, capabilities FROM node_types WHERE id=$1;"
+	NodeTypesGetAll       = "SELECT id, name, description, capabilities FROM node_types ORDER BY id;"
+	NodeTypeDelete        = "DELETE FROM node_types WHERE id=$1;"
+	NodeTypeUpdate        = "UPDATE node_types SET name = $2, description = $3, capabilities = $4 WHERE id = $1;"
+	RegionGetById         = "SELECT id, name, description, coalesce(org, ''), boundary, coalesce(parent, '') FROM regions WHERE id=$1 AND ($2 = '' OR org = $2);"
+	ClaimCodeAddNew       = "INSERT INTO claim_codes (code, region, type, created, expires) VALUES ($1,$2,$3,$4,$5);"
+	ClaimCodeGet          = "SELECT code, region, type, created, expires, coalesce(node, '') FROM claim_codes WHERE code=$1;"
+	ClaimCodesGetAll      = "SELECT c.code, c.region, c.type, c.created, c.expires, coalesce(c.node, '') FROM claim_codes c JOIN regions r ON r.id = c.region WHERE $1 = '' OR r.org = $1 ORDER BY c.created;"
+	ClaimCodeRedeem       = "UPDATE claim_codes SET node = $2 WHERE code = $1 AND node IS NULL AND expires > $3;"
+	ClaimAddNew           = "INSERT INTO claims (id, code, addr, node, result, err, created) VALUES ($1,$2,$3,$4,$5,$6,$7);"
+	ClaimsGetAll          = "SELECT c.id, c.code, c.addr, coalesce(c.node, ''), c.result, coalesce(c.err, ''), c.created FROM claims c LEFT JOIN claim_codes cc ON cc.code = c.code LEFT JOIN regions r ON r.id = cc.region WHERE $1 = '' OR r.org = $1 ORDER BY c.created;"
+	CredentialsSave       = "INSERT INTO node_credentials (node, key, created) VALUES ($1,$2,$3) ON CONFLICT (node) DO UPDATE SET key = $2, created = $3;"
+	CredentialsGet        = "SELECT key FROM node_credentials WHERE node = $1;"
+	CredentialsBackup     = "SELECT node, key, created FROM node_credentials ORDER BY node;"
+	CertificateAddNew     = "INSERT INTO certificates (serial, node, pem, issued, expires, org) VALUES ($1,$2,$3,$4,$5,$6);"
+	CertificateGet        = "SELECT serial, node, pem, issued, expires, revoked, coalesce(org, '') FROM certificates WHERE serial=$1 AND ($2 = '' OR org = $2);"
+	CertificatesGetAll    = "SELECT serial, node, '', issued, expires, revoked, coalesce(org, '') FROM certificates WHERE $1 = '' OR org = $1 ORDER BY issued;"
+	CertificateRevoke     = "UPDATE certificates SET revoked = $2 WHERE serial = $1 AND revoked IS NULL AND ($3 = '' OR org = $3);"
+	CertificatesRevokeAll = "UPDATE certificates SET revoked = $2 WHERE node = $1 AND revoked IS NULL AND expires > $2 AND ($3 = '' OR org = $3);"
+	CertificatesRevoked   = "SELECT serial, node, '', issued, expires, revoked, coalesce(org, '') FROM certificates WHERE revoked IS NOT NULL AND expires > $2 AND ($1 = '' OR org = $1) ORDER BY revoked;"
+	CertificatesBackup    = "SELECT serial, node, pem, issued, expires, revoked, coalesce(org, '') FROM certificates ORDER BY issued;"
+	APIKeyAddNew          = "INSERT INTO api_keys (id, name, owner, role, region, hash, created, expires) VALUES ($1,$2,$3,$4,$5,$6,$7,$8);"
+	APIKeyGet             = "SELECT k.id, k.name, k.owner, k.role, coalesce(k.region, ''), k.hash, k.created, k.expires, k.revoked, coalesce(u.org, '') FROM api_keys k JOIN users u ON u.id = k.owner WHERE k.id=$1 AND ($2 = '' OR u.org = $2);"
+	APIKeysGetAll         = "SELECT k.id, k.name, k.owner, k.role, coalesce(k.region, ''), '', k.created, k.expires, k.revoked, coalesce(u.org, '') FROM api_keys k JOIN users u ON u.id = k.owner WHERE ($1 = '' OR k.owner = $1) AND ($2 = '' OR u.org = $2) ORDER BY k.created;"
+	APIKeyRevoke          = "UPDATE api_keys SET revoked = $2 WHERE id = $1 AND revoked IS NULL AND ($3 = '' OR owner IN (SELECT id FROM users WHERE org = $3));"
+	APIKeysBackup         = "SELECT id, name, owner, role, coalesce(region, ''), hash, created, expires, revoked, '' FROM api_keys ORDER BY created;"
+	OrganizationAddNew    = "INSERT INTO organizations (id, name, description, created) VALUES ($1,$2,$3,$4);"
+	OrganizationGet       = "SELECT id, name, description, created FROM organizations WHERE id=$1 AND ($2 = '' OR id = $2);"
+	OrganizationsGetAll   = "SELECT id, name, description, created FROM organizations WHERE $1 = '' OR id = $1 ORDER BY created;"
+	OrganizationDelete    = "DELETE FROM organizations WHERE id=$1;"
+	EventAddNew           = "INSERT INTO events (uuid, name, region, actor, action, result, err, ts, exec_time, subject, org) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11);"
+	EventsGetAll          = "SELECT uuid, name, region, actor, action, result, err, ts, exec_time, subject, org FROM events WHERE $1 = '' OR org = $1 ORDER BY ts;"
+	EventsBetween         = "SELECT uuid, name, region, actor, action, result, err, ts, exec_time, subject, org FROM events WHERE ts >= $1 AND ts < $2 AND ($3 = '' OR org = $3) ORDER BY ts;"
+	EventsBefore          = "SELECT uuid, name, region, actor, action, result, err, ts, exec_time, subject, org FROM events WHERE ts < $1 AND ($2 = '' OR org = $2) ORDER BY ts;"
+	EventsAfter           = "SELECT uuid, name, region, actor, action, result, err, ts, exec_time, subject, org FROM events WHERE ts >= $1 AND ($2 = '' OR org = $2) ORDER BY ts;"
+	EventsByID            = "SELECT uuid, name, region, actor, action, result, err, ts, exec_time, subject, org FROM events WHERE (uuid = $1 OR actor = $1 OR subject = $1) AND ($2 = '' OR org = $2) ORDER BY ts;"
+	EventsByName          = "SELECT uuid, name, region, actor, action, result, err, ts, exec_time, subject, org FROM events WHERE name = $1 AND ($2 = '' OR org = $2) ORDER BY ts;"
+	FirmwareAddNew        = "INSERT INTO firmware (id, version, type, checksum, url, blob, created, org) VALUES ($1,$2,$3,$4,$5,$6,$7,$8);"
+	FirmwareGet           = "SELECT id, version, type, checksum, coalesce(url, ''), blob, created, coalesce(org, '') FROM firmware WHERE id=$1 AND ($2 = '' OR org = $2);"
+	FirmwareGetAll        = "SELECT id, version, type, checksum, coalesce(url, ''), NULL, created, coalesce(org, '') FROM firmware WHERE $1 = '' OR org = $1 ORDER BY created;"
+	FirmwareDelete        = "DELETE FROM firmware WHERE id=$1 AND ($2 = '' OR org = $2);"
+	FirmwareBackup        = "SELECT id, version, type, checksum, coalesce(url, ''), blob, created, coalesce(org, '') FROM firmware ORDER BY created;"
+	CampaignAddNew        = "INSERT INTO campaigns (id, name, firmware, region, subregions, labels, rollout, created, org) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9);"
+	CampaignGet           = "SELECT id, name, firmware, coalesce(region, ''), subregions, labels, rollout, created, coalesce(org, '') FROM campaigns WHERE id=$1 AND ($2 = '' OR org = $2);"
+	CampaignsGetAll       = "SELECT id, name, firmware, coalesce(region, ''), subregions, labels, rollout, created, coalesce(org, '') FROM campaigns WHERE $1 = '' OR org = $1 ORDER BY created;"
+	CampaignSetRollout    = "UPDATE campaigns SET rollout = $2 WHERE id = $1 AND ($3 = '' OR org = $3);"
+	RolloutAddNew         = "INSERT INTO rollouts (campaign, node, status, updated) VALUES ($1,$2,$3,$4) ON CONFLICT (campaign, node) DO NOTHING;"
+	RolloutsGetAll        = "SELECT r.campaign, r.node, r.status, r.err, r.updated FROM rollouts r JOIN campaigns c ON c.id = r.campaign WHERE r.campaign = $1 AND ($2 = '' OR c.org = $2) ORDER BY r.node;"
+	RolloutUpdate         = "UPDATE rollouts SET status = $3, err = $4, updated = $5 WHERE campaign = $1 AND node = $2 AND campaign IN (SELECT id FROM campaigns WHERE $6 = '' OR org = $6);"
+	RolloutsBackup        = "SELECT campaign, node, status, err, updated FROM rollouts ORDER BY campaign, node;"
+	SchemaAddNew          = "INSERT INTO schemas (id, kind, type, node, version, document, created, org) VALUES ($1,$2,$3,$4,$5,$6,$7,$8);"
+	SchemaGet             = "SELECT id, kind, coalesce(type, 0), coalesce(node, ''), version, document, created, coalesce(org, '') FROM schemas WHERE id=$1 AND ($2 = '' OR org IS NULL OR org = $2);"
+	SchemasGetAll         = "SELECT id, kind, coalesce(type, 0), coalesce(node, ''), version, document, created, coalesce(org, '') FROM schemas WHERE $1 = '' OR org IS NULL OR org = $1 ORDER BY created, version;"
+	QuotaSet              = "INSERT INTO quotas (scope, id, users, nodes, org) VALUES ($1,$2,$3,$4,$5) ON CONFLICT (scope, id) DO UPDATE SET users = $3, nodes = $4, org = $5;"
+	QuotaGet              = "SELECT scope, id, users, nodes, coalesce(org, '') FROM quotas WHERE scope=$1 AND id=$2 AND ($3 = '' OR org = $3);"
+	QuotasGetAll          = "SELECT scope, id, users, nodes, coalesce(org, '') FROM quotas WHERE $1 = '' OR org = $1 ORDER BY scope, id;"
+	QuotaLock             = "UPDATE quotas SET users = users WHERE (scope = 'region' AND id = $1) OR (scope = 'org' AND id = $2);"
+	QuotaUsers            = "SELECT count(*) FROM users WHERE (region = $1 OR org = $2) AND ($3 = '' OR org = $3);"
+	QuotaNodes            = "SELECT type, count(*) FROM nodes WHERE (region = $1 OR org = $2) AND ($3 = '' OR org = $3) GROUP BY type;"
+	IdempotencyReserve    = "INSERT INTO idempotency_keys (key, hash, created) VALUES ($1,$2,$3) ON CONFLICT (key) DO UPDATE SET hash = excluded.hash, status = 0, content_type = '', body = NULL, created = excluded.created WHERE idempotency_keys.created < $4;"
+	IdempotencyGet        = "SELECT key, hash, status, content_type, body, created FROM idempotency_keys WHERE key=$1;"
+	IdempotencyComplete   = "UPDATE idempotency_keys SET status = $2, content_type = $3, body = $4 WHERE key = $1;"
+	IdempotencyRelease    = "DELETE FROM idempotency_keys WHERE key=$1;"
+	IdempotencyPurge      = "DELETE FROM idempotency_keys WHERE created < $1;"
+	SchemaVersionGet      = "SELECT coalesce(max(version), 0) FROM schema_version;"
+	SchemaVersionAdd      = "INSERT INTO schema_version (version) VALUES ($1);"
 )
+
+// Tables are the tables of the registry schema
+var Tables = []string{
+	"organizations", "regions", "users", "node_types", "nodes", "claim_codes",
+	"claims", "node_credentials", "certificates", "api_keys", "events", "idempotency_keys",
+	"firmware", "campaigns", "rollouts", "schemas", "quotas",
+	"schema_version",
+}
@@ -0,0 +1,455 @@
+package sqlite_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/piusalfred/registry"
+	"github.com/piusalfred/registry/argon2id"
+	"github.com/piusalfred/registry/bcrypt"
+	"github.com/piusalfred/registry/ca"
+	"github.com/piusalfred/registry/feed"
+	"github.com/piusalfred/registry/logger"
+	"github.com/piusalfred/registry/pkg/errors"
+	"github.com/piusalfred/registry/sqlite"
+	"github.com/piusalfred/registry/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newService returns a service on top of a fresh database, the admin
+// context is the one of a request made with a key of the global Admin
+func newService(t *testing.T) (registry.Service, context.Context) {
+	db := openDB(t)
+	svc := serviceOf(t, db, store.NewUserRepository(db), bcrypt.NewWithCost(4))
+
+	admin := registry.WithAPIKey(context.Background(), registry.APIKey{Role: int(registry.Admin)})
+
+	return svc, admin
+}
+
+// openDB opens a fresh database that is removed with the test
+func openDB(t *testing.T) *store.DB {
+	dir, err := ioutil.TempDir("", "registry")
+	require.Nil(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	db, err := sqlite.Connect(filepath.Join(dir, "registry.db"))
+	require.Nil(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+// serviceOf returns a service on top of db storing users in users and
+// hashing their passwords with hasher
+func serviceOf(t *testing.T, db *store.DB, users registry.UserRepository, hasher registry.Hasher) registry.Service {
+	certPEM, keyPEM, err := ca.Generate("test CA", time.Hour)
+	require.Nil(t, err)
+	authority, err := ca.New(certPEM, keyPEM)
+	require.Nil(t, err)
+
+	return registry.NewService(users, store.NewNodeRepository(db), store.NewRegionRepository(db),
+		store.NewNodeTypeRepository(db), store.NewClaimRepository(db), store.NewCertificateRepository(db),
+		authority, store.NewAPIKeyRepository(db), store.NewOrganizationRepository(db),
+		store.NewFirmwareRepository(db), store.NewCampaignRepository(db), store.NewSchemaRepository(db),
+		store.NewQuotaRepository(db), feed.New(store.NewEventStore(db), 16), hasher, logger.NewNop(),
+		registry.New(), registry.GeofenceOff)
+}
+
+func TestProvisionNode(t *testing.T) {
+	svc, admin := newService(t)
+	ctx := context.Background()
+
+	code, err := svc.CreateClaimCode(admin, "AA001", int(registry.Sensor), time.Hour)
+	require.Nil(t, err)
+
+	device := registry.Node{Addr: "6F-5E-42-8B-36-C0", Name: "meter", Latd: "-6.7735", Long: "39.2395"}
+
+	creds, err := svc.ProvisionNode(ctx, code.Code, device)
+	require.Nil(t, err)
+	assert.NotEmpty(t, creds.Key)
+
+	node, err := svc.AuthenticateNode(ctx, creds.NodeID, creds.Key)
+	require.Nil(t, err)
+	assert.Equal(t, creds.NodeID, node.UUID)
+	assert.Equal(t, "AA001", node.Region)
+
+	_, err = svc.AuthenticateNode(ctx, creds.NodeID, creds.Key+"0")
+	assert.True(t, errors.Contains(err, registry.ErrInvalidNodeKey), "wrong node key accepted: %v", err)
+
+	_, err = svc.AuthenticateNode(ctx, "f3f204c7-962b-440f-bd7b-5ed7d83eb874", creds.Key)
+	assert.True(t, errors.Contains(err, registry.ErrInvalidNodeKey), "key of another node accepted: %v", err)
+
+	_, err = svc.ProvisionNode(ctx, code.Code, device)
+	assert.Equal(t, errors.Conflict, errors.KindOf(err), "claim code redeemed twice: %v", err)
+
+	_, err = svc.ProvisionNode(ctx, "AAAA-BBBB-CCCC-DDDD", device)
+	assert.Equal(t, errors.Invalid, errors.KindOf(err), "unknown claim code redeemed: %v", err)
+}
+
+func TestCertificatePermissions(t *testing.T) {
+	svc, admin := newService(t)
+
+	code, err := svc.CreateClaimCode(admin, "AA001", int(registry.Sensor), time.Hour)
+	require.Nil(t, err)
+	creds, err := svc.ProvisionNode(context.Background(), code.Code, registry.Node{Addr: "6F-5E-42-8B-36-C1", Name: "meter"})
+	require.Nil(t, err)
+	node := creds.NodeID
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{}, key)
+	require.Nil(t, err)
+	csr := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}))
+
+	keyOf := func(role registry.UserGroup, region string) context.Context {
+		return registry.WithAPIKey(context.Background(), registry.APIKey{Role: int(role), Region: region})
+	}
+
+	denied := []struct {
+		desc string
+		ctx  context.Context
+	}{
+		{desc: "region user", ctx: keyOf(registry.RegionUser, "AA001")},
+		{desc: "admin of another region", ctx: keyOf(registry.RegionAdmin, "AA002")},
+	}
+
+	for _, tc := range denied {
+		_, err := svc.IssueCertificate(tc.ctx, node, csr, time.Minute)
+		assert.Equal(t, errors.PermissionDenied, errors.KindOf(err), "%s issued a certificate: %v", tc.desc, err)
+	}
+
+	cert, err := svc.IssueCertificate(keyOf(registry.RegionAdmin, "AA001"), node, csr, time.Minute)
+	require.Nil(t, err)
+
+	for _, tc := range denied {
+		err := svc.RevokeCertificate(tc.ctx, cert.Serial)
+		assert.Equal(t, errors.PermissionDenied, errors.KindOf(err), "%s revoked a certificate: %v", tc.desc, err)
+	}
+
+	require.Nil(t, svc.RevokeCertificate(keyOf(registry.RegionAdmin, "AA001"), cert.Serial))
+
+	//the certificates of a deleted node are revoked by organization admins
+	cert, err = svc.IssueCertificate(admin, node, csr, time.Minute)
+	require.Nil(t, err)
+	require.Nil(t, svc.DeleteNode(admin, node))
+
+	err = svc.RevokeCertificate(keyOf(registry.RegionAdmin, "AA001"), cert.Serial)
+	assert.Equal(t, errors.PermissionDenied, errors.KindOf(err), "region admin revoked a certificate of a deleted node: %v", err)
+}
+
+func TestAuthAPIKey(t *testing.T) {
+	svc, admin := newService(t)
+
+	//an admin of AA001, seeded by the schema
+	key, err := svc.CreateAPIKey(admin, "ball8000us98", "script", int(registry.RegionUser), "AA001", time.Hour)
+	require.Nil(t, err)
+	require.NotEmpty(t, key.Key)
+
+	got, err := svc.AuthAPIKey(context.Background(), key.Key)
+	require.Nil(t, err)
+	assert.Equal(t, key.ID, got.ID)
+	assert.Equal(t, int(registry.RegionUser), got.Role)
+	assert.Empty(t, got.Hash)
+
+	cases := []struct {
+		desc string
+		key  string
+	}{
+		{desc: "wrong secret", key: key.ID + ".secret"},
+		{desc: "unknown id", key: "id." + key.Key[len(key.ID)+1:]},
+		{desc: "no secret", key: key.ID},
+	}
+
+	for _, tc := range cases {
+		_, err := svc.AuthAPIKey(context.Background(), tc.key)
+		assert.Equal(t, errors.Unauthenticated, errors.KindOf(err), "%s: %v", tc.desc, err)
+	}
+
+	require.Nil(t, svc.RevokeAPIKey(admin, key.ID))
+	_, err = svc.AuthAPIKey(context.Background(), key.Key)
+	assert.True(t, errors.Contains(err, registry.ErrAPIKeyRevoked), "revoked key accepted: %v", err)
+}
+
+// TestAPIKeyOwner checks that keys lose the rights their owner loses after
+// they are created
+func TestAPIKeyOwner(t *testing.T) {
+	svc, admin := newService(t)
+	ctx := context.Background()
+
+	require.Nil(t, svc.AddUser(admin, registry.User{Name: "Key Owner", Email: "owner@test.com", Password: "secret", Region: "AA001"}))
+
+	users, err := svc.ListUser(admin, registry.RegionFilter{})
+	require.Nil(t, err)
+
+	var owner registry.User
+	for _, u := range users {
+		if u.Email == "owner@test.com" {
+			owner = u
+		}
+	}
+	require.NotEmpty(t, owner.ID)
+
+	_, err = svc.UpdateUser(admin, owner.ID, registry.User{Group: int(registry.RegionAdmin)})
+	require.Nil(t, err)
+
+	key, err := svc.CreateAPIKey(admin, owner.ID, "script", int(registry.RegionAdmin), "AA001", time.Hour)
+	require.Nil(t, err)
+
+	_, err = svc.AuthAPIKey(ctx, key.Key)
+	require.Nil(t, err)
+
+	cases := []struct {
+		desc   string
+		update registry.User
+		err    error
+	}{
+		{desc: "owner moved to another region", update: registry.User{Region: "AA002", Group: int(registry.RegionAdmin)}, err: registry.ErrInvalidKeyScope},
+		{desc: "owner moved back", update: registry.User{Region: "AA001", Group: int(registry.RegionAdmin)}},
+		{desc: "owner demoted", update: registry.User{Group: int(registry.RegionUser)}, err: registry.ErrInvalidKeyScope},
+	}
+
+	for _, tc := range cases {
+		_, err = svc.UpdateUser(admin, owner.ID, tc.update)
+		require.Nil(t, err, tc.desc)
+
+		_, err = svc.AuthAPIKey(ctx, key.Key)
+		assertError(t, tc.err, err, "%s: %v", tc.desc, err)
+		if tc.err != nil {
+			assert.Equal(t, errors.Unauthenticated, errors.KindOf(err), tc.desc)
+		}
+	}
+
+	require.Nil(t, svc.DeleteUser(admin, owner.ID))
+	_, err = svc.AuthAPIKey(ctx, key.Key)
+	assert.Equal(t, errors.Unauthenticated, errors.KindOf(err), "key of a deleted owner accepted: %v", err)
+}
+
+func TestPermissions(t *testing.T) {
+	svc, admin := newService(t)
+
+	require.Nil(t, svc.AddRegion(admin, registry.Region{ID: "AA011", Name: "CoICT lab", Parent: "AA001"}))
+
+	//nodes of AA001 and AA002, seeded by the schema
+	const (
+		inRegion    = "f3f204c7-962b-440f-bd7b-5ed7d83eb874"
+		otherRegion = "36fe015e-758a-4599-9818-26fe1b1b0a13"
+	)
+
+	keyOf := func(role registry.UserGroup, region string) context.Context {
+		return registry.WithAPIKey(context.Background(), registry.APIKey{Role: int(role), Region: region})
+	}
+
+	scopes := []struct {
+		desc   string
+		ctx    context.Context
+		node   string
+		region string
+		err    error
+	}{
+		{desc: "no key", ctx: context.Background(), node: otherRegion, region: "AA002"},
+		{desc: "key without a region", ctx: keyOf(registry.RegionUser, ""), node: otherRegion, region: "AA002"},
+		{desc: "key of the region", ctx: keyOf(registry.RegionUser, "AA001"), node: inRegion, region: "AA001"},
+		{desc: "key of a parent region", ctx: keyOf(registry.RegionUser, "AA001"), region: "AA011"},
+		{desc: "key of another region", ctx: keyOf(registry.RegionUser, "AA001"), node: otherRegion, region: "AA002", err: registry.ErrPermissionDenied},
+		{desc: "key of a sub-region", ctx: keyOf(registry.RegionUser, "AA011"), node: inRegion, region: "AA001", err: registry.ErrPermissionDenied},
+	}
+
+	for _, tc := range scopes {
+		if tc.node != "" {
+			_, err := svc.GetNode(tc.ctx, tc.node)
+			assertError(t, tc.err, err, "%s: GetNode", tc.desc)
+		}
+
+		_, err := svc.CreateClaimCode(tc.ctx, tc.region, int(registry.Sensor), time.Hour)
+		assertError(t, tc.err, err, "%s: CreateClaimCode", tc.desc)
+	}
+
+	roles := []struct {
+		desc string
+		ctx  context.Context
+		err  error
+	}{
+		{desc: "no key", ctx: context.Background()},
+		{desc: "admin", ctx: keyOf(registry.Admin, "")},
+		{desc: "organization admin", ctx: keyOf(registry.OrgAdmin, "")},
+		{desc: "region admin", ctx: keyOf(registry.RegionAdmin, "AA001"), err: registry.ErrPermissionDenied},
+		{desc: "region user", ctx: keyOf(registry.RegionUser, "AA001"), err: registry.ErrPermissionDenied},
+	}
+
+	for _, tc := range roles {
+		err := svc.DeleteUser(tc.ctx, "unknown user")
+		if tc.err != nil {
+			assertError(t, tc.err, err, tc.desc)
+			continue
+		}
+
+		//the role is checked before the user is looked up
+		assert.Equal(t, errors.NotFound, errors.KindOf(err), "%s: %v", tc.desc, err)
+	}
+}
+
+func TestNodeMasters(t *testing.T) {
+	svc, admin := newService(t)
+
+	const (
+		master = "f3f204c7-962b-440f-bd7b-5ed7d83eb874" //controller of AA001
+		other  = "36fe015e-758a-4599-9818-26fe1b1b0a13" //controller of AA002
+	)
+
+	controller := registry.Node{Addr: "6F-5E-42-8B-36-C1", Name: "controller", Region: "AA001", Type: int(registry.Controller), Master: master}
+	require.Nil(t, svc.AddNode(admin, controller))
+
+	c, err := svc.GetNode(admin, controller.Addr)
+	require.Nil(t, err)
+
+	cases := []struct {
+		desc string
+		node registry.Node
+		err  error
+	}{
+		{desc: "master of the region", node: registry.Node{Addr: "6F-5E-42-8B-36-C2", Master: c.UUID}},
+		{desc: "unknown master", node: registry.Node{Addr: "6F-5E-42-8B-36-C3", Master: "unknown"}, err: registry.ErrUnknownMaster},
+		{desc: "master of another region", node: registry.Node{Addr: "6F-5E-42-8B-36-C4", Master: other}, err: registry.ErrMasterOutsideRegion},
+		{desc: "master that is not a controller", node: registry.Node{Addr: "6F-5E-42-8B-36-C5", Master: "833981d1-1040-4c2d-ad9f-f44e26c8d17c"}, err: registry.ErrMasterNotController},
+	}
+
+	for _, tc := range cases {
+		tc.node.Name, tc.node.Region, tc.node.Type = "sensor", "AA001", int(registry.Sensor)
+		assertError(t, tc.err, svc.AddNode(admin, tc.node), tc.desc)
+	}
+
+	_, err = svc.UpdateNode(admin, master, registry.Node{Master: c.UUID})
+	assertError(t, registry.ErrMasterCycle, err, "master of its own master")
+
+	_, err = svc.UpdateNode(admin, master, registry.Node{Region: "AA002"})
+	assertError(t, registry.ErrMasterInUse, err, "master moved away from its nodes")
+
+	assertError(t, registry.ErrMasterInUse, svc.DeleteNode(admin, c.UUID), "master of a node deleted")
+
+	//an empty master is kept, NoMaster removes it
+	slave, err := svc.UpdateNode(admin, "6F-5E-42-8B-36-C2", registry.Node{Name: "renamed"})
+	require.Nil(t, err)
+	assert.Equal(t, c.UUID, slave.Master)
+
+	slave, err = svc.UpdateNode(admin, slave.UUID, registry.Node{Master: registry.NoMaster})
+	require.Nil(t, err)
+	assert.Empty(t, slave.Master)
+
+	assert.Nil(t, svc.DeleteNode(admin, c.UUID), "master without nodes not deleted")
+}
+
+func TestQuotas(t *testing.T) {
+	svc, admin := newService(t)
+
+	usage, err := svc.Usage(admin, registry.QuotaRegion, "AA001")
+	require.Nil(t, err)
+
+	quota := registry.Quota{Scope: registry.QuotaRegion, ID: "AA001", Users: usage.Users.Used + 1, Nodes: registry.NodeLimits{int(registry.Sensor): usage.Nodes[int(registry.Sensor)].Used}}
+	_, err = svc.SetQuota(admin, quota)
+	require.Nil(t, err)
+
+	user := registry.User{Name: "Quota Test", Email: "quota@test.com", Password: "secret", Region: "AA001"}
+	require.Nil(t, svc.AddUser(admin, user))
+	assertError(t, registry.ErrQuotaExceeded, svc.AddUser(admin, user), "user added over the quota")
+
+	node := registry.Node{Addr: "6F-5E-42-8B-36-C6", Name: "sensor", Region: "AA001", Type: int(registry.Sensor)}
+	assertError(t, registry.ErrQuotaExceeded, svc.AddNode(admin, node), "node added over the quota")
+
+	node.Type = int(registry.Controller)
+	require.Nil(t, svc.AddNode(admin, node), "node of a type without a limit")
+
+	got, err := svc.Usage(admin, registry.QuotaRegion, "AA001")
+	require.Nil(t, err)
+	assert.Equal(t, registry.Consumption{Used: quota.Users, Limit: quota.Users}, got.Users)
+	assert.Equal(t, usage.Nodes[int(registry.Controller)].Used+1, got.Nodes[int(registry.Controller)].Used)
+}
+
+// readOnlyUsers fails to replace password hashes
+type readOnlyUsers struct {
+	registry.UserRepository
+}
+
+func (readOnlyUsers) UpdatePassword(context.Context, string, string) error {
+	return errors.New("database is read-only")
+}
+
+// TestRehash logs users in whose hashes were generated by an older
+// algorithm or with weaker parameters and checks that they are replaced
+func TestRehash(t *testing.T) {
+	db := openDB(t)
+	users := store.NewUserRepository(db)
+	ctx := context.Background()
+
+	hasher := argon2id.New(argon2id.Params{Memory: 1024, Iterations: 2, Parallelism: 1}, bcrypt.NewWithCost(4))
+	svc := serviceOf(t, db, users, hasher)
+
+	legacy, err := bcrypt.NewWithCost(4).Hash("secret")
+	require.Nil(t, err)
+	weak, err := argon2id.New(argon2id.Params{Memory: 512, Iterations: 1, Parallelism: 1}, nil).Hash("secret")
+	require.Nil(t, err)
+
+	newUser := func(hash string) registry.User {
+		id, err := registry.New().ID()
+		require.Nil(t, err)
+
+		user := registry.User{ID: id, Name: "Rehash Test", Email: "rehash@test.com", Password: hash,
+			Group: int(registry.RegionUser), Region: "AA001", Created: time.Now().Format(time.RFC3339)}
+		require.Nil(t, users.Add(ctx, user))
+
+		return user
+	}
+
+	cases := []struct {
+		desc string
+		hash string
+	}{
+		{desc: "bcrypt hash", hash: legacy},
+		{desc: "argon2id hash with weaker parameters", hash: weak},
+	}
+
+	for _, tc := range cases {
+		require.True(t, hasher.NeedsRehash(tc.hash), tc.desc)
+		user := newUser(tc.hash)
+
+		assert.NotNil(t, svc.AuthUser(ctx, user.ID, "wrong"), "%s: wrong password accepted", tc.desc)
+		require.Nil(t, svc.AuthUser(ctx, user.ID, "secret"), tc.desc)
+
+		stored, err := users.Get(ctx, user.ID)
+		require.Nil(t, err)
+		assert.True(t, strings.HasPrefix(stored.Password, "$argon2id$"), "%s: not rehashed with argon2id: %s", tc.desc, stored.Password)
+		assert.False(t, hasher.NeedsRehash(stored.Password), "%s: rehashed with weaker parameters", tc.desc)
+
+		require.Nil(t, svc.AuthUser(ctx, user.ID, "secret"), "%s: login after the rehash", tc.desc)
+	}
+
+	//the login succeeds when the new hash can not be stored
+	readOnly := serviceOf(t, db, readOnlyUsers{users}, hasher)
+	user := newUser(legacy)
+
+	require.Nil(t, readOnly.AuthUser(ctx, user.ID, "secret"))
+
+	stored, err := users.Get(ctx, user.ID)
+	require.Nil(t, err)
+	assert.Equal(t, legacy, stored.Password, "hash replaced by a failed rehash")
+}
+
+// assertError asserts that err is nil when want is, or contains want
+func assertError(t *testing.T, want error, err error, msgAndArgs ...interface{}) {
+	if want == nil {
+		assert.Nil(t, err, msgAndArgs...)
+		return
+	}
+
+	assert.True(t, errors.Contains(err, want), msgAndArgs...)
+}
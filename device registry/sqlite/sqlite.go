@@ -0,0 +1,223 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/mattn/go-sqlite3"
+	"github.com/piusalfred/registry/store"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Connect opens the SQLite database file at path, creating it if it does
+// not exist, and applies the registry schema.
+func Connect(path string) (*store.DB, error) {
+	url := fmt.Sprintf("file:%s?_foreign_keys=on&_journal_mode=WAL&_busy_timeout=5000", path)
+
+	db, err := sql.Open("sqlite3", url)
+	if err != nil {
+		return nil, err
+	}
+
+	//sqlite allows a single writer at a time
+	db.SetMaxOpenConns(1)
+
+	sdb := store.New(db, dialect{})
+
+	err = sdb.Migrate(context.Background())
+
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return sdb, nil
+}
+
+var (
+	placeholder = regexp.MustCompile(`\$(\d+)`)
+
+	//go-sqlite3 scans columns declared as timestamp into time.Time,
+	//sqlite has no array type so text[] columns are stored as text
+	types = strings.NewReplacer("timestamptz", "timestamp", "text[]", "text", "bytea", "blob")
+)
+
+type dialect struct{}
+
+// Rebind rewrites the $N placeholders of the queries in the sql package
+// to ?N, sqlite numbers $N parameters by order of appearance instead of N.
+func (dialect) Rebind(query string) string {
+	return placeholder.ReplaceAllString(query, "?$1")
+}
+
+// Timestamp normalizes t before it is stored, sqlite compares timestamps
+// as text so they all have to be in the same zone and precision.
+func (dialect) Timestamp(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Second)
+}
+
+// Array stores s the way postgres prints a text[], {a,b}
+func (dialect) Array(s []string) interface{} {
+	return "{" + strings.Join(s, ",") + "}"
+}
+
+func (dialect) ScanArray(s *[]string) interface{} {
+	return (*array)(s)
+}
+
+func (dialect) ForeignKeyViolation(err error) bool {
+	sqliteErr, ok := err.(sqlite3.Error)
+	return ok && sqliteErr.ExtendedCode == sqlite3.ErrConstraintForeignKey
+}
+
+// SnapshotOptions returns nil, sqlite transactions are serializable so all
+// tables are read from the same snapshot of the database
+func (dialect) SnapshotOptions() *sql.TxOptions {
+	return nil
+}
+
+func (dialect) Schema(schema string) string {
+	return types.Replace(schema)
+}
+
+// AddForeignKey creates table again with the foreign key and copies its
+// rows, sqlite can not add a constraint to an existing table. Foreign keys
+// are not enforced while the table is replaced so that the rows
+// referencing it are kept, they are checked before the copy is committed.
+func (dialect) AddForeignKey(ctx context.Context, db *sql.DB, table, column, ref string) error {
+	//foreign_keys applies to a connection and can not be changed in a transaction
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	rows, err := conn.QueryContext(ctx, "SELECT \"from\" FROM pragma_foreign_key_list(?);", table)
+	if err != nil {
+		return err
+	}
+
+	var columns []string
+	for rows.Next() {
+		var from string
+		if err := rows.Scan(&from); err != nil {
+			rows.Close()
+			return err
+		}
+
+		columns = append(columns, from)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, from := range columns {
+		if from == column {
+			return nil
+		}
+	}
+
+	var create string
+	err = conn.QueryRowContext(ctx, "SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?;", table).Scan(&create)
+	if err != nil {
+		return err
+	}
+
+	//the definitions of the columns and constraints are kept as they are
+	//and the foreign key is appended to them
+	start, end := strings.Index(create, "("), strings.LastIndex(create, ")")
+	if start < 0 || end < start {
+		return fmt.Errorf("sqlite: can not parse the schema of %s", table)
+	}
+
+	migrated := table + "_migrated"
+	create = fmt.Sprintf("CREATE TABLE %s %s,\n    FOREIGN KEY (%s) REFERENCES %s (id)\n);",
+		migrated, create[start:end], column, ref)
+
+	if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF;"); err != nil {
+		return err
+	}
+
+	err = replaceTable(ctx, conn, table, migrated, create)
+
+	if _, onErr := conn.ExecContext(ctx, "PRAGMA foreign_keys = ON;"); err == nil {
+		err = onErr
+	}
+
+	return err
+}
+
+// AlterColumnType does nothing, sqlite does not enforce the length of
+// varchar columns
+func (dialect) AlterColumnType(ctx context.Context, db *sql.DB, table, column, typ string) error {
+	return nil
+}
+
+// replaceTable replaces table with the one created by create under the
+// name migrated, the rows of table are copied to it
+func replaceTable(ctx context.Context, conn *sql.Conn, table, migrated, create string) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmts := []string{
+		create,
+		fmt.Sprintf("INSERT INTO %s SELECT * FROM %s;", migrated, table),
+		fmt.Sprintf("DROP TABLE %s;", table),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s;", migrated, table),
+	}
+
+	for _, stmt := range stmts {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+
+	rows, err := tx.QueryContext(ctx, "SELECT \"table\" FROM pragma_foreign_key_check(?);", table)
+	if err != nil {
+		return err
+	}
+
+	violated := rows.Next()
+	rows.Close()
+
+	if violated {
+		return fmt.Errorf("sqlite: rows of %s reference rows that do not exist", table)
+	}
+
+	return tx.Commit()
+}
+
+// array scans a text[] column stored by Array, databases created before
+// arrays had braces store a comma separated list.
+type array []string
+
+func (a *array) Scan(src interface{}) error {
+	var s string
+	switch v := src.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	case nil:
+	default:
+		return fmt.Errorf("sqlite: can not scan %T into a text array", src)
+	}
+
+	*a = nil
+	for _, e := range strings.Split(strings.TrimSuffix(strings.TrimPrefix(s, "{"), "}"), ",") {
+		if e == "" {
+			continue
+		}
+
+		*a = append(*a, e)
+	}
+
+	return nil
+}
@@ -0,0 +1,319 @@
+package sqlite_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/piusalfred/registry"
+	"github.com/piusalfred/registry/backup"
+	"github.com/piusalfred/registry/repotest"
+	sql2 "github.com/piusalfred/registry/sql"
+	"github.com/piusalfred/registry/sqlite"
+	"github.com/piusalfred/registry/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRepositories(t *testing.T) {
+	dir, err := ioutil.TempDir("", "registry")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := sqlite.Connect(filepath.Join(dir, "registry.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repotest.Run(t, repotest.Repositories{
+		Users:   store.NewUserRepository(db),
+		Nodes:   store.NewNodeRepository(db),
+		Regions: store.NewRegionRepository(db),
+		Events:  store.NewEventStore(db),
+
+		Types:  store.NewNodeTypeRepository(db),
+		Claims: store.NewClaimRepository(db),
+		Certs:  store.NewCertificateRepository(db),
+		Keys:   store.NewAPIKeyRepository(db),
+		Orgs:   store.NewOrganizationRepository(db),
+
+		Idempotency: store.NewIdempotencyRepository(db),
+		Firmware:    store.NewFirmwareRepository(db),
+		Campaigns:   store.NewCampaignRepository(db),
+		Schemas:     store.NewSchemaRepository(db),
+		Quotas:      store.NewQuotaRepository(db),
+	})
+}
+
+func TestMigrated(t *testing.T) {
+	dir, err := ioutil.TempDir("", "registry")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := sqlite.Connect(filepath.Join(dir, "registry.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if err := store.Migrated(context.Background(), db.DB); err != nil {
+		t.Fatalf("expected a migrated database got %v", err)
+	}
+
+	if _, err := db.Exec("DELETE FROM schema_version;"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Migrated(context.Background(), db.DB); !errors.Is(err, store.ErrNotMigrated) {
+		t.Fatalf("expected %v for a database without version got %v", store.ErrNotMigrated, err)
+	}
+
+	if _, err := db.Exec("DROP TABLE events;"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Migrated(context.Background(), db.DB); !errors.Is(err, store.ErrNotMigrated) {
+		t.Fatalf("expected %v got %v", store.ErrNotMigrated, err)
+	}
+}
+
+// legacySchema is the schema of a database created before organizations,
+// region boundaries and node labels, and before node masters had a foreign
+// key and the schema had a version
+const legacySchema = `
+create table regions
+(
+    id          varchar(50) not null primary key,
+    name        varchar(30),
+    description text        not null
+);
+
+create table users
+(
+    id       varchar(100) not null primary key,
+    name     varchar(100),
+    email    varchar(100) not null,
+    password varchar(100),
+    ugroup   integer,
+    region   varchar(50),
+    created  date,
+    foreign key (region) references regions(id)
+);
+
+create table node_types
+(
+    id           integer     not null primary key,
+    name         varchar(50) not null unique,
+    description  text,
+    capabilities text        not null default '{}'
+);
+
+create table nodes
+(
+    id      VARCHAR(600) NOT NULL PRIMARY KEY,
+    addr    VARCHAR(60)  NOT NULL UNIQUE,
+    name    VARCHAR(50)  NOT NULL,
+    type    INT          NOT NULL,
+    region  VARCHAR(5)   NOT NULL,
+    lat     VARCHAR(50)  NOT NULL,
+    long    VARCHAR(50)  NOT NULL,
+    created VARCHAR(60)  NOT NULL,
+    master  VARCHAR(60),
+    FOREIGN KEY (region) REFERENCES regions (id),
+    FOREIGN KEY (type) REFERENCES node_types (id)
+);
+
+create table node_credentials
+(
+    node   varchar(600) not null primary key,
+    secret varchar(200) not null,
+    foreign key (node) references nodes (id) on delete cascade
+);
+
+create table events
+(
+    uuid      varchar(100) not null primary key,
+    name      varchar(50)  not null,
+    region    varchar(50)  not null default '',
+    actor     varchar(100) not null default '',
+    action    varchar(100) not null default '',
+    result    varchar(50)  not null default '',
+    err       text         not null default '',
+    ts        bigint       not null,
+    exec_time bigint       not null
+);
+
+INSERT INTO regions (id, name, description) VALUES ('AA001', 'Kinondoni', 'Kinondoni');
+INSERT INTO node_types (id, name) VALUES (1, 'sensor'), (3, 'controller');
+INSERT INTO nodes (id, addr, name, type, region, lat, long, created, master)
+VALUES ('controller', 'AA-AA-AA-AA-AA-AA', 'controller', 3, 'AA001', '0', '0', '2020-01-17T11:24:20Z', NULL),
+       ('sensor', 'BB-BB-BB-BB-BB-BB', 'sensor', 1, 'AA001', '0', '0', '2020-01-17T11:24:20Z', 'controller');
+INSERT INTO node_credentials (node, secret) VALUES ('sensor', 'secret');
+INSERT INTO events (uuid, name, ts, exec_time) VALUES ('event', 'node added', 1, 1);
+`
+
+// TestMigrate opens a database created with an older schema and checks that
+// it is migrated to the current one without losing rows
+func TestMigrate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "registry.db")
+
+	legacy, err := sql.Open("sqlite3", "file:"+path+"?_foreign_keys=on")
+	require.Nil(t, err)
+	_, err = legacy.Exec(legacySchema)
+	require.Nil(t, err)
+	require.Nil(t, legacy.Close())
+
+	db, err := sqlite.Connect(path)
+	require.Nil(t, err)
+
+	ctx := context.Background()
+	require.Nil(t, store.Migrated(ctx, db.DB))
+
+	sensor, err := store.NewNodeRepository(db).Get(ctx, "sensor")
+	require.Nil(t, err)
+	assert.Equal(t, "controller", sensor.Master)
+
+	events, err := store.NewEventStore(db).Pull(ctx)
+	require.Nil(t, err)
+	assert.Len(t, events, 1)
+
+	//the rows referencing the nodes table are kept when it is replaced
+	var secret string
+	require.Nil(t, db.QueryRow("SELECT secret FROM node_credentials WHERE node = $1;", "sensor").Scan(&secret))
+	assert.Equal(t, "secret", secret)
+
+	_, err = db.Exec("DELETE FROM nodes WHERE id = $1;", "sensor")
+	require.Nil(t, err)
+
+	var credentials int
+	require.Nil(t, db.QueryRow("SELECT count(*) FROM node_credentials;").Scan(&credentials))
+	assert.Equal(t, 0, credentials, "credentials of a removed node")
+
+	_, err = db.Exec("UPDATE nodes SET master = $2 WHERE id = $1;", "controller", "missing")
+	assert.NotNil(t, err, "master is not a foreign key")
+
+	_, err = db.Exec("UPDATE regions SET parent = $2 WHERE id = $1;", "AA001", "missing")
+	assert.NotNil(t, err, "parent is not a foreign key")
+
+	require.Nil(t, db.Close())
+
+	//migrations are only run once
+	db, err = sqlite.Connect(path)
+	require.Nil(t, err)
+	defer db.Close()
+
+	var versions int
+	require.Nil(t, db.QueryRow("SELECT count(*) FROM schema_version;").Scan(&versions))
+	assert.Equal(t, sql2.Version, versions)
+}
+
+// TestBackup restores the archive of a database with a row in every table
+// into another one and checks that both hold the same rows
+func TestBackup(t *testing.T) {
+	dir, err := ioutil.TempDir("", "registry")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	src, err := sqlite.Connect(filepath.Join(dir, "src.db"))
+	require.Nil(t, err)
+	defer src.Close()
+
+	dst, err := sqlite.Connect(filepath.Join(dir, "dst.db"))
+	require.Nil(t, err)
+	defer dst.Close()
+
+	ctx := context.Background()
+	now := time.Now()
+	created := now.Format(time.RFC3339)
+
+	node := registry.Node{UUID: "4a4d3bd8-5b47-4f5b-9ec1-bb0b3b2f1b10", Addr: "6F-5E-42-8B-36-D0", Name: "meter", Type: 1,
+		Region: "AA001", Latd: "-6.7735", Long: "39.2395", Created: created, Org: "igrid", Labels: registry.Labels{"site": "lab"}}
+	provisioned := registry.Node{UUID: "4a4d3bd8-5b47-4f5b-9ec1-bb0b3b2f1b11", Addr: "6F-5E-42-8B-36-D1", Name: "meter", Type: 1,
+		Region: "AA001", Latd: "-6.7735", Long: "39.2395", Created: created}
+
+	claims := store.NewClaimRepository(src)
+	campaigns := store.NewCampaignRepository(src)
+	certs := store.NewCertificateRepository(src)
+	keys := store.NewAPIKeyRepository(src)
+
+	steps := []error{
+		store.NewOrganizationRepository(src).Add(ctx, registry.Organization{ID: "igrid", Name: "iGrid", Created: created}),
+		store.NewNodeRepository(src).Add(ctx, node),
+		store.NewEventStore(src).Save(ctx, registry.Event{UUID: "e1", Name: "create_node", Subject: node.UUID, Timestamp: 42}),
+		store.NewFirmwareRepository(src).Add(ctx, registry.Firmware{ID: "fw1", Version: "2.1.0", Type: 1,
+			Checksum: "checksum", Blob: []byte("firmware"), Created: created, Org: "igrid"}),
+		campaigns.Add(ctx, registry.Campaign{ID: "c1", Name: "2.1.0", Firmware: "fw1", Rollout: 50, Created: created, Org: "igrid",
+			Target: registry.CampaignTarget{Region: "AA001", Subregions: true, Labels: registry.Labels{"site": "lab"}}}),
+		campaigns.Enroll(ctx, "c1", []string{node.UUID}, now),
+		store.NewSchemaRepository(src).Add(ctx, registry.Schema{ID: "s1", Kind: registry.SchemaTelemetry, Node: node.UUID,
+			Version: 1, Document: []byte(`{"type":"object"}`), Created: created}),
+		store.NewQuotaRepository(src).Set(ctx, registry.Quota{Scope: registry.QuotaRegion, ID: "AA001", Users: 5,
+			Nodes: registry.NodeLimits{1: 10}}),
+		keys.Add(ctx, registry.APIKey{ID: "k1", Name: "script", Owner: "ball8000us98", Role: int(registry.RegionUser),
+			Region: "AA001", Hash: "key hash", Created: created, Expires: now.Add(time.Hour).Format(time.RFC3339)}),
+		keys.Revoke(ctx, "k1", now),
+		certs.Add(ctx, registry.Certificate{Serial: "01", Node: node.UUID, PEM: "certificate", Issued: created,
+			Expires: now.Add(time.Hour).Format(time.RFC3339), Org: "igrid"}),
+		certs.Revoke(ctx, "01", now),
+		claims.AddCode(ctx, registry.ClaimCode{Code: "AAAA-BBBB", Region: "AA001", Type: 1, Created: created,
+			Expires: now.Add(time.Hour).Format(time.RFC3339)}),
+		claims.Provision(ctx, "AAAA-BBBB", provisioned, "node key hash"),
+		claims.SaveClaim(ctx, registry.Claim{ID: "cl1", Code: "AAAA-BBBB", Addr: provisioned.Addr, Node: provisioned.UUID,
+			Result: registry.ClaimAccepted, Created: created}),
+	}
+	for i, err := range steps {
+		require.Nil(t, err, "step %d", i)
+	}
+
+	want, err := store.NewBackupStore(src).Snapshot(ctx)
+	require.Nil(t, err)
+
+	var archive bytes.Buffer
+	_, err = backup.Write(&archive, want)
+	require.Nil(t, err)
+
+	_, data, err := backup.Read(&archive)
+	require.Nil(t, err)
+
+	//the seed rows are in both databases
+	_, err = store.NewBackupStore(dst).Restore(ctx, data, backup.Skip)
+	require.Nil(t, err)
+
+	got, err := store.NewBackupStore(dst).Snapshot(ctx)
+	require.Nil(t, err)
+
+	w, g := reflect.ValueOf(want), reflect.ValueOf(got)
+	for i := 0; i < w.NumField(); i++ {
+		table := w.Type().Field(i).Name
+		assert.NotEmpty(t, w.Field(i).Interface(), "%s not backed up", table)
+		assert.ElementsMatch(t, w.Field(i).Interface(), g.Field(i).Interface(), "%s not restored", table)
+	}
+
+	//secrets the api does not return are backed up
+	assert.Equal(t, "key hash", got.APIKeys[0].Hash)
+	assert.Equal(t, "certificate", got.Certificates[0].PEM)
+	assert.Equal(t, []byte("firmware"), got.Firmware[0].Blob)
+	assert.Equal(t, []backup.Credentials{{Node: provisioned.UUID, Hash: "node key hash", Created: got.Credentials[0].Created}}, got.Credentials)
+
+	//rows of tables with a composite key are overwritten
+	data.Rollouts[0].Status = registry.RolloutFailed
+	data.Quotas[0].Users = 6
+	_, err = store.NewBackupStore(dst).Restore(ctx, data, backup.Overwrite)
+	require.Nil(t, err)
+
+	got, err = store.NewBackupStore(dst).Snapshot(ctx)
+	require.Nil(t, err)
+	assert.Equal(t, registry.RolloutFailed, got.Rollouts[0].Status)
+	assert.Equal(t, 6, got.Quotas[0].Users)
+}
@@ -0,0 +1,163 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"github.com/piusalfred/registry"
+	"github.com/piusalfred/registry/logger"
+	"github.com/piusalfred/registry/pkg/errors"
+	sql2 "github.com/piusalfred/registry/sql"
+	"log"
+	"os"
+	"time"
+)
+
+var (
+	ErrAPIKeyNotFound = errors.NewKind(errors.NotFound, "api key not found")
+)
+
+type apiKeysRepo struct {
+	db       *DB
+	dbLogger logger.Logger
+}
+
+func NewAPIKeyRepository(db *DB) registry.APIKeyRepository {
+
+	dlog, err := logger.New(os.Stdout, "debug")
+
+	if err != nil {
+		log.Fatal("could not create api keys repository database logger")
+	}
+	return &apiKeysRepo{
+		db:       db,
+		dbLogger: dlog,
+	}
+}
+
+type dbAPIKey struct {
+	ID      string
+	Name    string
+	Owner   string
+	Role    int
+	Region  string
+	Hash    string
+	Created time.Time
+	Expires sql.NullTime
+	Revoked sql.NullTime
+	Org     string
+}
+
+func (k dbAPIKey) toAPIKey() registry.APIKey {
+	key := registry.APIKey{
+		ID:      k.ID,
+		Name:    k.Name,
+		Owner:   k.Owner,
+		Role:    k.Role,
+		Region:  k.Region,
+		Hash:    k.Hash,
+		Org:     k.Org,
+		Created: k.Created.Format(time.RFC3339),
+	}
+
+	if k.Expires.Valid {
+		key.Expires = k.Expires.Time.Format(time.RFC3339)
+	}
+
+	if k.Revoked.Valid {
+		key.Revoked = k.Revoked.Time.Format(time.RFC3339)
+	}
+
+	return key
+}
+
+func (a apiKeysRepo) Add(ctx context.Context, key registry.APIKey) error {
+	created, err := time.Parse(time.RFC3339, key.Created)
+	if err != nil {
+		return err
+	}
+
+	expires := sql.NullTime{}
+	if key.Expires != "" {
+		expires.Time, err = time.Parse(time.RFC3339, key.Expires)
+		if err != nil {
+			return err
+		}
+		expires.Valid = true
+	}
+
+	_, err = a.db.Exec(sql2.APIKeyAddNew,
+		key.ID, key.Name, key.Owner, key.Role, nullString(key.Region), key.Hash, created, expires)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (a apiKeysRepo) Get(ctx context.Context, id string) (registry.APIKey, error) {
+	row := a.db.QueryRow(sql2.APIKeyGet, id, registry.OrgFromContext(ctx))
+	k := dbAPIKey{}
+
+	switch err := row.Scan(&k.ID, &k.Name, &k.Owner, &k.Role, &k.Region,
+		&k.Hash, &k.Created, &k.Expires, &k.Revoked, &k.Org); err {
+
+	case sql.ErrNoRows:
+		return registry.APIKey{}, ErrAPIKeyNotFound
+
+	case nil:
+		return k.toAPIKey(), nil
+
+	default:
+		return registry.APIKey{}, err
+	}
+}
+
+func (a apiKeysRepo) List(ctx context.Context, owner string) ([]registry.APIKey, error) {
+	rows, err := a.db.Query(sql2.APIKeysGetAll, owner, registry.OrgFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []registry.APIKey
+
+	for rows.Next() {
+		k := dbAPIKey{}
+		err := rows.Scan(&k.ID, &k.Name, &k.Owner, &k.Role, &k.Region,
+			&k.Hash, &k.Created, &k.Expires, &k.Revoked, &k.Org)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, k.toAPIKey())
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (a apiKeysRepo) Revoke(ctx context.Context, id string, at time.Time) error {
+	res, err := a.db.Exec(sql2.APIKeyRevoke, id, at, registry.OrgFromContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		if _, err := a.Get(ctx, id); err != nil {
+			return err
+		}
+		return registry.ErrAPIKeyRevoked
+	}
+
+	return nil
+}
@@ -0,0 +1,505 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/piusalfred/registry"
+	"github.com/piusalfred/registry/backup"
+	"github.com/piusalfred/registry/logger"
+	"github.com/piusalfred/registry/pkg/errors"
+	sql2 "github.com/piusalfred/registry/sql"
+	"log"
+	"os"
+	"time"
+)
+
+type backupStore struct {
+	db       *DB
+	dbLogger logger.Logger
+}
+
+func NewBackupStore(db *DB) backup.Store {
+
+	dlog, err := logger.New(os.Stdout, "debug")
+
+	if err != nil {
+		log.Fatal("could not create backup store database logger")
+	}
+	return &backupStore{
+		db:       db,
+		dbLogger: dlog,
+	}
+}
+
+func (b backupStore) Snapshot(ctx context.Context) (data backup.Data, err error) {
+	//all tables are read from the same snapshot of the database
+	tx, err := b.db.BeginTx(ctx, b.db.SnapshotOptions())
+	if err != nil {
+		return backup.Data{}, err
+	}
+	defer tx.Rollback()
+
+	err = each(tx, func(rows *sql.Rows) error {
+		d := dbOrganization{}
+		if err := rows.Scan(&d.ID, &d.Name, &d.Desc, &d.Created); err != nil {
+			return err
+		}
+		data.Organizations = append(data.Organizations, d.toOrganization())
+		return nil
+	}, sql2.OrganizationsGetAll, "")
+	if err != nil {
+		return backup.Data{}, err
+	}
+
+	err = each(tx, func(rows *sql.Rows) error {
+		nodeType, err := scanNodeType(b.db, rows)
+		if err != nil {
+			return err
+		}
+		data.NodeTypes = append(data.NodeTypes, nodeType)
+		return nil
+	}, sql2.NodeTypesGetAll)
+	if err != nil {
+		return backup.Data{}, err
+	}
+
+	err = each(tx, func(rows *sql.Rows) error {
+		r := registry.Region{}
+		if err := rows.Scan(&r.ID, &r.Name, &r.Desc, &r.Org, &r.Boundary, &r.Parent); err != nil {
+			return err
+		}
+		data.Regions = append(data.Regions, r)
+		return nil
+	}, sql2.RegionsSelectAll, "")
+	if err != nil {
+		return backup.Data{}, err
+	}
+
+	err = each(tx, func(rows *sql.Rows) error {
+		u := dbUser{}
+		if err := rows.Scan(&u.ID, &u.Name, &u.Email, &u.Password, &u.Group, &u.Region, &u.Created, &u.Org); err != nil {
+			return err
+		}
+		data.Users = append(data.Users, u.toUser())
+		return nil
+	}, sql2.UsersSelectAll, "")
+	if err != nil {
+		return backup.Data{}, err
+	}
+
+	err = each(tx, func(rows *sql.Rows) error {
+		n := registry.Node{}
+		if err := rows.Scan(&n.UUID, &n.Addr, &n.Name, &n.Type, &n.Region,
+			&n.Latd, &n.Long, &n.Created, &n.Master, &n.Org, &n.Firmware, &n.Labels); err != nil {
+			return err
+		}
+		data.Nodes = append(data.Nodes, n)
+		return nil
+	}, sql2.NodeGetAll, "")
+	if err != nil {
+		return backup.Data{}, err
+	}
+
+	err = each(tx, func(rows *sql.Rows) error {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return err
+		}
+		data.Events = append(data.Events, event)
+		return nil
+	}, sql2.EventsGetAll, "")
+	if err != nil {
+		return backup.Data{}, err
+	}
+
+	err = each(tx, func(rows *sql.Rows) error {
+		fw, err := scanFirmware(rows)
+		if err != nil {
+			return err
+		}
+		data.Firmware = append(data.Firmware, fw)
+		return nil
+	}, sql2.FirmwareBackup)
+	if err != nil {
+		return backup.Data{}, err
+	}
+
+	err = each(tx, func(rows *sql.Rows) error {
+		campaign, err := scanCampaign(rows)
+		if err != nil {
+			return err
+		}
+		data.Campaigns = append(data.Campaigns, campaign)
+		return nil
+	}, sql2.CampaignsGetAll, "")
+	if err != nil {
+		return backup.Data{}, err
+	}
+
+	err = each(tx, func(rows *sql.Rows) error {
+		r := registry.Rollout{}
+		var updated time.Time
+		if err := rows.Scan(&r.Campaign, &r.Node, &r.Status, &r.Err, &updated); err != nil {
+			return err
+		}
+		r.Updated = updated.Format(time.RFC3339)
+		data.Rollouts = append(data.Rollouts, r)
+		return nil
+	}, sql2.RolloutsBackup)
+	if err != nil {
+		return backup.Data{}, err
+	}
+
+	err = each(tx, func(rows *sql.Rows) error {
+		schema, err := scanSchema(rows)
+		if err != nil {
+			return err
+		}
+		data.Schemas = append(data.Schemas, schema)
+		return nil
+	}, sql2.SchemasGetAll, "")
+	if err != nil {
+		return backup.Data{}, err
+	}
+
+	err = each(tx, func(rows *sql.Rows) error {
+		quota, err := scanQuota(rows)
+		if err != nil {
+			return err
+		}
+		data.Quotas = append(data.Quotas, quota)
+		return nil
+	}, sql2.QuotasGetAll, "")
+	if err != nil {
+		return backup.Data{}, err
+	}
+
+	err = each(tx, func(rows *sql.Rows) error {
+		k := dbAPIKey{}
+		if err := rows.Scan(&k.ID, &k.Name, &k.Owner, &k.Role, &k.Region,
+			&k.Hash, &k.Created, &k.Expires, &k.Revoked, &k.Org); err != nil {
+			return err
+		}
+		key := k.toAPIKey()
+		data.APIKeys = append(data.APIKeys, backup.APIKey{APIKey: key, Hash: key.Hash})
+		return nil
+	}, sql2.APIKeysBackup)
+	if err != nil {
+		return backup.Data{}, err
+	}
+
+	err = each(tx, func(rows *sql.Rows) error {
+		d := dbCertificate{}
+		if err := rows.Scan(&d.Serial, &d.Node, &d.PEM, &d.Issued, &d.Expires, &d.Revoked, &d.Org); err != nil {
+			return err
+		}
+		data.Certificates = append(data.Certificates, d.toCertificate())
+		return nil
+	}, sql2.CertificatesBackup)
+	if err != nil {
+		return backup.Data{}, err
+	}
+
+	err = each(tx, func(rows *sql.Rows) error {
+		d := dbClaimCode{}
+		if err := rows.Scan(&d.Code, &d.Region, &d.Type, &d.Created, &d.Expires, &d.Node); err != nil {
+			return err
+		}
+		data.ClaimCodes = append(data.ClaimCodes, d.toClaimCode())
+		return nil
+	}, sql2.ClaimCodesGetAll, "")
+	if err != nil {
+		return backup.Data{}, err
+	}
+
+	err = each(tx, func(rows *sql.Rows) error {
+		var (
+			claim   registry.Claim
+			result  string
+			created time.Time
+		)
+		if err := rows.Scan(&claim.ID, &claim.Code, &claim.Addr, &claim.Node, &result, &claim.Err, &created); err != nil {
+			return err
+		}
+		claim.Result = registry.ClaimResult(result)
+		claim.Created = created.Format(time.RFC3339)
+		data.Claims = append(data.Claims, claim)
+		return nil
+	}, sql2.ClaimsGetAll, "")
+	if err != nil {
+		return backup.Data{}, err
+	}
+
+	err = each(tx, func(rows *sql.Rows) error {
+		c := backup.Credentials{}
+		var created time.Time
+		if err := rows.Scan(&c.Node, &c.Hash, &created); err != nil {
+			return err
+		}
+		c.Created = created.Format(time.RFC3339)
+		data.Credentials = append(data.Credentials, c)
+		return nil
+	}, sql2.CredentialsBackup)
+	if err != nil {
+		return backup.Data{}, err
+	}
+
+	return data, nil
+}
+
+func (b backupStore) Restore(ctx context.Context, data backup.Data, policy backup.Policy) ([]backup.Result, error) {
+	tables, err := backupRows(data)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := b.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var results []backup.Result
+
+	for _, t := range tables {
+		stmt, err := tx.PrepareContext(ctx, backup.InsertQuery(t.name, t.columns, t.keys, policy))
+		if err != nil {
+			return nil, err
+		}
+
+		result := backup.Result{Table: t.name}
+
+		for _, args := range t.rows {
+			res, err := stmt.ExecContext(ctx, args...)
+			if err != nil {
+				stmt.Close()
+				return nil, errors.Wrap(errors.New("could not restore "+t.name), err)
+			}
+
+			if n, _ := res.RowsAffected(); n == 0 {
+				result.Skipped++
+			} else {
+				result.Restored++
+			}
+		}
+
+		stmt.Close()
+		results = append(results, result)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// backupTable are the rows of a table to restore, in the order of columns,
+// the first keys columns are the primary key of the table
+type backupTable struct {
+	name    string
+	columns []string
+	keys    int
+	rows    [][]interface{}
+}
+
+// backupRows converts data to the rows of each table in the order they are
+// restored, referenced tables first.
+func backupRows(data backup.Data) ([]backupTable, error) {
+	orgs := backupTable{name: "organizations", columns: []string{"id", "name", "description", "created"}, keys: 1}
+	for _, o := range data.Organizations {
+		created, err := time.Parse(time.RFC3339, o.Created)
+		if err != nil {
+			return nil, err
+		}
+		orgs.rows = append(orgs.rows, []interface{}{o.ID, o.Name, nullString(o.Desc), created})
+	}
+
+	types := backupTable{name: "node_types", columns: []string{"id", "name", "description", "capabilities"}, keys: 1}
+	for _, t := range data.NodeTypes {
+		types.rows = append(types.rows, []interface{}{t.ID, t.Name, t.Desc,
+			capabilitiesToStrings(t.Capabilities)})
+	}
+
+	regions := backupTable{name: "regions", columns: []string{"id", "name", "description", "org", "boundary", "parent"}, keys: 1}
+	//parents are restored before their sub-regions
+	for _, r := range registry.NewRegionTree(data.Regions).Sorted() {
+		regions.rows = append(regions.rows, []interface{}{r.ID, r.Name, r.Desc, nullString(r.Org), r.Boundary, nullString(r.Parent)})
+	}
+
+	users := backupTable{name: "users", columns: []string{"id", "name", "email", "password", "ugroup", "region", "created", "org"}, keys: 1}
+	for _, user := range data.Users {
+		u, err := fromUser(user)
+		if err != nil {
+			return nil, err
+		}
+		users.rows = append(users.rows, []interface{}{u.ID, u.Name, u.Email, u.Password,
+			u.Group, u.Region, u.Created, nullString(u.Org)})
+	}
+
+	nodes := backupTable{name: "nodes", columns: []string{"id", "addr", "name", "type", "region", "lat", "long", "created", "master", "org", "firmware", "labels"}, keys: 1}
+	//masters are restored before the nodes they control
+	for _, n := range registry.MastersFirst(data.Nodes) {
+		nodes.rows = append(nodes.rows, []interface{}{n.UUID, n.Addr, n.Name, n.Type, n.Region,
+			n.Latd, n.Long, n.Created, nullString(n.Master), nullString(n.Org), nullString(n.Firmware), n.Labels})
+	}
+
+	events := backupTable{name: "events", columns: []string{"uuid", "name", "region", "actor", "action", "result", "err", "ts", "exec_time", "subject", "org"}, keys: 1}
+	for _, e := range data.Events {
+		events.rows = append(events.rows, []interface{}{e.UUID, e.Name, e.Region, e.Actor,
+			e.Action, e.Result, e.Err, int64(e.Timestamp), int64(e.ExecTime), e.Subject, e.Org})
+	}
+
+	firmware := backupTable{name: "firmware", columns: []string{"id", "version", "type", "checksum", "url", "blob", "created", "org"}, keys: 1}
+	for _, fw := range data.Firmware {
+		created, err := time.Parse(time.RFC3339, fw.Created)
+		if err != nil {
+			return nil, err
+		}
+		firmware.rows = append(firmware.rows, []interface{}{fw.ID, fw.Version, fw.Type, fw.Checksum,
+			nullString(fw.URL), fw.Blob, created, nullString(fw.Org)})
+	}
+
+	campaigns := backupTable{name: "campaigns", columns: []string{"id", "name", "firmware", "region", "subregions", "labels", "rollout", "created", "org"}, keys: 1}
+	for _, c := range data.Campaigns {
+		created, err := time.Parse(time.RFC3339, c.Created)
+		if err != nil {
+			return nil, err
+		}
+		campaigns.rows = append(campaigns.rows, []interface{}{c.ID, c.Name, c.Firmware, nullString(c.Target.Region),
+			c.Target.Subregions, c.Target.Labels, c.Rollout, created, nullString(c.Org)})
+	}
+
+	rollouts := backupTable{name: "rollouts", columns: []string{"campaign", "node", "status", "err", "updated"}, keys: 2}
+	for _, r := range data.Rollouts {
+		updated, err := time.Parse(time.RFC3339, r.Updated)
+		if err != nil {
+			return nil, err
+		}
+		rollouts.rows = append(rollouts.rows, []interface{}{r.Campaign, r.Node, string(r.Status), r.Err, updated})
+	}
+
+	schemas := backupTable{name: "schemas", columns: []string{"id", "kind", "type", "node", "version", "document", "created", "org"}, keys: 1}
+	for _, sc := range data.Schemas {
+		created, err := time.Parse(time.RFC3339, sc.Created)
+		if err != nil {
+			return nil, err
+		}
+		//documents are indented with the rest of the archive
+		var document bytes.Buffer
+		if err := json.Compact(&document, sc.Document); err != nil {
+			return nil, err
+		}
+		typ := sql.NullInt64{Int64: int64(sc.Type), Valid: sc.Type != 0}
+		schemas.rows = append(schemas.rows, []interface{}{sc.ID, string(sc.Kind), typ, nullString(sc.Node),
+			sc.Version, document.String(), created, nullString(sc.Org)})
+	}
+
+	quotas := backupTable{name: "quotas", columns: []string{"scope", "id", "users", "nodes", "org"}, keys: 2}
+	for _, q := range data.Quotas {
+		quotas.rows = append(quotas.rows, []interface{}{string(q.Scope), q.ID, q.Users, q.Nodes, nullString(q.Org)})
+	}
+
+	keys := backupTable{name: "api_keys", columns: []string{"id", "name", "owner", "role", "region", "hash", "created", "expires", "revoked"}, keys: 1}
+	for _, k := range data.APIKeys {
+		created, err := time.Parse(time.RFC3339, k.Created)
+		if err != nil {
+			return nil, err
+		}
+		expires, err := nullTime(k.Expires)
+		if err != nil {
+			return nil, err
+		}
+		revoked, err := nullTime(k.Revoked)
+		if err != nil {
+			return nil, err
+		}
+		keys.rows = append(keys.rows, []interface{}{k.ID, k.Name, k.Owner, k.Role, nullString(k.Region),
+			k.Hash, created, expires, revoked})
+	}
+
+	certs := backupTable{name: "certificates", columns: []string{"serial", "node", "pem", "issued", "expires", "revoked", "org"}, keys: 1}
+	for _, c := range data.Certificates {
+		issued, err := time.Parse(time.RFC3339, c.Issued)
+		if err != nil {
+			return nil, err
+		}
+		expires, err := time.Parse(time.RFC3339, c.Expires)
+		if err != nil {
+			return nil, err
+		}
+		revoked, err := nullTime(c.Revoked)
+		if err != nil {
+			return nil, err
+		}
+		certs.rows = append(certs.rows, []interface{}{c.Serial, c.Node, c.PEM, issued, expires, revoked, nullString(c.Org)})
+	}
+
+	codes := backupTable{name: "claim_codes", columns: []string{"code", "region", "type", "created", "expires", "node"}, keys: 1}
+	for _, c := range data.ClaimCodes {
+		created, err := time.Parse(time.RFC3339, c.Created)
+		if err != nil {
+			return nil, err
+		}
+		expires, err := time.Parse(time.RFC3339, c.Expires)
+		if err != nil {
+			return nil, err
+		}
+		codes.rows = append(codes.rows, []interface{}{c.Code, c.Region, c.Type, created, expires, nullString(c.Node)})
+	}
+
+	claims := backupTable{name: "claims", columns: []string{"id", "code", "addr", "node", "result", "err", "created"}, keys: 1}
+	for _, c := range data.Claims {
+		created, err := time.Parse(time.RFC3339, c.Created)
+		if err != nil {
+			return nil, err
+		}
+		claims.rows = append(claims.rows, []interface{}{c.ID, c.Code, c.Addr, nullString(c.Node),
+			string(c.Result), nullString(c.Err), created})
+	}
+
+	creds := backupTable{name: "node_credentials", columns: []string{"node", "key", "created"}, keys: 1}
+	for _, c := range data.Credentials {
+		created, err := time.Parse(time.RFC3339, c.Created)
+		if err != nil {
+			return nil, err
+		}
+		creds.rows = append(creds.rows, []interface{}{c.Node, c.Hash, created})
+	}
+
+	return []backupTable{orgs, types, regions, users, nodes, events, firmware, campaigns, rollouts,
+		schemas, quotas, keys, certs, codes, claims, creds}, nil
+}
+
+// nullTime parses an optional RFC3339 time
+func nullTime(s string) (sql.NullTime, error) {
+	if s == "" {
+		return sql.NullTime{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return sql.NullTime{}, err
+	}
+
+	return sql.NullTime{Time: t, Valid: true}, nil
+}
+
+// each calls fn for every row returned by query
+func each(tx *Tx, fn func(rows *sql.Rows) error, query string, args ...interface{}) error {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := fn(rows); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,165 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"github.com/piusalfred/registry"
+	"github.com/piusalfred/registry/logger"
+	"github.com/piusalfred/registry/pkg/errors"
+	sql2 "github.com/piusalfred/registry/sql"
+	"log"
+	"os"
+	"time"
+)
+
+var (
+	ErrCertificateNotFound = errors.NewKind(errors.NotFound, "certificate not found")
+)
+
+type certsRepo struct {
+	db       *DB
+	dbLogger logger.Logger
+}
+
+func NewCertificateRepository(db *DB) registry.CertificateRepository {
+
+	dlog, err := logger.New(os.Stdout, "debug")
+
+	if err != nil {
+		log.Fatal("could not create certificates repository database logger")
+	}
+	return &certsRepo{
+		db:       db,
+		dbLogger: dlog,
+	}
+}
+
+type dbCertificate struct {
+	Serial  string
+	Node    string
+	PEM     string
+	Issued  time.Time
+	Expires time.Time
+	Revoked sql.NullTime
+	Org     string
+}
+
+func (c dbCertificate) toCertificate() registry.Certificate {
+	cert := registry.Certificate{
+		Serial:  c.Serial,
+		Node:    c.Node,
+		PEM:     c.PEM,
+		Issued:  c.Issued.Format(time.RFC3339),
+		Expires: c.Expires.Format(time.RFC3339),
+		Org:     c.Org,
+	}
+
+	if c.Revoked.Valid {
+		cert.Revoked = c.Revoked.Time.Format(time.RFC3339)
+	}
+
+	return cert
+}
+
+func (c certsRepo) Add(ctx context.Context, cert registry.Certificate) error {
+	issued, err := time.Parse(time.RFC3339, cert.Issued)
+	if err != nil {
+		return err
+	}
+
+	expires, err := time.Parse(time.RFC3339, cert.Expires)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.db.Exec(sql2.CertificateAddNew,
+		cert.Serial, cert.Node, cert.PEM, issued, expires, nullString(cert.Org))
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c certsRepo) Get(ctx context.Context, serial string) (registry.Certificate, error) {
+	row := c.db.QueryRow(sql2.CertificateGet, serial, registry.OrgFromContext(ctx))
+	d := dbCertificate{}
+
+	switch err := row.Scan(&d.Serial, &d.Node, &d.PEM, &d.Issued, &d.Expires, &d.Revoked, &d.Org); err {
+
+	case sql.ErrNoRows:
+		return registry.Certificate{}, ErrCertificateNotFound
+
+	case nil:
+		return d.toCertificate(), nil
+
+	default:
+		return registry.Certificate{}, err
+	}
+}
+
+func (c certsRepo) List(ctx context.Context) ([]registry.Certificate, error) {
+	return c.query(sql2.CertificatesGetAll, registry.OrgFromContext(ctx))
+}
+
+func (c certsRepo) Revoke(ctx context.Context, serial string, at time.Time) error {
+	res, err := c.db.Exec(sql2.CertificateRevoke, serial, at, registry.OrgFromContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		if _, err := c.Get(ctx, serial); err != nil {
+			return err
+		}
+		return registry.ErrCertificateRevoked
+	}
+
+	return nil
+}
+
+func (c certsRepo) RevokeNode(ctx context.Context, node string, at time.Time) error {
+	_, err := c.db.Exec(sql2.CertificatesRevokeAll, node, at, registry.OrgFromContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c certsRepo) Revoked(ctx context.Context) ([]registry.Certificate, error) {
+	return c.query(sql2.CertificatesRevoked, registry.OrgFromContext(ctx), time.Now())
+}
+
+func (c certsRepo) query(query string, args ...interface{}) ([]registry.Certificate, error) {
+	rows, err := c.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var certs []registry.Certificate
+
+	for rows.Next() {
+		d := dbCertificate{}
+		err := rows.Scan(&d.Serial, &d.Node, &d.PEM, &d.Issued, &d.Expires, &d.Revoked, &d.Org)
+		if err != nil {
+			return nil, err
+		}
+
+		certs = append(certs, d.toCertificate())
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return certs, nil
+}
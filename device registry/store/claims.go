@@ -0,0 +1,223 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"github.com/piusalfred/registry"
+	"github.com/piusalfred/registry/logger"
+	"github.com/piusalfred/registry/pkg/errors"
+	sql2 "github.com/piusalfred/registry/sql"
+	"log"
+	"os"
+	"time"
+)
+
+var (
+	ErrClaimCodeNotFound   = errors.NewKind(errors.NotFound, "claim code not found")
+	ErrCredentialsNotFound = errors.NewKind(errors.NotFound, "node credentials not found")
+)
+
+type claimsRepo struct {
+	db       *DB
+	dbLogger logger.Logger
+}
+
+func NewClaimRepository(db *DB) registry.ClaimRepository {
+
+	dlog, err := logger.New(os.Stdout, "debug")
+
+	if err != nil {
+		log.Fatal("could not create claims repository database logger")
+	}
+	return &claimsRepo{
+		db:       db,
+		dbLogger: dlog,
+	}
+}
+
+type dbClaimCode struct {
+	Code    string
+	Region  string
+	Type    int
+	Created time.Time
+	Expires time.Time
+	Node    string
+}
+
+func (c dbClaimCode) toClaimCode() registry.ClaimCode {
+	return registry.ClaimCode{
+		Code:    c.Code,
+		Region:  c.Region,
+		Type:    c.Type,
+		Created: c.Created.Format(time.RFC3339),
+		Expires: c.Expires.Format(time.RFC3339),
+		Node:    c.Node,
+	}
+}
+
+func (c claimsRepo) AddCode(ctx context.Context, code registry.ClaimCode) error {
+	created, err := time.Parse(time.RFC3339, code.Created)
+	if err != nil {
+		return err
+	}
+
+	expires, err := time.Parse(time.RFC3339, code.Expires)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.db.Exec(sql2.ClaimCodeAddNew,
+		code.Code, code.Region, code.Type, created, expires)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c claimsRepo) GetCode(ctx context.Context, code string) (registry.ClaimCode, error) {
+	row := c.db.QueryRow(sql2.ClaimCodeGet, code)
+	dCode := dbClaimCode{}
+
+	switch err := row.Scan(
+		&dCode.Code, &dCode.Region, &dCode.Type,
+		&dCode.Created, &dCode.Expires, &dCode.Node); err {
+
+	case sql.ErrNoRows:
+		return registry.ClaimCode{}, ErrClaimCodeNotFound
+
+	case nil:
+		return dCode.toClaimCode(), nil
+
+	default:
+		return registry.ClaimCode{}, err
+	}
+}
+
+func (c claimsRepo) ListCodes(ctx context.Context) ([]registry.ClaimCode, error) {
+	rows, err := c.db.Query(sql2.ClaimCodesGetAll, registry.OrgFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var codes []registry.ClaimCode
+
+	for rows.Next() {
+		d := dbClaimCode{}
+		err := rows.Scan(&d.Code, &d.Region, &d.Type, &d.Created, &d.Expires, &d.Node)
+		if err != nil {
+			return nil, err
+		}
+
+		codes = append(codes, d.toClaimCode())
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func (c claimsRepo) Provision(ctx context.Context, code string, node registry.Node, keyHash string) error {
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, sql2.ClaimCodeRedeem, code, node.UUID, time.Now())
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return registry.ErrClaimCodeUsed
+	}
+
+	if err = addNode(ctx, tx, node); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, sql2.CredentialsSave, node.UUID, keyHash, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (c claimsRepo) SaveClaim(ctx context.Context, claim registry.Claim) error {
+	created, err := time.Parse(time.RFC3339, claim.Created)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.db.Exec(sql2.ClaimAddNew,
+		claim.ID, claim.Code, claim.Addr, nullString(claim.Node),
+		string(claim.Result), nullString(claim.Err), created)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c claimsRepo) ListClaims(ctx context.Context) ([]registry.Claim, error) {
+	rows, err := c.db.Query(sql2.ClaimsGetAll, registry.OrgFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var claims []registry.Claim
+
+	for rows.Next() {
+		var (
+			claim   registry.Claim
+			result  string
+			created time.Time
+		)
+
+		err := rows.Scan(&claim.ID, &claim.Code, &claim.Addr, &claim.Node,
+			&result, &claim.Err, &created)
+		if err != nil {
+			return nil, err
+		}
+
+		claim.Result = registry.ClaimResult(result)
+		claim.Created = created.Format(time.RFC3339)
+		claims = append(claims, claim)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func (c claimsRepo) Credentials(ctx context.Context, node string) (string, error) {
+	var hash string
+
+	switch err := c.db.QueryRowContext(ctx, sql2.CredentialsGet, node).Scan(&hash); err {
+	case sql.ErrNoRows:
+		return "", ErrCredentialsNotFound
+
+	case nil:
+		return hash, nil
+
+	default:
+		return "", err
+	}
+}
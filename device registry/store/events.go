@@ -0,0 +1,109 @@
+package store
+
+import (
+	"context"
+	"github.com/piusalfred/registry"
+	"github.com/piusalfred/registry/logger"
+	sql2 "github.com/piusalfred/registry/sql"
+	"log"
+	"os"
+	"time"
+)
+
+type eventStore struct {
+	db       *DB
+	dbLogger logger.Logger
+}
+
+func NewEventStore(db *DB) registry.EventStore {
+
+	dlog, err := logger.New(os.Stdout, "debug")
+
+	if err != nil {
+		log.Fatal("could not create event store database logger")
+	}
+	return &eventStore{
+		db:       db,
+		dbLogger: dlog,
+	}
+}
+
+func (e eventStore) Save(ctx context.Context, event registry.Event) error {
+	_, err := e.db.Exec(sql2.EventAddNew,
+		event.UUID, event.Name, event.Region, event.Actor, event.Action,
+		event.Result, event.Err, int64(event.Timestamp), int64(event.ExecTime), event.Subject, event.Org)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (e eventStore) Pull(ctx context.Context) ([]registry.Event, error) {
+	return e.query(sql2.EventsGetAll, registry.OrgFromContext(ctx))
+}
+
+func (e eventStore) Between(ctx context.Context, start time.Duration, end time.Duration) ([]registry.Event, error) {
+	return e.query(sql2.EventsBetween, int64(start), int64(end), registry.OrgFromContext(ctx))
+}
+
+func (e eventStore) Before(ctx context.Context, end time.Duration) ([]registry.Event, error) {
+	return e.query(sql2.EventsBefore, int64(end), registry.OrgFromContext(ctx))
+}
+
+func (e eventStore) After(ctx context.Context, start time.Duration) ([]registry.Event, error) {
+	return e.query(sql2.EventsAfter, int64(start), registry.OrgFromContext(ctx))
+}
+
+func (e eventStore) ByID(ctx context.Context, id string) ([]registry.Event, error) {
+	return e.query(sql2.EventsByID, id, registry.OrgFromContext(ctx))
+}
+
+func (e eventStore) ByEventName(ctx context.Context, name registry.EventName) ([]registry.Event, error) {
+	return e.query(sql2.EventsByName, name.String(), registry.OrgFromContext(ctx))
+}
+
+func (e eventStore) query(query string, args ...interface{}) ([]registry.Event, error) {
+	rows, err := e.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []registry.Event
+
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, event)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func scanEvent(row scanner) (registry.Event, error) {
+	var (
+		event    registry.Event
+		ts, exec int64
+	)
+
+	err := row.Scan(&event.UUID, &event.Name, &event.Region, &event.Actor,
+		&event.Action, &event.Result, &event.Err, &ts, &exec, &event.Subject, &event.Org)
+	if err != nil {
+		return registry.Event{}, err
+	}
+
+	event.Timestamp = time.Duration(ts)
+	event.ExecTime = time.Duration(exec)
+
+	return event, nil
+}
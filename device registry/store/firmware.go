@@ -0,0 +1,273 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"github.com/piusalfred/registry"
+	"github.com/piusalfred/registry/logger"
+	"github.com/piusalfred/registry/pkg/errors"
+	sql2 "github.com/piusalfred/registry/sql"
+	"log"
+	"os"
+	"time"
+)
+
+var (
+	ErrFirmwareNotFound = errors.NewKind(errors.NotFound, "firmware not found")
+	ErrCampaignNotFound = errors.NewKind(errors.NotFound, "campaign not found")
+	ErrRolloutNotFound  = errors.NewKind(errors.NotFound, "node is not enrolled in the campaign")
+)
+
+type firmwareRepo struct {
+	db       *DB
+	dbLogger logger.Logger
+}
+
+func NewFirmwareRepository(db *DB) registry.FirmwareRepository {
+
+	dlog, err := logger.New(os.Stdout, "debug")
+
+	if err != nil {
+		log.Fatal("could not create firmware repository database logger")
+	}
+	return &firmwareRepo{
+		db:       db,
+		dbLogger: dlog,
+	}
+}
+
+func (f firmwareRepo) Add(ctx context.Context, fw registry.Firmware) error {
+	created, err := time.Parse(time.RFC3339, fw.Created)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.db.Exec(sql2.FirmwareAddNew, fw.ID, fw.Version, fw.Type, fw.Checksum,
+		nullString(fw.URL), fw.Blob, created, nullString(fw.Org))
+
+	return err
+}
+
+func (f firmwareRepo) Get(ctx context.Context, id string) (registry.Firmware, error) {
+	row := f.db.QueryRow(sql2.FirmwareGet, id, registry.OrgFromContext(ctx))
+
+	switch fw, err := scanFirmware(row); err {
+	case sql.ErrNoRows:
+		return registry.Firmware{}, ErrFirmwareNotFound
+
+	default:
+		return fw, err
+	}
+}
+
+func (f firmwareRepo) List(ctx context.Context) ([]registry.Firmware, error) {
+	rows, err := f.db.Query(sql2.FirmwareGetAll, registry.OrgFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var releases []registry.Firmware
+
+	for rows.Next() {
+		fw, err := scanFirmware(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		releases = append(releases, fw)
+	}
+
+	return releases, rows.Err()
+}
+
+func (f firmwareRepo) Delete(ctx context.Context, id string) error {
+	res, err := f.db.Exec(sql2.FirmwareDelete, id, registry.OrgFromContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrFirmwareNotFound
+	}
+
+	return nil
+}
+
+func scanFirmware(row scanner) (registry.Firmware, error) {
+	fw := registry.Firmware{}
+	var created time.Time
+
+	err := row.Scan(&fw.ID, &fw.Version, &fw.Type, &fw.Checksum, &fw.URL, &fw.Blob, &created, &fw.Org)
+	if err != nil {
+		return registry.Firmware{}, err
+	}
+
+	fw.Created = created.Format(time.RFC3339)
+	return fw, nil
+}
+
+type campaignsRepo struct {
+	db       *DB
+	dbLogger logger.Logger
+}
+
+func NewCampaignRepository(db *DB) registry.CampaignRepository {
+
+	dlog, err := logger.New(os.Stdout, "debug")
+
+	if err != nil {
+		log.Fatal("could not create campaigns repository database logger")
+	}
+	return &campaignsRepo{
+		db:       db,
+		dbLogger: dlog,
+	}
+}
+
+func (c campaignsRepo) Add(ctx context.Context, campaign registry.Campaign) error {
+	created, err := time.Parse(time.RFC3339, campaign.Created)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.db.Exec(sql2.CampaignAddNew, campaign.ID, campaign.Name, campaign.Firmware,
+		nullString(campaign.Target.Region), campaign.Target.Subregions, campaign.Target.Labels,
+		campaign.Rollout, created, nullString(campaign.Org))
+
+	return err
+}
+
+func (c campaignsRepo) Get(ctx context.Context, id string) (registry.Campaign, error) {
+	row := c.db.QueryRow(sql2.CampaignGet, id, registry.OrgFromContext(ctx))
+
+	switch campaign, err := scanCampaign(row); err {
+	case sql.ErrNoRows:
+		return registry.Campaign{}, ErrCampaignNotFound
+
+	default:
+		return campaign, err
+	}
+}
+
+func (c campaignsRepo) List(ctx context.Context) ([]registry.Campaign, error) {
+	rows, err := c.db.Query(sql2.CampaignsGetAll, registry.OrgFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var campaigns []registry.Campaign
+
+	for rows.Next() {
+		campaign, err := scanCampaign(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		campaigns = append(campaigns, campaign)
+	}
+
+	return campaigns, rows.Err()
+}
+
+func (c campaignsRepo) SetRollout(ctx context.Context, id string, percent int) error {
+	res, err := c.db.Exec(sql2.CampaignSetRollout, id, percent, registry.OrgFromContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrCampaignNotFound
+	}
+
+	return nil
+}
+
+func (c campaignsRepo) Enroll(ctx context.Context, campaign string, nodes []string, at time.Time) error {
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, node := range nodes {
+		_, err = tx.Exec(sql2.RolloutAddNew, campaign, node, string(registry.RolloutPending), at)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (c campaignsRepo) Rollouts(ctx context.Context, campaign string) ([]registry.Rollout, error) {
+	rows, err := c.db.Query(sql2.RolloutsGetAll, campaign, registry.OrgFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rollouts []registry.Rollout
+
+	for rows.Next() {
+		r := registry.Rollout{}
+		var updated time.Time
+		if err := rows.Scan(&r.Campaign, &r.Node, &r.Status, &r.Err, &updated); err != nil {
+			return nil, err
+		}
+
+		r.Updated = updated.Format(time.RFC3339)
+		rollouts = append(rollouts, r)
+	}
+
+	return rollouts, rows.Err()
+}
+
+func (c campaignsRepo) UpdateRollout(ctx context.Context, rollout registry.Rollout) error {
+	updated, err := time.Parse(time.RFC3339, rollout.Updated)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.db.Exec(sql2.RolloutUpdate, rollout.Campaign, rollout.Node, string(rollout.Status),
+		rollout.Err, updated, registry.OrgFromContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrRolloutNotFound
+	}
+
+	return nil
+}
+
+func scanCampaign(row scanner) (registry.Campaign, error) {
+	campaign := registry.Campaign{}
+	var created time.Time
+
+	err := row.Scan(&campaign.ID, &campaign.Name, &campaign.Firmware, &campaign.Target.Region,
+		&campaign.Target.Subregions, &campaign.Target.Labels, &campaign.Rollout, &created, &campaign.Org)
+	if err != nil {
+		return registry.Campaign{}, err
+	}
+
+	campaign.Created = created.Format(time.RFC3339)
+	return campaign, nil
+}
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"github.com/piusalfred/registry"
+	"github.com/piusalfred/registry/logger"
+	sql2 "github.com/piusalfred/registry/sql"
+	"log"
+	"os"
+	"time"
+)
+
+type idempotencyRepo struct {
+	db       *DB
+	dbLogger logger.Logger
+}
+
+func NewIdempotencyRepository(db *DB) registry.IdempotencyRepository {
+
+	dlog, err := logger.New(os.Stdout, "debug")
+
+	if err != nil {
+		log.Fatal("could not create idempotency repository database logger")
+	}
+	return &idempotencyRepo{
+		db:       db,
+		dbLogger: dlog,
+	}
+}
+
+func (i idempotencyRepo) Reserve(ctx context.Context, req registry.IdempotentRequest, expired time.Time) (registry.IdempotentRequest, bool, error) {
+	//an expired key is taken over by the same statement so that two
+	//retries cannot both reserve it
+	res, err := i.db.ExecContext(ctx, sql2.IdempotencyReserve, req.Key, req.Hash, req.Created, expired)
+	if err != nil {
+		return registry.IdempotentRequest{}, false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return registry.IdempotentRequest{}, false, err
+	}
+
+	if n == 1 {
+		return req, true, nil
+	}
+
+	var recorded registry.IdempotentRequest
+	err = i.db.QueryRowContext(ctx, sql2.IdempotencyGet, req.Key).Scan(&recorded.Key, &recorded.Hash,
+		&recorded.Status, &recorded.ContentType, &recorded.Body, &recorded.Created)
+
+	//the key was released between the insert and the select
+	if err == sql.ErrNoRows {
+		return i.Reserve(ctx, req, expired)
+	}
+
+	if err != nil {
+		return registry.IdempotentRequest{}, false, err
+	}
+
+	return recorded, false, nil
+}
+
+func (i idempotencyRepo) Complete(ctx context.Context, req registry.IdempotentRequest) error {
+	_, err := i.db.ExecContext(ctx, sql2.IdempotencyComplete, req.Key, req.Status, req.ContentType, req.Body)
+	return err
+}
+
+func (i idempotencyRepo) Release(ctx context.Context, key string) error {
+	_, err := i.db.ExecContext(ctx, sql2.IdempotencyRelease, key)
+	return err
+}
+
+func (i idempotencyRepo) Purge(ctx context.Context, before time.Time) error {
+	_, err := i.db.ExecContext(ctx, sql2.IdempotencyPurge, before)
+	return err
+}
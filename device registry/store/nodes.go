@@ -0,0 +1,209 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"github.com/piusalfred/registry"
+	"github.com/piusalfred/registry/logger"
+	"github.com/piusalfred/registry/pkg/errors"
+	sql2 "github.com/piusalfred/registry/sql"
+	"log"
+	"os"
+)
+
+var (
+	ErrNodeNotFound = errors.NewKind(errors.NotFound, "node not found")
+)
+
+type nodesRepo struct {
+	db       *DB
+	dbLogger logger.Logger
+}
+
+func NewNodeRepository(db *DB) registry.NodeRepository {
+
+	dlog, err := logger.New(os.Stdout, "debug")
+
+	if err != nil {
+		log.Fatal("could not create nodes repository database logger")
+	}
+	return &nodesRepo{
+		db:       db,
+		dbLogger: dlog,
+	}
+}
+
+func (nodes nodesRepo) Get(ctx context.Context, id string) (registry.Node, error) {
+
+	row := nodes.db.QueryRow(sql2.NodeGetById, id, registry.OrgFromContext(ctx))
+
+	switch node, err := scanNode(row); err {
+
+	case sql.ErrNoRows:
+		return registry.Node{}, ErrNodeNotFound
+
+	case nil:
+		return node, nil
+
+	default:
+		return registry.Node{}, err
+	}
+}
+
+func (nodes nodesRepo) Add(ctx context.Context, node registry.Node) error {
+	tx, err := nodes.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = addNode(ctx, tx, node); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// addNode inserts node once the quotas of its region and organization
+// allow it, it is shared with the claims repository that adds the nodes of
+// provisioned devices in the transaction redeeming their claim code
+func addNode(ctx context.Context, tx *Tx, node registry.Node) error {
+	if err := checkQuota(ctx, tx, node.Region, node.Org, 0, node.Type); err != nil {
+		return err
+	}
+
+	_, err := tx.ExecContext(ctx, sql2.NodeAddNew,
+		node.UUID,
+		node.Addr,
+		node.Name,
+		node.Type,
+		node.Region,
+		node.Latd,
+		node.Long,
+		node.Created,
+		nullString(node.Master),
+		nullString(node.Org),
+		nullString(node.Firmware),
+		node.Labels,
+	)
+	if tx.db.ForeignKeyViolation(err) {
+		return registry.ErrInvalidReference
+	}
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (nodes nodesRepo) Delete(ctx context.Context, id string) error {
+
+	_, err := nodes.db.Exec(sql2.NodeDelete, id, registry.OrgFromContext(ctx))
+	if nodes.db.ForeignKeyViolation(err) {
+		return registry.ErrMasterInUse
+	}
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (nodes nodesRepo) List(ctx context.Context) ([]registry.Node, error) {
+	return nodes.list(ctx, sql2.NodeGetAll, registry.OrgFromContext(ctx))
+}
+
+func (nodes nodesRepo) Slaves(ctx context.Context, id string) ([]registry.Node, error) {
+	return nodes.list(ctx, sql2.NodeSlaves, id, registry.OrgFromContext(ctx))
+}
+
+func (nodes nodesRepo) list(ctx context.Context, query string, args ...interface{}) ([]registry.Node, error) {
+
+	rows, err := nodes.db.QueryContext(ctx, query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ns []registry.Node
+
+	for rows.Next() {
+		node, err := scanNode(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		ns = append(ns, node)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return ns, nil
+}
+
+func (nodes nodesRepo) Update(ctx context.Context, id string, node registry.Node) (registry.Node, error) {
+	org := registry.OrgFromContext(ctx)
+
+	tx, err := nodes.db.BeginTx(ctx, nil)
+	if err != nil {
+		return registry.Node{}, err
+	}
+	defer tx.Rollback()
+
+	current, err := scanNode(tx.QueryRowContext(ctx, sql2.NodeGetById, id, org))
+	if err == sql.ErrNoRows {
+		return registry.Node{}, ErrNodeNotFound
+	}
+	if err != nil {
+		return registry.Node{}, err
+	}
+
+	//nodes moved to another region count against its quota
+	if node.Region != current.Region {
+		if err = checkQuota(ctx, tx, node.Region, "", 0, current.Type); err != nil {
+			return registry.Node{}, err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, sql2.NodeUpdate, current.UUID, node.Name, node.Region,
+		node.Latd, node.Long, nullString(node.Master), nullString(node.Firmware), node.Labels, org)
+	if nodes.db.ForeignKeyViolation(err) {
+		return registry.Node{}, registry.ErrInvalidReference
+	}
+	if err != nil {
+		return registry.Node{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return registry.Node{}, err
+	}
+
+	return nodes.Get(ctx, id)
+}
+
+func scanNode(row scanner) (registry.Node, error) {
+	node := registry.Node{}
+
+	err := row.Scan(
+		&node.UUID,
+		&node.Addr,
+		&node.Name,
+		&node.Type,
+		&node.Region,
+		&node.Latd,
+		&node.Long,
+		&node.Created,
+		&node.Master,
+		&node.Org,
+		&node.Firmware,
+		&node.Labels)
+	if err != nil {
+		return registry.Node{}, err
+	}
+
+	return node, nil
+}
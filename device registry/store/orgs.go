@@ -0,0 +1,130 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"github.com/piusalfred/registry"
+	"github.com/piusalfred/registry/logger"
+	"github.com/piusalfred/registry/pkg/errors"
+	sql2 "github.com/piusalfred/registry/sql"
+	"log"
+	"os"
+	"time"
+)
+
+var (
+	ErrOrganizationNotFound = errors.NewKind(errors.NotFound, "organization not found")
+)
+
+type orgsRepo struct {
+	db       *DB
+	dbLogger logger.Logger
+}
+
+func NewOrganizationRepository(db *DB) registry.OrganizationRepository {
+
+	dlog, err := logger.New(os.Stdout, "debug")
+
+	if err != nil {
+		log.Fatal("could not create organizations repository database logger")
+	}
+	return &orgsRepo{
+		db:       db,
+		dbLogger: dlog,
+	}
+}
+
+type dbOrganization struct {
+	ID      string
+	Name    string
+	Desc    sql.NullString
+	Created time.Time
+}
+
+func (o dbOrganization) toOrganization() registry.Organization {
+	return registry.Organization{
+		ID:      o.ID,
+		Name:    o.Name,
+		Desc:    o.Desc.String,
+		Created: o.Created.Format(time.RFC3339),
+	}
+}
+
+func (o orgsRepo) Add(ctx context.Context, org registry.Organization) error {
+	created, err := time.Parse(time.RFC3339, org.Created)
+	if err != nil {
+		return err
+	}
+
+	_, err = o.db.Exec(sql2.OrganizationAddNew, org.ID, org.Name, nullString(org.Desc), created)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (o orgsRepo) Get(ctx context.Context, id string) (registry.Organization, error) {
+	row := o.db.QueryRow(sql2.OrganizationGet, id, registry.OrgFromContext(ctx))
+	d := dbOrganization{}
+
+	switch err := row.Scan(&d.ID, &d.Name, &d.Desc, &d.Created); err {
+
+	case sql.ErrNoRows:
+		return registry.Organization{}, ErrOrganizationNotFound
+
+	case nil:
+		return d.toOrganization(), nil
+
+	default:
+		return registry.Organization{}, err
+	}
+}
+
+func (o orgsRepo) List(ctx context.Context) ([]registry.Organization, error) {
+	rows, err := o.db.Query(sql2.OrganizationsGetAll, registry.OrgFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orgs []registry.Organization
+
+	for rows.Next() {
+		d := dbOrganization{}
+		err := rows.Scan(&d.ID, &d.Name, &d.Desc, &d.Created)
+		if err != nil {
+			return nil, err
+		}
+
+		orgs = append(orgs, d.toOrganization())
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return orgs, nil
+}
+
+func (o orgsRepo) Delete(ctx context.Context, id string) error {
+	res, err := o.db.Exec(sql2.OrganizationDelete, id)
+	if err != nil {
+		if o.db.ForeignKeyViolation(err) {
+			return registry.ErrOrganizationInUse
+		}
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrOrganizationNotFound
+	}
+
+	return nil
+}
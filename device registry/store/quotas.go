@@ -0,0 +1,177 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"github.com/piusalfred/registry"
+	"github.com/piusalfred/registry/logger"
+	"github.com/piusalfred/registry/pkg/errors"
+	sql2 "github.com/piusalfred/registry/sql"
+	"log"
+	"os"
+)
+
+var ErrQuotaNotFound = errors.NewKind(errors.NotFound, "quota not found")
+
+type quotasRepo struct {
+	db       *DB
+	dbLogger logger.Logger
+}
+
+func NewQuotaRepository(db *DB) registry.QuotaRepository {
+
+	dlog, err := logger.New(os.Stdout, "debug")
+
+	if err != nil {
+		log.Fatal("could not create quotas repository database logger")
+	}
+	return &quotasRepo{
+		db:       db,
+		dbLogger: dlog,
+	}
+}
+
+func (q quotasRepo) Set(ctx context.Context, quota registry.Quota) error {
+	_, err := q.db.Exec(sql2.QuotaSet, string(quota.Scope), quota.ID, quota.Users, quota.Nodes, nullString(quota.Org))
+	return err
+}
+
+func (q quotasRepo) Get(ctx context.Context, scope registry.QuotaScope, id string) (registry.Quota, error) {
+	row := q.db.QueryRow(sql2.QuotaGet, string(scope), id, registry.OrgFromContext(ctx))
+
+	switch quota, err := scanQuota(row); err {
+	case sql.ErrNoRows:
+		return registry.Quota{}, ErrQuotaNotFound
+
+	default:
+		return quota, err
+	}
+}
+
+func (q quotasRepo) List(ctx context.Context) ([]registry.Quota, error) {
+	rows, err := q.db.Query(sql2.QuotasGetAll, registry.OrgFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var quotas []registry.Quota
+
+	for rows.Next() {
+		quota, err := scanQuota(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		quotas = append(quotas, quota)
+	}
+
+	return quotas, rows.Err()
+}
+
+func (q quotasRepo) Usage(ctx context.Context, scope registry.QuotaScope, id string) (registry.Usage, error) {
+	quota, err := q.Get(ctx, scope, id)
+	switch {
+	case errors.KindOf(err) == errors.NotFound:
+		quota = registry.Quota{Scope: scope, ID: id}
+	case err != nil:
+		return registry.Usage{}, err
+	}
+
+	return countUsage(ctx, q.db, quota, registry.OrgFromContext(ctx))
+}
+
+// querier is either a DB or a Tx
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// countUsage counts the users and nodes of the region or organization of
+// quota that are visible to org, all of them when it is empty
+func countUsage(ctx context.Context, db querier, quota registry.Quota, org string) (registry.Usage, error) {
+	//rows have a region and may have an organization, neither is empty
+	var region, owner string
+	if quota.Scope == registry.QuotaRegion {
+		region = quota.ID
+	} else {
+		owner = quota.ID
+	}
+
+	var users int
+	err := db.QueryRowContext(ctx, sql2.QuotaUsers, region, owner, org).Scan(&users)
+	if err != nil {
+		return registry.Usage{}, err
+	}
+
+	rows, err := db.QueryContext(ctx, sql2.QuotaNodes, region, owner, org)
+	if err != nil {
+		return registry.Usage{}, err
+	}
+	defer rows.Close()
+
+	nodes := map[int]int{}
+	for rows.Next() {
+		var typ, n int
+		if err := rows.Scan(&typ, &n); err != nil {
+			return registry.Usage{}, err
+		}
+		nodes[typ] = n
+	}
+
+	if err := rows.Err(); err != nil {
+		return registry.Usage{}, err
+	}
+
+	return registry.CountUsage(quota, users, nodes), nil
+}
+
+// checkQuota fails with ErrQuotaExceeded when adding users users, or a node
+// of type typ when it is not 0, to region goes over the quota of the region
+// or of the organization org. An empty org only checks the region.
+//
+// The quotas stay locked until tx ends so that concurrent transactions
+// adding to the same region or organization are checked one after the
+// other, their rows are counted whatever the organization of ctx.
+func checkQuota(ctx context.Context, tx *Tx, region, org string, users, typ int) error {
+	if _, err := tx.ExecContext(ctx, sql2.QuotaLock, region, org); err != nil {
+		return err
+	}
+
+	quotas := []registry.Quota{{Scope: registry.QuotaRegion, ID: region}}
+	if org != "" {
+		quotas = append(quotas, registry.Quota{Scope: registry.QuotaOrg, ID: org})
+	}
+
+	for _, q := range quotas {
+		quota, err := scanQuota(tx.QueryRowContext(ctx, sql2.QuotaGet, string(q.Scope), q.ID, ""))
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		usage, err := countUsage(ctx, tx, quota, "")
+		if err != nil {
+			return err
+		}
+
+		if err = usage.Check(users, typ); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func scanQuota(row scanner) (registry.Quota, error) {
+	quota := registry.Quota{}
+
+	err := row.Scan(&quota.Scope, &quota.ID, &quota.Users, &quota.Nodes, &quota.Org)
+	if err != nil {
+		return registry.Quota{}, err
+	}
+
+	return quota, nil
+}
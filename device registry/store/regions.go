@@ -0,0 +1,131 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"github.com/piusalfred/registry"
+	"github.com/piusalfred/registry/logger"
+	"github.com/piusalfred/registry/pkg/errors"
+	sql2 "github.com/piusalfred/registry/sql"
+	"log"
+	"os"
+)
+
+var (
+	ErrRegionNotFound = errors.NewKind(errors.NotFound, "region not found")
+)
+
+type regionsRepo struct {
+	db       *DB
+	dbLogger logger.Logger
+}
+
+func NewRegionRepository(db *DB) registry.RegionRepository {
+
+	dlog, err := logger.New(os.Stdout, "debug")
+
+	if err != nil {
+		log.Fatal("could not create regions repository database logger")
+	}
+	return &regionsRepo{
+		db:       db,
+		dbLogger: dlog,
+	}
+}
+
+func (r regionsRepo) Get(ctx context.Context, id string) (registry.Region, error) {
+	row := r.db.QueryRow(sql2.RegionGetById, id, registry.OrgFromContext(ctx))
+	region := registry.Region{}
+
+	switch err := row.Scan(
+		&region.ID, &region.Name, &region.Desc, &region.Org, &region.Boundary, &region.Parent); err {
+
+	case sql.ErrNoRows:
+		return registry.Region{}, ErrRegionNotFound
+
+	case nil:
+		return region, nil
+
+	default:
+		return registry.Region{}, err
+	}
+}
+
+func (r regionsRepo) Add(ctx context.Context, region registry.Region) (err error) {
+
+	_, err = r.db.Exec(sql2.RegionAddNew,
+		region.ID, region.Name, region.Desc, nullString(region.Org), region.Boundary, nullString(region.Parent))
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r regionsRepo) Delete(ctx context.Context, id string) error {
+	res, err := r.db.Exec(sql2.RegionDelete, id, registry.OrgFromContext(ctx))
+	if err != nil {
+		if r.db.ForeignKeyViolation(err) {
+			return registry.ErrRegionInUse
+		}
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrRegionNotFound
+	}
+
+	return nil
+}
+
+func (r regionsRepo) List(ctx context.Context) ([]registry.Region, error) {
+	rows, err := r.db.Query(sql2.RegionsSelectAll, registry.OrgFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var regions []registry.Region
+	for rows.Next() {
+		r := registry.Region{}
+		err := rows.Scan(&r.ID, &r.Name, &r.Desc, &r.Org, &r.Boundary, &r.Parent)
+		if err != nil {
+			return nil, err
+		}
+
+		regions = append(regions, r)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return regions, nil
+}
+
+func (r regionsRepo) Update(ctx context.Context, id string, region registry.Region) (registry.Region, error) {
+	res, err := r.db.Exec(sql2.RegionUpdate, id, region.Name, region.Desc,
+		region.Boundary, nullString(region.Parent), registry.OrgFromContext(ctx))
+	if err != nil {
+		return registry.Region{}, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return registry.Region{}, err
+	}
+
+	if n == 0 {
+		return registry.Region{}, ErrRegionNotFound
+	}
+
+	return r.Get(ctx, id)
+}
@@ -0,0 +1,96 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"github.com/piusalfred/registry"
+	"github.com/piusalfred/registry/logger"
+	"github.com/piusalfred/registry/pkg/errors"
+	sql2 "github.com/piusalfred/registry/sql"
+	"log"
+	"os"
+	"time"
+)
+
+var ErrSchemaNotFound = errors.NewKind(errors.NotFound, "schema not found")
+
+type schemasRepo struct {
+	db       *DB
+	dbLogger logger.Logger
+}
+
+func NewSchemaRepository(db *DB) registry.SchemaRepository {
+
+	dlog, err := logger.New(os.Stdout, "debug")
+
+	if err != nil {
+		log.Fatal("could not create schemas repository database logger")
+	}
+	return &schemasRepo{
+		db:       db,
+		dbLogger: dlog,
+	}
+}
+
+func (s schemasRepo) Add(ctx context.Context, schema registry.Schema) error {
+	created, err := time.Parse(time.RFC3339, schema.Created)
+	if err != nil {
+		return err
+	}
+
+	//a schema describes either a node type or a node
+	typ := sql.NullInt64{Int64: int64(schema.Type), Valid: schema.Type != 0}
+
+	_, err = s.db.Exec(sql2.SchemaAddNew, schema.ID, string(schema.Kind), typ, nullString(schema.Node),
+		schema.Version, string(schema.Document), created, nullString(schema.Org))
+
+	return err
+}
+
+func (s schemasRepo) Get(ctx context.Context, id string) (registry.Schema, error) {
+	row := s.db.QueryRow(sql2.SchemaGet, id, registry.OrgFromContext(ctx))
+
+	switch schema, err := scanSchema(row); err {
+	case sql.ErrNoRows:
+		return registry.Schema{}, ErrSchemaNotFound
+
+	default:
+		return schema, err
+	}
+}
+
+func (s schemasRepo) List(ctx context.Context) ([]registry.Schema, error) {
+	rows, err := s.db.Query(sql2.SchemasGetAll, registry.OrgFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schemas []registry.Schema
+
+	for rows.Next() {
+		schema, err := scanSchema(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		schemas = append(schemas, schema)
+	}
+
+	return schemas, rows.Err()
+}
+
+func scanSchema(row scanner) (registry.Schema, error) {
+	schema := registry.Schema{}
+	var document string
+	var created time.Time
+
+	err := row.Scan(&schema.ID, &schema.Kind, &schema.Type, &schema.Node, &schema.Version, &document, &created, &schema.Org)
+	if err != nil {
+		return registry.Schema{}, err
+	}
+
+	schema.Document = []byte(document)
+	schema.Created = created.Format(time.RFC3339)
+	return schema, nil
+}
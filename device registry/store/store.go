@@ -0,0 +1,308 @@
+// Package store implements the registry repositories on top of
+// database/sql. The queries of the sql package are shared by every
+// backend, a Dialect switches the placeholders, column types and errors
+// that differ between them.
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/piusalfred/registry"
+	sql2 "github.com/piusalfred/registry/sql"
+	"time"
+)
+
+var (
+	_ registry.UserRepository         = (*userRepo)(nil)
+	_ registry.NodeRepository         = (*nodesRepo)(nil)
+	_ registry.RegionRepository       = (*regionsRepo)(nil)
+	_ registry.NodeTypeRepository     = (*nodeTypesRepo)(nil)
+	_ registry.ClaimRepository        = (*claimsRepo)(nil)
+	_ registry.CertificateRepository  = (*certsRepo)(nil)
+	_ registry.APIKeyRepository       = (*apiKeysRepo)(nil)
+	_ registry.OrganizationRepository = (*orgsRepo)(nil)
+	_ registry.EventStore             = (*eventStore)(nil)
+	_ registry.IdempotencyRepository  = (*idempotencyRepo)(nil)
+	_ registry.FirmwareRepository     = (*firmwareRepo)(nil)
+	_ registry.CampaignRepository     = (*campaignsRepo)(nil)
+	_ registry.SchemaRepository       = (*schemasRepo)(nil)
+	_ registry.QuotaRepository        = (*quotasRepo)(nil)
+)
+
+// Dialect is the part of a database backend that differs from postgres,
+// the dialect the queries and the schema of the sql package are written in.
+type Dialect interface {
+	// Rebind rewrites the $N placeholders of query
+	Rebind(query string) string
+
+	// Timestamp normalizes t before it is stored
+	Timestamp(t time.Time) time.Time
+
+	// Array converts s to the value of a text[] column
+	Array(s []string) interface{}
+
+	// ScanArray returns the scan destination of a text[] column
+	ScanArray(s *[]string) interface{}
+
+	// ForeignKeyViolation reports whether err is returned for a row
+	// referencing one that does not exist, or for removing a row that is
+	// still referenced
+	ForeignKeyViolation(err error) bool
+
+	// SnapshotOptions are the options of a transaction that reads all
+	// tables from the same snapshot of the database
+	SnapshotOptions() *sql.TxOptions
+
+	// Schema rewrites the schema of the sql package
+	Schema(schema string) string
+
+	// AddForeignKey makes column of table reference the id of ref, it does
+	// nothing when the column already references another table
+	AddForeignKey(ctx context.Context, db *sql.DB, table, column, ref string) error
+
+	// AlterColumnType changes the type of column of table to typ
+	AlterColumnType(ctx context.Context, db *sql.DB, table, column, typ string) error
+}
+
+// DB is a database and the dialect of its backend. Queries are rebound and
+// their arguments converted before they are passed to the database.
+type DB struct {
+	*sql.DB
+	Dialect
+}
+
+// New returns db with the dialect d
+func New(db *sql.DB, d Dialect) *DB {
+	return &DB{DB: db, Dialect: d}
+}
+
+// Migrate applies the registry schema and the migrations of the sql
+// package that were not run on the database yet
+func (db *DB) Migrate(ctx context.Context) error {
+	_, err := db.DB.ExecContext(ctx, db.Schema(sql2.Schema))
+	if err != nil {
+		return err
+	}
+
+	version, err := schemaVersion(ctx, db.DB)
+	if err != nil {
+		return err
+	}
+
+	for ; version < sql2.Version; version++ {
+		err = db.migrate(ctx, sql2.Migrations[version])
+		if err != nil {
+			return fmt.Errorf("migrating the schema to version %d: %w", version+1, err)
+		}
+
+		_, err = db.ExecContext(ctx, sql2.SchemaVersionAdd, version+1)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrate runs the steps of m that the database does not have
+func (db *DB) migrate(ctx context.Context, m sql2.Migration) error {
+	for _, c := range m.Columns {
+		columns, err := tableColumns(ctx, db.DB, c.Table)
+		if err != nil {
+			return err
+		}
+
+		if columns[c.Name] {
+			continue
+		}
+
+		stmt := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s;", c.Table, c.Name, c.Definition)
+		_, err = db.DB.ExecContext(ctx, db.Schema(stmt))
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, fk := range m.ForeignKeys {
+		err := db.AddForeignKey(ctx, db.DB, fk.Table, fk.Column, fk.References)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, c := range m.Types {
+		err := db.AlterColumnType(ctx, db.DB, c.Table, c.Name, c.Definition)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// schemaVersion returns the highest version recorded in schema_version
+func schemaVersion(ctx context.Context, db *sql.DB) (int, error) {
+	var version int
+	err := db.QueryRowContext(ctx, sql2.SchemaVersionGet).Scan(&version)
+	return version, err
+}
+
+// tableColumns returns the names of the columns of table
+func tableColumns(ctx context.Context, db *sql.DB, table string) (map[string]bool, error) {
+	rows, err := db.QueryContext(ctx, "SELECT * FROM "+table+" LIMIT 0;")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]bool, len(names))
+	for _, name := range names {
+		columns[name] = true
+	}
+
+	return columns, nil
+}
+
+// ErrNotMigrated is returned by Migrated when the registry schema has not
+// been applied to the database
+var ErrNotMigrated = errors.New("database schema is not migrated")
+
+// Migrated checks that every table of the registry schema exists in db and
+// that the migrations brought it to the version of the schema
+func Migrated(ctx context.Context, db *sql.DB) error {
+	for _, table := range sql2.Tables {
+		_, err := db.ExecContext(ctx, "SELECT 1 FROM "+table+" LIMIT 0;")
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("%w: %s: %v", ErrNotMigrated, table, err)
+		}
+	}
+
+	version, err := schemaVersion(ctx, db)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("%w: %v", ErrNotMigrated, err)
+	}
+
+	if version != sql2.Version {
+		return fmt.Errorf("%w: schema version is %d, want %d", ErrNotMigrated, version, sql2.Version)
+	}
+
+	return nil
+}
+
+func (db *DB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return db.DB.Exec(db.Rebind(query), db.args(args)...)
+}
+
+func (db *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return db.DB.ExecContext(ctx, db.Rebind(query), db.args(args)...)
+}
+
+func (db *DB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return db.DB.Query(db.Rebind(query), db.args(args)...)
+}
+
+func (db *DB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return db.DB.QueryContext(ctx, db.Rebind(query), db.args(args)...)
+}
+
+func (db *DB) QueryRow(query string, args ...interface{}) *sql.Row {
+	return db.DB.QueryRow(db.Rebind(query), db.args(args)...)
+}
+
+func (db *DB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return db.DB.QueryRowContext(ctx, db.Rebind(query), db.args(args)...)
+}
+
+func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	tx, err := db.DB.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tx{Tx: tx, db: db}, nil
+}
+
+// args converts the timestamps and string slices of args to the values
+// stored by the dialect
+func (db *DB) args(args []interface{}) []interface{} {
+	converted := make([]interface{}, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case time.Time:
+			converted[i] = db.Timestamp(v)
+		case sql.NullTime:
+			if v.Valid {
+				v.Time = db.Timestamp(v.Time)
+			}
+			converted[i] = v
+		case []string:
+			converted[i] = db.Array(v)
+		default:
+			converted[i] = arg
+		}
+	}
+
+	return converted
+}
+
+// Tx is a transaction of DB, its queries are rebound like the ones of DB
+type Tx struct {
+	*sql.Tx
+	db *DB
+}
+
+func (tx *Tx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return tx.Tx.Exec(tx.db.Rebind(query), tx.db.args(args)...)
+}
+
+func (tx *Tx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return tx.Tx.ExecContext(ctx, tx.db.Rebind(query), tx.db.args(args)...)
+}
+
+func (tx *Tx) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return tx.Tx.Query(tx.db.Rebind(query), tx.db.args(args)...)
+}
+
+func (tx *Tx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return tx.Tx.QueryContext(ctx, tx.db.Rebind(query), tx.db.args(args)...)
+}
+
+func (tx *Tx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return tx.Tx.QueryRowContext(ctx, tx.db.Rebind(query), tx.db.args(args)...)
+}
+
+func (tx *Tx) PrepareContext(ctx context.Context, query string) (*Stmt, error) {
+	stmt, err := tx.Tx.PrepareContext(ctx, tx.db.Rebind(query))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Stmt{Stmt: stmt, db: tx.db}, nil
+}
+
+// Stmt is a prepared statement of DB
+type Stmt struct {
+	*sql.Stmt
+	db *DB
+}
+
+func (s *Stmt) ExecContext(ctx context.Context, args ...interface{}) (sql.Result, error) {
+	return s.Stmt.ExecContext(ctx, s.db.args(args)...)
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,155 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"github.com/piusalfred/registry"
+	"github.com/piusalfred/registry/logger"
+	"github.com/piusalfred/registry/pkg/errors"
+	sql2 "github.com/piusalfred/registry/sql"
+	"log"
+	"os"
+)
+
+var (
+	ErrNodeTypeNotFound = errors.NewKind(errors.NotFound, "node type not found")
+)
+
+type nodeTypesRepo struct {
+	db       *DB
+	dbLogger logger.Logger
+}
+
+func NewNodeTypeRepository(db *DB) registry.NodeTypeRepository {
+
+	dlog, err := logger.New(os.Stdout, "debug")
+
+	if err != nil {
+		log.Fatal("could not create node types repository database logger")
+	}
+	return &nodeTypesRepo{
+		db:       db,
+		dbLogger: dlog,
+	}
+}
+
+func (t nodeTypesRepo) Get(ctx context.Context, id int) (registry.NodeType, error) {
+	row := t.db.QueryRow(sql2.NodeTypeGetById, id)
+
+	nodeType, err := scanNodeType(t.db, row)
+
+	switch err {
+	case sql.ErrNoRows:
+		return registry.NodeType{}, ErrNodeTypeNotFound
+
+	case nil:
+		return nodeType, nil
+
+	default:
+		return registry.NodeType{}, err
+	}
+}
+
+func (t nodeTypesRepo) Add(ctx context.Context, nodeType registry.NodeType) error {
+
+	_, err := t.db.Exec(sql2.NodeTypeAddNew,
+		nodeType.ID, nodeType.Name, nodeType.Desc,
+		capabilitiesToStrings(nodeType.Capabilities))
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (t nodeTypesRepo) Delete(ctx context.Context, id int) error {
+
+	res, err := t.db.Exec(sql2.NodeTypeDelete, id)
+	if err != nil {
+		if t.db.ForeignKeyViolation(err) {
+			return registry.ErrNodeTypeInUse
+		}
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNodeTypeNotFound
+	}
+
+	return nil
+}
+
+func (t nodeTypesRepo) List(ctx context.Context) ([]registry.NodeType, error) {
+	rows, err := t.db.Query(sql2.NodeTypesGetAll)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var types []registry.NodeType
+
+	for rows.Next() {
+		nodeType, err := scanNodeType(t.db, rows)
+		if err != nil {
+			return nil, err
+		}
+
+		types = append(types, nodeType)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return types, nil
+}
+
+func (t nodeTypesRepo) Update(ctx context.Context, id int, nodeType registry.NodeType) (registry.NodeType, error) {
+
+	res, err := t.db.Exec(sql2.NodeTypeUpdate,
+		id, nodeType.Name, nodeType.Desc,
+		capabilitiesToStrings(nodeType.Capabilities))
+
+	if err != nil {
+		return registry.NodeType{}, err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return registry.NodeType{}, ErrNodeTypeNotFound
+	}
+
+	return t.Get(ctx, id)
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanNodeType(db *DB, row scanner) (registry.NodeType, error) {
+	var (
+		nodeType registry.NodeType
+		caps     []string
+	)
+
+	err := row.Scan(&nodeType.ID, &nodeType.Name, &nodeType.Desc, db.ScanArray(&caps))
+	if err != nil {
+		return registry.NodeType{}, err
+	}
+
+	for _, c := range caps {
+		nodeType.Capabilities = append(nodeType.Capabilities, registry.Capability(c))
+	}
+
+	return nodeType, nil
+}
+
+func capabilitiesToStrings(caps []registry.Capability) []string {
+	strs := make([]string, 0, len(caps))
+	for _, c := range caps {
+		strs = append(strs, string(c))
+	}
+
+	return strs
+}
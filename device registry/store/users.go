@@ -1,4 +1,4 @@
-package postgres
+package store
 
 import (
 	"context"
@@ -13,12 +13,12 @@ import (
 )
 
 var (
-	ErrUserNotFound   = errors.New("user not found")
+	ErrUserNotFound   = errors.NewKind(errors.NotFound, "user not found")
 	ErrUserNotUpdated = errors.New("user not updated")
 )
 
 type userRepo struct {
-	db       *sql.DB
+	db       *DB
 	dbLogger logger.Logger
 }
 
@@ -30,6 +30,7 @@ type dbUser struct {
 	Group    int       `json:"group,omitempty"`               //user group
 	Region   string    `json:"region_of_operation,omitempty"` //operating region in case of multi cloud
 	Created  time.Time `json:"created,omitempty"`
+	Org      string    `json:"org,omitempty"`
 }
 
 func (u dbUser) toUser() registry.User {
@@ -41,6 +42,7 @@ func (u dbUser) toUser() registry.User {
 		Group:    u.Group,
 		Region:   u.Region,
 		Created:  u.Created.Format(time.RFC3339),
+		Org:      u.Org,
 	}
 }
 
@@ -59,10 +61,11 @@ func fromUser(user registry.User) (dbUser, error) {
 		Group:    user.Group,
 		Region:   user.Region,
 		Created:  now,
+		Org:      user.Org,
 	}, nil
 }
 
-func NewUserRepository(db *sql.DB) registry.UserRepository {
+func NewUserRepository(db *DB) registry.UserRepository {
 
 	dlog, err := logger.New(os.Stdout, "debug")
 
@@ -76,13 +79,13 @@ func NewUserRepository(db *sql.DB) registry.UserRepository {
 }
 
 func (u userRepo) Get(ctx context.Context, id string) (registry.User, error) {
-	row := u.db.QueryRow(sql2.UserSelectById, id)
+	row := u.db.QueryRow(sql2.UserSelectById, id, registry.OrgFromContext(ctx))
 	dUser := dbUser{}
 
 	switch err := row.Scan(
 		&dUser.ID, &dUser.Name, &dUser.Email,
 		&dUser.Password, &dUser.Group,
-		&dUser.Region, &dUser.Created); err {
+		&dUser.Region, &dUser.Created, &dUser.Org); err {
 
 	case sql.ErrNoRows:
 		return registry.User{}, ErrUserNotFound
@@ -103,20 +106,33 @@ func (u userRepo) Add(ctx context.Context, user registry.User) error {
 		return err
 	}
 
-	_, err = u.db.Exec(sql2.UserInsertNew,
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = checkQuota(ctx, tx, dUser.Region, dUser.Org, 1, 0); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, sql2.UserInsertNew,
 		dUser.ID, dUser.Name, dUser.Email, dUser.Password,
-		dUser.Group, dUser.Region, dUser.Created)
+		dUser.Group, dUser.Region, dUser.Created, nullString(dUser.Org))
 
+	if u.db.ForeignKeyViolation(err) {
+		return registry.ErrInvalidReference
+	}
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return tx.Commit()
 }
 
 func (u userRepo) Delete(ctx context.Context, id string) error {
 
-	_, err := u.db.Exec(sql2.UserDelete, id)
+	_, err := u.db.Exec(sql2.UserDelete, id, registry.OrgFromContext(ctx))
 	if err != nil {
 		return err
 	}
@@ -126,7 +142,7 @@ func (u userRepo) Delete(ctx context.Context, id string) error {
 
 func (u userRepo) List(ctx context.Context) ([]registry.User, error) {
 
-	rows, err := u.db.Query(sql2.UsersSelectAll)
+	rows, err := u.db.Query(sql2.UsersSelectAll, registry.OrgFromContext(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -136,7 +152,7 @@ func (u userRepo) List(ctx context.Context) ([]registry.User, error) {
 
 	for rows.Next() {
 		u := dbUser{}
-		err := rows.Scan(&u.ID, &u.Name, &u.Email, &u.Password, &u.Group, &u.Region, &u.Created)
+		err := rows.Scan(&u.ID, &u.Name, &u.Email, &u.Password, &u.Group, &u.Region, &u.Created, &u.Org)
 		if err != nil {
 			return nil, err
 		}
@@ -156,28 +172,60 @@ func (u userRepo) Update(ctx context.Context, id string, user registry.User) (re
 	//can only update group and region
 	region := user.Region
 	group := user.Group
+	org := registry.OrgFromContext(ctx)
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return registry.User{}, err
+	}
+	defer tx.Rollback()
 
 	if region != "" {
+		var current string
+		err := tx.QueryRowContext(ctx, sql2.UserSelectRegion, id, org).Scan(&current)
+		if err == sql.ErrNoRows {
+			return registry.User{}, ErrUserNotFound
+		}
+		if err != nil {
+			return registry.User{}, err
+		}
+
+		//users moved to another region count against its quota
+		if region != current {
+			if err = checkQuota(ctx, tx, region, "", 1, 0); err != nil {
+				return registry.User{}, err
+			}
+		}
 
 		//update all
-		if group <= 3 && group >= 1 {
-			_, err := u.db.Exec(sql2.UserUpdateRandG, id, group, region)
+		if group <= int(registry.OrgAdmin) && group >= 1 {
+			_, err := tx.ExecContext(ctx, sql2.UserUpdateRandG, id, group, region, org)
+			if u.db.ForeignKeyViolation(err) {
+				return registry.User{}, registry.ErrInvalidReference
+			}
 			if err != nil {
 				return registry.User{}, err
 			}
 		} else {
-			_, err := u.db.Exec(sql2.UserUpdateRegion, id, region)
+			_, err := tx.ExecContext(ctx, sql2.UserUpdateRegion, id, region, org)
+			if u.db.ForeignKeyViolation(err) {
+				return registry.User{}, registry.ErrInvalidReference
+			}
 			if err != nil {
 				return registry.User{}, err
 			}
 		}
 	}
 
-	_, err := u.db.Exec(sql2.UserUpdateGroup, id, group)
+	_, err = tx.ExecContext(ctx, sql2.UserUpdateGroup, id, group, org)
 	if err != nil {
 		return registry.User{}, err
 	}
 
+	if err = tx.Commit(); err != nil {
+		return registry.User{}, err
+	}
+
 	updatedUser, err := u.Get(ctx, id)
 	if err != nil {
 		return registry.User{}, ErrUserNotUpdated
@@ -185,3 +233,21 @@ func (u userRepo) Update(ctx context.Context, id string, user registry.User) (re
 
 	return updatedUser, nil
 }
+
+func (u userRepo) UpdatePassword(ctx context.Context, id, hash string) error {
+	res, err := u.db.Exec(sql2.UserUpdatePassword, id, hash, registry.OrgFromContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package registry
+
+import (
+	"context"
+	"github.com/piusalfred/registry/pkg/errors"
+	"strings"
+)
+
+var (
+	ErrUnknownNodeType   = errors.NewKind(errors.Invalid, "unknown node type")
+	ErrInvalidNodeType   = errors.NewKind(errors.Invalid, "node type must have a positive id and a name")
+	ErrInvalidCapability = errors.NewKind(errors.Invalid, "unrecognized node capability")
+	ErrNodeTypeInUse     = errors.NewKind(errors.Conflict, "node type is used by nodes, claim codes, firmware or schemas")
+)
+
+// Capability is an operation a node of a certain type is allowed to
+// perform in the igrid network.
+type Capability string
+
+const (
+	// Publish allows a node to publish to its own topic.
+	Publish Capability = "publish"
+	// Subscribe allows a node to subscribe to its own topic.
+	Subscribe Capability = "subscribe"
+	// Command allows a node to publish and subscribe to any topic of
+	// its region, this is what controllers do.
+	Command Capability = "command"
+)
+
+var capabilities = map[Capability]bool{
+	Publish:   true,
+	Subscribe: true,
+	Command:   true,
+}
+
+// NodeType describes a kind of node that can be registered, the
+// catalogue of node types is managed by the registry so that new
+// kinds of devices (gateways, meters, relays ...) can be added
+// without changing the code.
+type NodeType struct {
+	ID           int          `json:"id"`
+	Name         string       `json:"name"`
+	Desc         string       `json:"description"`
+	Capabilities []Capability `json:"capabilities"`
+}
+
+// DefaultNodeTypes are the node types every registry starts with.
+var DefaultNodeTypes = []NodeType{
+	{
+		ID:           int(Sensor),
+		Name:         Sensor.String(),
+		Desc:         "publishes measurements to its own topic",
+		Capabilities: []Capability{Publish},
+	},
+	{
+		ID:           int(Actuator),
+		Name:         Actuator.String(),
+		Desc:         "subscribes to its own topic for commands",
+		Capabilities: []Capability{Subscribe},
+	},
+	{
+		ID:           int(Controller),
+		Name:         Controller.String(),
+		Desc:         "publishes and subscribes to all topics of its region",
+		Capabilities: []Capability{Publish, Subscribe, Command},
+	},
+}
+
+// Validate returns an error if the node type representation is invalid.
+func (nt NodeType) Validate() error {
+	if nt.ID <= 0 || strings.TrimSpace(nt.Name) == "" {
+		return ErrInvalidNodeType
+	}
+
+	for _, c := range nt.Capabilities {
+		if !capabilities[c] {
+			return ErrInvalidCapability
+		}
+	}
+
+	return nil
+}
+
+// Can reports whether nodes of this type have the capability c.
+func (nt NodeType) Can(c Capability) bool {
+	for _, capability := range nt.Capabilities {
+		if capability == c {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (nt NodeType) String() string {
+	return nt.Name
+}
+
+// nodeType returns the node type id of the catalogue, a missing node type
+// is an invalid reference of the request so it fails with ErrUnknownNodeType
+func nodeType(ctx context.Context, types NodeTypeRepository, id int) (NodeType, error) {
+	nt, err := types.Get(ctx, id)
+	if errors.KindOf(err) == errors.NotFound {
+		return nt, ErrUnknownNodeType
+	}
+
+	return nt, err
+}
@@ -10,8 +10,8 @@ import (
 )
 
 var (
-	ErrInvalidEmail  = errors.New("invalid email format")
-	ErrShortPassword = errors.New("password length is short")
+	ErrInvalidEmail  = errors.NewKind(errors.Invalid, "invalid email format")
+	ErrShortPassword = errors.NewKind(errors.Invalid, "password length is short")
 )
 
 type UserGroup int
@@ -20,8 +20,29 @@ const (
 	Admin UserGroup = iota + 1 //owner of the network
 	RegionAdmin
 	RegionUser
+	OrgAdmin //administrator of a single organization
 )
 
+// Includes reports whether group g has at least the rights of group o
+func (g UserGroup) Includes(o UserGroup) bool {
+	return g.rank() <= o.rank()
+}
+
+func (g UserGroup) rank() int {
+	switch g {
+	case Admin:
+		return 0
+	case OrgAdmin:
+		return 1
+	case RegionAdmin:
+		return 2
+	case RegionUser:
+		return 3
+	default:
+		return 4
+	}
+}
+
 const (
 	minPassLen   = 8
 	maxLocalLen  = 64
@@ -45,6 +66,7 @@ type User struct {
 	Password string `json:"password,omitempty"` //password of user
 	Group    int    `json:"group,omitempty"`    //user group
 	Region   string `json:"region,omitempty"`   //operating region in case of multi cloud
+	Org      string `json:"org,omitempty"`      //organization the user belongs to
 	Created  string `json:"created,omitempty"`  //when was this user added
 }
 
@@ -1,8 +1,15 @@
 package registry
 
 import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
 	"github.com/gofrs/uuid"
 	"github.com/piusalfred/registry/pkg/errors"
+	"io"
+	mrand "math/rand"
+	"sync"
+	"time"
 )
 
 // UUIDProvider specifies an API for generating unique identifiers.
@@ -14,6 +21,30 @@ type UUIDProvider interface {
 // ErrGeneratingID indicates errors in generating UUID
 var ErrGeneratingID = errors.New("generating id failed")
 
+// ErrUnknownProvider indicates a provider name NewProvider does not know
+var ErrUnknownProvider = errors.NewKind(errors.Invalid, "unknown id provider, use uuidv4, uuidv7 or ulid")
+
+// Names of the providers known to NewProvider
+const (
+	UUIDv4 = "uuidv4"
+	UUIDv7 = "uuidv7"
+	ULID   = "ulid"
+)
+
+// NewProvider returns the provider called name
+func NewProvider(name string) (UUIDProvider, error) {
+	switch name {
+	case UUIDv4:
+		return New(), nil
+	case UUIDv7:
+		return NewV7(), nil
+	case ULID:
+		return NewULID(), nil
+	}
+
+	return nil, ErrUnknownProvider
+}
+
 var _ UUIDProvider = (*uuidProvider)(nil)
 
 type uuidProvider struct{}
@@ -32,3 +63,188 @@ func (up *uuidProvider) ID() (string, error) {
 
 	return id.String(), nil
 }
+
+// clock generates the 48 bit millisecond timestamp and the random bits of
+// time-ordered ids. Ids generated within the same millisecond increment the
+// random bits of the previous one so that they still sort in order.
+type clock struct {
+	mu   sync.Mutex
+	now  func() time.Time
+	rand io.Reader
+	bits uint
+	ms   uint64
+	last []byte
+}
+
+func newClock(bits uint) *clock {
+	return &clock{now: time.Now, rand: rand.Reader, bits: bits}
+}
+
+// next returns the timestamp and the random bits of the next id, the bits
+// are right aligned in big endian bytes.
+func (c *clock) next() (uint64, []byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := int(c.bits+7) / 8
+	top := byte(uint(1)<<(c.bits-8*uint(n-1)) - 1)
+
+	ms := uint64(c.now().UnixNano() / int64(time.Millisecond))
+
+	//same millisecond, or the clock went back: keep the last timestamp
+	if ms <= c.ms && c.last != nil {
+		if increment(c.last) && c.last[0] <= top {
+			return c.ms, append([]byte(nil), c.last...), nil
+		}
+		ms = c.ms + 1
+	}
+
+	random := make([]byte, n)
+	if _, err := io.ReadFull(c.rand, random); err != nil {
+		return 0, nil, errors.Wrap(ErrGeneratingID, err)
+	}
+	//the top bit is left clear so that increments rarely overflow
+	random[0] &= top >> 1
+
+	c.ms, c.last = ms, random
+	return c.ms, append([]byte(nil), random...), nil
+}
+
+// increment adds one to the big endian b, it reports false on overflow
+func increment(b []byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+var _ UUIDProvider = (*v7Provider)(nil)
+
+type v7Provider struct {
+	clock *clock
+}
+
+// NewV7 instantiates a provider of version 7 UUIDs, they start with the time
+// they were generated at in milliseconds and sort in the order they were
+// generated in.
+func NewV7() UUIDProvider {
+	return &v7Provider{clock: newClock(74)}
+}
+
+func (vp *v7Provider) ID() (string, error) {
+	//48 bits of timestamp, the version, 12 random bits, the variant and 62
+	//random bits
+	ms, random, err := vp.clock.next()
+	if err != nil {
+		return "", err
+	}
+
+	hi := uint64(binary.BigEndian.Uint16(random[:2]))
+	lo := binary.BigEndian.Uint64(random[2:])
+	randA := hi<<2 | lo>>62
+	randB := lo & (1<<62 - 1)
+
+	var id [16]byte
+	binary.BigEndian.PutUint64(id[:8], ms<<16|0x7000|randA)
+	binary.BigEndian.PutUint64(id[8:], 1<<63|randB)
+
+	return format(id), nil
+}
+
+// format returns the canonical 8-4-4-4-12 form of id
+func format(id [16]byte) string {
+	buf := make([]byte, 36)
+	hex.Encode(buf[0:8], id[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], id[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], id[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], id[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], id[10:])
+
+	return string(buf)
+}
+
+// crockford is the alphabet of ULIDs, it leaves out I, L, O and U
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+var _ UUIDProvider = (*ulidProvider)(nil)
+
+type ulidProvider struct {
+	clock *clock
+}
+
+// NewULID instantiates a provider of ULIDs, 26 character identifiers that
+// start with the time they were generated at in milliseconds and sort in
+// the order they were generated in.
+func NewULID() UUIDProvider {
+	return &ulidProvider{clock: newClock(80)}
+}
+
+func (up *ulidProvider) ID() (string, error) {
+	ms, random, err := up.clock.next()
+	if err != nil {
+		return "", err
+	}
+
+	var id [16]byte
+	binary.BigEndian.PutUint64(id[:8], ms<<16)
+	copy(id[6:], random)
+
+	return encodeULID(id), nil
+}
+
+// encodeULID encodes the 128 bits of id in 26 base32 characters, the first
+// one only carries 3 bits.
+func encodeULID(id [16]byte) string {
+	buf := make([]byte, 26)
+	//the 128 bits are left padded to 130
+	var acc uint64
+	bits := uint(2)
+	i := 0
+	for _, b := range id {
+		acc = acc<<8 | uint64(b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			buf[i] = crockford[(acc>>bits)&0x1f]
+			i++
+		}
+	}
+
+	return string(buf)
+}
+
+var _ UUIDProvider = (*seededProvider)(nil)
+
+type seededProvider struct {
+	mu   sync.Mutex
+	rand *mrand.Rand
+}
+
+// NewSeeded instantiates a provider of version 4 UUIDs drawn from a
+// pseudo-random source seeded with seed, providers with the same seed
+// generate the same ids in the same order. It is meant for tests and
+// fixtures, never for ids that must not be guessed.
+func NewSeeded(seed int64) UUIDProvider {
+	return &seededProvider{rand: mrand.New(mrand.NewSource(seed))}
+}
+
+func (sp *seededProvider) ID() (string, error) {
+	var id [16]byte
+
+	sp.mu.Lock()
+	sp.rand.Read(id[:])
+	sp.mu.Unlock()
+
+	id[6] = 0x40 | id[6]&0x0f
+	id[8] = 0x80 | id[8]&0x3f
+
+	return format(id), nil
+}
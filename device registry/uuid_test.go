@@ -0,0 +1,71 @@
+package registry_test
+
+import (
+	"regexp"
+	"sort"
+	"testing"
+
+	"github.com/piusalfred/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	uuidV4 = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	uuidV7 = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	ulid   = regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`)
+)
+
+func TestProviders(t *testing.T) {
+	cases := []struct {
+		desc    string
+		name    string
+		pattern *regexp.Regexp
+		ordered bool
+	}{
+		{desc: "random UUIDs", name: registry.UUIDv4, pattern: uuidV4},
+		{desc: "version 7 UUIDs", name: registry.UUIDv7, pattern: uuidV7, ordered: true},
+		{desc: "ULIDs", name: registry.ULID, pattern: ulid, ordered: true},
+	}
+
+	for _, tc := range cases {
+		provider, err := registry.NewProvider(tc.name)
+		require.Nil(t, err, tc.desc)
+
+		//enough ids for many of them to share a millisecond
+		ids := make([]string, 10000)
+		seen := make(map[string]bool, len(ids))
+		for i := range ids {
+			id, err := provider.ID()
+			require.Nil(t, err, tc.desc)
+			require.Regexp(t, tc.pattern, id, tc.desc)
+			require.False(t, seen[id], "%s: duplicate id %s", tc.desc, id)
+
+			ids[i] = id
+			seen[id] = true
+		}
+
+		if tc.ordered {
+			assert.True(t, sort.StringsAreSorted(ids), "%s: ids do not sort in the order they were generated in", tc.desc)
+		}
+	}
+
+	_, err := registry.NewProvider("snowflake")
+	assert.Equal(t, registry.ErrUnknownProvider, err)
+}
+
+func TestSeeded(t *testing.T) {
+	a, b := registry.NewSeeded(42), registry.NewSeeded(42)
+	other := registry.NewSeeded(7)
+
+	for i := 0; i < 5; i++ {
+		x, err := a.ID()
+		require.Nil(t, err)
+		y, _ := b.ID()
+		z, _ := other.ID()
+
+		assert.Regexp(t, uuidV4, x)
+		assert.Equal(t, x, y, "providers with the same seed generate other ids")
+		assert.NotEqual(t, x, z, "providers with other seeds generate the same ids")
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
 const (
@@ -55,6 +56,17 @@ const (
 
 	defLogLevel = "debug"
 	envLogLevel = "IGRID_LOG_LEVEL"
+
+	// node types in addition to the ones of the catalogue, ids are the
+	// ones of the registry e.g "4:publish;5:publish,subscribe"
+	defNodeTypes = ""
+	envNodeTypes = "IGRID_NODE_TYPES"
+
+	// registry the node types catalogue is loaded from and node keys are
+	// checked with, the gateway knows sensor, actuator and controller and
+	// checks keys with the users service when it is not set
+	defRegistryURL = ""
+	envRegistryURL = "IGRID_REGISTRY_URL"
 )
 
 const (
@@ -130,7 +142,27 @@ func main() {
 		log.Fatalf(err.Error())
 	}
 
-	h := mq.New(logger, us)
+	types := mq.DefaultCatalogue
+	if cfg.registryURL != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		types, err = mq.LoadCatalogue(ctx, http.DefaultClient, cfg.registryURL)
+		cancel()
+		if err != nil {
+			log.Fatalf("could not load node types from the registry %v\n", err)
+		}
+	}
+
+	types, err = types.Parse(cfg.nodeTypes)
+	if err != nil {
+		log.Fatalf("could not load node types %v\n", err)
+	}
+
+	var keys mq.Authenticator
+	if cfg.registryURL != "" {
+		keys = mq.NewRegistryAuthenticator(http.DefaultClient, cfg.registryURL)
+	}
+
+	h := mq.New(logger, us, types, keys)
 	errs := make(chan error, 3)
 
 	srv := &http.Server{
@@ -175,6 +207,9 @@ type config struct {
 	mqttTargetPort string
 
 	logLevel string
+
+	nodeTypes   string
+	registryURL string
 }
 
 func envRead(key, fallback string) string {
@@ -205,6 +240,10 @@ func loadConfig() config {
 
 		// Log
 		logLevel: envRead(envLogLevel, defLogLevel),
+
+		// Node types
+		nodeTypes:   envRead(envNodeTypes, defNodeTypes),
+		registryURL: envRead(envRegistryURL, defRegistryURL),
 	}
 }
 
@@ -3,6 +3,7 @@ package mq
 import (
 	"fmt"
 	"github.com/igridnet/users/models"
+	"strconv"
 	"strings"
 )
 
@@ -11,68 +12,170 @@ const (
 	SubscribeOperation = 2
 )
 
+// Capability is an operation nodes of a certain type are allowed to perform,
+// it mirrors the capabilities kept in the registry node types catalogue
+type Capability string
+
+const (
+	// Publish allows a node to publish in its own topic
+	Publish Capability = "publish"
+	// Subscribe allows a node to subscribe to its own topic
+	Subscribe Capability = "subscribe"
+	// Command allows a node to publish and subscribe to all the topics
+	// of its region
+	Command Capability = "command"
+)
+
+// Catalogue maps the ids of the node types in the registry catalogue to the
+// capabilities of the nodes of that type
+type Catalogue map[int][]Capability
+
+// ids of the node types every registry starts with
+const (
+	SensorType     = 1
+	ActuatorType   = 2
+	ControllerType = 3
+)
+
+// DefaultCatalogue contains the node types known to every gateway
+var DefaultCatalogue = Catalogue{
+	SensorType:     {Publish},
+	ActuatorType:   {Subscribe},
+	ControllerType: {Publish, Subscribe, Command},
+}
+
+// RegistryType returns the id in the registry catalogue of the node type t,
+// the users service numbers sensor, actuator and controller from zero
+func RegistryType(t models.NodeType) int {
+	return int(t) + 1
+}
+
+// Register adds a node type with its capabilities to the catalogue, an
+// already registered type gets its capabilities replaced
+func (c Catalogue) Register(typ int, caps ...Capability) {
+	c[typ] = caps
+}
+
+// ParseCatalogue reads node types in the form "<type>:<cap>,<cap>;<type>:<cap>"
+// e.g "4:publish;5:publish,subscribe" and adds them to the DefaultCatalogue
+func ParseCatalogue(spec string) (Catalogue, error) {
+	return DefaultCatalogue.Parse(spec)
+}
+
+// Parse returns a copy of the catalogue with the node types of spec added,
+// see ParseCatalogue for the format of spec
+func (c Catalogue) Parse(spec string) (Catalogue, error) {
+	catalogue := Catalogue{}
+	for typ, caps := range c {
+		catalogue.Register(typ, caps...)
+	}
+
+	for _, entry := range strings.Split(spec, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, ":", 2)
+		typ, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+		if err != nil || len(parts) != 2 {
+			return nil, fmt.Errorf("invalid node type entry %q, use <type>:<cap>,<cap>", entry)
+		}
+
+		var caps []Capability
+		for _, c := range strings.Split(parts[1], ",") {
+			capability := Capability(strings.TrimSpace(c))
+			if !capability.valid() {
+				return nil, fmt.Errorf("unrecognized capability %q for node type %d", c, typ)
+			}
+			caps = append(caps, capability)
+		}
+
+		catalogue.Register(typ, caps...)
+	}
+
+	return catalogue, nil
+}
+
+func (c Capability) valid() bool {
+	return c == Publish || c == Subscribe || c == Command
+}
+
+func (c Catalogue) can(typ int, capability Capability) bool {
+	for _, cp := range c[typ] {
+		if cp == capability {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Authorize check if a certain node is allowed to make either publish or
+// subscribe operation in a certain region comm channel using the node types
+// of the DefaultCatalogue
+func Authorize(node models.Node, topic string, operation int) (bool, error) {
+	return DefaultCatalogue.Authorize(node, topic, operation)
+}
+
 // Authorize check if a certain node is allowed to make either publish or
-// subscribe operation in a certain region comm channel
-// Actuator are allowed to subscribe only, Sensors can only publish. Controller
-// nodes can do both they can publish or subscribe to all communication channels
-// of the region
-func Authorize(node models.Node,topic string, operation int)(bool,error){
+// subscribe operation in a certain region comm channel.
+// Nodes whose type has the publish capability can publish to their own topic,
+// the ones with subscribe capability can subscribe to their own topic. Nodes
+// with command capability (controllers) can publish or subscribe to all
+// communication channels of the region as long as they have the capability
+// for the operation
+func (c Catalogue) Authorize(node models.Node, topic string, operation int) (bool, error) {
 	var (
 		region string
 		nodeId string
 	)
 
 	split := strings.Split(topic, "/")
-	if len(split) > 2{
+	if len(split) > 2 {
 		return false, fmt.Errorf("topic should be specified as -t <region-id>/<node-id> or just <region>")
 	}
-	nodeType := models.NodeType(node.Type)
-
-	if nodeType == models.ControllerNode{
-		if strings.TrimSpace(topic) == ""{
-			return false, fmt.Errorf("can not publish to null topic")
-		}
-
-		region = split[0]
-		if node.Region != region{
-			return false, fmt.Errorf("nodes are not allowed to publish/subscribe outsied their region")
-		}
 
-		return true,nil
+	typ := RegistryType(models.NodeType(node.Type))
+	if _, known := c[typ]; !known {
+		return false, fmt.Errorf("unrecognized node type %d", typ)
 	}
-	if nodeType != models.ControllerNode && len(split)<2{
-		return false, fmt.Errorf("not allowed to perform any operation in this topic use format <region-id>/<node-id>")
+
+	if strings.TrimSpace(topic) == "" {
+		return false, fmt.Errorf("can not publish to null topic")
 	}
 
-	if nodeType == models.ActuatorNode{
-		if operation == PublishOperation{
-			return false,fmt.Errorf("actuators are not allowed to publish, they can only subscribe")
+	switch operation {
+	case PublishOperation:
+		if !c.can(typ, Publish) {
+			return false, fmt.Errorf("nodes of type %d are not allowed to publish", typ)
 		}
 
-		if operation == SubscribeOperation{
-			region, nodeId = split[0],split[1]
-			if node.Region != region || node.UUID != nodeId{
-				return false,fmt.Errorf("nodes should only subscribes in the topic(node-id) of their region")
-			}
+	case SubscribeOperation:
+		if !c.can(typ, Subscribe) {
+			return false, fmt.Errorf("nodes of type %d are not allowed to subscribe", typ)
 		}
 
-		return true,nil
+	default:
+		return false, fmt.Errorf("unrecognized operation %d", operation)
 	}
 
-	if nodeType == models.SensorNode && operation == SubscribeOperation{
-		if operation == SubscribeOperation{
-			return false,fmt.Errorf("sensor nodes are not allowed to subscribe, they can only publish")
-		}
+	region = split[0]
+	if node.Region != region {
+		return false, fmt.Errorf("nodes are not allowed to publish/subscribe outsied their region")
+	}
 
-		if operation == PublishOperation{
-			region, nodeId = split[0],split[1]
-			if node.Region != region || node.UUID != nodeId{
-				return false,fmt.Errorf("nodes should only publish in the topic(node-id) of their region")
-			}
-		}
-		return true,nil
+	if c.can(typ, Command) {
+		return true, nil
 	}
 
-	return true,nil
-}
+	if len(split) < 2 {
+		return false, fmt.Errorf("not allowed to perform any operation in this topic use format <region-id>/<node-id>")
+	}
 
+	nodeId = split[1]
+	if node.UUID != nodeId {
+		return false, fmt.Errorf("nodes should only publish/subscribe in the topic(node-id) of their region")
+	}
+
+	return true, nil
+}
@@ -1,7 +1,12 @@
 package mq
 
 import (
+	"context"
+	"encoding/json"
 	"github.com/igridnet/users/models"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
 	"testing"
 )
 
@@ -18,28 +23,77 @@ func TestAuthorize(t *testing.T) {
 		wantErr bool
 	}{
 		{
-			name:    "",
-			args:    args{
-				node:      models.Node{
-					UUID:    "temperature",
-					Addr:    "",
-					Key:     "password",
-					Name:    "",
-					Type:    int(models.ActuatorNode),
-					Region:  "region",
-					Latd:    "",
-					Long:    "",
-					Created: 0,
-					Master:  "",
-				},
+			name: "actuator subscribing to the topic of another node",
+			args: args{
+				node:      models.Node{UUID: "temperature", Type: int(models.ActuatorNode), Region: "region"},
 				topic:     "region/temperaturet",
 				operation: SubscribeOperation,
 			},
 			want:    false,
-			wantErr:false,
+			wantErr: true,
+		},
+		{
+			name: "actuator subscribing to its own topic",
+			args: args{
+				node:      models.Node{UUID: "temperature", Type: int(models.ActuatorNode), Region: "region"},
+				topic:     "region/temperature",
+				operation: SubscribeOperation,
+			},
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name: "actuator publishing",
+			args: args{
+				node:      models.Node{UUID: "temperature", Type: int(models.ActuatorNode), Region: "region"},
+				topic:     "region/temperature",
+				operation: PublishOperation,
+			},
+			want:    false,
+			wantErr: true,
+		},
+		{
+			name: "sensor publishing to its own topic",
+			args: args{
+				node:      models.Node{UUID: "meter", Type: int(models.SensorNode), Region: "region"},
+				topic:     "region/meter",
+				operation: PublishOperation,
+			},
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name: "sensor subscribing",
+			args: args{
+				node:      models.Node{UUID: "meter", Type: int(models.SensorNode), Region: "region"},
+				topic:     "region/meter",
+				operation: SubscribeOperation,
+			},
+			want:    false,
+			wantErr: true,
+		},
+		{
+			name: "controller subscribing to the region",
+			args: args{
+				node:      models.Node{UUID: "controller", Type: int(models.ControllerNode), Region: "region"},
+				topic:     "region",
+				operation: SubscribeOperation,
+			},
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name: "controller publishing outside its region",
+			args: args{
+				node:      models.Node{UUID: "controller", Type: int(models.ControllerNode), Region: "region"},
+				topic:     "other/meter",
+				operation: PublishOperation,
+			},
+			want:    false,
+			wantErr: true,
 		},
 	}
-		for _, tt := range tests {
+	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			got, err := Authorize(tt.args.node, tt.args.topic, tt.args.operation)
 			if (err != nil) != tt.wantErr {
@@ -51,4 +105,155 @@ func TestAuthorize(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestParseCatalogue(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    Catalogue
+		wantErr bool
+	}{
+		{
+			name: "empty spec",
+			spec: "",
+			want: DefaultCatalogue,
+		},
+		{
+			name: "additional node types",
+			spec: "4:publish; 5:publish,subscribe",
+			want: Catalogue{
+				SensorType:     {Publish},
+				ActuatorType:   {Subscribe},
+				ControllerType: {Publish, Subscribe, Command},
+				4:              {Publish},
+				5:              {Publish, Subscribe},
+			},
+		},
+		{
+			name: "replaced node type",
+			spec: "1:subscribe;",
+			want: Catalogue{
+				SensorType:     {Subscribe},
+				ActuatorType:   {Subscribe},
+				ControllerType: {Publish, Subscribe, Command},
+			},
+		},
+		{
+			name:    "missing capabilities",
+			spec:    "4",
+			wantErr: true,
+		},
+		{
+			name:    "invalid type",
+			spec:    "gateway:publish",
+			wantErr: true,
+		},
+		{
+			name:    "unrecognized capability",
+			spec:    "4:publish,reboot",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCatalogue(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseCatalogue() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseCatalogue() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ParseCatalogue("1:subscribe"); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(DefaultCatalogue[SensorType], []Capability{Publish}) {
+		t.Errorf("ParseCatalogue() changed the DefaultCatalogue")
+	}
+}
+
+func TestLoadCatalogue(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/types" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"node_types":[{"id":1,"name":"sensor","capabilities":["publish"]},` +
+			`{"id":4,"name":"gateway","capabilities":["publish","subscribe"]}],"err":null}`))
+	}))
+	defer srv.Close()
+
+	got, err := LoadCatalogue(context.Background(), srv.Client(), srv.URL+"/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Catalogue{SensorType: {Publish}, 4: {Publish, Subscribe}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadCatalogue() got = %v, want %v", got, want)
+	}
+
+	ok, err := got.Authorize(models.Node{UUID: "meter", Type: int(models.SensorNode), Region: "region"}, "region/meter", PublishOperation)
+	if !ok || err != nil {
+		t.Errorf("Authorize() got = %v, %v, want true", ok, err)
+	}
+
+	if _, err := LoadCatalogue(context.Background(), srv.Client(), srv.URL+"/missing"); err == nil {
+		t.Errorf("LoadCatalogue() of a missing registry did not fail")
+	}
+}
+
+func TestRegistryAuthenticator(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			NodeID string `json:"node_id"`
+			Key    string `json:"key"`
+		}
+		if r.Method != http.MethodPost || r.URL.Path != "/nodes/authenticate" {
+			http.NotFound(w, r)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		switch {
+		case req.NodeID == "broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		case req.NodeID != "meter" || req.Key != "secret":
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			_, _ = w.Write([]byte(`{"node":{"uuid":"meter"},"err":null}`))
+		}
+	}))
+	defer srv.Close()
+
+	keys := NewRegistryAuthenticator(srv.Client(), srv.URL+"/")
+
+	tests := []struct {
+		name    string
+		node    string
+		key     string
+		wantErr error
+	}{
+		{name: "valid key", node: "meter", key: "secret"},
+		{name: "wrong key", node: "meter", key: "guess", wantErr: ErrInvalidKey},
+		{name: "unknown node", node: "other", key: "secret", wantErr: ErrInvalidKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := keys.Authenticate(context.Background(), tt.node, tt.key); err != tt.wantErr {
+				t.Errorf("Authenticate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := keys.Authenticate(context.Background(), "broken", "secret"); err == nil || err == ErrInvalidKey {
+		t.Errorf("Authenticate() error = %v, want a registry error", err)
+	}
+}
@@ -15,20 +15,29 @@ import (
 
 var _ session.Handler = (*Handler)(nil)
 
+// Authenticator checks the key a node connects with
+type Authenticator interface {
+	Authenticate(ctx context.Context, node string, key string) error
+}
+
 // Handler implements mqtt.Handler interface
 type Handler struct {
 	logger logger.Logger
 	users *api.Client
 	writer stdio.Writer
+	types Catalogue
+	keys Authenticator
 }
 
-// New creates new Event entity
-func New(logger logger.Logger,client *api.Client) *Handler {
+// New creates new Event entity, node keys are checked by keys when it is
+// not nil and compared to the ones of the users service otherwise
+func New(logger logger.Logger,client *api.Client, types Catalogue, keys Authenticator) *Handler {
 	return &Handler{
 		logger: logger,
 		users: client,
 		writer: io.Stderr,
-
+		types: types,
+		keys: keys,
 	}
 }
 
@@ -45,6 +54,16 @@ func (h *Handler) AuthConnect(c *session.Client) error {
 	}
 	msg := fmt.Sprintf("\nAuthConnect() request- clientID: %s, username: %s, password: %s, client_CN: %s\n", c.ID, c.Username, string(c.Password), c.Cert.Subject.CommonName)
 	_,_ = h.writer.Write([]byte(msg))
+
+	if h.keys != nil {
+		if err := h.keys.Authenticate(ctx, c.Username, string(c.Password)); err != nil {
+			msg := fmt.Sprintf("could not authenticate the node with id %s due to error: %v\n", c.Username, err)
+			_, _ = h.writer.Write([]byte(msg))
+			return err
+		}
+		return nil
+	}
+
 	node, err := h.users.GetNode(ctx,c.Username)
 	if err != nil {
 		msg := fmt.Sprintf("could not authenticate the node with id %s due to error: %v\n",c.Username,err)
@@ -55,7 +74,7 @@ func (h *Handler) AuthConnect(c *session.Client) error {
 	if node.Key != string(c.Password){
 		msg := fmt.Sprintf("password mismatch, not allowed\n")
 		_,_ = h.writer.Write([]byte(msg))
-		return err
+		return ErrInvalidKey
 	}
 	return nil
 }
@@ -74,7 +93,7 @@ func (h *Handler) AuthPublish(c *session.Client, topic *string, payload *[]byte)
 		return err
 	}
 
-	ok, err := Authorize(node,*topic,PublishOperation)
+	ok, err := h.types.Authorize(node,*topic,PublishOperation)
 	if err != nil {
 		msg := fmt.Sprintf("could not authenticate publish operation by the node with id %s due to error: %v",c.Username,err)
 		_,_ = h.writer.Write([]byte(msg))
@@ -106,7 +125,7 @@ func (h *Handler) AuthSubscribe(c *session.Client, topics *[]string) error {
 
 	tpcs := *topics
 
-	ok, err := Authorize(node,tpcs[0],SubscribeOperation)
+	ok, err := h.types.Authorize(node,tpcs[0],SubscribeOperation)
 	if err != nil {
 		msg := fmt.Sprintf("could not authenticate subscribe operation by the node with id %s due to error: %v",c.Username,err)
 		_,_ = h.writer.Write([]byte(msg))
@@ -0,0 +1,105 @@
+package mq
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrInvalidKey is returned when a node connects with a wrong key
+var ErrInvalidKey = errors.New("invalid node key")
+
+// registryNodeTypes is the response of the node types endpoint of the
+// registry
+type registryNodeTypes struct {
+	NodeTypes []struct {
+		ID           int          `json:"id"`
+		Capabilities []Capability `json:"capabilities"`
+	} `json:"node_types"`
+}
+
+// LoadCatalogue fetches the node types catalogue of the registry at url,
+// e.g "http://registry:8080"
+func LoadCatalogue(ctx context.Context, client *http.Client, url string) (Catalogue, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(url, "/")+"/types", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("registry responded with status %s to the node types request", resp.Status)
+	}
+
+	var types registryNodeTypes
+	if err := json.NewDecoder(resp.Body).Decode(&types); err != nil {
+		return nil, fmt.Errorf("could not decode the node types of the registry: %w", err)
+	}
+
+	catalogue := Catalogue{}
+	for _, t := range types.NodeTypes {
+		for _, c := range t.Capabilities {
+			if !c.valid() {
+				return nil, fmt.Errorf("unrecognized capability %q for node type %d", c, t.ID)
+			}
+		}
+
+		catalogue.Register(t.ID, t.Capabilities...)
+	}
+
+	return catalogue, nil
+}
+
+// RegistryAuthenticator checks node keys with the registry the nodes were
+// provisioned by
+type RegistryAuthenticator struct {
+	client *http.Client
+	url    string
+}
+
+// NewRegistryAuthenticator returns an Authenticator that checks node keys
+// with the registry at url, e.g "http://registry:8080"
+func NewRegistryAuthenticator(client *http.Client, url string) *RegistryAuthenticator {
+	return &RegistryAuthenticator{
+		client: client,
+		url:    strings.TrimSuffix(url, "/"),
+	}
+}
+
+// Authenticate implements Authenticator
+func (a *RegistryAuthenticator) Authenticate(ctx context.Context, node string, key string) error {
+	body, err := json.Marshal(map[string]string{"node_id": node, "key": key})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.url+"/nodes/authenticate", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized:
+		return ErrInvalidKey
+	default:
+		return fmt.Errorf("registry responded with status %s to the node authentication request", resp.Status)
+	}
+}